@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,6 +17,8 @@ import (
 	"servicegomodule/internal/api"
 	"servicegomodule/internal/app"
 	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/buildinfo"
 	"sharedgomodule/logging"
 	"sharedgomodule/utils"
 
@@ -21,111 +26,392 @@ import (
 )
 
 func main() {
+	overrides, err := config.ParseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
 	// Load .env file for local development (ignored in production)
 	loadEnvFile()
 
 	// Log environment info
 	logEnvironmentInfo()
-	cfg := loadConfig()
+	cfg, source := loadConfig(overrides)
 	if cfg == nil {
 		log.Fatal("Failed to load configuration, exiting")
 	}
+	if err := validateConfig(cfg); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
 	logger := initLoggerSettings(cfg)
 	defer logger.Close()
+	logger.Infow("Loaded configuration", "profile", source.Profile, "files", source.Files, "env_overrides", source.EnvOverrides, "flag_overrides", source.FlagOverrides)
+	if source.LoadError != "" {
+		logger.Warnw("Config file not loaded, using defaults", "error", source.LoadError)
+	}
 
 	// Create application instance
 	application := app.NewApplication(cfg, logger)
+	application.SetConfigSource(source)
 
-	// Start the application and its processing pipeline
-	if err := application.Start(); err != nil {
-		logger.Fatalf("Failed to start application: %v", err)
-	}
+	// Initialize handlers and setup HTTP muxes
+	mux, adminMux := setupRouter(cfg, logger, application)
 
-	// Initialize handlers and setup HTTP mux
-	mux := setupRouter(logger)
-
-	// Start server
-	startServer(mux, cfg, application)
+	// Start server; the application is started once the server is listening
+	// so probes see it as not ready until its services are running
+	if err := startServer(mux, adminMux, cfg, application, overrides); err != nil {
+		logger.Errorf("Server exited with error: %v", err)
+		logger.Close()
+		os.Exit(1)
+	}
 }
 
-func setupRouter(logger logging.Logger) *http.ServeMux {
+// setupRouter builds the HTTP mux for the service. When server.adminPort is
+// set the operational routes are served from a separate admin mux, returned
+// second; otherwise the admin mux is nil and every route is on the main mux.
+func setupRouter(cfg *config.RawConfig, logger logging.Logger, application *app.Application) (*http.ServeMux, *http.ServeMux) {
 
 	handler := api.NewHandler(logger)
+	if application != nil {
+		handler.SetReadinessChecker(application)
+		handler.SetHealthReporter(application)
+		handler.Use(api.ApplicationMiddleware(application))
+	}
+	handler.Use(api.AccessLogMiddleware(logger, api.AccessLogOptions{
+		Level:      cfg.Server.AccessLog.LogLevel(),
+		QuietPaths: cfg.Server.AccessLog.QuietPaths,
+	}))
+	corsPolicy := api.NewCORSPolicy(corsOptions(cfg.Server.CORS))
+	handler.Use(corsPolicy.Middleware())
+	rateLimiter := api.NewRateLimiter(rateLimitOptions(cfg.Server.RateLimit, cfg.Server.APIKeys))
+	handler.Use(api.RateLimitMiddleware(rateLimiter))
+	if application != nil {
+		// Pick up CORS and rate limit changes on configuration reload
+		application.OnConfigChange(func(old, new *config.RawConfig) {
+			corsPolicy.Update(corsOptions(new.Server.CORS))
+			if new.Server.RateLimit != old.Server.RateLimit {
+				rateLimiter.SetOptions(rateLimitOptions(new.Server.RateLimit, cfg.Server.APIKeys))
+			}
+		})
+	}
+	if len(cfg.Server.APIKeys) == 0 {
+		logger.Warn("No API keys configured, /api/ routes are unauthenticated")
+	}
+	handler.Use(api.APIKeyAuthMiddleware(cfg.Server.APIKeys))
+	handler.Use(api.BodyLimitMiddleware(cfg.Server.MaxBodyBytes))
+	if cfg.Server.EnableMetrics && application != nil {
+		handler.SetMetrics(application.Metrics())
+	}
 	mux := http.NewServeMux()
 
 	// Setup routes
-	handler.SetupRoutes(mux)
+	if cfg.Server.AdminPort == 0 {
+		handler.SetupRoutes(mux)
+		if cfg.Server.EnableDebug {
+			logger.Warn("Debug endpoints enabled under /debug/")
+			handler.SetupDebugRoutes(mux)
+		}
+		return mux, nil
+	}
+
+	handler.SetupAPIRoutes(mux)
+	adminMux := http.NewServeMux()
+	handler.SetupAdminRoutes(adminMux)
+	if cfg.Server.EnableDebug {
+		logger.Warn("Debug endpoints enabled under /debug/ on the admin port")
+		handler.SetupDebugRoutes(adminMux)
+	}
+	return mux, adminMux
+}
+
+func corsOptions(cfg config.RawCORSConfig) api.CORSOptions {
+	return api.CORSOptions{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		MaxAge:           cfg.MaxAge,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+}
 
-	return mux
+// rateLimitOptions returns the limiter options for cfg. apiKeys are the keys
+// the authentication middleware accepts, which alone get buckets of their own.
+func rateLimitOptions(cfg config.RawRateLimitConfig, apiKeys []string) api.RateLimitOptions {
+	return api.RateLimitOptions{
+		RequestsPerSecond: cfg.RequestsPerSecond,
+		Burst:             cfg.Burst,
+		ByAPIKey:          cfg.ByAPIKey,
+		APIKeys:           apiKeys,
+		IdleTimeout:       time.Duration(cfg.IdleTimeout) * time.Second,
+	}
 }
 
-func startServer(mux *http.ServeMux, cfg *config.RawConfig, application *app.Application) {
+// startServer serves mux, and adminMux on the admin port when it is not nil,
+// until SIGINT/SIGTERM and then shuts down the servers and application,
+// returning an error if any did not stop cleanly. SIGHUP reloads the
+// configuration file without stopping.
+func startServer(mux, adminMux *http.ServeMux, cfg *config.RawConfig, application *app.Application, overrides config.Overrides) error {
 	logger := application.Logger()
 
-	// Create server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
-		Handler:      mux,
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+	srv := newHTTPServer(cfg.Server, mux)
+
+	// Bind up front so a busy port fails startup instead of a background goroutine
+	ln, err := listenServer(cfg.Server, logger)
+	if err != nil {
+		return listenFailed(listenAddr(cfg.Server), err, logger)
 	}
 
-	// Start server in a goroutine
-	go func() {
-		logger.Debugf("Starting http server on %s:%d", cfg.Server.Host, cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("Failed to start server: %v", err)
+	var adminSrv *http.Server
+	if adminMux != nil {
+		adminSrv = newHTTPServer(cfg.Server, adminMux)
+		adminSrv.Addr = adminAddr(cfg.Server)
+		adminLn, err := listen(adminSrv.Addr, cfg.Server.BindRetries, logger)
+		if err != nil {
+			ln.Close()
+			return listenFailed(adminSrv.Addr, err, logger)
 		}
-	}()
+		go serve(adminSrv, adminLn, "admin", logger)
+	}
+	go serve(srv, ln, "http", logger)
+
+	// Start the application and its processing pipeline
+	if err := application.Start(); err != nil {
+		logger.Fatalf("Failed to start application: %v", err)
+		// Reached only when the logger's ExitFunc returns, as in tests
+		srv.Close()
+		if adminSrv != nil {
+			adminSrv.Close()
+		}
+		return fmt.Errorf("start application: %w", err)
+	}
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+waitLoop:
+	for {
+		select {
+		case <-hup:
+			reloadConfig(application, overrides)
+		case <-quit:
+			break waitLoop
+		}
+	}
 
 	logger.Info("Shutting down application ...")
 
-	// Give outstanding requests a 10-second deadline to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Give outstanding requests a bounded deadline to complete
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout(cfg.Server))
 	defer cancel()
 
-	// Shutdown the server
+	var errs []error
+
+	// Shutdown the server, then the admin server so probes answer while
+	// requests drain
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
+		errs = append(errs, fmt.Errorf("server shutdown: %w", err))
+	}
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Errorf("Admin server forced to shutdown: %v", err)
+			errs = append(errs, fmt.Errorf("admin server shutdown: %w", err))
+		}
+	}
+	// Closing the listener normally unlinks the socket; make sure it is gone
+	if cfg.Server.IsUnix() {
+		if err := os.Remove(cfg.Server.SocketPath); err != nil && !os.IsNotExist(err) {
+			logger.Warnw("Failed to remove socket file", "path", cfg.Server.SocketPath, "error", err)
+		}
 	}
 
 	// Shutdown the application
 	if err := application.Shutdown(); err != nil {
 		logger.Errorf("Application shutdown error: %v", err)
+		errs = append(errs, fmt.Errorf("application shutdown: %w", err))
 	}
 
 	logger.Info("Server exited")
+	return errors.Join(errs...)
+}
+
+// serve runs srv on ln until it is shut down
+func serve(srv *http.Server, ln net.Listener, name string, logger logging.Logger) {
+	logger.Debugf("Starting %s server on %s", name, ln.Addr())
+	if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+		logger.Fatalf("HTTP %s server failed: %v", name, err)
+	}
+}
+
+// listenFailed reports a bind failure on stderr and in the log and returns
+// the error for startServer
+func listenFailed(addr string, err error, logger logging.Logger) error {
+	fmt.Fprintf(os.Stderr, "Failed to listen on %s: %v\n", addr, err)
+	logger.Errorw("Failed to listen", "addr", addr, "error", err)
+	return fmt.Errorf("listen on %s: %w", addr, err)
+}
+
+// adminAddr returns the admin listener address. The admin listener is always
+// TCP on server.host, even when the main listener is a Unix socket.
+func adminAddr(cfg config.RawServerConfig) string {
+	return fmt.Sprintf("%s:%d", cfg.Host, cfg.AdminPort)
+}
+
+// listenServer creates the listener selected by server.listen
+func listenServer(cfg config.RawServerConfig, logger logging.Logger) (net.Listener, error) {
+	if cfg.IsUnix() {
+		mode, err := cfg.SocketFileMode()
+		if err != nil {
+			return nil, err
+		}
+		return listenUnix(cfg.SocketPath, mode)
+	}
+	return listen(fmt.Sprintf("%s:%d", cfg.Host, cfg.Port), cfg.BindRetries, logger)
+}
+
+// listenAddr describes the configured listen address for messages
+func listenAddr(cfg config.RawServerConfig) string {
+	if cfg.IsUnix() {
+		return "unix:" + cfg.SocketPath
+	}
+	return fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+}
+
+// listenUnix listens on a Unix domain socket at path with the given
+// permissions, replacing a stale socket left by a previous run. Any other
+// kind of file at path is left alone and reported as an error. The socket
+// file is removed when the listener is closed.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("set socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// bindRetryDelay is the wait before the first bind retry; it doubles after each attempt
+var bindRetryDelay = 500 * time.Millisecond
+
+// listen binds addr, retrying with exponential backoff up to retries more
+// times while the address is in use, e.g. by a previous instance still
+// shutting down. Other bind errors fail immediately.
+func listen(addr string, retries int, logger logging.Logger) (net.Listener, error) {
+	delay := bindRetryDelay
+	for attempt := 0; ; attempt++ {
+		ln, err := net.Listen("tcp", addr)
+		if err == nil || !errors.Is(err, syscall.EADDRINUSE) || attempt >= retries {
+			return ln, err
+		}
+		logger.Warnw("Address in use, retrying", "addr", addr, "attempt", attempt+1, "retries", retries, "delay", delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// defaultShutdownTimeout bounds srv.Shutdown when server.shutdownTimeout is unset
+const defaultShutdownTimeout = 10 * time.Second
+
+// newHTTPServer builds the HTTP server from the server configuration. Zero
+// values leave the corresponding net/http default in place.
+func newHTTPServer(cfg config.RawServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.IdleTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout) * time.Second,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// shutdownTimeout returns the configured graceful shutdown deadline
+func shutdownTimeout(cfg config.RawServerConfig) time.Duration {
+	if cfg.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return time.Duration(cfg.ShutdownTimeout) * time.Second
 }
 
-func loadConfig() *config.RawConfig {
-	// Load configuration using absolute paths based on SERVICE_HOME environment variable
+// loadConfig loads the service configuration. Command-line flags take
+// precedence over environment variables, which take precedence over the
+// config file and then the built-in defaults.
+func loadConfig(overrides config.Overrides) (*config.RawConfig, config.Source) {
+	return config.LoadConfigWithSource(configPath(overrides), overrides)
+}
+
+// configPath returns the --config path if given, otherwise the centralized
+// config file location under SERVICE_HOME
+func configPath(overrides config.Overrides) string {
+	if overrides.ConfigPath != "" {
+		return overrides.ConfigPath
+	}
 	homeDir := os.Getenv("SERVICE_HOME")
 	if homeDir == "" {
 		log.Fatal("SERVICE_HOME environment variable is required and must point to the repository root")
 	}
+	return filepath.Join(homeDir, "conf", "config.yaml")
+}
 
-	// Load configuration from the centralized config file
-	configPath := filepath.Join(homeDir, "conf", "config.yaml")
+// reloadConfig re-reads the config file and applies it to the running
+// application. A file that cannot be read or fails validation is logged and
+// the current configuration stays in effect.
+func reloadConfig(application *app.Application, overrides config.Overrides) {
+	logger := application.Logger()
+	logger.Info("Received SIGHUP, reloading configuration")
 
-	return config.LoadConfigWithDefaults(configPath)
+	cfg, source := config.LoadConfigWithSource(configPath(overrides), overrides)
+	if source.LoadError != "" {
+		logger.Errorw("Failed to reload configuration", "error", source.LoadError)
+		return
+	}
+	resolveLogPaths(cfg)
+	if err := application.Reload(cfg); err != nil {
+		logger.Errorw("Failed to reload configuration", "error", err)
+		return
+	}
+	application.SetConfigSource(source)
+}
+
+// validateConfig reports every problem with the service configuration,
+// including the processing pipeline settings derived from it
+func validateConfig(cfg *config.RawConfig) error {
+	var errs []error
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := processing.ValidateConfig(processing.DefaultConfig(cfg)); err != nil {
+		errs = append(errs, fmt.Errorf("processing: %w", err))
+	}
+	return errors.Join(errs...)
 }
 
 func initLoggerSettings(cfg *config.RawConfig) logging.Logger {
 	// create the log directory path if it does not exist
 	os.MkdirAll(utils.GetEnv("SERVICE_LOG_DIR", ""), 0755)
 
-	// update all log file paths to absolute paths
-	logDir := os.Getenv("SERVICE_LOG_DIR")
-	if logDir != "" && !filepath.IsAbs(cfg.Logging.FileName) {
-		cfg.Logging.FileName = filepath.Join(logDir, cfg.Logging.FileName)
-		cfg.Processing.PloggerConfig.FileName = filepath.Join(logDir, cfg.Processing.PloggerConfig.FileName)
-	}
+	resolveLogPaths(cfg)
 
 	// Convert config logging configuration to logger config
 	loggerConfig := cfg.Logging.ConvertToLoggerConfig()
@@ -137,6 +423,15 @@ func initLoggerSettings(cfg *config.RawConfig) logging.Logger {
 	return logger
 }
 
+// resolveLogPaths makes relative log file paths absolute under SERVICE_LOG_DIR
+func resolveLogPaths(cfg *config.RawConfig) {
+	logDir := os.Getenv("SERVICE_LOG_DIR")
+	if logDir != "" && !filepath.IsAbs(cfg.Logging.FileName) {
+		cfg.Logging.FileName = filepath.Join(logDir, cfg.Logging.FileName)
+		cfg.Processing.PloggerConfig.FileName = filepath.Join(logDir, cfg.Processing.PloggerConfig.FileName)
+	}
+}
+
 // loadEnvFile loads .env file for local development
 // In production (Docker/K8s), environment variables are set directly
 func loadEnvFile() {
@@ -188,7 +483,7 @@ func isRunningInContainer() bool {
 func logEnvironmentInfo() {
 	appEnv := getEnvWithDefault("APP_ENV", "production")
 	appName := getEnvWithDefault("APP_NAME", "cratos")
-	appVersion := getEnvWithDefault("APP_VERSION", "unknown")
+	appVersion := getEnvWithDefault("APP_VERSION", buildinfo.VersionString())
 
 	log.Printf("🚀 Starting %s v%s in %s environment", appName, appVersion, appEnv)
 
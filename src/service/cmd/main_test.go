@@ -2,8 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,45 +31,10 @@ const (
 	nonexistentPath = "/nonexistent"
 )
 
-// Mock logger for testing
-type mockLogger struct{}
-
-func (m *mockLogger) SetLevel(level logging.Level)                           { /* no-op for testing */ }
-func (m *mockLogger) GetLevel() logging.Level                                { return logging.InfoLevel }
-func (m *mockLogger) IsLevelEnabled(level logging.Level) bool                { return true }
-func (m *mockLogger) Debug(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Info(msg string)                                        { /* no-op for testing */ }
-func (m *mockLogger) Warn(msg string)                                        { /* no-op for testing */ }
-func (m *mockLogger) Error(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Fatal(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Panic(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Debugf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Infof(format string, args ...interface{})               { /* no-op for testing */ }
-func (m *mockLogger) Warnf(format string, args ...interface{})               { /* no-op for testing */ }
-func (m *mockLogger) Errorf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Fatalf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Panicf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Debugw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Infow(msg string, keysAndValues ...interface{})         { /* no-op for testing */ }
-func (m *mockLogger) Warnw(msg string, keysAndValues ...interface{})         { /* no-op for testing */ }
-func (m *mockLogger) Errorw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Fatalw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Panicw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) WithFields(fields logging.Fields) logging.Logger        { return m }
-func (m *mockLogger) WithField(key string, value interface{}) logging.Logger { return m }
-func (m *mockLogger) WithError(err error) logging.Logger                     { return m }
-func (m *mockLogger) WithContext(ctx context.Context) logging.Logger         { return m }
-func (m *mockLogger) Log(level logging.Level, msg string)                    { /* no-op for testing */ }
-func (m *mockLogger) Logf(level logging.Level, format string, args ...interface{}) { /* no-op for testing */
-}
-func (m *mockLogger) Logw(level logging.Level, msg string, keysAndValues ...interface{}) { /* no-op for testing */
-}
-func (m *mockLogger) Clone() logging.Logger { return &mockLogger{} }
-func (m *mockLogger) Close() error          { return nil }
-
 func TestSetupRouter(t *testing.T) {
-	logger := &mockLogger{}
-	mux := setupRouter(logger)
+	logger := logging.NewNopLogger()
+	cfg := config.LoadConfig()
+	mux, _ := setupRouter(cfg, logger, app.NewApplication(cfg, logger))
 
 	if mux == nil {
 		t.Fatal("expected mux to not be nil")
@@ -96,11 +67,66 @@ func TestSetupRouter(t *testing.T) {
 	}
 }
 
+func TestSetupRouterDebugEndpoints(t *testing.T) {
+	debugPaths := []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/vars"}
+
+	testCases := []struct {
+		name           string
+		enableDebug    bool
+		expectedStatus int
+	}{
+		{"disabled", false, http.StatusNotFound},
+		{"enabled", true, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.LoadConfig()
+			cfg.Server.EnableDebug = tc.enableDebug
+			mux, _ := setupRouter(cfg, logging.NewNopLogger(), nil)
+
+			for _, path := range debugPaths {
+				rr := httptest.NewRecorder()
+				mux.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+				if rr.Code != tc.expectedStatus {
+					t.Errorf("expected status %d for %s, got %d", tc.expectedStatus, path, rr.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestSetupRouterMetricsEndpoint(t *testing.T) {
+	testCases := []struct {
+		name           string
+		enableMetrics  bool
+		expectedStatus int
+	}{
+		{"disabled", false, http.StatusNotFound},
+		{"enabled", true, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.LoadConfig()
+			cfg.Server.EnableMetrics = tc.enableMetrics
+			logger := logging.NewNopLogger()
+			mux, _ := setupRouter(cfg, logger, app.NewApplication(cfg, logger))
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("expected status %d for /metrics, got %d", tc.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
 func TestSetupRouterWithNilHandler(t *testing.T) {
 	// Test setupRouter function - it creates its own handler internally
 	// This test verifies that setupRouter works correctly
-	logger := &mockLogger{}
-	mux := setupRouter(logger)
+	logger := logging.NewNopLogger()
+	mux, _ := setupRouter(config.LoadConfig(), logger, nil)
 
 	// The function should always return a valid mux since it creates the handler internally
 	if mux == nil {
@@ -142,10 +168,14 @@ func TestServerConfiguration(t *testing.T) {
 			name: "custom config",
 			rawconfig: &config.RawConfig{
 				Server: config.RawServerConfig{
-					Host:         testHostAll,
-					Port:         9090,
-					ReadTimeout:  15,
-					WriteTimeout: 20,
+					Host:              testHostAll,
+					Port:              9090,
+					ReadTimeout:       15,
+					WriteTimeout:      20,
+					IdleTimeout:       120,
+					ReadHeaderTimeout: 5,
+					MaxHeaderBytes:    64 << 10,
+					ShutdownTimeout:   30,
 				},
 			},
 		},
@@ -154,16 +184,11 @@ func TestServerConfiguration(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Create test server configuration
-			logger := &mockLogger{}
+			logger := logging.NewNopLogger()
 			application := app.NewApplication(tc.rawconfig, logger)
-			mux := setupRouter(logger)
+			mux, _ := setupRouter(tc.rawconfig, logger, nil)
 
-			// Create server with same configuration as startServer
-			srv := &http.Server{
-				Handler:      mux,
-				ReadTimeout:  time.Duration(tc.rawconfig.Server.ReadTimeout) * time.Second,
-				WriteTimeout: time.Duration(tc.rawconfig.Server.WriteTimeout) * time.Second,
-			}
+			srv := newHTTPServer(tc.rawconfig.Server, mux)
 
 			// Verify server configuration
 			if srv.Handler != mux {
@@ -180,12 +205,40 @@ func TestServerConfiguration(t *testing.T) {
 				t.Errorf("expected WriteTimeout to be %v, got %v", expectedWriteTimeout, srv.WriteTimeout)
 			}
 
+			expectedIdleTimeout := time.Duration(tc.rawconfig.Server.IdleTimeout) * time.Second
+			if srv.IdleTimeout != expectedIdleTimeout {
+				t.Errorf("expected IdleTimeout to be %v, got %v", expectedIdleTimeout, srv.IdleTimeout)
+			}
+
+			expectedReadHeaderTimeout := time.Duration(tc.rawconfig.Server.ReadHeaderTimeout) * time.Second
+			if srv.ReadHeaderTimeout != expectedReadHeaderTimeout {
+				t.Errorf("expected ReadHeaderTimeout to be %v, got %v", expectedReadHeaderTimeout, srv.ReadHeaderTimeout)
+			}
+
+			if srv.MaxHeaderBytes != tc.rawconfig.Server.MaxHeaderBytes {
+				t.Errorf("expected MaxHeaderBytes to be %d, got %d", tc.rawconfig.Server.MaxHeaderBytes, srv.MaxHeaderBytes)
+			}
+
+			expectedAddr := fmt.Sprintf("%s:%d", tc.rawconfig.Server.Host, tc.rawconfig.Server.Port)
+			if srv.Addr != expectedAddr {
+				t.Errorf("expected Addr to be %q, got %q", expectedAddr, srv.Addr)
+			}
+
 			// Clean up
 			application.Shutdown()
 		})
 	}
 }
 
+func TestShutdownTimeout(t *testing.T) {
+	if got := shutdownTimeout(config.RawServerConfig{}); got != defaultShutdownTimeout {
+		t.Errorf("expected unset shutdown timeout to default to %v, got %v", defaultShutdownTimeout, got)
+	}
+	if got := shutdownTimeout(config.RawServerConfig{ShutdownTimeout: 30}); got != 30*time.Second {
+		t.Errorf("expected shutdown timeout of 30s, got %v", got)
+	}
+}
+
 func TestApplicationInitialization(t *testing.T) {
 	// Test that application is initialized correctly
 	cfg := &config.RawConfig{
@@ -197,7 +250,7 @@ func TestApplicationInitialization(t *testing.T) {
 		},
 	}
 
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	application := app.NewApplication(cfg, logger)
 
 	// Verify application is created properly
@@ -242,7 +295,7 @@ func TestApplicationInitialization(t *testing.T) {
 }
 
 func TestHandlerInitialization(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := api.NewHandler(logger)
 
 	if handler == nil {
@@ -304,9 +357,9 @@ func TestLoggerConfiguration(t *testing.T) {
 func TestIntegrationComponents(t *testing.T) {
 	// Test that all components work together
 	cfg := config.LoadConfig()
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	application := app.NewApplication(cfg, logger)
-	mux := setupRouter(logger)
+	mux, _ := setupRouter(cfg, logger, application)
 
 	// Test that we can make requests through the complete stack
 	req, err := http.NewRequest("GET", healthEndpoint, nil)
@@ -341,6 +394,20 @@ func TestIntegrationComponents(t *testing.T) {
 		}
 	}
 
+	// The application was never started, so readiness must fail while
+	// liveness succeeds
+	probes := map[string]int{
+		"/livez":  http.StatusOK,
+		"/readyz": http.StatusServiceUnavailable,
+	}
+	for endpoint, expected := range probes {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", endpoint, nil))
+		if rr.Code != expected {
+			t.Errorf("expected status %d for %s, got %d", expected, endpoint, rr.Code)
+		}
+	}
+
 	// Clean up
 	application.Shutdown()
 }
@@ -356,7 +423,7 @@ func TestServerShutdownGraceful(t *testing.T) {
 		},
 	}
 
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	application := app.NewApplication(cfg, logger)
 
 	// Test that application can be shut down gracefully
@@ -399,17 +466,18 @@ func TestConfigLoadingDefault(t *testing.T) {
 
 // Benchmark tests for performance
 func BenchmarkSetupRouter(b *testing.B) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
+	cfg := config.LoadConfig()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		mux := setupRouter(logger)
+		mux, _ := setupRouter(cfg, logger, nil)
 		_ = mux
 	}
 }
 
 func BenchmarkHealthCheckRequest(b *testing.B) {
-	logger := &mockLogger{}
-	mux := setupRouter(logger)
+	logger := logging.NewNopLogger()
+	mux, _ := setupRouter(config.LoadConfig(), logger, nil)
 
 	req, _ := http.NewRequest("GET", healthEndpoint, nil)
 
@@ -429,7 +497,7 @@ func BenchmarkApplicationCreation(b *testing.B) {
 			WriteTimeout: 10,
 		},
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -437,3 +505,292 @@ func BenchmarkApplicationCreation(b *testing.B) {
 		app.Shutdown()
 	}
 }
+
+func TestValidateConfig(t *testing.T) {
+	if err := validateConfig(config.LoadConfig()); err != nil {
+		t.Errorf("expected default configuration to be valid, got:\n%v", err)
+	}
+
+	cfg := config.LoadConfig()
+	cfg.Server.Port = 99999
+	cfg.Processing.Output.OutputTopic = ""
+
+	err := validateConfig(cfg)
+	if err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	for _, want := range []string{"server.port", "processing: output topic cannot be empty"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestStartServerFailsWhenPortInUse(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to occupy a port: %v", err)
+	}
+	defer occupied.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = occupied.Addr().(*net.TCPAddr).Port
+	logger := logging.NewNopLogger()
+	application := app.NewApplication(cfg, logger)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- startServer(http.NewServeMux(), nil, cfg, application, config.Overrides{})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "listen on") {
+			t.Errorf("startServer() error = %v, want a listen error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("startServer() did not return promptly for a port already in use")
+	}
+	if application.IsReady() {
+		t.Error("application must not start when the server cannot bind")
+	}
+}
+
+func TestSetupRouterAdminPort(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Server.AdminPort = 9090
+	cfg.Server.EnableDebug = true
+	cfg.Server.EnableMetrics = true
+	logger := logging.NewNopLogger()
+	mux, adminMux := setupRouter(cfg, logger, app.NewApplication(cfg, logger))
+	if adminMux == nil {
+		t.Fatal("expected an admin mux when server.adminPort is set")
+	}
+
+	main := httptest.NewServer(mux)
+	defer main.Close()
+	admin := httptest.NewServer(adminMux)
+	defer admin.Close()
+
+	testCases := []struct {
+		path      string
+		mainCode  int
+		adminCode int
+	}{
+		{healthEndpoint, http.StatusNotFound, http.StatusOK},
+		{"/readyz", http.StatusNotFound, http.StatusServiceUnavailable},
+		{configEndpoint, http.StatusNotFound, http.StatusOK},
+		{"/debug/vars", http.StatusNotFound, http.StatusOK},
+		{"/metrics", http.StatusNotFound, http.StatusOK},
+		{statsEndpoint, http.StatusOK, http.StatusNotFound},
+		{"/api/v1/openapi.json", http.StatusOK, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		for _, target := range []struct {
+			srv  *httptest.Server
+			want int
+		}{{main, tc.mainCode}, {admin, tc.adminCode}} {
+			resp, err := http.Get(target.srv.URL + tc.path)
+			if err != nil {
+				t.Fatalf("GET %s failed: %v", tc.path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != target.want {
+				t.Errorf("GET %s on %s = %d, want %d", tc.path, target.srv.URL, resp.StatusCode, target.want)
+			}
+		}
+	}
+
+	cfg.Server.AdminPort = 0
+	if _, adminMux := setupRouter(cfg, logger, nil); adminMux != nil {
+		t.Error("expected no admin mux when server.adminPort is unset")
+	}
+}
+
+func TestStartServerFailsWhenAdminPortInUse(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to occupy a port: %v", err)
+	}
+	defer occupied.Close()
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = freePort
+	cfg.Server.AdminPort = occupied.Addr().(*net.TCPAddr).Port
+	logger := logging.NewNopLogger()
+	application := app.NewApplication(cfg, logger)
+
+	err = startServer(http.NewServeMux(), http.NewServeMux(), cfg, application, config.Overrides{})
+	if err == nil || !strings.Contains(err.Error(), adminAddr(cfg.Server)) {
+		t.Errorf("startServer() error = %v, want a listen error for the admin port", err)
+	}
+	if application.IsReady() {
+		t.Error("application must not start when the admin server cannot bind")
+	}
+
+	// The main listener must have been released
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", freePort))
+	if err != nil {
+		t.Fatalf("main port still bound after admin bind failure: %v", err)
+	}
+	ln.Close()
+}
+
+// failingService fails to start, making Application.Start fail
+type failingService struct{}
+
+func (failingService) Start(ctx context.Context) error {
+	return errors.New("broker unreachable")
+}
+
+func TestStartServerFatalWhenApplicationFailsToStart(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "service.log")
+	exitCode := -1
+	logger, err := logging.NewLogger(&logging.LoggerConfig{
+		Level:       logging.InfoLevel,
+		FilePath:    logPath,
+		LoggerName:  componentMain,
+		ServiceName: serviceName,
+		ExitFunc:    func(code int) { exitCode = code },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	application := app.NewApplication(cfg, logger)
+	if err := application.RegisterService("broker", failingService{}); err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+
+	err = startServer(http.NewServeMux(), nil, cfg, application, config.Overrides{})
+	if err == nil || !strings.Contains(err.Error(), "broker unreachable") {
+		t.Errorf("startServer() error = %v, want the start failure", err)
+	}
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	logged, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read the log: %v", err)
+	}
+	if !strings.Contains(string(logged), `"level":"fatal"`) || !strings.Contains(string(logged), "Failed to start application") {
+		t.Errorf("Expected the fatal start failure in the log, got:\n%s", logged)
+	}
+}
+
+func TestListenRetriesWhileAddressInUse(t *testing.T) {
+	bindRetryDelay = 20 * time.Millisecond
+	defer func() { bindRetryDelay = 500 * time.Millisecond }()
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to occupy a port: %v", err)
+	}
+	addr := occupied.Addr().String()
+
+	// Without retries the busy port fails immediately
+	if _, err := listen(addr, 0, logging.NewNopLogger()); err == nil {
+		t.Fatal("listen() succeeded on a port in use")
+	}
+
+	// Release the port while listen is backing off
+	time.AfterFunc(30*time.Millisecond, func() { occupied.Close() })
+	ln, err := listen(addr, 5, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("listen() with retries returned error: %v", err)
+	}
+	ln.Close()
+}
+
+// shortTempDir returns a temporary directory with a path short enough for a
+// Unix socket name
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "svc")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestUnixSocketServerRoundTrip(t *testing.T) {
+	socketPath := filepath.Join(shortTempDir(t), "api.sock")
+
+	// A stale socket from a previous run is replaced
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	cfg := config.LoadConfig()
+	cfg.Server.Listen = config.ListenUnix
+	cfg.Server.SocketPath = socketPath
+	cfg.Server.SocketMode = "0600"
+
+	ln, err := listenServer(cfg.Server, logging.NewNopLogger())
+	if err != nil {
+		t.Fatalf("listenServer() returned error: %v", err)
+	}
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("socket file missing: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	mux, _ := setupRouter(cfg, logging.NewNopLogger(), nil)
+	srv := newHTTPServer(cfg.Server, mux)
+	go srv.Serve(ln)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Get("http://unix" + healthEndpoint)
+	if err != nil {
+		t.Fatalf("request over unix socket failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("health status over unix socket = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket file should be removed on shutdown, stat error = %v", err)
+	}
+}
+
+func TestListenUnixRefusesNonSocketFile(t *testing.T) {
+	path := filepath.Join(shortTempDir(t), "not-a-socket")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := listenUnix(path, 0660); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("listenUnix() error = %v, want refusal to replace a regular file", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file must be left in place: %v", err)
+	}
+}
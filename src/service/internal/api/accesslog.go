@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"sharedgomodule/logging"
+)
+
+// AccessLogOptions controls how the access log middleware reports requests
+type AccessLogOptions struct {
+	Level      logging.Level // Level for successful (non-5xx) requests
+	QuietPaths []string      // Paths always logged at debug level, e.g. health probes
+}
+
+// responseRecorder wraps an http.ResponseWriter to capture the status code,
+// the number of body bytes written and any error message reported by writeResponse
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	bytes    int
+	errorMsg string
+}
+
+// WriteHeader records the status code before delegating
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body size, defaulting the status to 200 like net/http does
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// recordError attaches a handler error message to the response writer when it
+// is being recorded by the access log middleware
+func recordError(w http.ResponseWriter, msg string) {
+	if rec, ok := w.(*responseRecorder); ok {
+		rec.errorMsg = msg
+	}
+}
+
+// AccessLogMiddleware logs method, path, status, byte count, remote address
+// and duration for every request. Server errors are logged at error level
+// together with the handler's error message when one was written.
+func AccessLogMiddleware(logger logging.Logger, opts AccessLogOptions) Middleware {
+	quiet := make(map[string]bool, len(opts.QuietPaths))
+	for _, path := range opts.QuietPaths {
+		quiet[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+
+			defer func() {
+				panicValue := recover()
+				if panicValue != nil {
+					rec.status = http.StatusInternalServerError
+					rec.errorMsg = ErrInternalServer
+				}
+
+				logAccess(LoggerFromContext(r.Context(), logger), opts.Level, quiet[r.URL.Path], r, rec, time.Since(start))
+
+				// Let the recovery middleware produce the response
+				if panicValue != nil {
+					panic(panicValue)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// logAccess writes a single access log line at the level appropriate for the response
+func logAccess(logger logging.Logger, level logging.Level, quiet bool, r *http.Request, rec *responseRecorder, duration time.Duration) {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	keysAndValues := []interface{}{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", status,
+		"bytes", rec.bytes,
+		"remote_addr", r.RemoteAddr,
+		"duration_ms", duration.Milliseconds(),
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		if rec.errorMsg != "" {
+			keysAndValues = append(keysAndValues, "error", rec.errorMsg)
+		}
+		logger.Errorw("HTTP request failed", keysAndValues...)
+	case quiet:
+		logger.Debugw("HTTP request", keysAndValues...)
+	default:
+		logger.Logw(level, "HTTP request", keysAndValues...)
+	}
+}
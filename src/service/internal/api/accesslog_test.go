@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		handler       http.HandlerFunc
+		expectedLevel logging.Level
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name: "success logged at configured level",
+			path: testStatsPath,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeResponse(w, r, http.StatusOK, models.SuccessResponse{Message: "ok"})
+			},
+			expectedLevel: logging.WarnLevel,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "quiet path demoted to debug",
+			path: testHealthPath,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeResponse(w, r, http.StatusOK, models.HealthResponse{Status: "healthy"})
+			},
+			expectedLevel: logging.DebugLevel,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "server error logged at error level with message",
+			path: testStatsPath,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeResponse(w, r, http.StatusServiceUnavailable, models.ErrorResponse{Error: "pipeline down"})
+			},
+			expectedLevel: logging.ErrorLevel,
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedError: "pipeline down",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := logging.NewTestLogger()
+			mw := AccessLogMiddleware(logger, AccessLogOptions{Level: logging.WarnLevel, QuietPaths: []string{testHealthPath}})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rr := httptest.NewRecorder()
+			mw(tc.handler).ServeHTTP(rr, req)
+
+			entries := logger.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 access log entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tc.expectedLevel {
+				t.Errorf("level = %v, want %v", entry.Level, tc.expectedLevel)
+			}
+			if entry.Fields["status"] != tc.expectedCode {
+				t.Errorf("status = %v, want %d", entry.Fields["status"], tc.expectedCode)
+			}
+			if entry.Fields["bytes"] != rr.Body.Len() {
+				t.Errorf("bytes = %v, want %d", entry.Fields["bytes"], rr.Body.Len())
+			}
+			for _, key := range []string{"method", "path", "remote_addr", "duration_ms"} {
+				if _, ok := entry.Fields[key]; !ok {
+					t.Errorf("expected access log field %q", key)
+				}
+			}
+			if tc.expectedError != "" && entry.Fields["error"] != tc.expectedError {
+				t.Errorf("error = %v, want %q", entry.Fields["error"], tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestAccessLogMiddlewareWithPanic(t *testing.T) {
+	logger := logging.NewTestLogger()
+	handler := NewHandler(logger)
+	handler.Use(AccessLogMiddleware(logger, AccessLogOptions{Level: logging.InfoLevel}))
+
+	panicking := handler.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	rr := httptest.NewRecorder()
+	panicking.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+
+	found := false
+	for _, entry := range logger.Entries() {
+		if entry.Message == "HTTP request failed" && entry.Fields["status"] == http.StatusInternalServerError {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected panicking request to be access logged at error level")
+	}
+}
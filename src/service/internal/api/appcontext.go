@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/models"
+)
+
+// ErrApplicationUnavailable is returned when a handler needs the application
+// but the request context does not carry one
+const ErrApplicationUnavailable = "Application not available"
+
+// ApplicationMiddleware stores application in every request context so
+// handlers can reach its configuration and services with app.FromContext
+func ApplicationMiddleware(application *app.Application) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(app.NewContext(r.Context(), application)))
+		})
+	}
+}
+
+// applicationFromRequest returns the Application from the request context.
+// When it is missing a 500 response is written, the problem is logged and
+// false is returned.
+func (h *Handler) applicationFromRequest(w http.ResponseWriter, r *http.Request) (*app.Application, bool) {
+	application, ok := app.FromContext(r.Context())
+	if !ok {
+		h.requestLogger(r).Errorw("Application missing from request context", "path", r.URL.Path)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error: ErrApplicationUnavailable,
+			Code:  http.StatusInternalServerError,
+		})
+	}
+	return application, ok
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"servicegomodule/internal/models"
+)
+
+// Authentication constants
+const (
+	APIKeyHeader    = "X-API-Key"
+	ErrUnauthorized = "Missing or invalid API key"
+	bearerPrefix    = "Bearer "
+)
+
+// APIKeyAuthMiddleware rejects requests to /api/ routes that do not present
+// one of the configured keys, either as "Authorization: Bearer <key>" or in
+// the X-API-Key header. Operational endpoints such as /health and CORS
+// preflight requests are never authenticated so probes and browsers keep
+// working. An empty key set disables authentication.
+func APIKeyAuthMiddleware(keys []string) Middleware {
+	digests := apiKeyDigests(keys)
+
+	return func(next http.Handler) http.Handler {
+		if len(digests) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || !strings.HasPrefix(r.URL.Path, apiPathPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !validAPIKey(presentedAPIKey(r), digests) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				writeResponse(w, r, http.StatusUnauthorized, models.ErrorResponse{
+					Error: ErrUnauthorized,
+					Code:  http.StatusUnauthorized,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKeyDigests returns the digests of the non-empty keys. Keys are compared
+// by digest so every comparison covers the same number of bytes regardless
+// of the presented key's length.
+func apiKeyDigests(keys []string) [][sha256.Size]byte {
+	digests := make([][sha256.Size]byte, 0, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			digests = append(digests, sha256.Sum256([]byte(key)))
+		}
+	}
+	return digests
+}
+
+// presentedAPIKey extracts the API key from the request headers, preferring
+// the Authorization bearer token over X-API-Key
+func presentedAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, bearerPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(auth, bearerPrefix))
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// validAPIKey reports whether key matches any configured digest. Every digest
+// is checked so the time taken does not reveal which key, if any, matched.
+func validAPIKey(key string, digests [][sha256.Size]byte) bool {
+	if key == "" {
+		return false
+	}
+
+	presented := sha256.Sum256([]byte(key))
+	match := 0
+	for i := range digests {
+		match |= subtle.ConstantTimeCompare(presented[:], digests[i][:])
+	}
+	return match == 1
+}
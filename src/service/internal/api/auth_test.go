@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+const testAPIKey = "s3cret-key"
+
+func newAuthMux(keys []string) *http.ServeMux {
+	handler := NewHandler(logging.NewNopLogger())
+	handler.Use(APIKeyAuthMiddleware(keys))
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+	return mux
+}
+
+func TestAPIKeyAuthMiddleware(t *testing.T) {
+	mux := newAuthMux([]string{"other-key", testAPIKey})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"missing key", http.MethodGet, testStatsPath, nil, http.StatusUnauthorized},
+		{"invalid bearer", http.MethodGet, testStatsPath, map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"invalid header key", http.MethodGet, testStatsPath, map[string]string{APIKeyHeader: "wrong"}, http.StatusUnauthorized},
+		{"non-bearer scheme", http.MethodGet, testStatsPath, map[string]string{"Authorization": "Basic " + testAPIKey}, http.StatusUnauthorized},
+		{"valid bearer", http.MethodGet, testStatsPath, map[string]string{"Authorization": "Bearer " + testAPIKey}, http.StatusOK},
+		{"valid header key", http.MethodGet, testStatsPath, map[string]string{APIKeyHeader: testAPIKey}, http.StatusOK},
+		{"health is unauthenticated", http.MethodGet, testHealthPath, nil, http.StatusOK},
+		{"preflight is unauthenticated", http.MethodOptions, testStatsPath, nil, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if rr.Code != http.StatusUnauthorized {
+				return
+			}
+
+			if rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header on 401")
+			}
+			var response models.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Error != ErrUnauthorized || response.Code != http.StatusUnauthorized {
+				t.Errorf("unexpected error response: %+v", response)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthMiddlewareDisabled(t *testing.T) {
+	mux := newAuthMux([]string{"", ""})
+
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d with no keys configured, got %d", http.StatusOK, rr.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"servicegomodule/internal/models"
+)
+
+// Request body constants
+const (
+	DefaultMaxBodyBytes     int64 = 1 << 20 // 1 MiB
+	ErrRequestBodyTooLarge        = "Request body too large"
+	ErrUnsupportedMediaType       = "Content-Type must be application/json"
+	contentTypeJSON               = "application/json"
+	contentTypeXML                = "application/xml"
+)
+
+// BodyLimitMiddleware caps request bodies at maxBytes and rejects bodies that
+// are not JSON. Requests that declare an oversized Content-Length are refused
+// with 413 up front; chunked bodies are cut off by http.MaxBytesReader, which
+// decodeJSON maps to the same status. A non-positive maxBytes selects
+// DefaultMaxBodyBytes.
+func BodyLimitMiddleware(maxBytes int64) Middleware {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// ContentLength is -1 when unknown (chunked), so only an explicit 0 means no body
+			if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !isJSONContentType(r.Header.Get("Content-Type")) {
+				writeResponse(w, r, http.StatusUnsupportedMediaType, models.ErrorResponse{
+					Error: ErrUnsupportedMediaType,
+					Code:  http.StatusUnsupportedMediaType,
+				})
+				return
+			}
+
+			if r.ContentLength > maxBytes {
+				writeBodyTooLarge(w, r, maxBytes)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isJSONContentType reports whether the Content-Type header names JSON,
+// ignoring parameters such as charset
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == contentTypeJSON
+}
+
+// writeBodyTooLarge writes the 413 response for a body exceeding limit bytes
+func writeBodyTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	writeResponse(w, r, http.StatusRequestEntityTooLarge, models.ErrorResponse{
+		Error:   ErrRequestBodyTooLarge,
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "maximum body size is " + strconv.FormatInt(limit, 10) + " bytes",
+	})
+}
+
+// decodeJSON decodes the request body into dst. On failure it writes a 413
+// when the body exceeded the configured limit, or a 400 otherwise, and
+// returns false so the handler can simply return.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeBodyTooLarge(w, r, maxBytesErr.Limit)
+		return false
+	}
+
+	writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+		Error:   ErrInvalidRequestBody,
+		Code:    http.StatusBadRequest,
+		Message: err.Error(),
+	})
+	return false
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/models"
+)
+
+const testMaxBodyBytes = 64
+
+// newDecodeHandler returns a handler that decodes the body with decodeJSON
+// behind BodyLimitMiddleware and answers 200 on success
+func newDecodeHandler(maxBytes int64) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if !decodeJSON(w, r, &payload) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	return BodyLimitMiddleware(maxBytes)(handler)
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	oversized := `{"data":"` + strings.Repeat("x", testMaxBodyBytes) + `"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		contentType    string
+		body           string
+		chunked        bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{"valid json", http.MethodPost, "application/json", `{"name":"cfg"}`, false, http.StatusOK, ""},
+		{"json with charset", http.MethodPut, "application/json; charset=utf-8", `{"name":"cfg"}`, false, http.StatusOK, ""},
+		{"oversized declared length", http.MethodPost, "application/json", oversized, false, http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge},
+		{"oversized chunked body", http.MethodPost, "application/json", oversized, true, http.StatusRequestEntityTooLarge, ErrRequestBodyTooLarge},
+		{"wrong content type", http.MethodPost, "text/plain", `{"name":"cfg"}`, false, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType},
+		{"missing content type", http.MethodPost, "", `{"name":"cfg"}`, false, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType},
+		{"malformed json", http.MethodPost, "application/json", `{"name":`, false, http.StatusBadRequest, ErrInvalidRequestBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.chunked {
+				// Hide the length so the request is treated as chunked
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest(tt.method, testConfigPath, body)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			newDecodeHandler(testMaxBodyBytes).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedError == "" {
+				return
+			}
+
+			var response models.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Error != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, response.Error)
+			}
+		})
+	}
+}
+
+func TestBodyLimitMiddlewareIgnoresEmptyBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	rr := httptest.NewRecorder()
+
+	called := false
+	BodyLimitMiddleware(testMaxBodyBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})).ServeHTTP(rr, req)
+
+	if !called {
+		t.Error("expected request without a body to reach the handler")
+	}
+}
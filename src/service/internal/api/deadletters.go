@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"servicegomodule/internal/models"
+	"servicegomodule/internal/processing"
+)
+
+// defaultDeadLetterLimit is the number of dead letters listed without a
+// limit parameter
+const defaultDeadLetterLimit = 20
+
+// ListDeadLetters lists the most recent messages the pipeline dead-lettered,
+// newest first, up to the limit query parameter
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeadLetterLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+				Error:   ErrInvalidLimit,
+				Message: "limit must be a positive integer",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgDeadLetters,
+		Data:    application.ProcessingPipeline().DeadLetters(limit),
+	})
+}
+
+// ReplayDeadLetters republishes the dead letters selected by ID in the
+// request body to their source topics. Dead letters that are unknown or fail
+// to publish are reported in the result rather than failing the request.
+func (h *Handler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.DeadLetterReplayRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if len(request.IDs) == 0 {
+		writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+			Error:   ErrInvalidRequestBody,
+			Message: "ids must list at least one dead letter",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	result, err := application.ProcessingPipeline().ReplayDeadLetters(r.Context(), request.IDs)
+	if errors.Is(err, processing.ErrPipelineNotRunning) {
+		writeResponse(w, r, http.StatusConflict, models.ErrorResponse{
+			Error:   ErrReplayConflict,
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	h.requestLogger(r).Infow("Dead letters replayed", "replayed", len(result.Replayed), "not_found", len(result.NotFound), "failed", len(result.Failed))
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgDeadLetterReplay,
+		Data:    result,
+	})
+}
@@ -0,0 +1,87 @@
+//go:build local
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/models"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// TestDeadLetterReplay dead-letters a message over the local message bus,
+// lists it, replays it and checks it is processed the second time
+func TestDeadLetterReplay(t *testing.T) {
+	// Reject the message until it comes back replayed without failure headers
+	application, cfg := startLocalApplication(t, "replay-test", func(application *app.Application) {
+		application.ProcessingPipeline().SetMessageProcessor(processing.MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+			if msg.Headers[processing.HeaderReplayCount] != "1" || msg.Headers[processing.HeaderDeadLetterError] != "" {
+				msg.Nack(errors.New("downstream rejected"), false)
+				return nil, nil
+			}
+			return models.NewDataMessage(msg.Data, "test"), nil
+		}))
+	})
+
+	producer := messagebus.NewProducer("kafka-producer.yaml")
+	defer producer.Close()
+	message := &messagebus.Message{Topic: cfg.Processing.Input.Topics[0], Key: "order-1", Value: []byte(`{"id":"order-1"}`)}
+	if _, _, err := producer.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send() returned error: %v", err)
+	}
+
+	handler := NewHandler(logging.NewNopLogger())
+	var letters []models.DeadLetter
+	deadline := time.Now().Add(5 * time.Second)
+	for len(letters) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the message to be dead-lettered")
+		}
+		time.Sleep(20 * time.Millisecond)
+		rr := httptest.NewRecorder()
+		handler.ListDeadLetters(rr, withApplication(httptest.NewRequest(http.MethodGet, APIPipelineDLQPath+"?limit=5", nil), application))
+		var response struct{ Data []models.DeadLetter }
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode dead letters: %v", err)
+		}
+		letters = response.Data
+	}
+	letter := letters[0]
+	if letter.SourceTopic != cfg.Processing.Input.Topics[0] || letter.Key != "order-1" || letter.Error != "downstream rejected" || letter.PayloadPreview != `{"id":"order-1"}` {
+		t.Errorf("Unexpected dead letter %+v", letter)
+	}
+
+	body, _ := json.Marshal(models.DeadLetterReplayRequest{IDs: []uint64{letter.ID, 999}})
+	rr := httptest.NewRecorder()
+	handler.ReplayDeadLetters(rr, withApplication(httptest.NewRequest(http.MethodPost, APIPipelineReplayPath, bytes.NewReader(body)), application))
+	var response struct{ Data models.DeadLetterReplayResult }
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("Replay returned status %d: %v", rr.Code, err)
+	}
+	if len(response.Data.Replayed) != 1 || response.Data.Replayed[0] != letter.ID || len(response.Data.NotFound) != 1 {
+		t.Errorf("Expected dead letter %d replayed and 999 not found, got %+v", letter.ID, response.Data)
+	}
+
+	pipeline := application.ProcessingPipeline()
+	awaitPublished(t, application, 1)
+	if published := pipeline.Metrics().Published; published != 1 {
+		t.Fatalf("Expected the replayed message to be published, got %d published", published)
+	}
+	if remaining := pipeline.DeadLetters(5); len(remaining) != 0 {
+		t.Errorf("Expected the replayed dead letter to leave the log, got %+v", remaining)
+	}
+	stats := pipeline.GetStats()["dead_letter_stats"].(map[string]interface{})
+	if stats["replayed"] != int64(1) {
+		t.Errorf("Expected 1 replay in the stats, got %v", stats["replayed"])
+	}
+}
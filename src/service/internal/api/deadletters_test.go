@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestListDeadLetters(t *testing.T) {
+	application := newTestApplication()
+	handler := NewHandler(logging.NewNopLogger())
+
+	for _, tc := range []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"?limit=5", http.StatusOK},
+		{"?limit=0", http.StatusBadRequest},
+		{"?limit=many", http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		handler.ListDeadLetters(rr, withApplication(httptest.NewRequest(http.MethodGet, APIPipelineDLQPath+tc.query, nil), application))
+		if rr.Code != tc.code {
+			t.Errorf("%q: status = %d, want %d", tc.query, rr.Code, tc.code)
+		}
+		if tc.code == http.StatusOK && !strings.Contains(rr.Body.String(), `"data":[]`) {
+			t.Errorf("%q: expected an empty list, got %s", tc.query, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ListDeadLetters(rr, httptest.NewRequest(http.MethodGet, APIPipelineDLQPath, nil))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	application := newTestApplication()
+	handler := NewHandler(logging.NewNopLogger())
+
+	for _, tc := range []struct {
+		name  string
+		body  string
+		code  int
+		error string
+	}{
+		{"no ids", `{"ids":[]}`, http.StatusBadRequest, ErrInvalidRequestBody},
+		{"malformed", `{"ids":`, http.StatusBadRequest, ErrInvalidRequestBody},
+		{"pipeline not running", `{"ids":[1]}`, http.StatusConflict, ErrReplayConflict},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ReplayDeadLetters(rr, withApplication(httptest.NewRequest(http.MethodPost, APIPipelineReplayPath, strings.NewReader(tc.body)), application))
+			if rr.Code != tc.code {
+				t.Fatalf("status = %d, want %d", rr.Code, tc.code)
+			}
+			var response models.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil || response.Error != tc.error {
+				t.Errorf("error = %q (%v), want %q", response.Error, err, tc.error)
+			}
+		})
+	}
+}
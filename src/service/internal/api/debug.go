@@ -0,0 +1,36 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+)
+
+// Debug route constants
+const (
+	DebugPprofPath = "/debug/pprof/"
+	DebugVarsPath  = "/debug/vars"
+)
+
+// debugRoutes returns the pprof and expvar routes. They are kept out of
+// routes() so they are never registered unless explicitly enabled.
+func debugRoutes() []route {
+	return []route{
+		{Method: http.MethodGet, Pattern: DebugPprofPath, Handler: pprof.Index},
+		{Method: http.MethodGet, Pattern: DebugPprofPath + "cmdline", Handler: pprof.Cmdline},
+		{Method: http.MethodGet, Pattern: DebugPprofPath + "profile", Handler: pprof.Profile},
+		{Method: http.MethodGet, Pattern: DebugPprofPath + "symbol", Handler: pprof.Symbol},
+		{Method: http.MethodPost, Pattern: DebugPprofPath + "symbol", Handler: pprof.Symbol},
+		{Method: http.MethodGet, Pattern: DebugPprofPath + "trace", Handler: pprof.Trace},
+		{Method: http.MethodGet, Pattern: DebugVarsPath, Handler: expvar.Handler().ServeHTTP},
+	}
+}
+
+// SetupDebugRoutes mounts the net/http/pprof and expvar handlers under
+// /debug/, wrapped with the middleware chain. Only call this when debugging
+// is enabled, since profiles expose process internals.
+func (h *Handler) SetupDebugRoutes(mux *http.ServeMux) {
+	for _, rt := range debugRoutes() {
+		mux.Handle(rt.Method+" "+rt.Pattern, h.wrap(rt.Handler))
+	}
+}
@@ -1,11 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
+	"servicegomodule/internal/app"
 	"servicegomodule/internal/models"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/buildinfo"
 	"sharedgomodule/logging"
 )
 
@@ -17,120 +25,582 @@ const (
 	ErrMethodNotAllowed    = "Method not allowed"
 	ErrNotImplemented      = "Not implemented"
 	ErrServiceNotAvailable = "User service not available"
+	ErrRestartFailed       = "Pipeline restart failed"
+	ErrRestartConflict     = "Pipeline cannot be restarted now"
+	ErrInvalidTopics       = "Invalid topic list"
+	ErrTopicUpdateFailed   = "Topic update failed"
+	ErrInvalidFilterRules  = "Invalid filter rules"
+	ErrFilterReloadFailed  = "Filter reload failed"
+	ErrInvalidSchemas      = "Invalid payload schemas"
+	ErrSchemaReloadFailed  = "Schema reload failed"
+	ErrInvalidConfig       = "Invalid configuration"
+	ErrConfigUpdateFailed  = "Configuration update failed"
+	ErrInvalidLimit        = "Invalid limit"
+	ErrReplayConflict      = "Dead letters cannot be replayed now"
+	ErrInvalidLogLevel     = "Invalid log level"
 )
 
 // Success message constants
 const (
-	MsgStatsRetrieved  = "Statistics retrieved successfully"
-	MsgConfigRetrieved = "Configuration retrieved successfully"
+	MsgStatsRetrieved    = "Statistics retrieved successfully"
+	MsgConfigRetrieved   = "Configuration retrieved successfully"
+	MsgServicesRetrieved = "Services retrieved successfully"
+	MsgPipelineRestarted = "Pipeline restarted successfully"
+	MsgTopicsUpdated     = "Input topics updated successfully"
+	MsgFilterReloaded    = "Filter rules reloaded successfully"
+	MsgSchemasReloaded   = "Payload schemas reloaded successfully"
+	MsgConfigUpdated     = "Configuration updated successfully"
+	MsgDeadLetters       = "Dead letters retrieved successfully"
+	MsgDeadLetterReplay  = "Dead letter replay completed"
+	MsgLogLevel          = "Log level retrieved successfully"
+	MsgLogLevelUpdated   = "Log level updated successfully"
 )
 
+// Probe status constants
+const (
+	StatusAlive     = "alive"
+	StatusReady     = "ready"
+	StatusNotReady  = "not ready"
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// StatusNotStarted is the stats status of a pipeline that has not been started
+const StatusNotStarted = "not_started"
+
+// healthCheckTimeout bounds how long /health waits for service checks
+const healthCheckTimeout = 2 * time.Second
+
+// ReadinessChecker reports whether the application can serve traffic
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+// HealthReporter runs the health checks of registered services, returning a
+// nil error for each healthy service keyed by name
+type HealthReporter interface {
+	CheckHealth(ctx context.Context) map[string]error
+}
+
+// HealthDetailer is implemented by health reporters that also describe the
+// status of sub-components, reported under details by /health
+type HealthDetailer interface {
+	HealthDetails() map[string]string
+}
+
 // API route constants
 const (
-	APIUsersPath = "/api/v1/users/"
+	APIUsersPath    = "/api/v1/users/"
+	HealthPath      = "/health"
+	LivezPath       = "/livez"
+	ReadyzPath      = "/readyz"
+	VersionPath     = "/version"
+	APIStatsPath    = "/api/v1/stats"
+	APIConfigPath   = "/api/v1/config/"
+	APIServicesPath = "/api/v1/services"
+	OpenAPIPath     = "/api/v1/openapi.json"
+
+	APIPipelineRestartPath = "/api/v1/pipeline/restart"
+	APIPipelineTopicsPath  = "/api/v1/pipeline/topics"
+	APIPipelineDLQPath     = "/api/v1/pipeline/dlq"
+	APIPipelineReplayPath  = "/api/v1/pipeline/dlq/replay"
+	APIConfigFilterPath    = "/api/v1/config/filter/reload"
+	APIConfigSchemasPath   = "/api/v1/config/schemas/reload"
+	APILoggingLevelPath    = "/api/v1/logging/level"
 )
 
 // Handler holds the dependencies for API handlers
 type Handler struct {
-	logger logging.Logger
+	logger      logging.Logger
+	middlewares []Middleware
+	openAPISpec []byte           // Built once from the route table in NewHandler
+	readiness   ReadinessChecker // Consulted by /readyz; nil reports not ready
+	health      HealthReporter   // Consulted by /health; nil reports healthy
+	metrics     *httpMetrics     // Set by SetMetrics; nil disables /metrics
 	// Any implementation specific variables to be added
 }
 
-// NewHandler creates a new Handler instance
+// NewHandler creates a new Handler instance with the built-in request ID and
+// recovery middleware installed
 func NewHandler(logger logging.Logger) *Handler {
-	return &Handler{
+	h := &Handler{
 		logger: logger,
+		middlewares: []Middleware{
+			RequestIDMiddleware(logger),
+			RecoveryMiddleware(logger),
+		},
 	}
+
+	spec, err := json.Marshal(buildOpenAPISpec(h.routes()))
+	if err != nil {
+		logger.Errorw("Failed to build OpenAPI specification", "error", err)
+	}
+	h.openAPISpec = spec
+
+	return h
 }
 
-// SetupRoutes sets up the API routes
+// route describes a single method-qualified API route. Summary and Response
+// document the route in the OpenAPI specification; Response is a zero value
+// of the model written on success. Admin routes are operational endpoints
+// that move to the admin listener when one is configured.
+type route struct {
+	Method   string
+	Pattern  string
+	Handler  http.HandlerFunc
+	Summary  string
+	Response interface{}
+	Admin    bool
+}
+
+// routes returns the table of API routes served by the handler
+func (h *Handler) routes() []route {
+	return []route{
+		{Method: http.MethodGet, Pattern: HealthPath, Handler: h.HealthCheck,
+			Summary: "Report service health", Response: models.HealthResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: LivezPath, Handler: h.Livez,
+			Summary: "Report that the process is alive", Response: models.HealthResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: ReadyzPath, Handler: h.Readyz,
+			Summary: "Report whether the service is ready for traffic", Response: models.HealthResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: VersionPath, Handler: h.GetVersion,
+			Summary: "Report build version information", Response: buildinfo.Info{}, Admin: true},
+		{Method: http.MethodGet, Pattern: APIStatsPath, Handler: h.GetStats,
+			Summary: "Retrieve processing statistics", Response: models.SuccessResponse{}},
+		{Method: http.MethodGet, Pattern: APIConfigPath, Handler: h.GetConfig,
+			Summary: "Retrieve the effective configuration with secrets redacted", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPost, Pattern: APIConfigPath, Handler: h.UpdateProcessingConfig,
+			Summary: "Update processing settings, applying batch sizes, delays and rate limits to the running pipeline", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPost, Pattern: APIConfigFilterPath, Handler: h.ReloadFilterRules,
+			Summary: "Reload the message filter rules from the configured rules file", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPost, Pattern: APIConfigSchemasPath, Handler: h.ReloadSchemas,
+			Summary: "Reload the payload validation schemas from the configured schema files", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: APIServicesPath, Handler: h.GetServices,
+			Summary: "List registered services with their type, dependencies and state", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPost, Pattern: APIPipelineRestartPath, Handler: h.RestartPipeline,
+			Summary: "Restart the processing pipeline from the current configuration", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPut, Pattern: APIPipelineTopicsPath, Handler: h.UpdatePipelineTopics,
+			Summary: "Replace the input topics of the running pipeline", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: APIPipelineDLQPath, Handler: h.ListDeadLetters,
+			Summary: "List the most recent dead-lettered messages, newest first", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPost, Pattern: APIPipelineReplayPath, Handler: h.ReplayDeadLetters,
+			Summary: "Republish selected dead-lettered messages to their source topic", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: APILoggingLevelPath, Handler: h.GetLogLevel,
+			Summary: "Report the current log level", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodPut, Pattern: APILoggingLevelPath, Handler: h.SetLogLevel,
+			Summary: "Change the log level of the running service without a restart", Response: models.SuccessResponse{}, Admin: true},
+		{Method: http.MethodGet, Pattern: OpenAPIPath, Handler: h.GetOpenAPISpec,
+			Summary: "Retrieve this OpenAPI specification", Response: map[string]interface{}{}},
+	}
+}
+
+// SetupRoutes sets up the API routes using method-aware ServeMux patterns,
+// wrapping each route with the middleware chain. Requests with a method not
+// registered for a path get a 405 response with an Allow header from the mux.
 func (h *Handler) SetupRoutes(mux *http.ServeMux) {
-	// Health check
-	mux.HandleFunc("/health", h.HealthCheck)
+	h.setupRoutes(mux, func(route) bool { return true })
+}
 
-	mux.HandleFunc("/api/v1/stats", h.GetStats)
-	mux.HandleFunc("/api/v1/config/", h.HandleConfigs)
+// SetupAPIRoutes sets up only the business API routes, for a main listener
+// running alongside an admin listener
+func (h *Handler) SetupAPIRoutes(mux *http.ServeMux) {
+	h.setupRoutes(mux, func(rt route) bool { return !rt.Admin })
 }
 
-// Helper functions for JSON responses and middleware
+// SetupAdminRoutes sets up only the operational routes: health probes,
+// version, configuration, services and metrics
+func (h *Handler) SetupAdminRoutes(mux *http.ServeMux) {
+	h.setupRoutes(mux, func(rt route) bool { return rt.Admin })
+}
 
-// writeJSON writes a JSON response
-func writeJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+// setupRoutes registers the routes selected by include, plus /metrics when
+// metrics are enabled
+func (h *Handler) setupRoutes(mux *http.ServeMux, include func(route) bool) {
+	routes := h.routes()
+	if rt := h.metrics.route(); rt != nil {
+		routes = append(routes, *rt)
+	}
+
+	preflight := make(map[string]bool)
+	for _, rt := range routes {
+		if !include(rt) {
+			continue
+		}
+		mux.Handle(rt.Method+" "+muxPattern(rt.Pattern), h.metrics.instrument(rt.Method, rt.Pattern, h.wrap(rt.Handler)))
+
+		// Register CORS preflight once per path
+		if !preflight[rt.Pattern] {
+			preflight[rt.Pattern] = true
+			mux.Handle(http.MethodOptions+" "+muxPattern(rt.Pattern), h.metrics.instrument(http.MethodOptions, rt.Pattern, h.wrap(http.HandlerFunc(handlePreflight))))
+		}
+	}
 }
 
-// corsMiddleware handles CORS headers
-func (h *Handler) corsMiddleware(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Credentials", "true")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+// muxPattern returns the ServeMux pattern matching path exactly. A path
+// ending in a slash would otherwise match everything beneath it.
+func muxPattern(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return path + "{$}"
+	}
+	return path
+}
 
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusNoContent)
-		return
+// Helper functions for responses
+
+// writeResponse writes data encoded in the format negotiated from the
+// request's Accept header: XML when the client prefers it, JSON otherwise.
+// Data that cannot be encoded as XML falls back to JSON.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	switch errResp := data.(type) {
+	case models.ErrorResponse:
+		recordError(w, errResp.Error)
+	case models.ValidationErrorResponse:
+		recordError(w, errResp.Error)
+	}
+	w.Header().Add("Vary", "Accept")
+
+	if r != nil && prefersXML(r.Header.Get("Accept")) {
+		if body, err := xml.Marshal(data); err == nil {
+			w.Header().Set("Content-Type", contentTypeXML)
+			w.WriteHeader(status)
+			w.Write([]byte(xml.Header))
+			w.Write(body)
+			return
+		}
 	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// handlePreflight answers OPTIONS requests that reach a route; the CORS
+// middleware normally handles these before the route is invoked
+func handlePreflight(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // HealthCheck handles health check requests
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	h.logger.Infow("HealthCheck handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-	defer h.logger.Infow("HealthCheck handler exit", "method", r.Method, "path", r.URL.Path)
+	logger := h.requestLogger(r)
+	logger.Infow("HealthCheck handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+	defer logger.Infow("HealthCheck handler exit", "method", r.Method, "path", r.URL.Path)
 
-	// Apply CORS middleware
-	h.corsMiddleware(w, r)
-	if r.Method == "OPTIONS" {
-		return
+	health := &models.HealthResponse{
+		Status:    StatusHealthy,
+		Timestamp: time.Now(),
+		Version:   buildinfo.VersionString(),
 	}
+	status := http.StatusOK
 
-	health := &models.HealthResponse{
-		Status:    "healthy",
+	if h.health != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		results := h.health.CheckHealth(ctx)
+		names := make([]string, 0, len(results))
+		for name := range results {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			service := models.ServiceHealth{Name: name, Status: StatusHealthy}
+			if err := results[name]; err != nil {
+				service.Status = StatusUnhealthy
+				service.Error = err.Error()
+				health.Status = StatusDegraded
+				status = http.StatusServiceUnavailable
+				logger.Warnw("Service health check failed", "service", name, "error", err)
+			}
+			health.Services = append(health.Services, service)
+		}
+		if detailer, ok := h.health.(HealthDetailer); ok {
+			health.Details = detailer.HealthDetails()
+		}
+	}
+
+	writeResponse(w, r, status, health)
+}
+
+// SetReadinessChecker sets the checker consulted by the readiness probe
+func (h *Handler) SetReadinessChecker(readiness ReadinessChecker) {
+	h.readiness = readiness
+}
+
+// SetHealthReporter sets the source of per-service health for /health
+func (h *Handler) SetHealthReporter(health HealthReporter) {
+	h.health = health
+}
+
+// Livez handles liveness probes; it succeeds whenever the process can serve HTTP
+func (h *Handler) Livez(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, http.StatusOK, &models.HealthResponse{
+		Status:    StatusAlive,
 		Timestamp: time.Now(),
-		Version:   "1.0.0",
+		Version:   buildinfo.VersionString(),
+	})
+}
+
+// Readyz handles readiness probes, returning 503 until the application has
+// started and again once it begins shutting down
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	status, code := StatusReady, http.StatusOK
+	if h.readiness == nil || !h.readiness.IsReady() {
+		status, code = StatusNotReady, http.StatusServiceUnavailable
 	}
-	writeJSON(w, http.StatusOK, health)
+
+	writeResponse(w, r, code, &models.HealthResponse{
+		Status:    status,
+		Timestamp: time.Now(),
+		Version:   buildinfo.VersionString(),
+	})
+}
+
+// GetVersion reports the version, commit and build time of the binary
+func (h *Handler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, http.StatusOK, buildinfo.Get())
 }
 
-// GetStats handles statistics requests
+// GetStats reports the processing pipeline counters and per-stage detail.
+// Without an application in the request context there is no pipeline, and
+// zero counts are reported with status not_started.
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	h.logger.Infow("GetStats handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-	defer h.logger.Infow("GetStats handler exit", "method", r.Method, "path", r.URL.Path)
+	logger := h.requestLogger(r)
+	logger.Infow("GetStats handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+	defer logger.Infow("GetStats handler exit", "method", r.Method, "path", r.URL.Path)
 
-	stats := map[string]interface{}{
-		"total_messages": 0, // Stub implementation
+	stats := models.StatsResponse{Status: StatusNotStarted}
+	if application, ok := app.FromContext(r.Context()); ok {
+		stats = pipelineStats(application.ProcessingPipeline())
 	}
 
-	writeJSON(w, http.StatusOK, models.SuccessResponse{
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
 		Message: MsgStatsRetrieved,
 		Data:    stats,
 	})
 }
 
-// handles config related requests
-func (h *Handler) HandleConfigs(w http.ResponseWriter, r *http.Request) {
-	h.logger.Infow("HandleConfigs handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-	defer h.logger.Infow("HandleConfigs handler exit", "method", r.Method, "path", r.URL.Path)
+// pipelineStats builds the stats response from the live pipeline counters
+func pipelineStats(pipeline *processing.Pipeline) models.StatsResponse {
+	metrics := pipeline.Metrics()
+	status := string(metrics.State)
+	if !pipeline.Started() && metrics.State == processing.PipelineStopped {
+		status = StatusNotStarted
+	}
+	return models.StatsResponse{
+		Status:        status,
+		TotalMessages: metrics.Consumed,
+		Consumed:      metrics.Consumed,
+		Processed:     metrics.Processed,
+		Published:     metrics.Published,
+		Failed:        metrics.Failed,
+		Dropped:       metrics.Dropped,
+		Pipeline:      pipeline.GetStats(),
+	}
+}
+
+// GetServices lists the services registered with the application
+func (h *Handler) GetServices(w http.ResponseWriter, r *http.Request) {
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgServicesRetrieved,
+		Data:    application.Registry().Describe(),
+	})
+}
 
-	// Apply CORS middleware
-	h.corsMiddleware(w, r)
-	if r.Method == "OPTIONS" {
+// GetConfig reports the effective configuration the process is running
+// with, secrets redacted, along with where it was loaded from
+func (h *Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	logger.Infow("GetConfig handler entry", "method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+	defer logger.Infow("GetConfig handler exit", "method", r.Method, "path", r.URL.Path)
+
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgConfigRetrieved,
+		Data:    application.EffectiveConfig(),
+	})
+}
+
+// UpdateProcessingConfig updates the processing settings from a JSON object
+// shaped like the processing section of the configuration file and reports
+// which changes took effect and which wait for a pipeline restart. Settings
+// that fail validation get a 400 listing each problem in the details.
+func (h *Handler) UpdateProcessingConfig(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	switch r.Method {
-	case "GET":
-		// Stub implementation for reading info
-		data := []interface{}{} // Empty list for now
-		writeJSON(w, http.StatusOK, models.SuccessResponse{
-			Message: MsgConfigRetrieved,
-			Data:    data,
+	var patch json.RawMessage
+	if !decodeJSON(w, r, &patch) {
+		return
+	}
+
+	result, err := application.UpdateProcessingConfig(patch)
+	var invalid *app.InvalidConfigError
+	switch {
+	case errors.As(err, &invalid):
+		writeResponse(w, r, http.StatusBadRequest, models.ValidationErrorResponse{
+			ErrorResponse: models.ErrorResponse{
+				Error:   ErrInvalidConfig,
+				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+			},
+			Details: invalid.Problems,
+		})
+	case err != nil:
+		logger.Errorw("Configuration update failed", "error", err)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   ErrConfigUpdateFailed,
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+	default:
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgConfigUpdated,
+			Data:    result,
+		})
+	}
+}
+
+// RestartPipeline stops the processing pipeline and starts a new one from
+// the current configuration, reporting how long each step took
+func (h *Handler) RestartPipeline(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := application.RestartPipeline()
+	switch {
+	case errors.Is(err, app.ErrNotRunning):
+		writeResponse(w, r, http.StatusConflict, models.ErrorResponse{
+			Error:   ErrRestartConflict,
+			Message: err.Error(),
+			Code:    http.StatusConflict,
+		})
+	case err != nil:
+		logger.Errorw("Pipeline restart failed", "error", err)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   ErrRestartFailed,
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+	default:
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgPipelineRestarted,
+			Data:    result,
+		})
+	}
+}
+
+// UpdatePipelineTopics resubscribes the pipeline input to the topics in the
+// request body without restarting the rest of the pipeline
+func (h *Handler) UpdatePipelineTopics(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.TopicsRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	err := application.UpdateInputTopics(request.Topics)
+	switch {
+	case errors.Is(err, processing.ErrInvalidTopics):
+		writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+			Error:   ErrInvalidTopics,
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+	case err != nil:
+		logger.Errorw("Input topic update failed", "error", err)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   ErrTopicUpdateFailed,
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+	default:
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgTopicsUpdated,
+			Data:    request,
+		})
+	}
+}
+
+// ReloadFilterRules reloads the pipeline's message filter rules from the
+// rules file in the current configuration
+func (h *Handler) ReloadFilterRules(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := application.ReloadFilterRules()
+	switch {
+	case errors.Is(err, processing.ErrInvalidRules):
+		writeResponse(w, r, http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   ErrInvalidFilterRules,
+			Message: err.Error(),
+			Code:    http.StatusUnprocessableEntity,
+		})
+	case err != nil:
+		logger.Errorw("Filter reload failed", "error", err)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   ErrFilterReloadFailed,
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+	default:
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgFilterReloaded,
+			Data:    map[string]int{"rules": rules},
+		})
+	}
+}
+
+// ReloadSchemas reloads the pipeline's payload validation schemas from the
+// files named in the current configuration
+func (h *Handler) ReloadSchemas(w http.ResponseWriter, r *http.Request) {
+	logger := h.requestLogger(r)
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	schemas, err := application.ReloadSchemas()
+	switch {
+	case errors.Is(err, processing.ErrInvalidSchema):
+		writeResponse(w, r, http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   ErrInvalidSchemas,
+			Message: err.Error(),
+			Code:    http.StatusUnprocessableEntity,
+		})
+	case err != nil:
+		logger.Errorw("Schema reload failed", "error", err)
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error:   ErrSchemaReloadFailed,
+			Message: err.Error(),
+			Code:    http.StatusInternalServerError,
 		})
 	default:
-		h.logger.Warnw("Method not allowed", "method", r.Method, "path", r.URL.Path)
-		writeJSON(w, http.StatusMethodNotAllowed, models.ErrorResponse{
-			Error: ErrMethodNotAllowed,
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgSchemasReloaded,
+			Data:    map[string]int{"schemas": schemas},
 		})
 	}
 }
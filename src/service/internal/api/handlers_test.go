@@ -1,13 +1,19 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/config"
 	"servicegomodule/internal/models"
+	"sharedgomodule/buildinfo"
 	"sharedgomodule/logging"
 )
 
@@ -18,46 +24,11 @@ const (
 	testConfigPath    = "/api/v1/config/"
 	contentTypeHeader = "Content-Type"
 	jsonContentType   = "application/json"
+	testOrigin        = "https://app.example.com"
 )
 
-// Mock logger for testing
-type mockLogger struct{}
-
-func (m *mockLogger) SetLevel(level logging.Level)                           { /* no-op for testing */ }
-func (m *mockLogger) GetLevel() logging.Level                                { return logging.InfoLevel }
-func (m *mockLogger) IsLevelEnabled(level logging.Level) bool                { return true }
-func (m *mockLogger) Debug(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Info(msg string)                                        { /* no-op for testing */ }
-func (m *mockLogger) Warn(msg string)                                        { /* no-op for testing */ }
-func (m *mockLogger) Error(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Fatal(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Panic(msg string)                                       { /* no-op for testing */ }
-func (m *mockLogger) Debugf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Infof(format string, args ...interface{})               { /* no-op for testing */ }
-func (m *mockLogger) Warnf(format string, args ...interface{})               { /* no-op for testing */ }
-func (m *mockLogger) Errorf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Fatalf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Panicf(format string, args ...interface{})              { /* no-op for testing */ }
-func (m *mockLogger) Debugw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Infow(msg string, keysAndValues ...interface{})         { /* no-op for testing */ }
-func (m *mockLogger) Warnw(msg string, keysAndValues ...interface{})         { /* no-op for testing */ }
-func (m *mockLogger) Errorw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Fatalw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) Panicw(msg string, keysAndValues ...interface{})        { /* no-op for testing */ }
-func (m *mockLogger) WithFields(fields logging.Fields) logging.Logger        { return m }
-func (m *mockLogger) WithField(key string, value interface{}) logging.Logger { return m }
-func (m *mockLogger) WithError(err error) logging.Logger                     { return m }
-func (m *mockLogger) WithContext(ctx context.Context) logging.Logger         { return m }
-func (m *mockLogger) Log(level logging.Level, msg string)                    { /* no-op for testing */ }
-func (m *mockLogger) Logf(level logging.Level, format string, args ...interface{}) { /* no-op for testing */
-}
-func (m *mockLogger) Logw(level logging.Level, msg string, keysAndValues ...interface{}) { /* no-op for testing */
-}
-func (m *mockLogger) Clone() logging.Logger { return &mockLogger{} }
-func (m *mockLogger) Close() error          { return nil }
-
 func TestNewHandler(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
 	if handler == nil {
 		t.Fatal("NewHandler() returned nil")
@@ -65,7 +36,7 @@ func TestNewHandler(t *testing.T) {
 }
 
 func TestHealthCheck(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
 	req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
 	rr := httptest.NewRecorder()
@@ -92,13 +63,13 @@ func TestHealthCheck(t *testing.T) {
 		t.Errorf("HealthCheck() status = %q, want %q", health.Status, "healthy")
 	}
 
-	if health.Version != "1.0.0" {
-		t.Errorf("HealthCheck() version = %q, want %q", health.Version, "1.0.0")
+	if health.Version != buildinfo.VersionString() {
+		t.Errorf("HealthCheck() version = %q, want %q", health.Version, buildinfo.VersionString())
 	}
 }
 
 func TestGetStats(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
 	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
 	rr := httptest.NewRecorder()
@@ -126,36 +97,66 @@ func TestGetStats(t *testing.T) {
 	}
 }
 
-func TestWriteJSON(t *testing.T) {
+func TestWriteResponse(t *testing.T) {
 	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
 	data := models.SuccessResponse{Message: "test", Data: "data"}
 
-	writeJSON(rr, http.StatusOK, data)
+	writeResponse(rr, req, http.StatusOK, data)
 
 	// Check status code
 	if rr.Code != http.StatusOK {
-		t.Errorf("writeJSON() status = %d, want %d", rr.Code, http.StatusOK)
+		t.Errorf("writeResponse() status = %d, want %d", rr.Code, http.StatusOK)
 	}
 
 	// Check Content-Type
 	if contentType := rr.Header().Get(contentTypeHeader); contentType != jsonContentType {
-		t.Errorf("writeJSON() Content-Type = %q, want %q", contentType, jsonContentType)
+		t.Errorf("writeResponse() Content-Type = %q, want %q", contentType, jsonContentType)
 	}
 
 	// Check that response body is valid JSON
 	var result interface{}
 	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
-		t.Errorf("writeJSON() produced invalid JSON: %v", err)
+		t.Errorf("writeResponse() produced invalid JSON: %v", err)
+	}
+}
+
+func TestWriteResponseXML(t *testing.T) {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	req.Header.Set("Accept", contentTypeXML)
+
+	writeResponse(rr, req, http.StatusOK, models.SuccessResponse{Message: "test", Data: "data"})
+
+	if contentType := rr.Header().Get(contentTypeHeader); contentType != contentTypeXML {
+		t.Errorf("writeResponse() Content-Type = %q, want %q", contentType, contentTypeXML)
+	}
+	if vary := rr.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("writeResponse() Vary = %q, want %q", vary, "Accept")
+	}
+
+	var result struct {
+		Message string `xml:"message"`
+		Data    string `xml:"data"`
+	}
+	if err := xml.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("writeResponse() produced invalid XML: %v", err)
+	}
+	if result.Message != "test" || result.Data != "data" {
+		t.Errorf("writeResponse() XML = %+v", result)
 	}
 }
 
 func TestHealthCheckOPTIONS(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
-	req := httptest.NewRequest(http.MethodOptions, testHealthPath, nil)
+	handler.Use(CORSMiddleware(CORSOptions{AllowedOrigins: []string{testOrigin}, AllowCredentials: true}))
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+	req := newPreflightRequest(testHealthPath, testOrigin)
 	rr := httptest.NewRecorder()
 
-	handler.HealthCheck(rr, req)
+	mux.ServeHTTP(rr, req)
 
 	// Check status code for OPTIONS
 	if rr.Code != http.StatusNoContent {
@@ -164,9 +165,9 @@ func TestHealthCheckOPTIONS(t *testing.T) {
 
 	// Check CORS headers
 	expectedHeaders := map[string]string{
-		"Access-Control-Allow-Origin":      "*",
+		"Access-Control-Allow-Origin":      testOrigin,
 		"Access-Control-Allow-Credentials": "true",
-		"Access-Control-Allow-Methods":     "POST, OPTIONS, GET, PUT, DELETE",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE, OPTIONS",
 	}
 
 	for header, expectedValue := range expectedHeaders {
@@ -177,8 +178,9 @@ func TestHealthCheckOPTIONS(t *testing.T) {
 }
 
 func TestSetupRoutes(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
+	handler.Use(ApplicationMiddleware(newTestApplication()))
 	mux := http.NewServeMux()
 
 	// Setup routes
@@ -207,51 +209,104 @@ func TestSetupRoutes(t *testing.T) {
 }
 
 func TestGetStatsResponseData(t *testing.T) {
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
 	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
 	rr := httptest.NewRecorder()
 
 	handler.GetStats(rr, req)
 
-	// Parse response
-	var response models.SuccessResponse
+	// Without an application there is no pipeline, so zeros are reported
+	stats := decodeStats(t, rr)
+	if stats.Status != StatusNotStarted || stats.TotalMessages != 0 || stats.Consumed != 0 || stats.Pipeline != nil {
+		t.Errorf("GetStats() = %+v, want zero counts with status %q and no pipeline", stats, StatusNotStarted)
+	}
+}
+
+func TestGetStatsWithApplication(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	application := newTestApplication()
+	defer application.Shutdown()
+	rr := httptest.NewRecorder()
+	handler.GetStats(rr, withApplication(httptest.NewRequest(http.MethodGet, testStatsPath, nil), application))
+
+	stats := decodeStats(t, rr)
+	if stats.Status != StatusNotStarted || stats.Consumed != 0 {
+		t.Errorf("GetStats() = %+v, want a pipeline that has not started with no messages", stats)
+	}
+	metrics, ok := stats.Pipeline["metrics"].(map[string]interface{})
+	if !ok || metrics["state"] != "stopped" {
+		t.Errorf("GetStats() pipeline metrics = %v, want the per-stage detail of a stopped pipeline", stats.Pipeline["metrics"])
+	}
+
+	application.ProcessingPipeline().Stop()
+	rr = httptest.NewRecorder()
+	handler.GetStats(rr, withApplication(httptest.NewRequest(http.MethodGet, testStatsPath, nil), application))
+	if stats := decodeStats(t, rr); stats.Status != StatusNotStarted {
+		t.Errorf("GetStats() status = %q after stopping a pipeline that never started, want %q", stats.Status, StatusNotStarted)
+	}
+}
+
+// decodeStats decodes the stats from a GetStats response
+func decodeStats(t *testing.T, rr *httptest.ResponseRecorder) models.StatsResponse {
+	t.Helper()
+	var response struct {
+		Message string               `json:"message"`
+		Data    models.StatsResponse `json:"data"`
+	}
 	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
 		t.Fatalf("Failed to decode stats response: %v", err)
 	}
+	return response.Data
+}
 
-	// Check that data contains expected fields
-	data, ok := response.Data.(map[string]interface{})
-	if !ok {
-		t.Fatal("GetStats() response data is not a map")
-	}
+// newTestApplication returns an application with an API key configured so
+// redaction can be observed
+func newTestApplication() *app.Application {
+	cfg := config.LoadConfig()
+	cfg.Server.APIKeys = []string{"secret-key"}
+	return app.NewApplication(cfg, logging.NewNopLogger())
+}
+
+// withApplication returns req carrying application in its context
+func withApplication(req *http.Request, application *app.Application) *http.Request {
+	return req.WithContext(app.NewContext(req.Context(), application))
+}
 
-	// Verify the data structure - now expecting total_messages instead of total_users
-	if totalMessages, exists := data["total_messages"]; !exists {
-		t.Error("GetStats() response missing 'total_messages' field")
-	} else if totalMessages != float64(0) {
-		t.Errorf("GetStats() total_messages = %v, want %v", totalMessages, 0)
+// assertApplicationUnavailable checks rr holds the missing-application 500
+func assertApplicationUnavailable(t *testing.T, rr *httptest.ResponseRecorder) {
+	t.Helper()
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	var response models.ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if response.Error != ErrApplicationUnavailable {
+		t.Errorf("error = %q, want %q", response.Error, ErrApplicationUnavailable)
 	}
 }
 
-func TestHandleConfigs(t *testing.T) {
-	logger := &mockLogger{}
+func TestGetConfig(t *testing.T) {
+	logger := logging.NewNopLogger()
 	handler := NewHandler(logger)
+	application := newTestApplication()
 
 	t.Run("GET request", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, testConfigPath, nil)
+		req := withApplication(httptest.NewRequest(http.MethodGet, testConfigPath, nil), application)
 		rr := httptest.NewRecorder()
 
-		handler.HandleConfigs(rr, req)
+		handler.GetConfig(rr, req)
 
 		// Check status code
 		if rr.Code != http.StatusOK {
-			t.Errorf("HandleConfigs GET status = %d, want %d", rr.Code, http.StatusOK)
+			t.Errorf("GetConfig status = %d, want %d", rr.Code, http.StatusOK)
 		}
 
 		// Check Content-Type
 		if contentType := rr.Header().Get(contentTypeHeader); contentType != jsonContentType {
-			t.Errorf("HandleConfigs GET Content-Type = %q, want %q", contentType, jsonContentType)
+			t.Errorf("GetConfig Content-Type = %q, want %q", contentType, jsonContentType)
 		}
 
 		// Parse and validate JSON response
@@ -261,64 +316,433 @@ func TestHandleConfigs(t *testing.T) {
 		}
 
 		if response.Message != MsgConfigRetrieved {
-			t.Errorf("HandleConfigs GET message = %q, want %q", response.Message, MsgConfigRetrieved)
+			t.Errorf("GetConfig message = %q, want %q", response.Message, MsgConfigRetrieved)
 		}
 
-		// Verify data is an empty array
-		data, ok := response.Data.([]interface{})
+		data, ok := response.Data.(map[string]interface{})
 		if !ok {
-			t.Fatal("HandleConfigs GET response data is not an array")
+			t.Fatal("GetConfig response data is not an object")
 		}
-
-		if len(data) != 0 {
-			t.Errorf("HandleConfigs GET data length = %d, want %d", len(data), 0)
+		server := data["config"].(map[string]interface{})["server"].(map[string]interface{})
+		if server["apiKeys"] != config.RedactedValue {
+			t.Errorf("GetConfig apiKeys = %v, want %q", server["apiKeys"], config.RedactedValue)
 		}
 	})
 
+	t.Run("GET request without application", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetConfig(rr, httptest.NewRequest(http.MethodGet, testConfigPath, nil))
+		assertApplicationUnavailable(t, rr)
+	})
+
 	t.Run("OPTIONS request", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodOptions, testConfigPath, nil)
+		corsHandler := NewHandler(logger)
+		corsHandler.Use(CORSMiddleware(CORSOptions{AllowedOrigins: []string{testOrigin}}))
+		mux := http.NewServeMux()
+		corsHandler.SetupRoutes(mux)
+		req := newPreflightRequest(testConfigPath, testOrigin)
 		rr := httptest.NewRecorder()
 
-		handler.HandleConfigs(rr, req)
+		mux.ServeHTTP(rr, req)
 
 		// Check status code for OPTIONS
 		if rr.Code != http.StatusNoContent {
-			t.Errorf("HandleConfigs OPTIONS status = %d, want %d", rr.Code, http.StatusNoContent)
+			t.Errorf("GetConfig OPTIONS status = %d, want %d", rr.Code, http.StatusNoContent)
 		}
 
 		// Check CORS headers
 		expectedHeaders := map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-			"Access-Control-Allow-Methods":     "POST, OPTIONS, GET, PUT, DELETE",
+			"Access-Control-Allow-Origin":      testOrigin,
+			"Access-Control-Allow-Credentials": "",
+			"Access-Control-Allow-Methods":     "GET, POST, PUT, DELETE, OPTIONS",
 		}
 
 		for header, expectedValue := range expectedHeaders {
 			if got := rr.Header().Get(header); got != expectedValue {
-				t.Errorf("HandleConfigs CORS header %s = %q, want %q", header, got, expectedValue)
+				t.Errorf("GetConfig CORS header %s = %q, want %q", header, got, expectedValue)
 			}
 		}
 	})
 
 	t.Run("Unsupported method", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodPost, testConfigPath, nil)
+		mux := http.NewServeMux()
+		handler.SetupRoutes(mux)
 		rr := httptest.NewRecorder()
 
-		handler.HandleConfigs(rr, req)
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, testConfigPath, nil))
 
-		// Check status code for unsupported method
+		// The mux rejects methods without a registered handler
 		if rr.Code != http.StatusMethodNotAllowed {
-			t.Errorf("HandleConfigs POST status = %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+			t.Errorf("DELETE %s status = %d, want %d", testConfigPath, rr.Code, http.StatusMethodNotAllowed)
+		}
+		if allow := rr.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodPost) {
+			t.Errorf("DELETE %s Allow header = %q, want GET and POST", testConfigPath, allow)
 		}
+	})
+}
+
+func TestSetupRoutesMethodNotAllowed(t *testing.T) {
+	logger := logging.NewNopLogger()
+	handler := NewHandler(logger)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
 
-		// Parse and validate error response
-		var response models.ErrorResponse
+	testCases := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodDelete, testHealthPath},
+		{http.MethodPost, testStatsPath},
+		{http.MethodPut, testConfigPath},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, http.StatusMethodNotAllowed, rr.Code)
+		}
+
+		allow := rr.Header().Get("Allow")
+		if !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodOptions) {
+			t.Errorf("%s %s: Allow header = %q, want GET and OPTIONS", tc.method, tc.path, allow)
+		}
+	}
+}
+
+func TestSetupRoutesExactPathMatching(t *testing.T) {
+	logger := logging.NewNopLogger()
+	handler := NewHandler(logger)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	testCases := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, testHealthPath + "/extra"},
+		{http.MethodGet, testConfigPath + "extra"},
+		{http.MethodPost, testConfigPath + "extra"},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, http.StatusNotFound, rr.Code)
+		}
+	}
+}
+
+func TestSetupRoutesPreflight(t *testing.T) {
+	logger := logging.NewNopLogger()
+	handler := NewHandler(logger)
+	handler.Use(CORSMiddleware(CORSOptions{AllowedOrigins: []string{testOrigin}}))
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	for _, path := range []string{testHealthPath, testStatsPath, testConfigPath} {
+		req := newPreflightRequest(path, testOrigin)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("OPTIONS %s: expected status %d, got %d", path, http.StatusNoContent, rr.Code)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got == "" {
+			t.Errorf("OPTIONS %s: missing Access-Control-Allow-Methods header", path)
+		}
+	}
+}
+
+// newPreflightRequest builds a CORS preflight request from the given origin
+func newPreflightRequest(path, origin string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	return req
+}
+
+func TestGetServices(t *testing.T) {
+	decode := func(t *testing.T, handler *Handler, application *app.Application) []interface{} {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.GetServices(rr, withApplication(httptest.NewRequest(http.MethodGet, APIServicesPath, nil), application))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GetServices status = %d, want %d", rr.Code, http.StatusOK)
+		}
+		var response models.SuccessResponse
 		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
-			t.Fatalf("Failed to decode error response: %v", err)
+			t.Fatalf("Failed to decode services response: %v", err)
 		}
+		if response.Message != MsgServicesRetrieved {
+			t.Errorf("GetServices message = %q, want %q", response.Message, MsgServicesRetrieved)
+		}
+		services, ok := response.Data.([]interface{})
+		if !ok {
+			t.Fatalf("GetServices data is %T, want an array", response.Data)
+		}
+		return services
+	}
+
+	handler := NewHandler(logging.NewNopLogger())
+	application := newTestApplication()
+	if services := decode(t, handler, application); len(services) != 0 {
+		t.Errorf("GetServices without registered services returned %v, want none", services)
+	}
+
+	if err := application.Registry().Register("cache", struct{}{}); err != nil {
+		t.Fatalf("Register() returned error: %v", err)
+	}
+	services := decode(t, handler, application)
+	if len(services) != 1 || services[0].(map[string]interface{})["name"] != "cache" {
+		t.Errorf("GetServices returned %v, want the cache service", services)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.GetServices(rr, httptest.NewRequest(http.MethodGet, APIServicesPath, nil))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestApplicationMiddleware(t *testing.T) {
+	application := newTestApplication()
+	handler := NewHandler(logging.NewNopLogger())
+	handler.Use(ApplicationMiddleware(application))
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, APIServicesPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("GET %s through the middleware status = %d, want %d", APIServicesPath, rr.Code, http.StatusOK)
+	}
+}
+
+func TestRestartPipeline(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	application := newTestApplication()
+	defer application.Shutdown()
+	restart := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.RestartPipeline(rr, withApplication(httptest.NewRequest(http.MethodPost, APIPipelineRestartPath, nil), application))
+		return rr
+	}
+
+	// The application has not finished starting
+	if rr := restart(); rr.Code != http.StatusConflict {
+		t.Errorf("RestartPipeline before MarkReady status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	if err := application.MarkReady(); err != nil {
+		t.Fatalf("MarkReady() returned error: %v", err)
+	}
+	old := application.ProcessingPipeline()
+	rr := restart()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("RestartPipeline status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var response models.SuccessResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode restart response: %v", err)
+	}
+	if response.Message != MsgPipelineRestarted {
+		t.Errorf("RestartPipeline message = %q, want %q", response.Message, MsgPipelineRestarted)
+	}
+	if data, ok := response.Data.(map[string]interface{}); !ok || data["stop_timed_out"] != false {
+		t.Errorf("RestartPipeline data = %v, want the restart timings", response.Data)
+	}
+	if application.ProcessingPipeline() == old {
+		t.Error("Expected RestartPipeline to replace the pipeline")
+	}
+
+	rr = httptest.NewRecorder()
+	handler.RestartPipeline(rr, httptest.NewRequest(http.MethodPost, APIPipelineRestartPath, nil))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestUpdatePipelineTopics(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	application := newTestApplication()
+	defer application.Shutdown()
+	update := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, APIPipelineTopicsPath, strings.NewReader(body))
+		handler.UpdatePipelineTopics(rr, withApplication(req, application))
+		return rr
+	}
 
-		if response.Error != ErrMethodNotAllowed {
-			t.Errorf("HandleConfigs POST error = %q, want %q", response.Error, ErrMethodNotAllowed)
+	for _, body := range []string{`{"topics":[]}`, `{}`, `{"topics":[""]}`, `not json`} {
+		if rr := update(body); rr.Code != http.StatusBadRequest {
+			t.Errorf("UpdatePipelineTopics(%s) status = %d, want %d", body, rr.Code, http.StatusBadRequest)
 		}
-	})
+	}
+
+	rr := update(`{"topics":["orders","refunds"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("UpdatePipelineTopics status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var response models.SuccessResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode topics response: %v", err)
+	}
+	if response.Message != MsgTopicsUpdated {
+		t.Errorf("UpdatePipelineTopics message = %q, want %q", response.Message, MsgTopicsUpdated)
+	}
+	if topics := application.Config().Processing.Input.Topics; len(topics) != 2 || topics[1] != "refunds" {
+		t.Errorf("Expected the configuration to hold the new topics, got %v", topics)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.UpdatePipelineTopics(rr, httptest.NewRequest(http.MethodPut, APIPipelineTopicsPath, strings.NewReader(`{"topics":["a"]}`)))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestUpdateProcessingConfig(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	application := newTestApplication()
+	defer application.Shutdown()
+	update := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, testConfigPath, strings.NewReader(body))
+		handler.UpdateProcessingConfig(rr, withApplication(req, application))
+		return rr
+	}
+
+	rr := update(`{"output":{"flushTimeout":"-1s","maxMessagesPerSecond":-5}}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("UpdateProcessingConfig status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	var invalid models.ValidationErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&invalid); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if invalid.Error != ErrInvalidConfig || len(invalid.Details) != 2 || !strings.HasPrefix(invalid.Details[0], "processing.output.flushTimeout") {
+		t.Errorf("Expected a problem per invalid field, got %+v", invalid)
+	}
+	for _, body := range []string{`not json`, `{"output":{"batchSize":0}}`} {
+		if rr := update(body); rr.Code != http.StatusBadRequest {
+			t.Errorf("UpdateProcessingConfig %s status = %d, want %d", body, rr.Code, http.StatusBadRequest)
+		}
+	}
+
+	rr = update(`{"processor":{"batchSize":25},"output":{"deadLetterTopic":"dlq"}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("UpdateProcessingConfig status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var response struct {
+		Message string
+		Data    app.ProcessingConfigUpdate
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode update response: %v", err)
+	}
+	if response.Message != MsgConfigUpdated {
+		t.Errorf("UpdateProcessingConfig message = %q, want %q", response.Message, MsgConfigUpdated)
+	}
+	if applied := response.Data.Applied; len(applied) != 1 || applied[0] != "processing.processor.batchSize" {
+		t.Errorf("Expected the batch size applied, got %v", applied)
+	}
+	if restart := response.Data.RestartRequired; len(restart) != 1 || restart[0] != "processing.output.deadLetterTopic" {
+		t.Errorf("Expected the dead-letter topic to need a restart, got %v", restart)
+	}
+	processor := application.ProcessingPipeline().GetStats()["processor_stats"].(map[string]interface{})
+	if processor["batch_size"] != 25 {
+		t.Errorf("Expected the running pipeline to use batch size 25, got %v", processor["batch_size"])
+	}
+
+	rr = httptest.NewRecorder()
+	handler.UpdateProcessingConfig(rr, httptest.NewRequest(http.MethodPost, testConfigPath, strings.NewReader(`{}`)))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestReloadFilterRules(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	writeRules := func(rules string) {
+		t.Helper()
+		if err := os.WriteFile(rulesFile, []byte(rules), 0o644); err != nil {
+			t.Fatalf("Failed to write rules file: %v", err)
+		}
+	}
+	cfg := config.LoadConfig()
+	cfg.Processing.Filter.RulesFile = rulesFile
+	application := app.NewApplication(cfg, logging.NewNopLogger())
+	defer application.Shutdown()
+	handler := NewHandler(logging.NewNopLogger())
+	reload := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ReloadFilterRules(rr, withApplication(httptest.NewRequest(http.MethodPost, APIConfigFilterPath, nil), application))
+		return rr
+	}
+
+	writeRules(`[{"uuid":"a","payload":[{"condition":{"all":[{"identifier":"type","operator":"eq","value":"noise"}]}}]},
+		{"uuid":"b","payload":[{"condition":{"any":[{"identifier":"level","operator":"lt","value":2}]}}]}]`)
+	rr := reload()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("ReloadFilterRules status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var response models.SuccessResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode reload response: %v", err)
+	}
+	if data, _ := response.Data.(map[string]interface{}); response.Message != MsgFilterReloaded || data["rules"] != float64(2) {
+		t.Errorf("ReloadFilterRules response = %+v, want 2 rules", response)
+	}
+
+	writeRules(`{"uuid":`)
+	if rr := reload(); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ReloadFilterRules with a malformed file status = %d, want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ReloadFilterRules(rr, httptest.NewRequest(http.MethodPost, APIConfigFilterPath, nil))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestReloadSchemas(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "orders.json")
+	writeSchema := func(schema string) {
+		t.Helper()
+		if err := os.WriteFile(schemaFile, []byte(schema), 0o644); err != nil {
+			t.Fatalf("Failed to write schema file: %v", err)
+		}
+	}
+	cfg := config.LoadConfig()
+	cfg.Processing.Validation.Schemas = map[string]string{"orders": schemaFile}
+	application := app.NewApplication(cfg, logging.NewNopLogger())
+	defer application.Shutdown()
+	handler := NewHandler(logging.NewNopLogger())
+	reload := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ReloadSchemas(rr, withApplication(httptest.NewRequest(http.MethodPost, APIConfigSchemasPath, nil), application))
+		return rr
+	}
+
+	writeSchema(`{"type":"object","required":["id"]}`)
+	rr := reload()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("ReloadSchemas status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var response models.SuccessResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode reload response: %v", err)
+	}
+	if data, _ := response.Data.(map[string]interface{}); response.Message != MsgSchemasReloaded || data["schemas"] != float64(1) {
+		t.Errorf("ReloadSchemas response = %+v, want 1 schema", response)
+	}
+
+	writeSchema(`{"type":"object","oneOf":[]}`)
+	if rr := reload(); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ReloadSchemas with an unsupported keyword status = %d, want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ReloadSchemas(rr, httptest.NewRequest(http.MethodPost, APIConfigSchemasPath, nil))
+	assertApplicationUnavailable(t, rr)
 }
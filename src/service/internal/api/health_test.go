@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// fakeHealthReporter returns canned per-service results
+type fakeHealthReporter struct {
+	results map[string]error
+}
+
+func (f *fakeHealthReporter) CheckHealth(ctx context.Context) map[string]error {
+	if _, ok := ctx.Deadline(); !ok {
+		return map[string]error{"deadline": errors.New("health checks must run with a timeout")}
+	}
+	return f.results
+}
+
+func TestHealthCheckAggregatesServices(t *testing.T) {
+	reporter := &fakeHealthReporter{results: map[string]error{"db": nil, "cache": nil}}
+	handler := NewHandler(logging.NewNopLogger())
+	handler.SetHealthReporter(reporter)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	serve := func() (int, models.HealthResponse) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+		var response models.HealthResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode health response: %v", err)
+		}
+		return rr.Code, response
+	}
+
+	code, response := serve()
+	if code != http.StatusOK || response.Status != StatusHealthy {
+		t.Errorf("expected 200 %q, got %d %q", StatusHealthy, code, response.Status)
+	}
+	if len(response.Services) != 2 || response.Services[0].Name != "cache" || response.Services[1].Name != "db" {
+		t.Errorf("expected services sorted by name, got %+v", response.Services)
+	}
+
+	reporter.results["db"] = errors.New("connection refused")
+	code, response = serve()
+	if code != http.StatusServiceUnavailable || response.Status != StatusDegraded {
+		t.Errorf("expected 503 %q, got %d %q", StatusDegraded, code, response.Status)
+	}
+	for _, service := range response.Services {
+		switch service.Name {
+		case "db":
+			if service.Status != StatusUnhealthy || service.Error != "connection refused" {
+				t.Errorf("unexpected db health: %+v", service)
+			}
+		case "cache":
+			if service.Status != StatusHealthy || service.Error != "" {
+				t.Errorf("unexpected cache health: %+v", service)
+			}
+		}
+	}
+}
+
+func TestHealthCheckReportsStoppedPipeline(t *testing.T) {
+	application := newTestApplication()
+	defer application.Shutdown()
+	handler := NewHandler(logging.NewNopLogger())
+	handler.SetHealthReporter(application)
+
+	// The application claims to be running but its pipeline never started
+	if err := application.MarkReady(); err != nil {
+		t.Fatalf("MarkReady() returned error: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	handler.HealthCheck(rr, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+
+	var response models.HealthResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode health response: %v", err)
+	}
+	if rr.Code != http.StatusServiceUnavailable || response.Status != StatusDegraded {
+		t.Errorf("expected 503 %q, got %d %q", StatusDegraded, rr.Code, response.Status)
+	}
+	if len(response.Services) != 1 || response.Services[0].Name != "pipeline" || response.Services[0].Error == "" {
+		t.Errorf("expected a failing pipeline entry with a reason, got %+v", response.Services)
+	}
+	if response.Details["pipeline"] != "pipeline is stopped" || response.Details["input"] != "healthy" {
+		t.Errorf("unexpected health details %v", response.Details)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// GetLogLevel reports the level of the application logger, or with a
+// component query parameter the level of that component: its override if
+// it has one, and the application level otherwise
+func (h *Handler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	level := application.Logger().GetLevel()
+	component := r.URL.Query().Get("component")
+	if component != "" {
+		for _, leveler := range componentLevelers(application) {
+			if override, ok := leveler.ComponentLevels()[component]; ok {
+				level = override
+				break
+			}
+		}
+	}
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgLogLevel,
+		Data:    models.LogLevel{Level: strings.ToLower(level.String()), Component: component},
+	})
+}
+
+// SetLogLevel changes the level of the application logger, and with it every
+// logger derived from it, until the next restart or configuration reload.
+// With a component it overrides the level of that component only, in the
+// application and pipeline loggers.
+func (h *Handler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	application, ok := h.applicationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.LogLevel
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	level, err := logging.ParseLevel(request.Level)
+	if err != nil {
+		writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+			Error:   ErrInvalidLogLevel,
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if request.Component != "" {
+		levelers := componentLevelers(application)
+		if len(levelers) == 0 {
+			writeResponse(w, r, http.StatusBadRequest, models.ErrorResponse{
+				Error:   ErrInvalidLogLevel,
+				Message: "the loggers do not support component levels",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		for _, leveler := range levelers {
+			leveler.SetComponentLevel(request.Component, level)
+		}
+		h.requestLogger(r).Infow("Component log level changed", "component", request.Component, "to", strings.ToLower(level.String()))
+		writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+			Message: MsgLogLevelUpdated,
+			Data:    models.LogLevel{Level: strings.ToLower(level.String()), Component: request.Component},
+		})
+		return
+	}
+
+	logger := application.Logger()
+	previous := logger.GetLevel()
+	logger.SetLevel(level)
+	h.requestLogger(r).Infow("Log level changed", "from", strings.ToLower(previous.String()), "to", strings.ToLower(level.String()))
+	writeResponse(w, r, http.StatusOK, models.SuccessResponse{
+		Message: MsgLogLevelUpdated,
+		Data:    models.LogLevel{Level: strings.ToLower(level.String())},
+	})
+}
+
+// componentLevelers returns the application and pipeline loggers of
+// application that support per-component levels
+func componentLevelers(application *app.Application) []logging.ComponentLeveler {
+	loggers := []logging.Logger{application.Logger()}
+	if pipeline := application.ProcessingPipeline(); pipeline != nil {
+		loggers = append(loggers, pipeline.PipelineLogger())
+	}
+	var levelers []logging.ComponentLeveler
+	for _, logger := range loggers {
+		if leveler, ok := logger.(logging.ComponentLeveler); ok {
+			levelers = append(levelers, leveler)
+		}
+	}
+	return levelers
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestLogLevelEndpoints(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "main.log")
+	logger, err := logging.NewLogger(&logging.LoggerConfig{Level: logging.InfoLevel, FilePath: logFile, LoggerName: "main", ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("NewLogger() returned error: %v", err)
+	}
+	defer logger.Close()
+	application := app.NewApplication(config.LoadConfig(), logger)
+	derived := logger.WithField("module", "orders")
+	handler := NewHandler(logging.NewNopLogger())
+
+	request := func(method, body string) (int, models.LogLevel) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req := withApplication(httptest.NewRequest(method, APILoggingLevelPath, strings.NewReader(body)), application)
+		if method == http.MethodPut {
+			handler.SetLogLevel(rr, req)
+		} else {
+			handler.GetLogLevel(rr, req)
+		}
+		var response struct{ Data models.LogLevel }
+		json.NewDecoder(rr.Body).Decode(&response)
+		return rr.Code, response.Data
+	}
+
+	derived.Debug("debug before")
+	if code, level := request(http.MethodPut, `{"level":"DEBUG"}`); code != http.StatusOK || level.Level != "debug" {
+		t.Fatalf("PUT debug = %d %+v, want 200 debug", code, level)
+	}
+	// The change reaches loggers derived before it
+	derived.Debug("debug while enabled")
+	if code, level := request(http.MethodGet, ""); code != http.StatusOK || level.Level != "debug" {
+		t.Errorf("GET = %d %+v, want 200 debug", code, level)
+	}
+	if code, level := request(http.MethodPut, `{"level":"info"}`); code != http.StatusOK || level.Level != "info" {
+		t.Fatalf("PUT info = %d %+v, want 200 info", code, level)
+	}
+	derived.Debug("debug after")
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "debug while enabled") {
+		t.Errorf("Expected the debug line logged at debug level, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "debug before") || strings.Contains(string(content), "debug after") {
+		t.Errorf("Expected debug lines at info level to be dropped, got:\n%s", content)
+	}
+}
+
+func TestSetLogLevelInvalid(t *testing.T) {
+	application := newTestApplication()
+	handler := NewHandler(logging.NewNopLogger())
+
+	for _, body := range []string{`{"level":"verbose"}`, `{"level":""}`, `{"level":`} {
+		rr := httptest.NewRecorder()
+		handler.SetLogLevel(rr, withApplication(httptest.NewRequest(http.MethodPut, APILoggingLevelPath, strings.NewReader(body)), application))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", body, rr.Code, http.StatusBadRequest)
+			continue
+		}
+		var response models.ErrorResponse
+		json.NewDecoder(rr.Body).Decode(&response)
+		if body != `{"level":` && (response.Error != ErrInvalidLogLevel || !strings.Contains(response.Message, "debug, info, warn, error, fatal, panic")) {
+			t.Errorf("%s: error = %+v, want %q listing the valid levels", body, response, ErrInvalidLogLevel)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	handler.GetLogLevel(rr, httptest.NewRequest(http.MethodGet, APILoggingLevelPath, nil))
+	assertApplicationUnavailable(t, rr)
+}
+
+func TestComponentLogLevel(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "main.log")
+	logger, err := logging.NewLogger(&logging.LoggerConfig{Level: logging.InfoLevel, FilePath: logFile, LoggerName: "main", ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("NewLogger() returned error: %v", err)
+	}
+	defer logger.Close()
+	application := app.NewApplication(config.LoadConfig(), logger)
+	handler := NewHandler(logging.NewNopLogger())
+
+	rr := httptest.NewRecorder()
+	handler.SetLogLevel(rr, withApplication(httptest.NewRequest(http.MethodPut, APILoggingLevelPath, strings.NewReader(`{"level":"debug","component":"processing"}`)), application))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT processing debug = %d %s, want 200", rr.Code, rr.Body)
+	}
+	logger.WithField(logging.FieldComponent, "processing").Debug("processing debug")
+	logger.WithField(logging.FieldComponent, "api").Debug("api debug")
+
+	get := func(path string) models.LogLevel {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.GetLogLevel(rr, withApplication(httptest.NewRequest(http.MethodGet, path, nil), application))
+		var response struct{ Data models.LogLevel }
+		json.NewDecoder(rr.Body).Decode(&response)
+		return response.Data
+	}
+	if level := get(APILoggingLevelPath + "?component=processing"); level != (models.LogLevel{Level: "debug", Component: "processing"}) {
+		t.Errorf("GET processing = %+v, want debug", level)
+	}
+	if level := get(APILoggingLevelPath); level.Level != "info" {
+		t.Errorf("GET = %+v, want the application level unchanged", level)
+	}
+	pipelineLevels := application.ProcessingPipeline().PipelineLogger().(logging.ComponentLeveler).ComponentLevels()
+	if pipelineLevels["processing"] != logging.DebugLevel {
+		t.Errorf("Expected the override applied to the pipeline logger, got %v", pipelineLevels)
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "processing debug") || strings.Contains(string(content), "api debug") {
+		t.Errorf("Expected only the processing component's debug line, got:\n%s", content)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"servicegomodule/internal/metrics"
+)
+
+// MetricsPath serves the Prometheus metrics when they are enabled
+const MetricsPath = "/metrics"
+
+// HTTP metric names. Requests are labelled with the route pattern that
+// served them, such as /api/v1/config/, rather than the request path, so the
+// label values stay bounded.
+const (
+	// MetricHTTPRequests counts requests by method, route and status code
+	MetricHTTPRequests = "http_requests_total"
+	// MetricHTTPRequestDuration is the time taken to serve requests by
+	// method and route
+	MetricHTTPRequestDuration = "http_request_duration_seconds"
+)
+
+// httpMetrics records the requests served by each route. The methods of a
+// nil httpMetrics do nothing.
+type httpMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+// SetMetrics registers the HTTP metrics in registry and serves registry at
+// /metrics, from the admin routes when they are set up separately. It must
+// be called once, before SetupRoutes.
+func (h *Handler) SetMetrics(registry *metrics.Registry) {
+	h.metrics = &httpMetrics{
+		registry: registry,
+		requests: registry.NewCounterVec(MetricHTTPRequests, "HTTP requests served, by method, route and status code.", "method", "route", "status"),
+		duration: registry.NewHistogramVec(MetricHTTPRequestDuration, "Time taken to serve HTTP requests, by method and route.", metrics.DefaultBuckets, "method", "route"),
+	}
+}
+
+// route returns the /metrics route, or nil when metrics are disabled
+func (m *httpMetrics) route() *route {
+	if m == nil {
+		return nil
+	}
+	return &route{Method: http.MethodGet, Pattern: MetricsPath, Handler: m.registry.Handler().ServeHTTP, Admin: true}
+}
+
+// instrument wraps next, the handler of the route registered for method and
+// pattern, to record its requests. It wraps the whole middleware chain, so
+// requests rejected by middleware are counted too.
+func (m *httpMetrics) instrument(method, pattern string, next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.requests.Inc(method, pattern, strconv.Itoa(status))
+		m.duration.Observe(time.Since(start).Seconds(), method, pattern)
+	})
+}
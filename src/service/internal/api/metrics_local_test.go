@@ -0,0 +1,104 @@
+//go:build local
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/app"
+	"servicegomodule/internal/config"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// startLocalApplication starts an application over the file-based local
+// message bus once prepare has set it up, and shuts it down when the test
+// ends. It reads, writes and dead-letters to fresh topics named after prefix,
+// since the bus keeps topics on disk across runs.
+func startLocalApplication(t *testing.T, prefix string, prepare ...func(*app.Application)) (*app.Application, *config.RawConfig) {
+	t.Helper()
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	cfg := config.LoadConfig()
+	cfg.Processing.Input.Topics = []string{prefix + "-input-" + suffix}
+	cfg.Processing.Input.PollTimeout = 100 * time.Millisecond
+	cfg.Processing.Output.OutputTopic = prefix + "-output-" + suffix
+	cfg.Processing.Output.DeadLetterTopic = prefix + "-dead-" + suffix
+	cfg.Processing.Output.BatchSize = 1
+	cfg.Processing.Processor.ProcessingDelay = 0
+
+	application := app.NewApplication(cfg, logging.NewNopLogger())
+	for _, p := range prepare {
+		p(application)
+	}
+	if err := application.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	t.Cleanup(func() { application.Shutdown() })
+	return application, cfg
+}
+
+// sendRecords sends n processing records, r0 to r(n-1), to topic
+func sendRecords(t *testing.T, topic string, n int) {
+	t.Helper()
+	producer := messagebus.NewProducer("kafka-producer.yaml")
+	t.Cleanup(func() { producer.Close() })
+	for i := 0; i < n; i++ {
+		record := fmt.Sprintf(`{"id":"r%d","data":{"name":"widget"}}`, i)
+		if _, _, err := producer.Send(context.Background(), &messagebus.Message{Topic: topic, Value: []byte(record)}); err != nil {
+			t.Fatalf("Send() returned error: %v", err)
+		}
+	}
+}
+
+// awaitPublished waits until the application's pipeline has published n
+// messages
+func awaitPublished(t *testing.T, application *app.Application, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for application.ProcessingPipeline().Metrics().Published < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d messages published, got %d", n, application.ProcessingPipeline().Metrics().Published)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestMetricsAfterPipelineTraffic pushes messages through the application
+// over the file-based local message bus and scrapes /metrics
+func TestMetricsAfterPipelineTraffic(t *testing.T) {
+	application, cfg := startLocalApplication(t, "metrics-test")
+	handler := NewHandler(logging.NewNopLogger())
+	handler.SetMetrics(application.Metrics())
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	sendRecords(t, cfg.Processing.Input.Topics[0], 3)
+	awaitPublished(t, application, 3)
+	// Stopping waits for the output handler, so every flush has been observed
+	if err := application.ProcessingPipeline().Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	exposition := rr.Body.String()
+	for _, line := range []string{
+		app.MetricPipelineMessages + `{stage="consumed"} 3`,
+		app.MetricPipelineMessages + `{stage="published"} 3`,
+		app.MetricPipelineRunning + " 0",
+		app.MetricTopicConsumed + `{topic="` + cfg.Processing.Input.Topics[0] + `"} 3`,
+		app.MetricFlushDuration + "_count 3",
+		app.MetricFlushMessages + " 3",
+		`# TYPE ` + app.MetricChannelFill + " gauge",
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("Expected %q in the exposition:\n%s", line, exposition)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/metrics"
+	"sharedgomodule/logging"
+)
+
+func TestSetMetricsRecordsRequests(t *testing.T) {
+	registry := metrics.NewRegistry()
+	handler := NewHandler(logging.NewNopLogger())
+	handler.Use(APIKeyAuthMiddleware([]string{"secret-key"}))
+	handler.SetMetrics(registry)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	for _, target := range []string{HealthPath, HealthPath, APIStatsPath} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("Expected a 200 exposition, got %d with %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	exposition := rr.Body.String()
+	for _, line := range []string{
+		MetricHTTPRequests + `{method="GET",route="/health",status="200"} 2`,
+		MetricHTTPRequests + `{method="GET",route="/api/v1/stats",status="401"} 1`,
+		MetricHTTPRequestDuration + `_count{method="GET",route="/health"} 2`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("Expected %q in the exposition:\n%s", line, exposition)
+		}
+	}
+}
+
+func TestMetricsDisabledByDefault(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(logging.NewNopLogger()).SetupRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected no /metrics route without SetMetrics, got %d", rr.Code)
+	}
+}
+
+func TestMetricsServedFromAdminRoutes(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	handler.SetMetrics(metrics.NewRegistry())
+	api, admin := http.NewServeMux(), http.NewServeMux()
+	handler.SetupAPIRoutes(api)
+	handler.SetupAdminRoutes(admin)
+
+	for _, tc := range []struct {
+		mux  *http.ServeMux
+		want int
+	}{{api, http.StatusNotFound}, {admin, http.StatusOK}} {
+		rr := httptest.NewRecorder()
+		tc.mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+		if rr.Code != tc.want {
+			t.Errorf("Expected %d for /metrics, got %d", tc.want, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// Error message constants for middleware
+const (
+	ErrInternalServer   = "Internal server error"
+	ErrOriginNotAllowed = "Origin not allowed"
+)
+
+// apiPathPrefix marks routes that carry API traffic, as opposed to
+// operational endpoints such as /health
+const apiPathPrefix = "/api/"
+
+// Middleware wraps an http.Handler with cross-cutting behavior
+type Middleware func(http.Handler) http.Handler
+
+// Use appends middleware to the handler chain. Middleware registered first is
+// the outermost wrapper, so it sees the request before any middleware added
+// later. Use must be called before SetupRoutes for the chain to take effect.
+func (h *Handler) Use(mw ...Middleware) {
+	h.middlewares = append(h.middlewares, mw...)
+}
+
+// wrap applies the middleware chain to the given handler
+func (h *Handler) wrap(next http.Handler) http.Handler {
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		next = h.middlewares[i](next)
+	}
+	return next
+}
+
+// CORSOptions configures the CORS middleware. An empty AllowedOrigins list
+// disables CORS handling entirely.
+type CORSOptions struct {
+	AllowedOrigins   []string // Exact origins allowed; "*" allows any origin without credentials
+	AllowedMethods   []string // Methods advertised on preflight responses
+	AllowedHeaders   []string // Request headers advertised on preflight responses
+	MaxAge           int      // Seconds a preflight response may be cached; 0 omits the header
+	AllowCredentials bool     // Whether credentialed requests are allowed for listed origins
+}
+
+// Default CORS values used when the configuration leaves them empty
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", APIKeyHeader, RequestIDHeader}
+)
+
+// corsRules is the precomputed form of CORSOptions used on each request
+type corsRules struct {
+	allowed          map[string]bool
+	allowAny         bool
+	allowMethods     string
+	allowHeaders     string
+	maxAge           int
+	allowCredentials bool
+}
+
+func newCORSRules(opts CORSOptions) *corsRules {
+	allowed := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		allowed[origin] = true
+	}
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+
+	return &corsRules{
+		allowed:          allowed,
+		allowAny:         allowed["*"],
+		allowMethods:     strings.Join(methods, ", "),
+		allowHeaders:     strings.Join(headers, ", "),
+		maxAge:           opts.MaxAge,
+		allowCredentials: opts.AllowCredentials,
+	}
+}
+
+// CORSPolicy holds a CORS configuration that can be replaced at runtime,
+// for example when the configuration is reloaded.
+type CORSPolicy struct {
+	rules atomic.Pointer[corsRules]
+}
+
+// NewCORSPolicy creates a CORS policy from the given options
+func NewCORSPolicy(opts CORSOptions) *CORSPolicy {
+	p := &CORSPolicy{}
+	p.Update(opts)
+	return p
+}
+
+// Update replaces the policy; subsequent requests use the new options
+func (p *CORSPolicy) Update(opts CORSOptions) {
+	p.rules.Store(newCORSRules(opts))
+}
+
+// CORSMiddleware applies the configured CORS policy. The request origin is
+// echoed back only when it is in the allow-list; preflight OPTIONS requests
+// are answered without invoking the wrapped handler.
+func CORSMiddleware(opts CORSOptions) Middleware {
+	return NewCORSPolicy(opts).Middleware()
+}
+
+// Middleware returns a middleware enforcing the current policy. An empty
+// allow-list passes requests through untouched.
+func (p *CORSPolicy) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rules := p.rules.Load()
+			origin := r.Header.Get("Origin")
+			if len(rules.allowed) == 0 || origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			originAllowed := rules.allowed[origin] || rules.allowAny
+			isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !originAllowed {
+				if isPreflight {
+					writeResponse(w, r, http.StatusForbidden, models.ErrorResponse{
+						Error: ErrOriginNotAllowed,
+						Code:  http.StatusForbidden,
+					})
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			// Credentials are never combined with a wildcard allow-list
+			if rules.allowCredentials && rules.allowed[origin] {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if isPreflight {
+				w.Header().Set("Access-Control-Allow-Methods", rules.allowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", rules.allowHeaders)
+				if rules.maxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rules.maxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RecoveryMiddleware converts a panic in a downstream handler into a 500
+// response and logs the panic instead of crashing the connection
+func RecoveryMiddleware(logger logging.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					LoggerFromContext(r.Context(), logger).Errorw("Handler panic recovered", "panic", rec, "method", r.Method, "path", r.URL.Path)
+					writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+						Error: ErrInternalServer,
+						Code:  http.StatusInternalServerError,
+					})
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
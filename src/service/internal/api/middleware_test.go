@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// recordingMiddleware appends its name to calls before and after the wrapped handler runs
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name+":before")
+			next.ServeHTTP(w, r)
+			*calls = append(*calls, name+":after")
+		})
+	}
+}
+
+func TestUseMiddlewareOrdering(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	var calls []string
+	handler.Use(recordingMiddleware("first", &calls), recordingMiddleware("second", &calls))
+	handler.Use(recordingMiddleware("third", &calls))
+
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	expected := []string{
+		"first:before", "second:before", "third:before",
+		"third:after", "second:after", "first:after",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("middleware call order = %v, want %v", calls, expected)
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	opts := CORSOptions{
+		AllowedOrigins:   []string{testOrigin},
+		AllowedMethods:   []string{http.MethodGet},
+		AllowedHeaders:   []string{"Content-Type"},
+		MaxAge:           300,
+		AllowCredentials: true,
+	}
+	wrapped := CORSMiddleware(opts)(next)
+
+	t.Run("preflight from allowed origin", func(t *testing.T) {
+		nextCalled = false
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, newPreflightRequest(testHealthPath, testOrigin))
+
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+		}
+		if nextCalled {
+			t.Error("expected wrapped handler not to be called for preflight")
+		}
+		expectedHeaders := map[string]string{
+			"Access-Control-Allow-Origin":      testOrigin,
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET",
+			"Access-Control-Allow-Headers":     "Content-Type",
+			"Access-Control-Max-Age":           "300",
+			"Vary":                             "Origin",
+		}
+		for header, expectedValue := range expectedHeaders {
+			if got := rr.Header().Get(header); got != expectedValue {
+				t.Errorf("header %s = %q, want %q", header, got, expectedValue)
+			}
+		}
+	})
+
+	t.Run("preflight from disallowed origin", func(t *testing.T) {
+		nextCalled = false
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, newPreflightRequest(testHealthPath, "https://evil.example.com"))
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status %d, got %d", http.StatusForbidden, rr.Code)
+		}
+		if nextCalled {
+			t.Error("expected wrapped handler not to be called for rejected preflight")
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no Access-Control-Allow-Origin header, got %q", got)
+		}
+	})
+
+	t.Run("simple request from disallowed origin gets no CORS headers", func(t *testing.T) {
+		nextCalled = false
+		req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		if !nextCalled {
+			t.Error("expected wrapped handler to be called")
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no Access-Control-Allow-Origin header, got %q", got)
+		}
+	})
+
+	t.Run("simple request from allowed origin", func(t *testing.T) {
+		nextCalled = false
+		req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+		req.Header.Set("Origin", testOrigin)
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		if !nextCalled {
+			t.Error("expected wrapped handler to be called for GET")
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != testOrigin {
+			t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, testOrigin)
+		}
+	})
+}
+
+func TestCORSMiddlewareDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := CORSMiddleware(CORSOptions{})(next)
+
+	req := newPreflightRequest(testHealthPath, testOrigin)
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, req)
+
+	for _, header := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Credentials"} {
+		if got := rr.Header().Get(header); got != "" {
+			t.Errorf("expected no %s header with empty config, got %q", header, got)
+		}
+	}
+}
+
+func TestCORSMiddlewareWildcardNeverSendsCredentials(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	wrapped := CORSMiddleware(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})(next)
+
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, newPreflightRequest(testHealthPath, testOrigin))
+
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != testOrigin {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, testOrigin)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("expected no credentials header for wildcard origin, got %q", got)
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	wrapped := RecoveryMiddleware(logging.NewNopLogger())(panicking)
+
+	req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+
+	var response models.ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if response.Error != ErrInternalServer {
+		t.Errorf("error = %q, want %q", response.Error, ErrInternalServer)
+	}
+}
+
+func TestCORSPolicyUpdate(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	policy := NewCORSPolicy(CORSOptions{})
+	wrapped := policy.Middleware()(next)
+
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, newPreflightRequest(testHealthPath, testOrigin))
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected no Access-Control-Allow-Origin before update, got %q", got)
+	}
+
+	policy.Update(CORSOptions{AllowedOrigins: []string{testOrigin}})
+	rr = httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, newPreflightRequest(testHealthPath, testOrigin))
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != testOrigin {
+		t.Errorf("Access-Control-Allow-Origin after update = %q, want %q", got, testOrigin)
+	}
+}
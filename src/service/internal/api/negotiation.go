@@ -0,0 +1,45 @@
+package api
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// prefersXML reports whether an Accept header ranks XML above JSON. JSON is
+// the default, so XML is only chosen when application/xml or text/xml is
+// listed with a higher quality than any explicit application/json entry.
+// Wildcards such as */* never select XML.
+func prefersXML(accept string) bool {
+	if accept == "" {
+		return false
+	}
+
+	var xmlQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case contentTypeXML, "text/xml":
+			if q > xmlQ {
+				xmlQ = q
+			}
+		case contentTypeJSON:
+			if q > jsonQ {
+				jsonQ = q
+			}
+		}
+	}
+
+	return xmlQ > 0 && xmlQ > jsonQ
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestPrefersXML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/xml", true},
+		{"text/xml", true},
+		{"application/xml, */*", true},
+		{"application/json, application/xml", false},
+		{"application/json;q=0.5, application/xml", true},
+		{"application/xml;q=0.5, application/json", false},
+		{"application/xml;q=0", false},
+	}
+
+	for _, tt := range tests {
+		if got := prefersXML(tt.accept); got != tt.want {
+			t.Errorf("prefersXML(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestGetStatsXML(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(logging.NewNopLogger()).SetupRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	req.Header.Set("Accept", contentTypeXML)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != contentTypeXML {
+		t.Errorf("expected Content-Type %q, got %q", contentTypeXML, ct)
+	}
+
+	var response struct {
+		XMLName xml.Name `xml:"SuccessResponse"`
+		Message string   `xml:"message"`
+		Data    struct {
+			TotalMessages int `xml:"total_messages"`
+		} `xml:"data"`
+	}
+	if err := xml.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode XML response: %v", err)
+	}
+	if response.Message != MsgStatsRetrieved {
+		t.Errorf("expected message %q, got %q", MsgStatsRetrieved, response.Message)
+	}
+}
+
+func TestErrorResponseNegotiation(t *testing.T) {
+	mux := newAuthMux([]string{testAPIKey})
+
+	req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+	req.Header.Set("Accept", contentTypeXML)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != contentTypeXML {
+		t.Errorf("expected Content-Type %q, got %q", contentTypeXML, ct)
+	}
+
+	var response models.ErrorResponse
+	if err := xml.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode XML error response: %v", err)
+	}
+	if response.Error != ErrUnauthorized || response.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected error response: %+v", response)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"servicegomodule/internal/models"
+)
+
+// OpenAPI document constants
+const (
+	openAPIVersion = "3.0.3"
+	apiTitle       = "Cratos Service API"
+	apiVersion     = "1.0.0"
+	schemaRefBase  = "#/components/schemas/"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// buildOpenAPISpec assembles an OpenAPI 3 document from the route table,
+// reflecting each route's response model into a component schema. Routes
+// under /api/ are marked as requiring an API key.
+func buildOpenAPISpec(routes []route) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	errorSchema := schemaFor(reflect.TypeOf(models.ErrorResponse{}), schemas)
+
+	paths := make(map[string]interface{})
+	for _, rt := range routes {
+		operation := map[string]interface{}{
+			"summary": rt.Summary,
+			"responses": map[string]interface{}{
+				"200":     jsonResponse("Successful response", schemaFor(reflect.TypeOf(rt.Response), schemas)),
+				"default": jsonResponse("Error response", errorSchema),
+			},
+		}
+		if strings.HasPrefix(rt.Pattern, apiPathPrefix) {
+			operation["security"] = []interface{}{
+				map[string]interface{}{"bearerAuth": []string{}},
+				map[string]interface{}{"apiKeyAuth": []string{}},
+			}
+		}
+
+		item, ok := paths[rt.Pattern].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[rt.Pattern] = item
+		}
+		item[strings.ToLower(rt.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   apiTitle,
+			"version": apiVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+				"apiKeyAuth": map[string]interface{}{"type": "apiKey", "in": "header", "name": APIKeyHeader},
+			},
+		},
+	}
+}
+
+// jsonResponse describes a response with a JSON body of the given schema
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			contentTypeJSON: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// schemaFor returns the schema for t. Named structs are registered once in
+// schemas and referenced by name; fields follow their json tags, and fields
+// without omitempty are listed as required.
+func schemaFor(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, exists := schemas[t.Name()]; !exists {
+			// Reserve the name before recursing so self-referencing types terminate
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": schemaRefBase + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	default:
+		// interface{} fields accept any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema builds an object schema from the exported fields of t
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitEmpty = omitEmpty || opt == "omitempty"
+			}
+		}
+
+		properties[name] = schemaFor(field.Type, schemas)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// GetOpenAPISpec serves the OpenAPI specification built at startup
+func (h *Handler) GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	if h.openAPISpec == nil {
+		writeResponse(w, r, http.StatusInternalServerError, models.ErrorResponse{
+			Error: ErrInternalServer,
+			Code:  http.StatusInternalServerError,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.openAPISpec)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sharedgomodule/logging"
+)
+
+// fetchOpenAPISpec serves the spec through a fully configured mux and decodes it
+func fetchOpenAPISpec(t *testing.T, handler *Handler) map[string]interface{} {
+	t.Helper()
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != contentTypeJSON {
+		t.Errorf("expected Content-Type %q, got %q", contentTypeJSON, ct)
+	}
+
+	var spec map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+	return spec
+}
+
+func TestOpenAPISpecDocumentsEveryRoute(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	spec := fetchOpenAPISpec(t, handler)
+
+	if spec["openapi"] != openAPIVersion {
+		t.Errorf("expected openapi %q, got %v", openAPIVersion, spec["openapi"])
+	}
+
+	paths, ok := spec["paths"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("spec has no paths object: %v", spec["paths"])
+	}
+	for _, rt := range handler.routes() {
+		item, ok := paths[rt.Pattern].(map[string]interface{})
+		if !ok {
+			t.Errorf("route %s %s is missing from the spec", rt.Method, rt.Pattern)
+			continue
+		}
+		if _, ok := item[strings.ToLower(rt.Method)]; !ok {
+			t.Errorf("route %s %s is missing its operation in the spec", rt.Method, rt.Pattern)
+		}
+		if rt.Summary == "" {
+			t.Errorf("route %s %s has no summary", rt.Method, rt.Pattern)
+		}
+	}
+}
+
+func TestOpenAPISpecReflectsModels(t *testing.T) {
+	spec := fetchOpenAPISpec(t, NewHandler(logging.NewNopLogger()))
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"HealthResponse", "SuccessResponse", "ErrorResponse"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("expected schema %s in components", name)
+		}
+	}
+
+	health := schemas["HealthResponse"].(map[string]interface{})
+	timestamp := health["properties"].(map[string]interface{})["timestamp"].(map[string]interface{})
+	if timestamp["type"] != "string" || timestamp["format"] != "date-time" {
+		t.Errorf("expected timestamp to be a date-time string, got %v", timestamp)
+	}
+
+	errorResponse := schemas["ErrorResponse"].(map[string]interface{})
+	required, _ := errorResponse["required"].([]interface{})
+	if len(required) != 1 || required[0] != "error" {
+		t.Errorf("expected only omitempty-free fields to be required, got %v", required)
+	}
+}
+
+func TestOpenAPISpecIsCached(t *testing.T) {
+	handler := NewHandler(logging.NewNopLogger())
+	cached := handler.openAPISpec
+	if len(cached) == 0 {
+		t.Fatal("expected spec to be built by NewHandler")
+	}
+
+	rr := httptest.NewRecorder()
+	handler.GetOpenAPISpec(rr, httptest.NewRequest(http.MethodGet, OpenAPIPath, nil))
+	if rr.Body.String() != string(cached) {
+		t.Error("expected handler to serve the cached spec bytes")
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// fakeReadiness is a ReadinessChecker that can be toggled by tests
+type fakeReadiness struct {
+	ready bool
+}
+
+func (f *fakeReadiness) IsReady() bool { return f.ready }
+
+func serveProbe(t *testing.T, mux *http.ServeMux, path string) (int, models.HealthResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var response models.HealthResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode %s response: %v", path, err)
+	}
+	return rr.Code, response
+}
+
+func TestLivez(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(logging.NewNopLogger()).SetupRoutes(mux)
+
+	code, response := serveProbe(t, mux, LivezPath)
+	if code != http.StatusOK || response.Status != StatusAlive {
+		t.Errorf("expected 200 %q, got %d %q", StatusAlive, code, response.Status)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	readiness := &fakeReadiness{}
+	handler := NewHandler(logging.NewNopLogger())
+	handler.SetReadinessChecker(readiness)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	code, response := serveProbe(t, mux, ReadyzPath)
+	if code != http.StatusServiceUnavailable || response.Status != StatusNotReady {
+		t.Errorf("expected 503 %q before ready, got %d %q", StatusNotReady, code, response.Status)
+	}
+
+	readiness.ready = true
+	code, response = serveProbe(t, mux, ReadyzPath)
+	if code != http.StatusOK || response.Status != StatusReady {
+		t.Errorf("expected 200 %q once ready, got %d %q", StatusReady, code, response.Status)
+	}
+
+	readiness.ready = false
+	code, _ = serveProbe(t, mux, ReadyzPath)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after readiness cleared, got %d", code)
+	}
+}
+
+func TestReadyzWithoutChecker(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(logging.NewNopLogger()).SetupRoutes(mux)
+
+	if code, _ := serveProbe(t, mux, ReadyzPath); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a readiness checker, got %d", code)
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"crypto/sha256"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"servicegomodule/internal/models"
+)
+
+// Rate limiting constants
+const (
+	ErrRateLimitExceeded    = "Rate limit exceeded"
+	defaultRateLimitIdleTTL = 5 * time.Minute
+)
+
+// RateLimitOptions configures the token bucket rate limiter
+type RateLimitOptions struct {
+	RequestsPerSecond float64       // Sustained refill rate per client; <= 0 disables limiting
+	Burst             int           // Bucket capacity; values below 1 are treated as 1
+	ByAPIKey          bool          // Key buckets by API key when a valid one is presented, instead of client IP
+	APIKeys           []string      // Keys accepted for a bucket of their own under ByAPIKey
+	IdleTimeout       time.Duration // Buckets unused for this long are discarded (default 5m)
+}
+
+// tokenBucket tracks the available tokens for a single client
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter is a per-client token bucket limiter. Idle buckets are swept
+// lazily from Allow so memory stays bounded without a background goroutine.
+type RateLimiter struct {
+	mu        sync.Mutex
+	opts      RateLimitOptions
+	buckets   map[string]*tokenBucket
+	digests   [][sha256.Size]byte // Of opts.APIKeys
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter creates a new rate limiter with the given options
+func NewRateLimiter(opts RateLimitOptions) *RateLimiter {
+	return &RateLimiter{
+		opts:    normalizeRateLimitOptions(opts),
+		buckets: make(map[string]*tokenBucket),
+		digests: apiKeyDigests(opts.APIKeys),
+		now:     time.Now,
+	}
+}
+
+// SetOptions replaces the limiter options at runtime. Existing buckets are
+// dropped so every client starts again from the new burst size.
+func (l *RateLimiter) SetOptions(opts RateLimitOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.opts = normalizeRateLimitOptions(opts)
+	l.buckets = make(map[string]*tokenBucket)
+	l.digests = apiKeyDigests(opts.APIKeys)
+}
+
+// enabled reports whether the limiter currently enforces a rate
+func (l *RateLimiter) enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.opts.RequestsPerSecond > 0
+}
+
+// normalizeRateLimitOptions fills in defaults for unset options
+func normalizeRateLimitOptions(opts RateLimitOptions) RateLimitOptions {
+	if opts.Burst < 1 {
+		opts.Burst = 1
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = defaultRateLimitIdleTTL
+	}
+	return opts
+}
+
+// Allow consumes a token for the given key. When no token is available it
+// returns false together with the time until the next token is refilled.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweepIdle(now)
+
+	burst := float64(l.opts.Burst)
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: burst, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(burst, bucket.tokens+elapsed*l.opts.RequestsPerSecond)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := (1 - bucket.tokens) / l.opts.RequestsPerSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// Len returns the number of tracked client buckets
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// sweepIdle removes buckets that have been idle longer than the idle timeout.
+// Must be called with the mutex held.
+func (l *RateLimiter) sweepIdle(now time.Time) {
+	if now.Sub(l.lastSweep) < l.opts.IdleTimeout {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= l.opts.IdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientKey identifies the client a request should be accounted against.
+// The limiter runs before authentication, so only a key matching one of
+// opts.APIKeys gets its own bucket; any other key would let a client rotate
+// keys for a fresh bucket on every request, and is limited by client IP.
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	l.mu.Lock()
+	byAPIKey, digests := l.opts.ByAPIKey, l.digests
+	l.mu.Unlock()
+
+	if byAPIKey {
+		if key := presentedAPIKey(r); validAPIKey(key, digests) {
+			return "key:" + key
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// RateLimitMiddleware rejects requests to /api/ routes with 429 once the
+// client's bucket is empty. Operational endpoints such as /health are never
+// limited so probes keep working.
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, apiPathPrefix) || !limiter.enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := limiter.Allow(limiter.clientKey(r))
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				writeResponse(w, r, http.StatusTooManyRequests, models.ErrorResponse{
+					Error: ErrRateLimitExceeded,
+					Code:  http.StatusTooManyRequests,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
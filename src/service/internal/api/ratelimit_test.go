@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+)
+
+// fakeClock is a manually advanced clock for rate limiter tests
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.current }
+func (c *fakeClock) Advance(d time.Duration) { c.current = c.current.Add(d) }
+func newFakeClock() *fakeClock               { return &fakeClock{current: time.Unix(1700000000, 0)} }
+func newTestLimiter(opts RateLimitOptions, clock *fakeClock) *RateLimiter {
+	limiter := NewRateLimiter(opts)
+	limiter.now = clock.Now
+	return limiter
+}
+
+func TestRateLimiterAllowAndRefill(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{RequestsPerSecond: 2, Burst: 3}, clock)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow("client"); !allowed {
+			t.Fatalf("request %d within burst was rejected", i+1)
+		}
+	}
+
+	allowed, retryAfter := limiter.Allow("client")
+	if allowed {
+		t.Fatal("request beyond burst was allowed")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("retryAfter = %v, want %v", retryAfter, 500*time.Millisecond)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if allowed, _ := limiter.Allow("client"); !allowed {
+		t.Error("request after refill was rejected")
+	}
+
+	// A different client has its own bucket
+	if allowed, _ := limiter.Allow("other"); !allowed {
+		t.Error("request from a different client was rejected")
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{RequestsPerSecond: 1, Burst: 1, IdleTimeout: time.Minute}, clock)
+
+	limiter.Allow("a")
+	limiter.Allow("b")
+	if limiter.Len() != 2 {
+		t.Fatalf("expected 2 buckets, got %d", limiter.Len())
+	}
+
+	clock.Advance(2 * time.Minute)
+	limiter.Allow("c")
+	if limiter.Len() != 1 {
+		t.Errorf("expected idle buckets to be swept leaving 1, got %d", limiter.Len())
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{RequestsPerSecond: 1, Burst: 1}, clock)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := RateLimitMiddleware(limiter)(next)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send(testStatsPath); rr.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	rr := send(testStatsPath)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want %q", got, "1")
+	}
+	var response models.ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if response.Error != ErrRateLimitExceeded {
+		t.Errorf("error = %q, want %q", response.Error, ErrRateLimitExceeded)
+	}
+
+	// Health probes are never limited
+	if rr := send(testHealthPath); rr.Code != http.StatusOK {
+		t.Errorf("health status = %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	clock.Advance(time.Second)
+	if rr := send(testStatsPath); rr.Code != http.StatusOK {
+		t.Errorf("request after refill status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitMiddlewareByAPIKey(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{RequestsPerSecond: 1, Burst: 1, ByAPIKey: true, APIKeys: []string{"key-one", "key-two"}}, clock)
+	wrapped := RateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, key := range []string{"key-one", "key-two"} {
+		req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("request with %s from shared IP status = %d, want %d", key, rr.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareByAPIKeyIgnoresInvalidKeys(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{RequestsPerSecond: 1, Burst: 1, ByAPIKey: true, APIKeys: []string{"key-one"}}, clock)
+	wrapped := RateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Rotating unknown keys shares the client IP's bucket
+	for i, key := range []string{"guess-1", "guess-2", "guess-3"} {
+		req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		req.Header.Set("Authorization", "Bearer "+key)
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		want := http.StatusTooManyRequests
+		if i == 0 {
+			want = http.StatusOK
+		}
+		if rr.Code != want {
+			t.Errorf("request with %s status = %d, want %d", key, rr.Code, want)
+		}
+	}
+	if limiter.Len() != 1 {
+		t.Errorf("Expected a single bucket for the client IP, got %d", limiter.Len())
+	}
+}
+
+func TestRateLimiterSetOptions(t *testing.T) {
+	clock := newFakeClock()
+	limiter := newTestLimiter(RateLimitOptions{}, clock)
+	wrapped := RateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodGet, testStatsPath, nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := send(); code != http.StatusOK {
+			t.Fatalf("request %d with limiting disabled status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+
+	limiter.SetOptions(RateLimitOptions{RequestsPerSecond: 1, Burst: 1})
+	if code := send(); code != http.StatusOK {
+		t.Fatalf("first request after SetOptions status = %d, want %d", code, http.StatusOK)
+	}
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Errorf("second request after SetOptions status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"sharedgomodule/logging"
+	"sharedgomodule/utils"
+)
+
+// RequestIDHeader is the header used to propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps client supplied request IDs to keep log lines bounded
+const maxRequestIDLength = 128
+
+// contextKey is a private type for request context keys to avoid collisions
+type contextKey string
+
+const loggerContextKey contextKey = "logger"
+
+// RequestIDMiddleware reads the X-Request-ID header (generating a UUID when it
+// is absent or unusable), echoes it on the response, and stores both the ID and
+// a logger enriched with a request_id field in the request context. The ID is
+// stored with logging.ContextWithRequestID, so loggers' WithContext attach it
+// too.
+func RequestIDMiddleware(logger logging.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				generated, err := utils.NewUUID()
+				if err != nil {
+					logger.Warnw("Failed to generate request ID", "error", err)
+				}
+				requestID = generated
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := logging.ContextWithRequestID(r.Context(), requestID)
+			ctx = context.WithValue(ctx, loggerContextKey, logger.WithField(logging.FieldRequestID, requestID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in the context, or an
+// empty string when none is present
+func RequestIDFromContext(ctx context.Context) string {
+	return logging.RequestIDFromContext(ctx)
+}
+
+// LoggerFromContext returns the request-scoped logger stored in the context,
+// falling back to the given logger when none is present
+func LoggerFromContext(ctx context.Context, fallback logging.Logger) logging.Logger {
+	if ctx == nil {
+		return fallback
+	}
+	if logger, ok := ctx.Value(loggerContextKey).(logging.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// requestLogger returns the logger handlers should use for a request
+func (h *Handler) requestLogger(r *http.Request) logging.Logger {
+	return LoggerFromContext(r.Context(), h.logger)
+}
+
+// isValidRequestID reports whether a client supplied request ID is safe to
+// propagate into headers and log lines
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
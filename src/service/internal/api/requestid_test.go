@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sharedgomodule/logging"
+)
+
+func TestRequestIDMiddlewareRoundTrip(t *testing.T) {
+	var seenID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenID = RequestIDFromContext(r.Context())
+	})
+	wrapped := RequestIDMiddleware(logging.NewNopLogger())(next)
+
+	t.Run("client supplied ID is echoed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get(RequestIDHeader); got != "abc-123" {
+			t.Errorf("response %s = %q, want %q", RequestIDHeader, got, "abc-123")
+		}
+		if seenID != "abc-123" {
+			t.Errorf("context request ID = %q, want %q", seenID, "abc-123")
+		}
+	})
+
+	t.Run("missing ID is generated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		got := rr.Header().Get(RequestIDHeader)
+		if len(got) != 36 {
+			t.Errorf("generated %s = %q, want a UUID", RequestIDHeader, got)
+		}
+		if seenID != got {
+			t.Errorf("context request ID = %q, want %q", seenID, got)
+		}
+	})
+
+	t.Run("unsafe ID is replaced", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+		req.Header.Set(RequestIDHeader, "bad id\twith spaces")
+		rr := httptest.NewRecorder()
+		wrapped.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get(RequestIDHeader); got == "bad id\twith spaces" || got == "" {
+			t.Errorf("expected unsafe request ID to be replaced, got %q", got)
+		}
+	})
+}
+
+func TestRequestIDContextHelpersMissing(t *testing.T) {
+	fallback := logging.NewNopLogger()
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Errorf("RequestIDFromContext() = %q, want empty", got)
+	}
+	if got := LoggerFromContext(context.Background(), fallback); got != fallback {
+		t.Error("LoggerFromContext() did not return fallback logger")
+	}
+}
+
+func TestRequestIDAppearsInLogOutput(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "requestid.log")
+	logger, err := logging.NewLogger(&logging.LoggerConfig{
+		Level:       logging.InfoLevel,
+		FilePath:    logFile,
+		LoggerName:  "test",
+		ServiceName: "test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	handler := NewHandler(logger)
+	mux := http.NewServeMux()
+	handler.SetupRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+	req.Header.Set(RequestIDHeader, "trace-me-42")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"request_id":"trace-me-42"`) {
+		t.Errorf("expected log output to contain request_id field, got: %s", data)
+	}
+}
+
+func TestRequestIDReachesWithContext(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "withcontext.log")
+	logger, err := logging.NewLogger(&logging.LoggerConfig{
+		Level:       logging.InfoLevel,
+		FilePath:    logFile,
+		LoggerName:  "test",
+		ServiceName: "test",
+		ContextKeys: logging.DefaultContextKeys(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.WithContext(r.Context()).Info("Handled")
+	})
+	req := httptest.NewRequest(http.MethodGet, testHealthPath, nil)
+	req.Header.Set(RequestIDHeader, "ctx-7")
+	RequestIDMiddleware(logging.NewNopLogger())(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"request_id":"ctx-7"`) {
+		t.Errorf("expected WithContext to attach request_id, got: %s", data)
+	}
+}
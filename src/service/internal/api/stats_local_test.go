@@ -0,0 +1,34 @@
+//go:build local
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// TestGetStatsAfterPipelineTraffic pushes messages through the application
+// over the file-based local message bus and checks GetStats reports them
+func TestGetStatsAfterPipelineTraffic(t *testing.T) {
+	application, cfg := startLocalApplication(t, "stats-test")
+	sendRecords(t, cfg.Processing.Input.Topics[0], 3)
+	awaitPublished(t, application, 3)
+
+	rr := httptest.NewRecorder()
+	NewHandler(logging.NewNopLogger()).GetStats(rr, withApplication(httptest.NewRequest(http.MethodGet, testStatsPath, nil), application))
+	stats := decodeStats(t, rr)
+	if stats.Status != string(processing.PipelineRunning) {
+		t.Errorf("GetStats() status = %q, want %q", stats.Status, processing.PipelineRunning)
+	}
+	if stats.Consumed != 3 || stats.TotalMessages != 3 || stats.Processed != 3 || stats.Published != 3 {
+		t.Errorf("GetStats() = %+v, want 3 messages consumed, processed and published", stats)
+	}
+	input, ok := stats.Pipeline["input_stats"].(map[string]interface{})
+	if !ok || input["topics"] == nil {
+		t.Errorf("GetStats() per-stage detail = %v, want the input stats by topic", stats.Pipeline)
+	}
+}
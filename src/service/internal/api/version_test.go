@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sharedgomodule/buildinfo"
+	"sharedgomodule/logging"
+)
+
+func TestGetVersion(t *testing.T) {
+	oldVersion, oldCommit, oldTime := buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime
+	buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime = "2.3.4", "cafef00d", "2024-03-03T03:03:03Z"
+	t.Cleanup(func() {
+		buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime = oldVersion, oldCommit, oldTime
+	})
+
+	mux := http.NewServeMux()
+	NewHandler(logging.NewNopLogger()).SetupRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var info buildinfo.Info
+	if err := json.NewDecoder(rr.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode version response: %v", err)
+	}
+	if info.Version != "2.3.4" || info.GitCommit != "cafef00d" || info.BuildTime != "2024-03-03T03:03:03Z" {
+		t.Errorf("unexpected version info: %+v", info)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	var health struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode health response: %v", err)
+	}
+	if health.Version != "2.3.4" {
+		t.Errorf("expected health version 2.3.4, got %q", health.Version)
+	}
+}
@@ -2,9 +2,13 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"servicegomodule/internal/config"
+	"servicegomodule/internal/metrics"
 	"servicegomodule/internal/processing"
 	"sharedgomodule/logging"
 )
@@ -14,9 +18,19 @@ type Application struct {
 	rawconfig          *config.RawConfig
 	logger             logging.Logger
 	processingPipeline *processing.Pipeline
+	registry           *ServiceRegistry
+	serviceStopTimeout time.Duration
 	mutex              sync.RWMutex
+	pipelineMutex      sync.Mutex // Serializes pipeline restarts with each other and with Shutdown
 	ctx                context.Context
 	cancel             context.CancelFunc
+	state              State  // Lifecycle state, guarded by stateMutex
+	stateReason        string // Why the application is degraded
+	stateMutex         sync.Mutex
+	configSubscribers  []ConfigChangeFunc
+	configSource       config.Source // Where rawconfig was loaded from, for the config endpoint
+
+	metrics *metrics.Registry // Pipeline metrics, plus HTTP metrics once the API handler registers them
 }
 
 // NewApplication creates a new application instance
@@ -25,15 +39,25 @@ func NewApplication(cfg *config.RawConfig, logger logging.Logger) *Application {
 
 	// Create processing pipeline with configuration from config file
 	processingConfig := processing.DefaultConfig(cfg)
-	processingPipeline := processing.NewPipeline(processingConfig, logger.WithField("module", "processing"))
+	processingPipeline := processing.NewPipeline(processingConfig, logger.WithField(logging.FieldComponent, "processing"))
 
-	return &Application{
+	app := &Application{
 		rawconfig:          cfg,
 		logger:             logger,
 		processingPipeline: processingPipeline,
+		registry:           NewServiceRegistry(),
+		serviceStopTimeout: defaultServiceStopTimeout,
 		ctx:                ctx,
 		cancel:             cancel,
+		state:              StateStarting,
+		metrics:            metrics.NewRegistry(),
 	}
+
+	processingPipeline.SetFailureHandler(app.pipelineFailed)
+	app.registerPipelineMetrics(processingPipeline)
+	app.registerLogMetrics(logger)
+
+	return app
 }
 
 // Config returns the application configuration
@@ -62,38 +86,90 @@ func (app *Application) ProcessingPipeline() *processing.Pipeline {
 	return app.processingPipeline
 }
 
-// Start starts the application and its processing pipeline
+// Registry returns the application's service registry
+func (app *Application) Registry() *ServiceRegistry {
+	return app.registry
+}
+
+// RegisterService adds a named service to the application's registry.
+// Services named in dependsOn are started before it and stopped after it.
+func (app *Application) RegisterService(name string, service interface{}, dependsOn ...string) error {
+	if err := app.registry.Register(name, service, dependsOn...); err != nil {
+		return err
+	}
+	app.logger.Infow("Registered service", "name", name, "depends_on", dependsOn)
+	return nil
+}
+
+// Start starts the registered services in dependency order, then the
+// processing pipeline
 func (app *Application) Start() error {
 	app.logger.Info("Starting application...")
 
+	if err := app.startServices(app.ctx); err != nil {
+		app.logger.Errorw("Failed to start services", "error", err)
+		return err
+	}
+
 	// Start the processing pipeline
 	if err := app.processingPipeline.Start(); err != nil {
 		app.logger.Errorw("Failed to start processing pipeline", "error", err)
+		app.stopServices(app.stopOrder())
 		return err
 	}
 
+	if err := app.MarkReady(); err != nil {
+		return err
+	}
 	app.logger.Info("Application started successfully")
 	return nil
 }
 
-// Shutdown gracefully shuts down the application
+// Shutdown gracefully shuts down the processing pipeline and then the
+// registered services in reverse dependency order. Every service is given
+// the chance to stop even if an earlier one fails; the failures are returned
+// as a single joined error.
 func (app *Application) Shutdown() error {
 	app.logger.Info("Shutting down application...")
 
-	// Stop the processing pipeline
-	if app.processingPipeline != nil {
-		if err := app.processingPipeline.Stop(); err != nil {
-			app.logger.Errorw("Error stopping processing pipeline", "error", err)
+	// Stop accepting traffic before tearing anything down
+	app.transition(StateStopping, "")
+
+	var errs []error
+
+	// Stop the processing pipeline, waiting for a restart in progress
+	app.pipelineMutex.Lock()
+	if pipeline := app.ProcessingPipeline(); pipeline != nil {
+		if err := app.stopPipeline(pipeline); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop processing pipeline: %w", err))
 		}
 	}
+	app.pipelineMutex.Unlock()
+
+	if err := app.stopServices(app.stopOrder()); err != nil {
+		errs = append(errs, err)
+	}
 
 	// Cancel the application context
 	app.cancel()
 
+	if err := errors.Join(errs...); err != nil {
+		app.logger.Errorw("Application shutdown completed with errors", "error", err)
+		return err
+	}
 	app.logger.Info("Application shutdown completed")
 	return nil
 }
 
+// pipelineShutdownTimeout returns how long Shutdown waits for the pipeline
+// to stop
+func (app *Application) pipelineShutdownTimeout() time.Duration {
+	if cfg := app.Config(); cfg != nil && cfg.Processing.ShutdownTimeout > 0 {
+		return cfg.Processing.ShutdownTimeout
+	}
+	return defaultPipelineShutdownTimeout
+}
+
 // IsShuttingDown returns true if the application is shutting down
 func (app *Application) IsShuttingDown() bool {
 	select {
@@ -1,57 +1,12 @@
 package app
 
 import (
-	"context"
 	"testing"
 
 	"servicegomodule/internal/config"
 	"sharedgomodule/logging"
 )
 
-// mockLogger implements basic logging interface for testing
-type mockLogger struct {
-	logCalls []string
-}
-
-func newMockLogger() *mockLogger {
-	return &mockLogger{
-		logCalls: make([]string, 0),
-	}
-}
-
-func (m *mockLogger) SetLevel(level logging.Level)                    {}
-func (m *mockLogger) GetLevel() logging.Level                         { return logging.InfoLevel }
-func (m *mockLogger) IsLevelEnabled(level logging.Level) bool         { return true }
-func (m *mockLogger) Debug(msg string)                                { m.logCalls = append(m.logCalls, "DEBUG: "+msg) }
-func (m *mockLogger) Info(msg string)                                 { m.logCalls = append(m.logCalls, "INFO: "+msg) }
-func (m *mockLogger) Warn(msg string)                                 { m.logCalls = append(m.logCalls, "WARN: "+msg) }
-func (m *mockLogger) Error(msg string)                                { m.logCalls = append(m.logCalls, "ERROR: "+msg) }
-func (m *mockLogger) Fatal(msg string)                                { m.logCalls = append(m.logCalls, "FATAL: "+msg) }
-func (m *mockLogger) Panic(msg string)                                { m.logCalls = append(m.logCalls, "PANIC: "+msg) }
-func (m *mockLogger) Debugf(format string, args ...interface{})       {}
-func (m *mockLogger) Infof(format string, args ...interface{})        {}
-func (m *mockLogger) Warnf(format string, args ...interface{})        {}
-func (m *mockLogger) Errorf(format string, args ...interface{})       {}
-func (m *mockLogger) Fatalf(format string, args ...interface{})       {}
-func (m *mockLogger) Panicf(format string, args ...interface{})       {}
-func (m *mockLogger) Debugw(msg string, keysAndValues ...interface{}) {}
-func (m *mockLogger) Infow(msg string, keysAndValues ...interface{})  {}
-func (m *mockLogger) Warnw(msg string, keysAndValues ...interface{})  {}
-func (m *mockLogger) Errorw(msg string, keysAndValues ...interface{}) {
-	m.logCalls = append(m.logCalls, "ERRORW: "+msg)
-}
-func (m *mockLogger) Fatalw(msg string, keysAndValues ...interface{})                    {}
-func (m *mockLogger) Panicw(msg string, keysAndValues ...interface{})                    {}
-func (m *mockLogger) WithFields(fields logging.Fields) logging.Logger                    { return m }
-func (m *mockLogger) WithField(key string, value interface{}) logging.Logger             { return m }
-func (m *mockLogger) WithError(err error) logging.Logger                                 { return m }
-func (m *mockLogger) WithContext(ctx context.Context) logging.Logger                     { return m }
-func (m *mockLogger) Log(level logging.Level, msg string)                                {}
-func (m *mockLogger) Logf(level logging.Level, format string, args ...interface{})       {}
-func (m *mockLogger) Logw(level logging.Level, msg string, keysAndValues ...interface{}) {}
-func (m *mockLogger) Clone() logging.Logger                                              { return m }
-func (m *mockLogger) Close() error                                                       { return nil }
-
 func TestNewApplication(t *testing.T) {
 	cfg := &config.RawConfig{
 		Server: config.RawServerConfig{
@@ -59,7 +14,7 @@ func TestNewApplication(t *testing.T) {
 			Port: 8080,
 		},
 	}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 
 	app := NewApplication(cfg, logger)
 
@@ -88,6 +43,18 @@ func TestNewApplication(t *testing.T) {
 	}
 }
 
+func TestNewApplicationUsesProcessingConfig(t *testing.T) {
+	t.Setenv("PROCESSING_BATCH_SIZE", "37")
+	cfg := config.LoadConfig()
+
+	app := NewApplication(cfg, logging.NewTestLogger())
+
+	processorStats := app.ProcessingPipeline().GetStats()["processor_stats"].(map[string]interface{})
+	if got := processorStats["batch_size"]; got != 37 {
+		t.Errorf("pipeline batch_size = %v, want the configured 37", got)
+	}
+}
+
 func TestApplicationConfig(t *testing.T) {
 	cfg := &config.RawConfig{
 		Server: config.RawServerConfig{
@@ -95,7 +62,7 @@ func TestApplicationConfig(t *testing.T) {
 			Port: 8080,
 		},
 	}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	retrievedConfig := app.Config()
@@ -114,7 +81,7 @@ func TestApplicationConfig(t *testing.T) {
 
 func TestApplicationLogger(t *testing.T) {
 	cfg := &config.RawConfig{}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	retrievedLogger := app.Logger()
@@ -125,7 +92,7 @@ func TestApplicationLogger(t *testing.T) {
 
 func TestApplicationContext(t *testing.T) {
 	cfg := &config.RawConfig{}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	ctx := app.Context()
@@ -143,7 +110,7 @@ func TestApplicationContext(t *testing.T) {
 
 func TestApplicationProcessingPipeline(t *testing.T) {
 	cfg := &config.RawConfig{}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	pipeline := app.ProcessingPipeline()
@@ -154,7 +121,7 @@ func TestApplicationProcessingPipeline(t *testing.T) {
 
 func TestApplicationShutdown(t *testing.T) {
 	cfg := &config.RawConfig{}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	// Verify context is not cancelled initially
@@ -179,7 +146,7 @@ func TestApplicationShutdown(t *testing.T) {
 
 func TestApplicationIsShuttingDown(t *testing.T) {
 	cfg := &config.RawConfig{}
-	logger := newMockLogger()
+	logger := logging.NewTestLogger()
 	app := NewApplication(cfg, logger)
 
 	// Initially should not be shutting down
@@ -193,3 +160,31 @@ func TestApplicationIsShuttingDown(t *testing.T) {
 		t.Error("Application should be shutting down after Shutdown() call")
 	}
 }
+
+func TestApplicationReadiness(t *testing.T) {
+	cfg := &config.RawConfig{}
+	logger := logging.NewTestLogger()
+	app := NewApplication(cfg, logger)
+
+	// Not ready until started
+	if app.IsReady() || app.State() != StateStarting {
+		t.Errorf("Application should be starting and not ready before Start(), got %s", app.State())
+	}
+
+	if err := app.MarkReady(); err != nil || !app.IsReady() {
+		t.Errorf("Application should be ready after MarkReady(), got %s and error %v", app.State(), err)
+	}
+
+	if err := app.SetDegraded("pipeline failed"); err != nil || app.IsReady() {
+		t.Errorf("Application should not be ready while degraded, got %s and error %v", app.State(), err)
+	}
+
+	// Shutdown always reports not ready, and readiness cannot be restored
+	app.Shutdown()
+	if app.IsReady() || app.State() != StateStopping {
+		t.Errorf("Application should be stopping after Shutdown(), got %s", app.State())
+	}
+	if err := app.MarkReady(); err == nil || app.IsReady() {
+		t.Error("Application should not become ready again while shutting down")
+	}
+}
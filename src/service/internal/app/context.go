@@ -0,0 +1,21 @@
+package app
+
+import "context"
+
+// contextKey is the unexported key under which the Application is stored
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying app
+func NewContext(ctx context.Context, app *Application) context.Context {
+	return context.WithValue(ctx, contextKey{}, app)
+}
+
+// FromContext returns the Application stored in ctx by NewContext. It
+// reports false for a nil context or one without an Application.
+func FromContext(ctx context.Context) (*Application, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	app, ok := ctx.Value(contextKey{}).(*Application)
+	return app, ok && app != nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"servicegomodule/internal/config"
+	"sharedgomodule/logging"
+)
+
+func TestContextRoundTrip(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+
+	got, ok := FromContext(NewContext(context.Background(), app))
+	if !ok || got != app {
+		t.Errorf("FromContext() = %p, %v, want %p, true", got, ok, app)
+	}
+}
+
+func TestFromContextMissing(t *testing.T) {
+	if got, ok := FromContext(context.Background()); ok || got != nil {
+		t.Errorf("FromContext() without an application = %v, %v, want nil, false", got, ok)
+	}
+	//nolint:staticcheck // a nil context must not panic
+	if got, ok := FromContext(nil); ok || got != nil {
+		t.Errorf("FromContext(nil) = %v, %v, want nil, false", got, ok)
+	}
+	if got, ok := FromContext(NewContext(context.Background(), nil)); ok || got != nil {
+		t.Errorf("FromContext() with a nil application = %v, %v, want nil, false", got, ok)
+	}
+}
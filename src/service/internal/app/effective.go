@@ -0,0 +1,28 @@
+package app
+
+import "servicegomodule/internal/config"
+
+// EffectiveConfig is the running configuration with secrets redacted,
+// together with where it was loaded from
+type EffectiveConfig struct {
+	Config map[string]interface{} `json:"config"`
+	Source config.Source          `json:"source"`
+}
+
+// SetConfigSource records where the running configuration was loaded from
+func (app *Application) SetConfigSource(source config.Source) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.configSource = source
+}
+
+// EffectiveConfig returns the redacted running configuration for the config
+// endpoint
+func (app *Application) EffectiveConfig() EffectiveConfig {
+	app.mutex.RLock()
+	defer app.mutex.RUnlock()
+	return EffectiveConfig{
+		Config: config.Redact(app.rawconfig),
+		Source: app.configSource,
+	}
+}
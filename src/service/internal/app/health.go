@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// HealthChecker is implemented by registered services that can report their
+// own health. HealthCheck should return promptly once ctx is done.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// applicationHealthName is the CheckHealth key reporting a degraded application
+const applicationHealthName = "application"
+
+// pipelineHealthName is the CheckHealth key reporting the processing pipeline
+const pipelineHealthName = "pipeline"
+
+// healthStatusHealthy is the HealthDetails value for a healthy component
+const healthStatusHealthy = "healthy"
+
+// HealthDetails keys reporting the log files of the application and
+// pipeline loggers
+const (
+	logOutputHealthName         = "log_output"
+	pipelineLogOutputHealthName = "pipeline_log_output"
+)
+
+// healthResult carries the outcome of a single service health check
+type healthResult struct {
+	name string
+	err  error
+}
+
+// CheckHealth runs every registered HealthChecker concurrently and returns the
+// outcome per service name, with a nil error for healthy services. Checkers
+// still running when ctx is done are reported with the context's error, so a
+// hung service cannot stall the caller. While the application is running the
+// processing pipeline is reported too, failing if any of its components does.
+func (app *Application) CheckHealth(ctx context.Context) map[string]error {
+	checkers := app.registry.HealthCheckers()
+	results := make(map[string]error, len(checkers)+2)
+	// A degraded application is reported as its own failing entry
+	if reason := app.DegradedReason(); reason != "" {
+		results[applicationHealthName] = errors.New(reason)
+	}
+	if components := app.pipelineHealth(); components != nil {
+		var errs []error
+		for _, name := range []string{processing.HealthComponentPipeline, processing.HealthComponentInput, processing.HealthComponentProcessor} {
+			if err := components[name]; err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		results[pipelineHealthName] = errors.Join(errs...)
+	}
+	if len(checkers) == 0 {
+		return results
+	}
+
+	// Buffered so late checkers never block after the caller has returned
+	resultCh := make(chan healthResult, len(checkers))
+	for name, checker := range checkers {
+		go func(name string, checker HealthChecker) {
+			resultCh <- healthResult{name: name, err: checker.HealthCheck(ctx)}
+		}(name, checker)
+	}
+
+	pending := len(checkers)
+	for pending > 0 {
+		select {
+		case result := <-resultCh:
+			results[result.name] = result.err
+			pending--
+		case <-ctx.Done():
+			for name := range checkers {
+				if _, done := results[name]; !done {
+					results[name] = ctx.Err()
+				}
+			}
+			pending = 0
+		}
+	}
+
+	return results
+}
+
+// HealthDetails describes each processing pipeline component as "healthy" or
+// the reason it is not, along with the failed writes to the log files of the
+// application and pipeline loggers. It returns nil unless the application is
+// running, since the pipeline is only expected to run then.
+func (app *Application) HealthDetails() map[string]string {
+	components := app.pipelineHealth()
+	if components == nil {
+		return nil
+	}
+	details := make(map[string]string, len(components)+2)
+	for name, err := range components {
+		details[name] = healthStatusHealthy
+		if err != nil {
+			details[name] = err.Error()
+		}
+	}
+	loggers := map[string]logging.Logger{
+		logOutputHealthName:         app.Logger(),
+		pipelineLogOutputHealthName: app.ProcessingPipeline().PipelineLogger(),
+	}
+	for name, logger := range loggers {
+		if reporter, ok := logger.(logging.OutputReporter); ok {
+			details[name] = logOutputStatus(reporter.OutputStats())
+		}
+	}
+	return details
+}
+
+// logOutputStatus describes the failed writes to a log file, and whether
+// its lines currently go to the fallback output instead
+func logOutputStatus(stats logging.OutputStats) string {
+	switch {
+	case stats.OnFallback:
+		return fmt.Sprintf("writing to the fallback output after %d failed writes", stats.FailedWrites)
+	case stats.FailedWrites > 0:
+		return fmt.Sprintf("%s, %d failed writes since start", healthStatusHealthy, stats.FailedWrites)
+	default:
+		return healthStatusHealthy
+	}
+}
+
+// pipelineHealth checks the pipeline while the application is ready or
+// degraded, and returns nil otherwise
+func (app *Application) pipelineHealth() map[string]error {
+	if state := app.State(); state != StateReady && state != StateDegraded {
+		return nil
+	}
+	return app.ProcessingPipeline().CheckHealth()
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// fakeHealthService is a HealthChecker that can be toggled between passing
+// and failing, or made to hang until its context is done
+type fakeHealthService struct {
+	failing atomic.Bool
+	hang    bool
+}
+
+func (f *fakeHealthService) HealthCheck(ctx context.Context) error {
+	if f.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if f.failing.Load() {
+		return errors.New("backend unreachable")
+	}
+	return nil
+}
+
+func TestApplicationCheckHealth(t *testing.T) {
+	app := NewApplication(&config.RawConfig{}, logging.NewTestLogger())
+	service := &fakeHealthService{}
+	if err := app.RegisterService("toggle", service); err != nil {
+		t.Fatalf("RegisterService() returned error: %v", err)
+	}
+	app.RegisterService("plain", "not a checker")
+
+	results := app.CheckHealth(context.Background())
+	if len(results) != 1 {
+		t.Fatalf("CheckHealth() returned %d results, want 1", len(results))
+	}
+	if err := results["toggle"]; err != nil {
+		t.Errorf("expected passing service to report nil, got %v", err)
+	}
+
+	service.failing.Store(true)
+	results = app.CheckHealth(context.Background())
+	if err := results["toggle"]; err == nil || err.Error() != "backend unreachable" {
+		t.Errorf("expected failing service to report its error, got %v", err)
+	}
+}
+
+func TestApplicationCheckHealthTimeout(t *testing.T) {
+	app := NewApplication(&config.RawConfig{}, logging.NewTestLogger())
+	app.RegisterService("hung", &fakeHealthService{hang: true})
+	app.RegisterService("fine", &fakeHealthService{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results := app.CheckHealth(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CheckHealth() took %v, expected it to honor the context deadline", elapsed)
+	}
+
+	if !errors.Is(results["hung"], context.DeadlineExceeded) {
+		t.Errorf("expected hung service to report deadline exceeded, got %v", results["hung"])
+	}
+	if results["fine"] != nil {
+		t.Errorf("expected healthy service to report nil, got %v", results["fine"])
+	}
+}
+
+func TestApplicationCheckHealthReportsPipeline(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	defer app.Shutdown()
+
+	// The pipeline is not expected to run until the application is ready
+	if _, ok := app.CheckHealth(context.Background())[pipelineHealthName]; ok {
+		t.Error("Expected no pipeline entry while starting")
+	}
+	if details := app.HealthDetails(); details != nil {
+		t.Errorf("Expected no details while starting, got %v", details)
+	}
+
+	// Ready, but the pipeline was never started
+	if err := app.MarkReady(); err != nil {
+		t.Fatalf("MarkReady() returned error: %v", err)
+	}
+	if err := app.CheckHealth(context.Background())[pipelineHealthName]; err == nil || !strings.Contains(err.Error(), "pipeline is stopped") {
+		t.Errorf("Expected a stopped pipeline to be reported, got %v", err)
+	}
+	details := app.HealthDetails()
+	if details[processing.HealthComponentPipeline] != "pipeline is stopped" || details[processing.HealthComponentInput] != healthStatusHealthy {
+		t.Errorf("Unexpected health details %v", details)
+	}
+	// The test logger has no log file to report
+	if _, ok := details[logOutputHealthName]; ok {
+		t.Errorf("Expected no log output entry for the test logger, got %v", details)
+	}
+}
+
+func TestLogOutputStatus(t *testing.T) {
+	for _, tt := range []struct {
+		stats logging.OutputStats
+		want  string
+	}{
+		{logging.OutputStats{}, healthStatusHealthy},
+		{logging.OutputStats{FailedWrites: 2}, "healthy, 2 failed writes since start"},
+		{logging.OutputStats{FailedWrites: 5, Fallbacks: 1, OnFallback: true}, "writing to the fallback output after 5 failed writes"},
+	} {
+		if got := logOutputStatus(tt.stats); got != tt.want {
+			t.Errorf("logOutputStatus(%+v) = %q, want %q", tt.stats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// defaultServiceStopTimeout bounds how long Shutdown waits for each service
+const defaultServiceStopTimeout = 5 * time.Second
+
+// defaultPipelineShutdownTimeout bounds how long Shutdown waits for the
+// pipeline when processing.shutdownTimeout is not set
+const defaultPipelineShutdownTimeout = 15 * time.Second
+
+// Startable is implemented by registered services that need to start
+// background work. Start is called from Application.Start in dependency
+// order with the application context.
+type Startable interface {
+	Start(ctx context.Context) error
+}
+
+// Stoppable is implemented by registered services that must release
+// resources on shutdown. Stop is called from Application.Shutdown in reverse
+// dependency order with a per-service deadline.
+type Stoppable interface {
+	Stop(ctx context.Context) error
+}
+
+// startServices starts every Startable service in dependency order. If one
+// fails, the services already started are stopped in reverse order before
+// returning.
+func (app *Application) startServices(ctx context.Context) error {
+	names, err := app.registry.ResolveOrder()
+	if err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		service, _ := app.registry.Get(name)
+		startable, ok := service.(Startable)
+		if !ok {
+			app.registry.setState(name, ServiceRunning)
+			continue
+		}
+
+		app.logger.Infow("Starting service", "name", name)
+		if err := startable.Start(ctx); err != nil {
+			app.registry.setState(name, ServiceFailed)
+			app.stopServices(names[:i])
+			return fmt.Errorf("failed to start service %s: %w", name, err)
+		}
+		app.registry.setState(name, ServiceRunning)
+	}
+	return nil
+}
+
+// stopOrder returns the services in start order for stopServices to walk in
+// reverse. If dependencies cannot be resolved, registration order is used so
+// shutdown still reaches every service.
+func (app *Application) stopOrder() []string {
+	names, err := app.registry.ResolveOrder()
+	if err != nil {
+		app.logger.Warnw("Cannot resolve service dependencies, stopping in registration order", "error", err)
+		return app.registry.List()
+	}
+	return names
+}
+
+// stopServices stops the named services in reverse order, giving each its
+// own timeout. A failure is logged and the remaining services are still
+// stopped; the joined errors of all failures are returned.
+func (app *Application) stopServices(names []string) error {
+	var errs []error
+	for i := len(names) - 1; i >= 0; i-- {
+		service, err := app.registry.Get(names[i])
+		if err != nil {
+			continue
+		}
+		if err := app.stopService(names[i], service); err != nil {
+			app.logger.Errorw("Failed to stop service", "service", names[i], "error", err)
+			app.registry.setState(names[i], ServiceFailed)
+			errs = append(errs, err)
+			continue
+		}
+		app.registry.setState(names[i], ServiceStopped)
+	}
+	return errors.Join(errs...)
+}
+
+// stopService calls Stop or Close on a single service, abandoning it once the
+// stop timeout elapses so one stuck service cannot block the others
+func (app *Application) stopService(name string, service interface{}) error {
+	var stop func(ctx context.Context) error
+	switch s := service.(type) {
+	case Stoppable:
+		stop = s.Stop
+	case io.Closer:
+		stop = func(context.Context) error { return s.Close() }
+	default:
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.serviceStopTimeout)
+	defer cancel()
+
+	// Buffered so an abandoned stop can still complete without leaking
+	done := make(chan error, 1)
+	go func() {
+		done <- stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to stop service %s: %w", name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("service %s did not stop within %v", name, app.serviceStopTimeout)
+	}
+}
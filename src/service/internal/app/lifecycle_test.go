@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// lifecycleRecorder collects start and stop events across fake services
+type lifecycleRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *lifecycleRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *lifecycleRecorder) Events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// fakeLifecycleService records its lifecycle calls and can be made to fail
+// or to block in Stop until its context is done
+type fakeLifecycleService struct {
+	name     string
+	recorder *lifecycleRecorder
+	startErr error
+	stopErr  error
+	hangStop bool
+}
+
+func (f *fakeLifecycleService) Start(ctx context.Context) error {
+	f.recorder.record("start:" + f.name)
+	return f.startErr
+}
+
+func (f *fakeLifecycleService) Stop(ctx context.Context) error {
+	f.recorder.record("stop:" + f.name)
+	if f.hangStop {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return f.stopErr
+}
+
+// fakeCloser only implements io.Closer
+type fakeCloser struct {
+	name     string
+	recorder *lifecycleRecorder
+}
+
+func (f *fakeCloser) Close() error {
+	f.recorder.record("close:" + f.name)
+	return nil
+}
+
+func newLifecycleApp(t *testing.T) *Application {
+	t.Helper()
+	app := NewApplication(&config.RawConfig{}, logging.NewTestLogger())
+	app.serviceStopTimeout = 50 * time.Millisecond
+	return app
+}
+
+func TestLifecycleOrdering(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("a", &fakeLifecycleService{name: "a", recorder: recorder})
+	app.RegisterService("plain", "no lifecycle")
+	app.RegisterService("b", &fakeCloser{name: "b", recorder: recorder})
+	app.RegisterService("c", &fakeLifecycleService{name: "c", recorder: recorder})
+
+	if err := app.startServices(app.Context()); err != nil {
+		t.Fatalf("startServices() returned error: %v", err)
+	}
+	if err := app.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+
+	expected := []string{"start:a", "start:c", "stop:c", "close:b", "stop:a"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+}
+
+func TestLifecycleStartFailureStopsStartedServices(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("a", &fakeLifecycleService{name: "a", recorder: recorder})
+	app.RegisterService("b", &fakeLifecycleService{name: "b", recorder: recorder, startErr: errors.New("boom")})
+	app.RegisterService("c", &fakeLifecycleService{name: "c", recorder: recorder})
+
+	err := app.startServices(app.Context())
+	if err == nil || !strings.Contains(err.Error(), "failed to start service b") {
+		t.Fatalf("startServices() error = %v, want failure naming service b", err)
+	}
+
+	expected := []string{"start:a", "start:b", "stop:a"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+}
+
+func TestLifecycleStopTimeout(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("first", &fakeLifecycleService{name: "first", recorder: recorder})
+	app.RegisterService("stuck", &fakeLifecycleService{name: "stuck", recorder: recorder, hangStop: true})
+
+	start := time.Now()
+	err := app.Shutdown()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v, expected the per-service timeout to apply", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "service stuck did not stop within") {
+		t.Errorf("Shutdown() error = %v, want timeout for service stuck", err)
+	}
+
+	// The stuck service must not prevent the remaining service from stopping
+	expected := []string{"stop:stuck", "stop:first"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+}
+
+func TestShutdownPipelineTimeout(t *testing.T) {
+	original := stopPipelineContext
+	stopPipelineContext = func(_ *processing.Pipeline, ctx context.Context) error {
+		<-ctx.Done()
+		return &processing.ShutdownTimeoutError{InFlight: 3, Err: ctx.Err()}
+	}
+	t.Cleanup(func() { stopPipelineContext = original })
+
+	app := newLifecycleApp(t)
+	app.rawconfig.Processing.ShutdownTimeout = 50 * time.Millisecond
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("a", &fakeLifecycleService{name: "a", recorder: recorder})
+
+	start := time.Now()
+	err := app.Shutdown()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v, expected processing.shutdownTimeout to apply", elapsed)
+	}
+	var timeoutErr *processing.ShutdownTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.InFlight != 3 {
+		t.Fatalf("Shutdown() error = %v, want a ShutdownTimeoutError with 3 in flight", err)
+	}
+	// The services are still stopped after the pipeline gave up
+	if got := recorder.Events(); !reflect.DeepEqual(got, []string{"stop:a"}) {
+		t.Errorf("lifecycle events = %v, want [stop:a]", got)
+	}
+}
+
+func TestShutdownAggregatesServiceErrors(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	stopErr := errors.New("flush failed")
+	app.RegisterService("healthy", &fakeLifecycleService{name: "healthy", recorder: recorder})
+	app.RegisterService("broken", &fakeLifecycleService{name: "broken", recorder: recorder, stopErr: stopErr})
+
+	err := app.Shutdown()
+	if !errors.Is(err, stopErr) {
+		t.Fatalf("Shutdown() error = %v, want it to wrap %v", err, stopErr)
+	}
+	if !strings.Contains(err.Error(), "failed to stop service broken") {
+		t.Errorf("Shutdown() error = %q, want it to name the failing service", err)
+	}
+
+	// The failing service is stopped first but must not prevent the other
+	expected := []string{"stop:broken", "stop:healthy"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+
+	logger := app.Logger().(*logging.TestLogger)
+	entry, found := logger.FindEntry(logging.ErrorLevel, "Failed to stop service")
+	if !found {
+		t.Errorf("expected the failure to be logged at error level, got %v", logger.Entries())
+	} else if entry.Fields["service"] != "broken" {
+		t.Errorf("expected the failing service as a field, got %v", entry.Fields)
+	}
+}
+
+func TestLifecycleDependencyOrdering(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("users", &fakeLifecycleService{name: "users", recorder: recorder}, "repo")
+	app.RegisterService("repo", &fakeLifecycleService{name: "repo", recorder: recorder}, "db")
+	app.RegisterService("db", &fakeLifecycleService{name: "db", recorder: recorder})
+
+	if err := app.startServices(app.Context()); err != nil {
+		t.Fatalf("startServices() returned error: %v", err)
+	}
+	if err := app.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() returned error: %v", err)
+	}
+
+	expected := []string{"start:db", "start:repo", "start:users", "stop:users", "stop:repo", "stop:db"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+}
+
+func TestLifecycleCycleFailsStart(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("a", &fakeLifecycleService{name: "a", recorder: recorder}, "b")
+	app.RegisterService("b", &fakeLifecycleService{name: "b", recorder: recorder}, "a")
+
+	err := app.startServices(app.Context())
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Fatalf("startServices() error = %v, want dependency cycle", err)
+	}
+	if events := recorder.Events(); len(events) != 0 {
+		t.Errorf("expected no service to start with a cycle, got %v", events)
+	}
+
+	// Shutdown still reaches every service, in reverse registration order
+	app.Shutdown()
+	expected := []string{"stop:b", "stop:a"}
+	if got := recorder.Events(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("lifecycle events = %v, want %v", got, expected)
+	}
+}
+
+func TestLifecycleRecordsServiceState(t *testing.T) {
+	app := newLifecycleApp(t)
+	recorder := &lifecycleRecorder{}
+	app.RegisterService("a", &fakeLifecycleService{name: "a", recorder: recorder})
+	app.RegisterService("plain", "no lifecycle")
+	app.RegisterService("broken", &fakeLifecycleService{name: "broken", recorder: recorder, stopErr: errors.New("boom")})
+
+	states := func() map[string]ServiceState {
+		result := make(map[string]ServiceState)
+		for _, info := range app.registry.Describe() {
+			result[info.Name] = info.State
+		}
+		return result
+	}
+
+	if err := app.startServices(app.Context()); err != nil {
+		t.Fatalf("startServices() returned error: %v", err)
+	}
+	want := map[string]ServiceState{"a": ServiceRunning, "plain": ServiceRunning, "broken": ServiceRunning}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("states after start = %v, want %v", got, want)
+	}
+
+	app.Shutdown()
+	want = map[string]ServiceState{"a": ServiceStopped, "plain": ServiceStopped, "broken": ServiceFailed}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("states after shutdown = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"servicegomodule/internal/metrics"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// Pipeline metric names and labels. Dashboards and alerts are built on them,
+// so they must not change. The counters are read from the current pipeline
+// on every scrape and start again from zero when the pipeline is restarted,
+// which Prometheus treats as a counter reset.
+const (
+	// MetricPipelineMessages counts messages by pipeline stage: consumed,
+	// processed, published, failed and dropped
+	MetricPipelineMessages = "pipeline_messages_total"
+	// MetricPipelineRunning is 1 while the pipeline is running, degraded or
+	// not, and 0 when it is stopped or has failed
+	MetricPipelineRunning = "pipeline_running"
+	// MetricChannelFill is how full the input and output channels are, from
+	// 0 to 1, by stage
+	MetricChannelFill = "pipeline_channel_fill_ratio"
+	// MetricTopicConsumed counts messages consumed by input topic
+	MetricTopicConsumed = "pipeline_topic_messages_consumed_total"
+	// MetricTopicErrors counts messages that failed processing or publishing
+	// by input topic
+	MetricTopicErrors = "pipeline_topic_errors_total"
+	// MetricFlushDuration is the time taken to publish each output batch
+	MetricFlushDuration = "pipeline_batch_flush_duration_seconds"
+	// MetricFlushMessages counts the messages in flushed output batches
+	MetricFlushMessages = "pipeline_batch_flush_messages_total"
+
+	// MetricLogEvents counts the warning and error lines logged through the
+	// application logger and the loggers derived from it, by level
+	MetricLogEvents = "log_events_total"
+
+	LabelStage = "stage"
+	LabelTopic = "topic"
+	LabelLevel = "level"
+)
+
+// Metrics returns the application's metrics registry, served at /metrics
+// when server.enableMetrics is set
+func (app *Application) Metrics() *metrics.Registry {
+	return app.metrics
+}
+
+// registerPipelineMetrics adds the pipeline metric families to the registry
+// and records batch flushes from pipeline. Pipelines rebuilt from it keep
+// recording.
+func (app *Application) registerPipelineMetrics(pipeline *processing.Pipeline) {
+	registry := app.metrics
+
+	registry.Collect(MetricPipelineMessages, "Messages handled by the processing pipeline, by stage.",
+		metrics.TypeCounter, []string{LabelStage}, func() []metrics.Sample {
+			snapshot := app.ProcessingPipeline().Metrics()
+			return []metrics.Sample{
+				{LabelValues: []string{"consumed"}, Value: float64(snapshot.Consumed)},
+				{LabelValues: []string{"processed"}, Value: float64(snapshot.Processed)},
+				{LabelValues: []string{"published"}, Value: float64(snapshot.Published)},
+				{LabelValues: []string{"failed"}, Value: float64(snapshot.Failed)},
+				{LabelValues: []string{"dropped"}, Value: float64(snapshot.Dropped)},
+			}
+		})
+	registry.Collect(MetricPipelineRunning, "Whether the processing pipeline is running.",
+		metrics.TypeGauge, nil, func() []metrics.Sample {
+			running := 0.0
+			if state := app.ProcessingPipeline().Metrics().State; state == processing.PipelineRunning || state == processing.PipelineDegraded {
+				running = 1
+			}
+			return []metrics.Sample{{Value: running}}
+		})
+	registry.Collect(MetricChannelFill, "How full the pipeline channels are, from 0 to 1, by stage.",
+		metrics.TypeGauge, []string{LabelStage}, func() []metrics.Sample {
+			snapshot := app.ProcessingPipeline().Metrics()
+			return []metrics.Sample{
+				{LabelValues: []string{"input"}, Value: snapshot.InputChannel.Fill},
+				{LabelValues: []string{"output"}, Value: snapshot.OutputChannel.Fill},
+			}
+		})
+	registry.Collect(MetricTopicConsumed, "Messages consumed by input topic.",
+		metrics.TypeCounter, []string{LabelTopic}, func() []metrics.Sample {
+			return topicSamples(app.ProcessingPipeline(), func(topic processing.TopicMetrics) int64 { return topic.Consumed })
+		})
+	registry.Collect(MetricTopicErrors, "Messages that failed processing or publishing, by input topic.",
+		metrics.TypeCounter, []string{LabelTopic}, func() []metrics.Sample {
+			return topicSamples(app.ProcessingPipeline(), func(topic processing.TopicMetrics) int64 { return topic.Errors })
+		})
+
+	duration := registry.NewHistogramVec(MetricFlushDuration, "Time taken to publish an output batch.", metrics.DefaultBuckets)
+	messages := registry.NewCounterVec(MetricFlushMessages, "Messages in flushed output batches.")
+	pipeline.SetFlushObserver(func(count int, elapsed time.Duration) {
+		duration.Observe(elapsed.Seconds())
+		messages.Add(float64(count))
+	})
+}
+
+// registerLogMetrics counts the warning and error events of logger, and of
+// every logger derived from it, with a hook
+func (app *Application) registerLogMetrics(logger logging.Logger) {
+	counts := logging.NewCountingHook(logging.WarnLevel)
+	logger.AddHook(counts)
+
+	app.metrics.Collect(MetricLogEvents, "Log lines at warning level and above, by level.",
+		metrics.TypeCounter, []string{LabelLevel}, func() []metrics.Sample {
+			levels := []logging.Level{logging.WarnLevel, logging.ErrorLevel, logging.FatalLevel, logging.PanicLevel}
+			samples := make([]metrics.Sample, 0, len(levels))
+			for _, level := range levels {
+				samples = append(samples, metrics.Sample{
+					LabelValues: []string{strings.ToLower(level.String())},
+					Value:       float64(counts.Count(level)),
+				})
+			}
+			return samples
+		})
+}
+
+// topicSamples returns one sample per input topic holding the counter
+// selected by value
+func topicSamples(pipeline *processing.Pipeline, value func(processing.TopicMetrics) int64) []metrics.Sample {
+	topics := pipeline.TopicMetrics()
+	samples := make([]metrics.Sample, 0, len(topics))
+	for topic, counters := range topics {
+		samples = append(samples, metrics.Sample{LabelValues: []string{topic}, Value: float64(value(counters))})
+	}
+	return samples
+}
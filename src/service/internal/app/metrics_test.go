@@ -0,0 +1,87 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+func scrape(t *testing.T, app *Application) string {
+	t.Helper()
+	var out strings.Builder
+	if _, err := app.Metrics().WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() returned error: %v", err)
+	}
+	return out.String()
+}
+
+func TestApplicationPipelineMetrics(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Processing.Input.Topics = []string{"orders"}
+	app := NewApplication(cfg, logging.NewTestLogger())
+	defer app.Shutdown()
+
+	exposition := scrape(t, app)
+	for _, line := range []string{
+		"# TYPE " + MetricPipelineMessages + " counter",
+		MetricPipelineMessages + `{stage="consumed"} 0`,
+		MetricPipelineMessages + `{stage="dropped"} 0`,
+		MetricPipelineRunning + " 0",
+		MetricChannelFill + `{stage="input"} 0`,
+		MetricTopicConsumed + `{topic="orders"} 0`,
+		MetricTopicErrors + `{topic="orders"} 0`,
+		"# TYPE " + MetricFlushDuration + " histogram",
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("Expected %q in the exposition:\n%s", line, exposition)
+		}
+	}
+}
+
+func TestApplicationMetricsFollowRestart(t *testing.T) {
+	stubPipelineStart(t, func(*processing.Pipeline) error { return nil })
+	app := newRunningApp(t)
+	if _, err := app.RestartPipeline(); err != nil {
+		t.Fatalf("RestartPipeline() returned error: %v", err)
+	}
+	if err := app.UpdateInputTopics([]string{"payments"}); err != nil {
+		t.Fatalf("UpdateInputTopics() returned error: %v", err)
+	}
+
+	if exposition := scrape(t, app); !strings.Contains(exposition, MetricTopicConsumed+`{topic="payments"} 0`) {
+		t.Errorf("Expected the metrics to read from the restarted pipeline, got:\n%s", exposition)
+	}
+}
+
+func TestApplicationLogMetrics(t *testing.T) {
+	logger, err := logging.NewLogger(&logging.LoggerConfig{
+		Level:       logging.InfoLevel,
+		FilePath:    filepath.Join(t.TempDir(), "app.log"),
+		LoggerName:  "test",
+		ServiceName: "test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	app := NewApplication(config.LoadConfig(), logger)
+	defer app.Shutdown()
+
+	logger.Warn("Slow")
+	logger.WithField(logging.FieldComponent, "processing").Error("Failed")
+	logger.Info("Not counted")
+
+	exposition := scrape(t, app)
+	for _, line := range []string{
+		MetricLogEvents + `{level="warn"} 1`,
+		MetricLogEvents + `{level="error"} 1`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("Expected %q in the exposition:\n%s", line, exposition)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+)
+
+// ErrNotRunning is returned by RestartPipeline while the application is
+// starting or shutting down
+var ErrNotRunning = errors.New("application is not running")
+
+// startPipeline starts a rebuilt pipeline; tests replace it to simulate failures
+var startPipeline = (*processing.Pipeline).Start
+
+// stopPipelineContext stops the pipeline on shutdown and restart; tests
+// replace it to simulate a pipeline that does not stop in time
+var stopPipelineContext = (*processing.Pipeline).StopContext
+
+// PipelineRestart reports how a pipeline restart went
+type PipelineRestart struct {
+	StopMs       int64 `json:"stop_ms"`        // Time spent stopping the old pipeline
+	StartMs      int64 `json:"start_ms"`       // Time spent starting the new one
+	StopTimedOut bool  `json:"stop_timed_out"` // The old pipeline was abandoned after the stop timeout
+}
+
+// RestartPipeline stops the processing pipeline and starts a new one built
+// from the current configuration, keeping the installed message processors.
+// The new pipeline is started once the old one has drained and stopped, or
+// processing.shutdownTimeout has passed. Concurrent restarts run one after
+// another.
+//
+// If the new pipeline fails to start the application is marked degraded; a
+// successful restart makes a degraded application ready again.
+func (app *Application) RestartPipeline() (PipelineRestart, error) {
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	var result PipelineRestart
+	if state := app.State(); state != StateReady && state != StateDegraded {
+		return result, fmt.Errorf("cannot restart the pipeline while %s: %w", state, ErrNotRunning)
+	}
+
+	app.mutex.RLock()
+	old := app.processingPipeline
+	cfg := app.rawconfig
+	app.mutex.RUnlock()
+
+	app.logger.Info("Restarting processing pipeline")
+	began := time.Now()
+	var timeoutErr *processing.ShutdownTimeoutError
+	result.StopTimedOut = errors.As(app.stopPipeline(old), &timeoutErr)
+	result.StopMs = time.Since(began).Milliseconds()
+
+	next := old.Rebuild(processing.DefaultConfig(cfg))
+	app.mutex.Lock()
+	app.processingPipeline = next
+	app.mutex.Unlock()
+
+	began = time.Now()
+	err := startPipeline(next)
+	result.StartMs = time.Since(began).Milliseconds()
+	if err != nil {
+		app.logger.Errorw("Failed to restart processing pipeline", "error", err)
+		app.SetDegraded(fmt.Sprintf("pipeline restart failed: %v", err))
+		return result, fmt.Errorf("failed to start processing pipeline: %w", err)
+	}
+
+	if app.State() == StateDegraded {
+		app.MarkReady()
+	}
+	app.logger.Infow("Processing pipeline restarted", "stop_ms", result.StopMs, "start_ms", result.StartMs, "stop_timed_out", result.StopTimedOut)
+	return result, nil
+}
+
+// UpdateInputTopics resubscribes the running pipeline to topics and records
+// them in the configuration, so /api/v1/config and later pipeline restarts
+// use the new list.
+func (app *Application) UpdateInputTopics(topics []string) error {
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	if err := app.ProcessingPipeline().UpdateTopics(topics); err != nil {
+		return err
+	}
+
+	app.mutex.Lock()
+	next := *app.rawconfig
+	next.Processing.Input.Topics = append([]string(nil), topics...)
+	app.rawconfig = &next
+	app.mutex.Unlock()
+	return nil
+}
+
+// runtimeProcessingFields lists the processing settings UpdateProcessingConfig
+// applies to the running pipeline; see processing.Pipeline.ApplyRuntimeConfig
+var runtimeProcessingFields = []string{
+	"processing.processor.processingDelay",
+	"processing.processor.batchSize",
+	"processing.processor.maxRetries",
+	"processing.output.batchSize",
+	"processing.output.flushTimeout",
+	"processing.output.maxMessagesPerSecond",
+}
+
+// InvalidConfigError is returned by UpdateProcessingConfig for settings that
+// fail validation, with one entry per problem
+type InvalidConfigError struct {
+	Problems []string
+}
+
+func (e *InvalidConfigError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// ProcessingConfigUpdate reports how UpdateProcessingConfig applied new
+// processing settings
+type ProcessingConfigUpdate struct {
+	Applied         []string    `json:"applied"`          // Changed fields now in effect on the running pipeline
+	RestartRequired []string    `json:"restart_required"` // Changed fields that take effect on the next pipeline restart
+	Config          interface{} `json:"config"`           // The processing configuration after the update
+}
+
+// UpdateProcessingConfig applies patch, a JSON object of processing settings
+// as read by config.PatchProcessing, to the processing configuration once the
+// result passes the same validation as the configuration file and the
+// pipeline. The batch sizes, processing delay, retries, flush timeout and
+// publish rate limit are applied to the running pipeline; other changes are
+// recorded and reported as requiring a pipeline restart. Invalid settings
+// change nothing and return an *InvalidConfigError.
+func (app *Application) UpdateProcessingConfig(patch []byte) (ProcessingConfigUpdate, error) {
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	var result ProcessingConfigUpdate
+	app.mutex.RLock()
+	old := app.rawconfig
+	app.mutex.RUnlock()
+
+	settings, err := config.PatchProcessing(old.Processing, patch)
+	if err != nil {
+		return result, &InvalidConfigError{Problems: []string{err.Error()}}
+	}
+	next := *old
+	next.Processing = settings
+	if err := validateProcessing(&next); err != nil {
+		return result, err
+	}
+
+	for _, field := range config.Diff(old, &next) {
+		if slices.Contains(runtimeProcessingFields, field) {
+			result.Applied = append(result.Applied, field)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, field)
+		}
+	}
+
+	app.mutex.Lock()
+	app.rawconfig = &next
+	app.mutex.Unlock()
+
+	if len(result.Applied) > 0 {
+		app.ProcessingPipeline().ApplyRuntimeConfig(processing.DefaultConfig(&next))
+	}
+	if len(result.RestartRequired) > 0 {
+		app.logger.Warnw("Processing configuration changes require a pipeline restart", "fields", result.RestartRequired)
+	}
+	app.logger.Infow("Processing configuration updated", "applied", result.Applied)
+	result.Config = config.Redact(&next)["processing"]
+	return result, nil
+}
+
+// validateProcessing checks cfg as the configuration file and the pipeline
+// would, returning an *InvalidConfigError listing every problem
+func validateProcessing(cfg *config.RawConfig) error {
+	err := cfg.Validate()
+	if err == nil {
+		err = processing.ValidateConfig(processing.DefaultConfig(cfg))
+	}
+	if err == nil {
+		return nil
+	}
+
+	var problems []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, problem := range joined.Unwrap() {
+			problems = append(problems, problem.Error())
+		}
+	} else {
+		problems = append(problems, err.Error())
+	}
+	return &InvalidConfigError{Problems: problems}
+}
+
+// ReloadFilterRules re-reads the filter rules file named by the current
+// configuration into the pipeline and returns the number of rule blocks
+// loaded. If the file cannot be loaded the previous rules stay in effect and
+// the error wraps processing.ErrInvalidRules.
+func (app *Application) ReloadFilterRules() (int, error) {
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	settings := processing.DefaultConfig(app.Config()).Filter
+	return app.ProcessingPipeline().ReloadFilter(settings)
+}
+
+// ReloadSchemas re-reads the payload schema files named by the current
+// configuration into the pipeline and returns the number of schemas loaded.
+// If any schema cannot be loaded the previous ones stay in effect and the
+// error wraps processing.ErrInvalidSchema.
+func (app *Application) ReloadSchemas() (int, error) {
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	settings := processing.DefaultConfig(app.Config()).Validation
+	return app.ProcessingPipeline().ReloadSchemas(settings)
+}
+
+// stopPipeline stops pipeline, giving up once processing.shutdownTimeout has
+// passed with a *processing.ShutdownTimeoutError
+func (app *Application) stopPipeline(pipeline *processing.Pipeline) error {
+	timeout := app.pipelineShutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := stopPipelineContext(pipeline, ctx)
+	var timeoutErr *processing.ShutdownTimeoutError
+	if errors.As(err, &timeoutErr) {
+		app.logger.Errorw("Processing pipeline did not stop in time", "in_flight", timeoutErr.InFlight, "timeout", timeout)
+	} else if err != nil {
+		app.logger.Errorw("Error stopping processing pipeline", "error", err)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+//go:build local
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// startLocalApplication starts an application over the file-based local
+// message bus, reading and writing fresh topics named after prefix since the
+// bus keeps topics on disk across runs, and shuts it down when the test ends
+func startLocalApplication(t *testing.T, prefix string) (*Application, *config.RawConfig) {
+	t.Helper()
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	cfg := config.LoadConfig()
+	cfg.Processing.Input.Topics = []string{prefix + "-input-" + suffix}
+	cfg.Processing.Input.PollTimeout = 100 * time.Millisecond
+	cfg.Processing.Output.OutputTopic = prefix + "-output-" + suffix
+	cfg.Processing.Output.BatchSize = 1
+	cfg.Processing.Processor.ProcessingDelay = 0
+
+	app := NewApplication(cfg, logging.NewTestLogger())
+	if err := app.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	t.Cleanup(func() { app.Shutdown() })
+	return app, cfg
+}
+
+// sendRecords sends n processing records, r0 to r(n-1), to topic
+func sendRecords(t *testing.T, topic string, n int) {
+	t.Helper()
+	producer := messagebus.NewProducer("kafka-producer.yaml")
+	t.Cleanup(func() { producer.Close() })
+	for i := 0; i < n; i++ {
+		record, err := json.Marshal(processing.ProcessingRecord{ID: fmt.Sprintf("r%d", i), Data: map[string]interface{}{"name": "widget"}})
+		if err != nil {
+			t.Fatalf("Failed to marshal record: %v", err)
+		}
+		if _, _, err := producer.Send(context.Background(), &messagebus.Message{Topic: topic, Value: record}); err != nil {
+			t.Fatalf("Send() returned error: %v", err)
+		}
+	}
+}
+
+// TestApplicationProcessesMessages runs a message through the started
+// application over the file-based local message bus
+func TestApplicationProcessesMessages(t *testing.T) {
+	app, cfg := startLocalApplication(t, "app-test")
+	sendRecords(t, cfg.Processing.Input.Topics[0], 1)
+
+	consumer := messagebus.NewConsumer("kafka-consumer.yaml", "app-test")
+	defer consumer.Close()
+	if err := consumer.Subscribe([]string{cfg.Processing.Output.OutputTopic}); err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	message, err := consumer.Poll(5 * time.Second)
+	if err != nil || message == nil {
+		t.Fatalf("No processed message on %s: %v", cfg.Processing.Output.OutputTopic, err)
+	}
+
+	var processed processing.ProcessingRecord
+	if err := json.Unmarshal(message.Value, &processed); err != nil {
+		t.Fatalf("Failed to unmarshal processed record: %v", err)
+	}
+	if processed.ID != "r0" || processed.Data["name"] != "processed_widget" {
+		t.Errorf("processed record = %+v, want id r0 with name processed_widget", processed)
+	}
+
+	if err := app.Shutdown(); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+}
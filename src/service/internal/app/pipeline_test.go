@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+	"sharedgomodule/logging"
+)
+
+// stubPipelineStart replaces startPipeline for the duration of the test
+func stubPipelineStart(t *testing.T, start func(*processing.Pipeline) error) {
+	t.Helper()
+	original := startPipeline
+	startPipeline = start
+	t.Cleanup(func() { startPipeline = original })
+}
+
+func newRunningApp(t *testing.T) *Application {
+	t.Helper()
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	if err := app.MarkReady(); err != nil {
+		t.Fatalf("MarkReady() returned error: %v", err)
+	}
+	t.Cleanup(func() { app.Shutdown() })
+	return app
+}
+
+func TestRestartPipelineRequiresRunningApplication(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	if _, err := app.RestartPipeline(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning before Start, got %v", err)
+	}
+
+	app.Shutdown()
+	if _, err := app.RestartPipeline(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning after Shutdown, got %v", err)
+	}
+}
+
+func TestRestartPipelineReplacesPipeline(t *testing.T) {
+	stubPipelineStart(t, func(*processing.Pipeline) error { return nil })
+	app := newRunningApp(t)
+	old := app.ProcessingPipeline()
+
+	result, err := app.RestartPipeline()
+	if err != nil {
+		t.Fatalf("RestartPipeline() returned error: %v", err)
+	}
+	if app.ProcessingPipeline() == old {
+		t.Error("Expected a new pipeline after the restart")
+	}
+	if result.StopTimedOut || result.StopMs < 0 || result.StartMs < 0 {
+		t.Errorf("Unexpected restart result %+v", result)
+	}
+	if !app.IsReady() {
+		t.Errorf("Expected the application to stay ready, got %s", app.State())
+	}
+}
+
+func TestRestartPipelineFailureDegradesApplication(t *testing.T) {
+	stubPipelineStart(t, func(*processing.Pipeline) error { return errors.New("broker unreachable") })
+	app := newRunningApp(t)
+
+	if _, err := app.RestartPipeline(); err == nil {
+		t.Fatal("Expected the failed start to be reported")
+	}
+	if app.State() != StateDegraded || !strings.Contains(app.DegradedReason(), "broker unreachable") {
+		t.Errorf("Expected a degraded application, got %s (%q)", app.State(), app.DegradedReason())
+	}
+
+	// A later successful restart recovers
+	startPipeline = func(*processing.Pipeline) error { return nil }
+	if _, err := app.RestartPipeline(); err != nil {
+		t.Fatalf("RestartPipeline() returned error: %v", err)
+	}
+	if !app.IsReady() {
+		t.Errorf("Expected the application to be ready again, got %s", app.State())
+	}
+}
+
+func TestRestartPipelineStopTimeout(t *testing.T) {
+	stubPipelineStart(t, func(*processing.Pipeline) error { return nil })
+	original := stopPipelineContext
+	stopPipelineContext = func(_ *processing.Pipeline, ctx context.Context) error {
+		<-ctx.Done()
+		return &processing.ShutdownTimeoutError{InFlight: 2, Err: ctx.Err()}
+	}
+	t.Cleanup(func() { stopPipelineContext = original })
+	app := newRunningApp(t)
+	app.rawconfig.Processing.ShutdownTimeout = 50 * time.Millisecond
+
+	result, err := app.RestartPipeline()
+	if err != nil {
+		t.Fatalf("RestartPipeline() returned error: %v", err)
+	}
+	if !result.StopTimedOut {
+		t.Error("Expected the restart to report the stop timing out")
+	}
+	if result.StopMs > time.Second.Milliseconds() {
+		t.Errorf("Stopping took %dms, expected processing.shutdownTimeout to apply", result.StopMs)
+	}
+}
+
+func TestRestartPipelineSerializesRestarts(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	stubPipelineStart(t, func(*processing.Pipeline) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	app := newRunningApp(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := app.RestartPipeline(); err != nil {
+				t.Errorf("RestartPipeline() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() != 1 {
+		t.Errorf("Expected restarts to run one at a time, saw %d at once", maxInFlight.Load())
+	}
+}
+
+func TestUpdateInputTopicsRecordsConfig(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	defer app.Shutdown()
+	before := app.Config()
+
+	if err := app.UpdateInputTopics(nil); !errors.Is(err, processing.ErrInvalidTopics) {
+		t.Errorf("Expected ErrInvalidTopics for an empty list, got %v", err)
+	}
+	if err := app.UpdateInputTopics([]string{"orders", "refunds"}); err != nil {
+		t.Fatalf("UpdateInputTopics() returned error: %v", err)
+	}
+
+	topics := app.Config().Processing.Input.Topics
+	if len(topics) != 2 || topics[0] != "orders" || topics[1] != "refunds" {
+		t.Errorf("Expected the configuration to hold the new topics, got %v", topics)
+	}
+	if before.Processing.Input.Topics[0] == "orders" {
+		t.Error("Expected the previous configuration to be left unchanged")
+	}
+}
+
+func TestUpdateProcessingConfig(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	patch := `{"processor":{"batchSize":7,"concurrency":3},"output":{"flushTimeout":"250ms","maxMessagesPerSecond":20}}`
+
+	result, err := app.UpdateProcessingConfig([]byte(patch))
+	if err != nil {
+		t.Fatalf("UpdateProcessingConfig() returned error: %v", err)
+	}
+	wantApplied := []string{"processing.processor.batchSize", "processing.output.flushTimeout", "processing.output.maxMessagesPerSecond"}
+	if strings.Join(result.Applied, ",") != strings.Join(wantApplied, ",") {
+		t.Errorf("Applied = %v, want %v", result.Applied, wantApplied)
+	}
+	if strings.Join(result.RestartRequired, ",") != "processing.processor.concurrency" {
+		t.Errorf("RestartRequired = %v, want the concurrency", result.RestartRequired)
+	}
+	if output := result.Config.(map[string]interface{})["output"].(map[string]interface{}); output["flushTimeout"] != "250ms" {
+		t.Errorf("Expected the updated configuration in the result, got %v", output)
+	}
+
+	stats := app.ProcessingPipeline().GetStats()
+	processor, output := stats["processor_stats"].(map[string]interface{}), stats["output_stats"].(map[string]interface{})
+	if processor["batch_size"] != 7 || processor["concurrency"] != 1 {
+		t.Errorf("Expected the batch size applied and the concurrency left for a restart, got %v and %v", processor["batch_size"], processor["concurrency"])
+	}
+	if output["flush_timeout"] != "250ms" || output["max_messages_per_second"] != 20.0 {
+		t.Errorf("Expected the output settings applied, got %v and %v", output["flush_timeout"], output["max_messages_per_second"])
+	}
+	if current := app.Config().Processing; current.Processor.Concurrency != 3 || current.Output.FlushTimeout != 250*time.Millisecond {
+		t.Errorf("Expected the configuration to record every change, got %+v", current)
+	}
+}
+
+func TestUpdateProcessingConfigRejectsInvalidSettings(t *testing.T) {
+	app := NewApplication(config.LoadConfig(), logging.NewTestLogger())
+	before := app.Config()
+
+	for patch, want := range map[string]string{
+		`{"processor":{"batchSize":0},"output":{"maxMessagesPerSecond":-1}}`: "processing.output.maxMessagesPerSecond must not be negative",
+		`{"output":{"batchSise":5}}`:                                         "field batchSise not found",
+		`{"output":{"flushTimeout":"soon"}}`:                                 "cannot unmarshal",
+		`[1, 2]`:                                                             "must be a JSON object",
+	} {
+		_, err := app.UpdateProcessingConfig([]byte(patch))
+		var invalid *InvalidConfigError
+		if !errors.As(err, &invalid) || !strings.Contains(strings.Join(invalid.Problems, "\n"), want) {
+			t.Errorf("UpdateProcessingConfig(%s) error = %v, want a problem containing %q", patch, err, want)
+		}
+	}
+	if app.Config() != before {
+		t.Error("Expected invalid settings to leave the configuration unchanged")
+	}
+}
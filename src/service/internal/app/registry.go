@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceState is the lifecycle state of a registered service
+type ServiceState string
+
+// Service states recorded by the application lifecycle
+const (
+	ServiceRegistered ServiceState = "registered" // Registered but not yet started
+	ServiceRunning    ServiceState = "running"    // Started, or nothing to start
+	ServiceStopped    ServiceState = "stopped"    // Stopped cleanly
+	ServiceFailed     ServiceState = "failed"     // Start or stop returned an error
+)
+
+// ServiceInfo describes a registered service for debugging and the services
+// endpoint
+type ServiceInfo struct {
+	Name         string       `json:"name"`
+	Type         string       `json:"type"` // Concrete Go type, e.g. *cache.Store
+	RegisteredAt time.Time    `json:"registeredAt"`
+	DependsOn    []string     `json:"dependsOn"`
+	State        ServiceState `json:"state"`
+}
+
+// ServiceRegistry holds the named services wired into the application,
+// remembering registration order and declared dependencies so lifecycle
+// hooks run deterministically
+type ServiceRegistry struct {
+	services     map[string]interface{}
+	dependsOn    map[string][]string
+	registeredAt map[string]time.Time
+	states       map[string]ServiceState
+	order        []string
+	mutex        sync.RWMutex
+}
+
+// NewServiceRegistry creates an empty service registry
+func NewServiceRegistry() *ServiceRegistry {
+	return &ServiceRegistry{
+		services:     make(map[string]interface{}),
+		dependsOn:    make(map[string][]string),
+		registeredAt: make(map[string]time.Time),
+		states:       make(map[string]ServiceState),
+	}
+}
+
+// Register adds a service under the given name. dependsOn names services that
+// must be started before this one; they may be registered later, and are
+// only checked when the order is resolved.
+func (r *ServiceRegistry) Register(name string, service interface{}, dependsOn ...string) error {
+	if name == "" {
+		return fmt.Errorf("service name cannot be empty")
+	}
+	if service == nil {
+		return fmt.Errorf("service %s cannot be nil", name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.services[name]; exists {
+		return fmt.Errorf("service %s is already registered", name)
+	}
+	r.services[name] = service
+	r.dependsOn[name] = append([]string(nil), dependsOn...)
+	r.registeredAt[name] = time.Now()
+	r.states[name] = ServiceRegistered
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Get returns the service registered under the given name
+func (r *ServiceRegistry) Get(name string) (interface{}, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	service, exists := r.services[name]
+	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	return service, nil
+}
+
+// GetAs returns the service registered under name as type T, failing with a
+// descriptive error when the stored service has a different type
+func GetAs[T any](r *ServiceRegistry, name string) (T, error) {
+	var zero T
+	service, err := r.Get(name)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := service.(T)
+	if !ok {
+		return zero, fmt.Errorf("service %s is %T, not %v", name, service, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return typed, nil
+}
+
+// List returns the names of all registered services in registration order
+func (r *ServiceRegistry) List() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, len(r.order))
+	copy(names, r.order)
+	return names
+}
+
+// Describe returns metadata for every registered service in registration order
+func (r *ServiceRegistry) Describe() []ServiceInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	infos := make([]ServiceInfo, 0, len(r.order))
+	for _, name := range r.order {
+		infos = append(infos, ServiceInfo{
+			Name:         name,
+			Type:         reflect.TypeOf(r.services[name]).String(),
+			RegisteredAt: r.registeredAt[name],
+			DependsOn:    append([]string{}, r.dependsOn[name]...),
+			State:        r.states[name],
+		})
+	}
+	return infos
+}
+
+// setState records the lifecycle state of a registered service
+func (r *ServiceRegistry) setState(name string, state ServiceState) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, exists := r.services[name]; exists {
+		r.states[name] = state
+	}
+}
+
+// ResolveOrder returns the service names ordered so every service follows
+// the services it depends on. Independent services keep their registration
+// order. An error is returned for a dependency on an unregistered service or
+// for a dependency cycle.
+func (r *ServiceRegistry) ResolveOrder() ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(r.order))
+	resolved := make([]string, 0, len(r.order))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			// Trim the path to the start of the cycle for a readable error
+			for i, p := range path {
+				if p == name {
+					path = path[i:]
+					break
+				}
+			}
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range r.dependsOn[name] {
+			if _, exists := r.services[dep]; !exists {
+				return fmt.Errorf("service %s depends on unregistered service %s", name, dep)
+			}
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		resolved = append(resolved, name)
+		return nil
+	}
+
+	for _, name := range r.order {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// HealthCheckers returns the registered services that implement HealthChecker,
+// keyed by service name
+func (r *ServiceRegistry) HealthCheckers() map[string]HealthChecker {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	checkers := make(map[string]HealthChecker)
+	for _, name := range r.order {
+		if checker, ok := r.services[name].(HealthChecker); ok {
+			checkers[name] = checker
+		}
+	}
+	return checkers
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServiceRegistryRegisterAndGet(t *testing.T) {
+	registry := NewServiceRegistry()
+
+	if err := registry.Register("first", "service-one"); err != nil {
+		t.Fatalf("Register() returned error: %v", err)
+	}
+	if err := registry.Register("second", 2); err != nil {
+		t.Fatalf("Register() returned error: %v", err)
+	}
+
+	service, err := registry.Get("first")
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if service != "service-one" {
+		t.Errorf("Get() = %v, want service-one", service)
+	}
+
+	if got := registry.List(); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("List() = %v, want registration order [first second]", got)
+	}
+}
+
+func TestServiceRegistryErrors(t *testing.T) {
+	registry := NewServiceRegistry()
+	registry.Register("dup", "service")
+
+	tests := []struct {
+		name    string
+		svcName string
+		service interface{}
+		wantErr string
+	}{
+		{"empty name", "", "service", "service name cannot be empty"},
+		{"nil service", "nil", nil, "service nil cannot be nil"},
+		{"duplicate", "dup", "other", "service dup is already registered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.svcName, tt.service)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Register() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := registry.Get("missing"); err == nil || err.Error() != "service missing not found" {
+		t.Errorf("Get() error = %v, want %q", err, "service missing not found")
+	}
+}
+
+func TestServiceRegistryHealthCheckers(t *testing.T) {
+	registry := NewServiceRegistry()
+	registry.Register("plain", "not a checker")
+	registry.Register("checked", &fakeHealthService{})
+
+	checkers := registry.HealthCheckers()
+	if len(checkers) != 1 {
+		t.Fatalf("HealthCheckers() returned %d checkers, want 1", len(checkers))
+	}
+	if _, ok := checkers["checked"]; !ok {
+		t.Error("HealthCheckers() is missing the service implementing HealthChecker")
+	}
+}
+
+func TestGetAs(t *testing.T) {
+	registry := NewServiceRegistry()
+	checker := &fakeHealthService{}
+	registry.Register("checker", checker)
+	registry.Register("name", "plain string")
+
+	t.Run("concrete type", func(t *testing.T) {
+		got, err := GetAs[*fakeHealthService](registry, "checker")
+		if err != nil {
+			t.Fatalf("GetAs() returned error: %v", err)
+		}
+		if got != checker {
+			t.Error("GetAs() returned a different instance")
+		}
+	})
+
+	t.Run("interface type", func(t *testing.T) {
+		got, err := GetAs[HealthChecker](registry, "checker")
+		if err != nil {
+			t.Fatalf("GetAs() returned error: %v", err)
+		}
+		if got == nil {
+			t.Error("GetAs() returned a nil HealthChecker")
+		}
+	})
+
+	t.Run("mismatched concrete type", func(t *testing.T) {
+		got, err := GetAs[*lifecycleRecorder](registry, "checker")
+		want := "service checker is *app.fakeHealthService, not *app.lifecycleRecorder"
+		if err == nil || err.Error() != want {
+			t.Errorf("GetAs() error = %v, want %q", err, want)
+		}
+		if got != nil {
+			t.Errorf("GetAs() = %v, want zero value on mismatch", got)
+		}
+	})
+
+	t.Run("value not implementing interface", func(t *testing.T) {
+		_, err := GetAs[HealthChecker](registry, "name")
+		want := "service name is string, not app.HealthChecker"
+		if err == nil || err.Error() != want {
+			t.Errorf("GetAs() error = %v, want %q", err, want)
+		}
+	})
+
+	t.Run("missing service", func(t *testing.T) {
+		_, err := GetAs[string](registry, "missing")
+		if err == nil || err.Error() != "service missing not found" {
+			t.Errorf("GetAs() error = %v, want not found", err)
+		}
+	})
+}
+
+func TestResolveOrderDiamond(t *testing.T) {
+	registry := NewServiceRegistry()
+	// api depends on users and audit, both of which depend on db; db is
+	// registered last to prove order comes from dependencies
+	registry.Register("api", "api", "users", "audit")
+	registry.Register("users", "users", "db")
+	registry.Register("audit", "audit", "db")
+	registry.Register("db", "db")
+
+	order, err := registry.ResolveOrder()
+	if err != nil {
+		t.Fatalf("ResolveOrder() returned error: %v", err)
+	}
+
+	expected := []string{"db", "users", "audit", "api"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("ResolveOrder() = %v, want %v", order, expected)
+	}
+	if got := registry.List(); !reflect.DeepEqual(got, []string{"api", "users", "audit", "db"}) {
+		t.Errorf("List() = %v, want registration order", got)
+	}
+}
+
+func TestResolveOrderErrors(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		registry := NewServiceRegistry()
+		registry.Register("standalone", "s")
+		registry.Register("a", "a", "b")
+		registry.Register("b", "b", "c")
+		registry.Register("c", "c", "a")
+
+		_, err := registry.ResolveOrder()
+		want := "dependency cycle: a -> b -> c -> a"
+		if err == nil || err.Error() != want {
+			t.Errorf("ResolveOrder() error = %v, want %q", err, want)
+		}
+	})
+
+	t.Run("self dependency", func(t *testing.T) {
+		registry := NewServiceRegistry()
+		registry.Register("loop", "l", "loop")
+
+		_, err := registry.ResolveOrder()
+		if err == nil || err.Error() != "dependency cycle: loop -> loop" {
+			t.Errorf("ResolveOrder() error = %v, want self cycle", err)
+		}
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		registry := NewServiceRegistry()
+		registry.Register("users", "users", "db")
+
+		_, err := registry.ResolveOrder()
+		want := "service users depends on unregistered service db"
+		if err == nil || err.Error() != want {
+			t.Errorf("ResolveOrder() error = %v, want %q", err, want)
+		}
+	})
+}
+
+func TestServiceRegistryDescribe(t *testing.T) {
+	registry := NewServiceRegistry()
+	before := time.Now()
+	registry.Register("db", &fakeCloser{name: "db"})
+	registry.Register("cache", "in-memory", "db")
+
+	infos := registry.Describe()
+	if len(infos) != 2 {
+		t.Fatalf("Describe() returned %d services, want 2", len(infos))
+	}
+
+	db, cache := infos[0], infos[1]
+	if db.Name != "db" || db.Type != "*app.fakeCloser" || db.State != ServiceRegistered || len(db.DependsOn) != 0 {
+		t.Errorf("unexpected db info: %+v", db)
+	}
+	if cache.Name != "cache" || cache.Type != "string" || !reflect.DeepEqual(cache.DependsOn, []string{"db"}) {
+		t.Errorf("unexpected cache info: %+v", cache)
+	}
+	for _, info := range infos {
+		if info.RegisteredAt.Before(before) || info.RegisteredAt.After(time.Now()) {
+			t.Errorf("%s registered at %v, want between %v and now", info.Name, info.RegisteredAt, before)
+		}
+	}
+
+	// Describe returns copies that callers may modify freely
+	cache.DependsOn[0] = "changed"
+	if got := registry.Describe()[1].DependsOn[0]; got != "db" {
+		t.Errorf("Describe() dependencies were modified through a returned slice, got %q", got)
+	}
+}
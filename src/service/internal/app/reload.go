@@ -0,0 +1,150 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/processing"
+)
+
+// reloadablePrefixes lists the configuration paths that can be applied to a
+// running application, including every runtime processing setting a
+// processing update applies. Anything else is only picked up after a restart.
+var reloadablePrefixes = append([]string{
+	"logging.level",
+	"server.cors.",
+	"server.rateLimit.",
+	"processing.filter.",
+	"processing.validation.",
+}, runtimeProcessingFields...)
+
+// ConfigChangeFunc is called after a reload with the previous and the newly
+// applied configuration
+type ConfigChangeFunc func(old, new *config.RawConfig)
+
+// OnConfigChange registers fn to be called after every successful Reload.
+// Subscribers are called in registration order.
+func (app *Application) OnConfigChange(fn ConfigChangeFunc) {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	app.configSubscribers = append(app.configSubscribers, fn)
+}
+
+// Reload applies the reloadable subset of cfg to the running application:
+// the log level, CORS policy, rate limits, runtime processing settings,
+// filter settings (re-reading the rules file) and payload schemas. Changes to
+// any other field are logged as requiring a restart and otherwise ignored.
+// cfg is validated as a processing update would be; an invalid cfg changes
+// nothing and returns an *InvalidConfigError. Filter rules or schemas that
+// fail to load keep their previous settings, in the configuration as in the
+// pipeline, and the failure is returned once the rest has been applied.
+func (app *Application) Reload(cfg *config.RawConfig) error {
+	if err := validateProcessing(cfg); err != nil {
+		return err
+	}
+
+	// Serialized with UpdateProcessingConfig and pipeline restarts, which
+	// also read and replace rawconfig
+	app.pipelineMutex.Lock()
+	defer app.pipelineMutex.Unlock()
+
+	app.mutex.RLock()
+	old := app.rawconfig
+	pipeline := app.processingPipeline
+	subscribers := append([]ConfigChangeFunc(nil), app.configSubscribers...)
+	app.mutex.RUnlock()
+
+	var applied, restart []string
+	for _, field := range config.Diff(old, cfg) {
+		if isReloadable(field) {
+			applied = append(applied, field)
+		} else {
+			restart = append(restart, field)
+		}
+	}
+	if len(restart) > 0 {
+		app.logger.Warnw("Configuration changes require a restart", "fields", restart)
+	}
+	if len(applied) == 0 {
+		app.logger.Info("Configuration reloaded with no applicable changes")
+		return nil
+	}
+
+	next := *old
+	next.Logging.Level = cfg.Logging.Level
+	next.Server.CORS = cfg.Server.CORS
+	next.Server.RateLimit = cfg.Server.RateLimit
+	next.Processing.Processor.ProcessingDelay = cfg.Processing.Processor.ProcessingDelay
+	next.Processing.Processor.BatchSize = cfg.Processing.Processor.BatchSize
+	next.Processing.Processor.MaxRetries = cfg.Processing.Processor.MaxRetries
+	next.Processing.Output.BatchSize = cfg.Processing.Output.BatchSize
+	next.Processing.Output.FlushTimeout = cfg.Processing.Output.FlushTimeout
+	next.Processing.Output.MaxMessagesPerSecond = cfg.Processing.Output.MaxMessagesPerSecond
+	next.Processing.Filter = cfg.Processing.Filter
+	next.Processing.Validation = cfg.Processing.Validation
+
+	// Load the rules and schemas before recording them, so a failure leaves
+	// the configuration describing what the pipeline runs
+	var errs []error
+	if next.Processing.Filter != old.Processing.Filter && pipeline != nil {
+		if _, err := pipeline.ReloadFilter(processing.DefaultConfig(&next).Filter); err != nil {
+			app.logger.Errorw("Failed to reload filter rules, keeping the previous ones", "error", err)
+			next.Processing.Filter = old.Processing.Filter
+			applied = withoutPrefix(applied, "processing.filter.")
+			errs = append(errs, err)
+		}
+	}
+	if !maps.Equal(next.Processing.Validation.Schemas, old.Processing.Validation.Schemas) && pipeline != nil {
+		if _, err := pipeline.ReloadSchemas(processing.DefaultConfig(&next).Validation); err != nil {
+			app.logger.Errorw("Failed to reload payload schemas, keeping the previous ones", "error", err)
+			next.Processing.Validation = old.Processing.Validation
+			applied = withoutPrefix(applied, "processing.validation.")
+			errs = append(errs, err)
+		}
+	}
+
+	app.mutex.Lock()
+	app.rawconfig = &next
+	app.mutex.Unlock()
+
+	if next.Logging.Level != old.Logging.Level {
+		app.logger.SetLevel(next.Logging.ConvertToLoggerConfig().Level)
+	}
+	if pipeline != nil && slices.ContainsFunc(applied, func(field string) bool { return slices.Contains(runtimeProcessingFields, field) }) {
+		pipeline.ApplyRuntimeConfig(processing.DefaultConfig(&next))
+	}
+	for _, fn := range subscribers {
+		fn(old, &next)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		app.logger.Errorw("Configuration partly reloaded", "applied", applied, "error", err)
+		return fmt.Errorf("configuration partly reloaded: %w", err)
+	}
+	app.logger.Infow("Configuration reloaded", "applied", applied)
+	return nil
+}
+
+// withoutPrefix returns the fields that do not start with prefix
+func withoutPrefix(fields []string, prefix string) []string {
+	var kept []string
+	for _, field := range fields {
+		if !strings.HasPrefix(field, prefix) {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
+
+func isReloadable(field string) bool {
+	for _, prefix := range reloadablePrefixes {
+		if field == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(field, prefix)) {
+			return true
+		}
+	}
+	return false
+}
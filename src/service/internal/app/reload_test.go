@@ -0,0 +1,269 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"sharedgomodule/logging"
+)
+
+func TestReloadAppliesSafeFields(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+
+	var notified *config.RawConfig
+	app.OnConfigChange(func(old, new *config.RawConfig) {
+		if old != cfg {
+			t.Error("subscriber did not receive the previous configuration")
+		}
+		notified = new
+	})
+
+	next := *cfg
+	next.Logging.Level = "debug"
+	next.Server.RateLimit.Burst = cfg.Server.RateLimit.Burst + 5
+	next.Processing.Processor.BatchSize = cfg.Processing.Processor.BatchSize + 1
+	next.Server.Port = cfg.Server.Port + 1
+
+	if err := app.Reload(&next); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	current := app.Config()
+	if notified != current {
+		t.Error("subscriber was not called with the applied configuration")
+	}
+	if current.Logging.Level != "debug" || current.Server.RateLimit.Burst != next.Server.RateLimit.Burst {
+		t.Errorf("reloadable fields were not applied: %+v", current)
+	}
+	if current.Server.Port != cfg.Server.Port {
+		t.Errorf("server.port = %d, want unchanged %d", current.Server.Port, cfg.Server.Port)
+	}
+}
+
+func TestReloadLeavesRestartOnlyProcessorFields(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+	// Recorded for the next pipeline restart, not applied to the running one
+	if _, err := app.UpdateProcessingConfig([]byte(`{"processor":{"concurrency":4,"orderedByKey":true,"batchMode":true,"errorPolicy":"drop"}}`)); err != nil {
+		t.Fatalf("UpdateProcessingConfig() returned error: %v", err)
+	}
+
+	next := *cfg
+	next.Processing.Processor.BatchSize = cfg.Processing.Processor.BatchSize + 1
+	if err := app.Reload(&next); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	stats := app.ProcessingPipeline().GetStats()["processor_stats"].(map[string]interface{})
+	if stats["batch_size"] != next.Processing.Processor.BatchSize {
+		t.Errorf("batch_size = %v, want the reloaded %d", stats["batch_size"], next.Processing.Processor.BatchSize)
+	}
+	if stats["concurrency"] != 1 || stats["ordered_by_key"] != false || stats["batch_mode"] != false || stats["error_policy"] != cfg.Processing.Processor.ErrorPolicy {
+		t.Errorf("Expected the restart-only settings left to the running processor, got %v", stats)
+	}
+}
+
+func TestReloadConcurrentWithProcessingUpdate(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+	const rounds = 50
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= rounds; i++ {
+			next := *cfg
+			next.Server.RateLimit.Burst = cfg.Server.RateLimit.Burst + i
+			if err := app.Reload(&next); err != nil {
+				t.Errorf("Reload() returned error: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= rounds; i++ {
+			if _, err := app.UpdateProcessingConfig([]byte(fmt.Sprintf(`{"output":{"deadLetterTopic":"dlq-%d"}}`, i))); err != nil {
+				t.Errorf("UpdateProcessingConfig() returned error: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	current := app.Config()
+	if current.Server.RateLimit.Burst != cfg.Server.RateLimit.Burst+rounds {
+		t.Errorf("Expected the last reload kept, got burst %d", current.Server.RateLimit.Burst)
+	}
+	if want := fmt.Sprintf("dlq-%d", rounds); current.Processing.Output.DeadLetterTopic != want {
+		t.Errorf("Expected the last processing update kept, got dead-letter topic %q", current.Processing.Output.DeadLetterTopic)
+	}
+}
+
+func TestReloadAppliesFilterRules(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{"uuid":"noise","payload":[{"condition":{"all":[{"identifier":"type","operator":"eq","value":"noise"}]}}]}`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+
+	next := *cfg
+	next.Processing.Filter.RulesFile = rulesFile
+	next.Processing.Filter.Mode = config.FilterModePass
+	if err := app.Reload(&next); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	stats := app.ProcessingPipeline().GetStats()["filter_stats"].(map[string]interface{})
+	if stats["rules_file"] != rulesFile || stats["mode"] != config.FilterModePass || stats["rules"] != 1 {
+		t.Errorf("Expected the reloaded filter in the pipeline stats, got %v", stats)
+	}
+}
+
+func TestReloadKeepsFilterThatFailsToLoad(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+	var notified *config.RawConfig
+	app.OnConfigChange(func(old, new *config.RawConfig) { notified = new })
+
+	next := *cfg
+	next.Logging.Level = "debug"
+	next.Processing.Filter.RulesFile = filepath.Join(t.TempDir(), "missing.json")
+	next.Processing.Filter.Mode = config.FilterModePass
+	if err := app.Reload(&next); err == nil {
+		t.Fatal("Expected Reload() to report the filter rules that failed to load")
+	}
+
+	current := app.Config()
+	if current.Processing.Filter != cfg.Processing.Filter {
+		t.Errorf("Expected the previous filter settings kept, got %+v", current.Processing.Filter)
+	}
+	if current.Logging.Level != "debug" {
+		t.Errorf("Expected the rest of the reload applied, got log level %q", current.Logging.Level)
+	}
+	if notified != current {
+		t.Error("Expected subscribers to be told about the configuration actually applied")
+	}
+	if stats := app.ProcessingPipeline().GetStats()["filter_stats"].(map[string]interface{}); stats["rules_file"] == next.Processing.Filter.RulesFile {
+		t.Errorf("Expected the pipeline to keep the previous filter, got %v", stats)
+	}
+}
+
+func TestReloadAppliesOutputSettings(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+
+	next := *cfg
+	next.Processing.Output.FlushTimeout = 250 * time.Millisecond
+	next.Processing.Output.MaxMessagesPerSecond = 20
+	if err := app.Reload(&next); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	output := app.ProcessingPipeline().GetStats()["output_stats"].(map[string]interface{})
+	if output["flush_timeout"] != "250ms" || output["max_messages_per_second"] != 20.0 {
+		t.Errorf("Expected the output settings applied, got %v and %v", output["flush_timeout"], output["max_messages_per_second"])
+	}
+	if current := app.Config().Processing.Output; current.FlushTimeout != 250*time.Millisecond || current.MaxMessagesPerSecond != 20 {
+		t.Errorf("Expected the configuration to record the output settings, got %+v", current)
+	}
+}
+
+func TestReloadAppliesSchemas(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "orders.json")
+	if err := os.WriteFile(schemaFile, []byte(`{"type":"object","required":["id"]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write schema file: %v", err)
+	}
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+
+	next := *cfg
+	next.Processing.Validation.Schemas = map[string]string{"orders": schemaFile}
+	if err := app.Reload(&next); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+
+	stats := app.ProcessingPipeline().GetStats()["validation_stats"].(map[string]interface{})
+	if schemas, _ := stats["schemas"].(map[string]string); stats["enabled"] != true || schemas["orders"] != schemaFile {
+		t.Errorf("Expected the reloaded schema in the pipeline stats, got %v", stats)
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	cfg := config.LoadConfig()
+	app := NewApplication(cfg, logging.NewTestLogger())
+	called := false
+	app.OnConfigChange(func(old, new *config.RawConfig) { called = true })
+
+	next := *cfg
+	next.Logging.Level = "verbose"
+	err := app.Reload(&next)
+	if err == nil || !strings.Contains(err.Error(), "logging.level") {
+		t.Fatalf("Reload() error = %v, want a logging.level violation", err)
+	}
+	if app.Config() != cfg || called {
+		t.Error("invalid configuration must not be applied")
+	}
+
+	// Settings only the pipeline rejects are refused as by a processing update
+	next = *cfg
+	next.Processing.Processor.BatchSize = 0
+	err = app.Reload(&next)
+	var invalid *InvalidConfigError
+	if !errors.As(err, &invalid) || !strings.Contains(err.Error(), "processor batch size must be positive") {
+		t.Fatalf("Reload() error = %v, want an *InvalidConfigError for the batch size", err)
+	}
+	if app.Config() != cfg || called {
+		t.Error("configuration the pipeline rejects must not be applied")
+	}
+}
+
+func TestIsReloadable(t *testing.T) {
+	var got []string
+	for _, field := range []string{
+		"logging.level",
+		"logging.fileName",
+		"server.cors.allowedOrigins",
+		"server.rateLimit.burst",
+		"server.port",
+		"processing.processor.processingDelay",
+		"processing.output.flushTimeout",
+		"processing.output.deadLetterTopic",
+		"processing.input.pollTimeout",
+		"processing.filter.rulesFile",
+		"processing.validation.schemas",
+	} {
+		if isReloadable(field) {
+			got = append(got, field)
+		}
+	}
+	want := []string{"logging.level", "server.cors.allowedOrigins", "server.rateLimit.burst", "processing.processor.processingDelay", "processing.output.flushTimeout", "processing.filter.rulesFile", "processing.validation.schemas"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reloadable fields = %v, want %v", got, want)
+	}
+}
+
+func TestEffectiveConfigRedactsSecrets(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Server.APIKeys = []string{"secret"}
+	app := NewApplication(cfg, logging.NewTestLogger())
+	app.SetConfigSource(config.Source{Files: []string{"config.yaml"}})
+
+	effective := app.EffectiveConfig()
+	if got := effective.Config["server"].(map[string]interface{})["apiKeys"]; got != config.RedactedValue {
+		t.Errorf("apiKeys = %v, want %q", got, config.RedactedValue)
+	}
+	if !reflect.DeepEqual(effective.Source.Files, []string{"config.yaml"}) {
+		t.Errorf("source files = %v, want [config.yaml]", effective.Source.Files)
+	}
+}
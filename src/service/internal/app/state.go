@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+)
+
+// State is the lifecycle state of the application
+type State int32
+
+// Application states. An application begins in StateStarting, moves to
+// StateReady once Start completes and may move between StateReady and
+// StateDegraded while running. StateStopping is terminal.
+const (
+	StateStarting State = iota
+	StateReady
+	StateDegraded
+	StateStopping
+)
+
+// String returns the lower-case state name
+func (s State) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateReady:
+		return "ready"
+	case StateDegraded:
+		return "degraded"
+	case StateStopping:
+		return "stopping"
+	default:
+		return fmt.Sprintf("state(%d)", int32(s))
+	}
+}
+
+// stateTransitions lists the states reachable from each state
+var stateTransitions = map[State][]State{
+	StateStarting: {StateReady, StateDegraded, StateStopping},
+	StateReady:    {StateDegraded, StateStopping},
+	StateDegraded: {StateReady, StateStopping},
+	StateStopping: {},
+}
+
+// canTransition reports whether the application may move from one state to another
+func canTransition(from, to State) bool {
+	for _, allowed := range stateTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the current lifecycle state
+func (app *Application) State() State {
+	app.stateMutex.Lock()
+	defer app.stateMutex.Unlock()
+	return app.state
+}
+
+// DegradedReason returns why the application is degraded, or an empty
+// string when it is not
+func (app *Application) DegradedReason() string {
+	app.stateMutex.Lock()
+	defer app.stateMutex.Unlock()
+	if app.state != StateDegraded {
+		return ""
+	}
+	return app.stateReason
+}
+
+// MarkReady moves the application to StateReady. Marking an already ready
+// application is a no-op; an application that is stopping cannot become ready.
+func (app *Application) MarkReady() error {
+	return app.transition(StateReady, "")
+}
+
+// SetDegraded moves the application to StateDegraded with the given reason.
+// Calling it while already degraded updates the reason.
+func (app *Application) SetDegraded(reason string) error {
+	return app.transition(StateDegraded, reason)
+}
+
+// IsReady returns true if the application is in StateReady
+func (app *Application) IsReady() bool {
+	return app.State() == StateReady
+}
+
+// pipelineFailed marks the application degraded when a pipeline stage dies,
+// leaving the service running but unable to process messages
+func (app *Application) pipelineFailed(err error) {
+	if stateErr := app.SetDegraded(err.Error()); stateErr != nil {
+		app.logger.Warnw("Pipeline failed while not running", "error", err, "state", app.State().String())
+	}
+}
+
+// transition moves the application to state, logging the change
+func (app *Application) transition(to State, reason string) error {
+	app.stateMutex.Lock()
+	from := app.state
+	if from != to && !canTransition(from, to) {
+		app.stateMutex.Unlock()
+		return fmt.Errorf("invalid state transition from %s to %s", from, to)
+	}
+	app.state = to
+	app.stateReason = reason
+	app.stateMutex.Unlock()
+
+	if from != to {
+		app.logger.Infow("Application state changed", "from", from.String(), "to", to.String(), "reason", reason)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"servicegomodule/internal/config"
+	"sharedgomodule/logging"
+)
+
+func newStateApp(t *testing.T, state State) *Application {
+	t.Helper()
+	app := NewApplication(&config.RawConfig{}, logging.NewTestLogger())
+	app.state = state
+	return app
+}
+
+func TestStateTransitions(t *testing.T) {
+	states := []State{StateStarting, StateReady, StateDegraded, StateStopping}
+	legal := map[[2]State]bool{
+		{StateStarting, StateReady}:    true,
+		{StateStarting, StateDegraded}: true,
+		{StateStarting, StateStopping}: true,
+		{StateReady, StateDegraded}:    true,
+		{StateReady, StateStopping}:    true,
+		{StateDegraded, StateReady}:    true,
+		{StateDegraded, StateStopping}: true,
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			t.Run(from.String()+"->"+to.String(), func(t *testing.T) {
+				app := newStateApp(t, from)
+				err := app.transition(to, "reason")
+
+				// Staying in the same state is always a no-op
+				wantOK := legal[[2]State{from, to}] || from == to
+				if wantOK && err != nil {
+					t.Fatalf("transition returned error: %v", err)
+				}
+				if !wantOK {
+					if err == nil {
+						t.Fatal("expected illegal transition to be rejected")
+					}
+					if app.State() != from {
+						t.Errorf("state after rejected transition = %s, want %s", app.State(), from)
+					}
+					return
+				}
+				if app.State() != to {
+					t.Errorf("state = %s, want %s", app.State(), to)
+				}
+			})
+		}
+	}
+}
+
+func TestSetDegradedReason(t *testing.T) {
+	app := newStateApp(t, StateReady)
+	if reason := app.DegradedReason(); reason != "" {
+		t.Errorf("DegradedReason() while ready = %q, want empty", reason)
+	}
+
+	app.SetDegraded("input stopped")
+	app.SetDegraded("processor stopped")
+	if reason := app.DegradedReason(); reason != "processor stopped" {
+		t.Errorf("DegradedReason() = %q, want %q", reason, "processor stopped")
+	}
+
+	health := app.CheckHealth(context.Background())
+	if err := health[applicationHealthName]; err == nil || err.Error() != "processor stopped" {
+		t.Errorf("CheckHealth()[%q] = %v, want the degraded reason", applicationHealthName, err)
+	}
+
+	if err := app.MarkReady(); err != nil {
+		t.Fatalf("MarkReady() returned error: %v", err)
+	}
+	if reason := app.DegradedReason(); reason != "" {
+		t.Errorf("DegradedReason() after recovery = %q, want empty", reason)
+	}
+	if _, reported := app.CheckHealth(context.Background())[applicationHealthName]; reported {
+		t.Error("CheckHealth() should not report the application once it has recovered")
+	}
+}
+
+func TestPipelineFailureDegradesApplication(t *testing.T) {
+	app := newStateApp(t, StateReady)
+	failure := errors.New("processor stopped after panic: boom")
+	app.pipelineFailed(failure)
+
+	if app.State() != StateDegraded || app.DegradedReason() != failure.Error() {
+		t.Errorf("state = %s (%q), want degraded with the pipeline error", app.State(), app.DegradedReason())
+	}
+
+	// A failure during shutdown must not revive the application
+	stopping := newStateApp(t, StateStopping)
+	stopping.pipelineFailed(failure)
+	if stopping.State() != StateStopping {
+		t.Errorf("state = %s, want %s", stopping.State(), StateStopping)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	if got := State(42).String(); got != "state(42)" {
+		t.Errorf("String() = %q, want %q", got, "state(42)")
+	}
+}
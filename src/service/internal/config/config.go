@@ -2,14 +2,53 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"sharedgomodule/logging"
 	"sharedgomodule/utils"
+)
+
+// Listener types for RawServerConfig.Listen
+const (
+	ListenTCP  = "tcp"
+	ListenUnix = "unix"
+)
+
+// Error policies for RawProcessorConfig.ErrorPolicy
+const (
+	ErrorPolicyDrop       = "drop"       // Log and discard the message
+	ErrorPolicyRetry      = "retry"      // Retry up to maxRetries times, then discard
+	ErrorPolicyDeadLetter = "deadletter" // Publish the original message to deadLetterTopic
+)
+
+// Backpressure policies for RawChannelConfig.BackpressurePolicy
+const (
+	BackpressureBlock      = "block"       // Wait for room in the channel
+	BackpressureDropNewest = "drop_newest" // Discard the message being sent
+	BackpressureDropOldest = "drop_oldest" // Discard the oldest queued message to make room
+)
+
+// Filter modes for RawFilterConfig.Mode
+const (
+	FilterModeDrop = "drop" // Discard messages that match a rule
+	FilterModePass = "pass" // Discard messages that match no rule
+)
+
+// Output formats for RawOutputConfig.Format
+const (
+	OutputFormatRaw          = "raw"           // Publish payloads unchanged
+	OutputFormatJSONEnvelope = "json-envelope" // Wrap payloads in a JSON envelope with their metadata
+)
 
-	"gopkg.in/yaml.v3"
+// Output compressions for RawOutputConfig.Compression
+const (
+	CompressionNone = "none" // Publish payloads uncompressed
+	CompressionGzip = "gzip" // Gzip payloads and mark them with a content-encoding header
 )
 
 // Config holds the application configuration
@@ -21,10 +60,51 @@ type RawConfig struct {
 
 // ServerConfig holds server-related configuration
 type RawServerConfig struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	ReadTimeout  int    `yaml:"readTimeout"`
-	WriteTimeout int    `yaml:"writeTimeout"`
+	Host              string             `yaml:"host"`
+	Port              int                `yaml:"port"`
+	AdminPort         int                `yaml:"adminPort"`  // Separate port for health, config and debug endpoints; 0 serves them on port
+	Listen            string             `yaml:"listen"`     // Listener type: tcp (host:port) or unix (socketPath)
+	SocketPath        string             `yaml:"socketPath"` // Unix socket path when listen is unix
+	SocketMode        string             `yaml:"socketMode"` // Octal permissions for the socket file, e.g. "0660"
+	ReadTimeout       int                `yaml:"readTimeout"`
+	WriteTimeout      int                `yaml:"writeTimeout"`
+	IdleTimeout       int                `yaml:"idleTimeout"`       // Keep-alive idle timeout in seconds; 0 falls back to readTimeout
+	ReadHeaderTimeout int                `yaml:"readHeaderTimeout"` // Seconds allowed to read request headers; 0 falls back to readTimeout
+	MaxHeaderBytes    int                `yaml:"maxHeaderBytes"`    // Request header size cap in bytes; 0 uses the net/http default
+	ShutdownTimeout   int                `yaml:"shutdownTimeout"`   // Seconds to drain requests on shutdown; 0 uses the default
+	BindRetries       int                `yaml:"bindRetries"`       // Extra bind attempts, with backoff, while the port is in use
+	MaxBodyBytes      int64              `yaml:"maxBodyBytes"`      // Request body size cap in bytes
+	EnableDebug       bool               `yaml:"enableDebug"`       // Mount pprof and expvar handlers under /debug/
+	EnableMetrics     bool               `yaml:"enableMetrics"`     // Mount the Prometheus metrics handler at /metrics
+	AccessLog         RawAccessLogConfig `yaml:"accessLog"`
+	CORS              RawCORSConfig      `yaml:"cors"`
+	RateLimit         RawRateLimitConfig `yaml:"rateLimit"`
+	APIKeys           []string           `yaml:"apiKeys" secret:"true"` // Keys accepted on /api/ routes; empty disables authentication
+}
+
+// RateLimitConfig holds per-client rate limiting for /api/ routes.
+// A non-positive requestsPerSecond disables rate limiting.
+type RawRateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"`
+	ByAPIKey          bool    `yaml:"byApiKey"`    // Key buckets by API key instead of client IP when present
+	IdleTimeout       int     `yaml:"idleTimeout"` // Seconds before an idle client bucket is discarded
+}
+
+// CORSConfig holds the cross-origin resource sharing policy.
+// An empty allowedOrigins list disables CORS headers entirely.
+type RawCORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowedMethods   []string `yaml:"allowedMethods"`
+	AllowedHeaders   []string `yaml:"allowedHeaders"`
+	MaxAge           int      `yaml:"maxAge"` // Preflight cache duration in seconds
+	AllowCredentials bool     `yaml:"allowCredentials"`
+}
+
+// AccessLogConfig holds HTTP access logging configuration
+type RawAccessLogConfig struct {
+	Level      string   `yaml:"level"`      // Level for successful requests: debug, info, warn, error
+	QuietPaths []string `yaml:"quietPaths"` // Paths demoted to debug level, e.g. health probes
 }
 
 // LoggingConfig holds logging-related configuration
@@ -33,15 +113,54 @@ type RawLoggingConfig struct {
 	FileName    string `yaml:"fileName"`    // Path to the log file
 	LoggerName  string `yaml:"loggerName"`  // Name identifier for the logger
 	ServiceName string `yaml:"serviceName"` // Service name for structured logging
+
+	Output  string   `yaml:"output"`  // file, stdout or stderr. Empty means file; fileName is ignored for the streams
+	Outputs []string `yaml:"outputs"` // Several outputs written at once, e.g. [file, stdout]; overrides output when set
+	Format  string   `yaml:"format"`  // json, or text (alias console) for human-readable lines on every output. Empty means json
+	Color   bool     `yaml:"color"`   // Colorize text lines, for terminals
+	Strict  bool     `yaml:"strict"`  // Fail at startup when any output cannot be opened instead of skipping it
+
+	Rotate     bool `yaml:"rotate"`     // Rotate fileName under the limits below
+	MaxSize    int  `yaml:"maxSize"`    // Megabytes before the file is rotated. 0 means 100
+	MaxAge     int  `yaml:"maxAge"`     // Days rotated backups are kept. 0 keeps them regardless of age
+	MaxBackups int  `yaml:"maxBackups"` // Rotated backups kept. 0 keeps them all
+	Compress   bool `yaml:"compress"`   // Gzip rotated backups
+
+	Async      bool   `yaml:"async"`      // Write lines from a background goroutine instead of the logging call
+	BufferSize int    `yaml:"bufferSize"` // Lines queued in async mode. 0 means 1024
+	Overflow   string `yaml:"overflow"`   // block or drop when the async buffer is full. Empty means block
+
+	ContextFields []string `yaml:"contextFields"` // Context values attached to lines of loggers bound with WithContext, e.g. [request_id, trace_id]
+	RedactFields  []string `yaml:"redactFields"`  // Field name globs logged as "***" at any depth, e.g. [password, "*_secret"]; case-insensitive
+
+	LevelOverrides map[string]string `yaml:"levelOverrides"` // Levels by component or logger name, e.g. {processing: debug}; the others use level
+
+	IncludeStack bool   `yaml:"includeStack"` // Add a stack trace and the wrapped error chain to error lines of loggers with an attached error
+	StackCapture string `yaml:"stackCapture"` // log or with_error: where the stack is captured. Empty means log
+
+	TimestampFormat string `yaml:"timestampFormat"` // Go time layout, or RFC3339 or RFC3339Nano by name. Empty means RFC3339
+	TimeZone        string `yaml:"timeZone"`        // UTC, Local or an IANA zone name for timestamps. Empty means UTC
+
+	FallbackPath  string        `yaml:"fallbackPath"`  // File written while fileName keeps rejecting writes. Empty means stderr
+	FallbackAfter int           `yaml:"fallbackAfter"` // Consecutive failed writes before fileName is skipped. 0 means 3
+	FallbackRetry time.Duration `yaml:"fallbackRetry"` // How often a skipped fileName is tried again. 0 means 30s
+
+	StrictKeyValues bool `yaml:"strictKeyValues"` // Log an error diagnostic for malformed key/value pairs instead of a logging_error field
 }
 
 // ProcessingConfig holds processing pipeline configuration
 type RawProcessingConfig struct {
-	Input         RawInputConfig     `yaml:"input"`
-	Processor     RawProcessorConfig `yaml:"processor"`
-	Output        RawOutputConfig    `yaml:"output"`
-	Channels      RawChannelConfig   `yaml:"channels"`
-	PloggerConfig RawLoggingConfig   `yaml:"logging"`
+	Input         RawInputConfig      `yaml:"input"`
+	Processor     RawProcessorConfig  `yaml:"processor"`
+	Output        RawOutputConfig     `yaml:"output"`
+	Channels      RawChannelConfig    `yaml:"channels"`
+	Health        RawHealthConfig     `yaml:"health"`
+	Filter        RawFilterConfig     `yaml:"filter"`
+	Validation    RawValidationConfig `yaml:"validation"`
+	PloggerConfig RawLoggingConfig    `yaml:"logging"`
+	StatsInterval time.Duration       `yaml:"statsInterval"` // How often the pipeline logs its counters. 0 disables the stats line
+
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"` // Longest application shutdown waits for the pipeline to stop. 0 means 15s
 }
 
 // InputConfig holds input handler configuration
@@ -49,68 +168,217 @@ type RawInputConfig struct {
 	Topics            []string      `yaml:"topics"`
 	PollTimeout       time.Duration `yaml:"pollTimeout"`
 	ChannelBufferSize int           `yaml:"channelBufferSize"`
+	PollBackoff       time.Duration `yaml:"pollBackoff"`    // Wait after a failed poll, doubled for each consecutive failure. 0 means 100ms
+	PollMaxBackoff    time.Duration `yaml:"pollMaxBackoff"` // Cap on the wait between failed polls. 0 means 30s
+	ReconnectAfter    int           `yaml:"reconnectAfter"` // Consecutive failed polls before the consumer is recreated. 0 means 5
+	MaxReconnects     int           `yaml:"maxReconnects"`  // Reconnects without a successful poll before the pipeline is marked degraded. 0 means 3
 }
 
 // ProcessorConfig holds processor configuration
 type RawProcessorConfig struct {
-	ProcessingDelay time.Duration `yaml:"processingDelay"`
+	ProcessingDelay time.Duration `yaml:"processingDelay"` // Deprecated: sleep applied to each record; use output.maxMessagesPerSecond to limit throughput
 	BatchSize       int           `yaml:"batchSize"`
+	BatchMode       bool          `yaml:"batchMode"`       // Process data messages in batches of up to batchSize
+	BatchLinger     time.Duration `yaml:"batchLinger"`     // Longest a partial batch waits in batch mode. 0 means 100ms
+	Concurrency     int           `yaml:"concurrency"`     // Worker goroutines; above 1 messages may be emitted out of order. 0 means 1
+	OrderedByKey    bool          `yaml:"orderedByKey"`    // Send all messages with the same key to the same worker, keeping their order
+	DedupWindow     time.Duration `yaml:"dedupWindow"`     // Drop messages whose key (or dedupHeader) was seen this recently. 0 disables dedup
+	DedupMaxEntries int           `yaml:"dedupMaxEntries"` // Most keys remembered for dedup; the least recently seen go first. 0 means 10000
+	DedupHeader     string        `yaml:"dedupHeader"`     // Header to deduplicate on instead of the message key
+	ErrorPolicy     string        `yaml:"errorPolicy"`     // What to do with messages that fail processing: drop, retry or deadletter
+	MaxRetries      int           `yaml:"maxRetries"`      // Extra attempts under the retry policy
+	DeadLetterTopic string        `yaml:"deadLetterTopic"` // Topic for failed messages under the deadletter policy
 }
 
 // OutputConfig holds output handler configuration
 type RawOutputConfig struct {
-	OutputTopic       string        `yaml:"outputTopic"`
-	BatchSize         int           `yaml:"batchSize"`
-	FlushTimeout      time.Duration `yaml:"flushTimeout"`
-	ChannelBufferSize int           `yaml:"channelBufferSize"`
+	OutputTopic       string         `yaml:"outputTopic"`
+	BatchSize         int            `yaml:"batchSize"`
+	FlushTimeout      time.Duration  `yaml:"flushTimeout"`
+	ChannelBufferSize int            `yaml:"channelBufferSize"`
+	Retry             RawRetryConfig `yaml:"retry"`
+	DeadLetterTopic   string         `yaml:"deadLetterTopic"` // Topic for messages that fail publishing, and processing under the deadletter policy
+	DropLogLevel      string         `yaml:"dropLogLevel"`    // Level for logging messages dropped without a dead-letter topic: debug, info, warn or error
+
+	MaxMessagesPerSecond float64 `yaml:"maxMessagesPerSecond"` // Cap on the publish rate. 0 means unlimited
+	Format               string  `yaml:"format"`               // Payload encoding: raw or json-envelope
+	Compression          string  `yaml:"compression"`          // Payload compression after encoding: none or gzip
+}
+
+// RawRetryConfig holds the retry policy for failed output publishes
+type RawRetryConfig struct {
+	MaxAttempts    int           `yaml:"maxAttempts"`    // Publish attempts per message, including the first. 0 means 1
+	InitialBackoff time.Duration `yaml:"initialBackoff"` // Wait before the first retry, doubled for each one after
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`     // Cap on the wait between retries. 0 means no cap
+	Jitter         float64       `yaml:"jitter"`         // Randomizes each wait by up to this fraction, 0 to 1
 }
 
 // ChannelConfig holds channel buffer configuration
 type RawChannelConfig struct {
-	InputBufferSize  int `yaml:"inputBufferSize"`
-	OutputBufferSize int `yaml:"outputBufferSize"`
+	InputBufferSize    int           `yaml:"inputBufferSize"`
+	OutputBufferSize   int           `yaml:"outputBufferSize"`
+	BackpressurePolicy string        `yaml:"backpressurePolicy"` // What a full channel does to new messages: block, drop_newest or drop_oldest
+	DrainTimeout       time.Duration `yaml:"drainTimeout"`       // Longest shutdown waits for queued input to be processed. 0 means 5s
+}
+
+// RawHealthConfig holds the thresholds at which /health reports the pipeline unhealthy
+type RawHealthConfig struct {
+	MaxMissedPolls int     `yaml:"maxMissedPolls"` // Poll timeouts the consumer may go without a successful poll. 0 means 5
+	MaxErrorRate   float64 `yaml:"maxErrorRate"`   // Fraction of messages failing over the last minute, 0 to 1. 0 disables the check
+}
+
+// RawFilterConfig holds the rule-based message filter settings
+type RawFilterConfig struct {
+	RulesFile string `yaml:"rulesFile"` // JSON file of rule blocks in the ruleenginelib format; empty disables the filter
+	Mode      string `yaml:"mode"`      // drop discards matching messages, pass keeps only matching ones. Empty means drop
+}
+
+// RawValidationConfig holds the payload schema validation settings
+type RawValidationConfig struct {
+	Schemas map[string]string `yaml:"schemas"` // JSON Schema file for each input topic; messages on other topics are not validated
 }
 
 // LoadConfig loads configuration from environment variables with defaults
 func LoadConfig() *RawConfig {
 	config := &RawConfig{
 		Server: RawServerConfig{
-			Host:         utils.GetEnv("SERVER_HOST", "localhost"),
-			Port:         utils.GetEnvInt("SERVER_PORT", 8080),
-			ReadTimeout:  utils.GetEnvInt("SERVER_READ_TIMEOUT", 10),
-			WriteTimeout: utils.GetEnvInt("SERVER_WRITE_TIMEOUT", 10),
+			Host:              utils.GetEnv("SERVER_HOST", "localhost"),
+			Port:              utils.GetEnvInt("SERVER_PORT", 8080),
+			AdminPort:         utils.GetEnvInt("SERVER_ADMIN_PORT", 0),
+			Listen:            utils.GetEnv("SERVER_LISTEN", ListenTCP),
+			SocketPath:        utils.GetEnv("SERVER_SOCKET_PATH", ""),
+			SocketMode:        utils.GetEnv("SERVER_SOCKET_MODE", "0660"),
+			ReadTimeout:       utils.GetEnvInt("SERVER_READ_TIMEOUT", 10),
+			WriteTimeout:      utils.GetEnvInt("SERVER_WRITE_TIMEOUT", 10),
+			IdleTimeout:       utils.GetEnvInt("SERVER_IDLE_TIMEOUT", 0),
+			ReadHeaderTimeout: utils.GetEnvInt("SERVER_READ_HEADER_TIMEOUT", 0),
+			MaxHeaderBytes:    utils.GetEnvInt("SERVER_MAX_HEADER_BYTES", 0),
+			ShutdownTimeout:   utils.GetEnvInt("SERVER_SHUTDOWN_TIMEOUT", 10),
+			BindRetries:       utils.GetEnvInt("SERVER_BIND_RETRIES", 0),
+			MaxBodyBytes:      int64(utils.GetEnvInt("SERVER_MAX_BODY_BYTES", 1<<20)),
+			EnableDebug:       utils.GetEnvBool("SERVER_ENABLE_DEBUG", false), // pprof/expvar stay unregistered unless enabled
+			EnableMetrics:     utils.GetEnvBool("SERVER_ENABLE_METRICS", false),
+			AccessLog: RawAccessLogConfig{
+				Level:      utils.GetEnv("SERVER_ACCESS_LOG_LEVEL", "info"),
+				QuietPaths: parseTopics(utils.GetEnv("SERVER_ACCESS_LOG_QUIET_PATHS", "/health,/livez,/readyz")),
+			},
+			CORS: RawCORSConfig{
+				AllowedOrigins:   parseTopics(utils.GetEnv("SERVER_CORS_ALLOWED_ORIGINS", "")),
+				AllowedMethods:   parseTopics(utils.GetEnv("SERVER_CORS_ALLOWED_METHODS", "GET, POST, PUT, DELETE, OPTIONS")),
+				AllowedHeaders:   parseTopics(utils.GetEnv("SERVER_CORS_ALLOWED_HEADERS", "Content-Type, Authorization, X-API-Key, X-Request-ID")),
+				MaxAge:           utils.GetEnvInt("SERVER_CORS_MAX_AGE", 600),
+				AllowCredentials: utils.GetEnvBool("SERVER_CORS_ALLOW_CREDENTIALS", false),
+			},
+			APIKeys: parseTopics(utils.GetEnvOrFile("SERVER_API_KEYS", "")),
+			RateLimit: RawRateLimitConfig{
+				RequestsPerSecond: utils.GetEnvFloat("SERVER_RATE_LIMIT_RPS", 0),
+				Burst:             utils.GetEnvInt("SERVER_RATE_LIMIT_BURST", 20),
+				ByAPIKey:          utils.GetEnvBool("SERVER_RATE_LIMIT_BY_API_KEY", false),
+				IdleTimeout:       utils.GetEnvInt("SERVER_RATE_LIMIT_IDLE_TIMEOUT", 300),
+			},
 		},
 		Logging: RawLoggingConfig{
 			Level:       utils.GetEnv("LOG_LEVEL", "info"),
 			FileName:    utils.GetEnv("LOG_FILE_NAME", "main.log"),
 			LoggerName:  utils.GetEnv("LOG_LOGGER_NAME", "main"),
 			ServiceName: utils.GetEnv("LOG_SERVICE_NAME", "cratos"),
+			Output:      utils.GetEnv("LOG_OUTPUT", logging.OutputFile),
+			Outputs:     parseTopics(utils.GetEnv("LOG_OUTPUTS", "")),
+			Format:      utils.GetEnv("LOG_FORMAT", logging.FormatJSON),
+			Color:       utils.GetEnvBool("LOG_COLOR", false),
+			Strict:      utils.GetEnvBool("LOG_STRICT", false),
+			Rotate:      utils.GetEnvBool("LOG_ROTATE", false),
+			MaxSize:     utils.GetEnvInt("LOG_MAX_SIZE_MB", 100),
+			MaxAge:      utils.GetEnvInt("LOG_MAX_AGE_DAYS", 0),
+			MaxBackups:  utils.GetEnvInt("LOG_MAX_BACKUPS", 0),
+			Compress:    utils.GetEnvBool("LOG_COMPRESS", false),
+			Async:       utils.GetEnvBool("LOG_ASYNC", false),
+			BufferSize:  utils.GetEnvInt("LOG_BUFFER_SIZE", 1024),
+			Overflow:    utils.GetEnv("LOG_OVERFLOW", logging.OverflowBlock),
+
+			ContextFields: parseTopics(utils.GetEnv("LOG_CONTEXT_FIELDS", "request_id,trace_id")),
+			RedactFields:  parseTopics(utils.GetEnv("LOG_REDACT_FIELDS", strings.Join(logging.DefaultRedactPatterns(), ","))),
+
+			LevelOverrides: parseSchemas(utils.GetEnv("LOG_LEVEL_OVERRIDES", "")),
+
+			IncludeStack: utils.GetEnvBool("LOG_INCLUDE_STACK", false),
+			StackCapture: utils.GetEnv("LOG_STACK_CAPTURE", logging.StackCaptureLog),
+
+			TimestampFormat: utils.GetEnv("LOG_TIMESTAMP_FORMAT", logging.TimestampRFC3339),
+			TimeZone:        utils.GetEnv("LOG_TIME_ZONE", "UTC"),
+
+			FallbackPath:  utils.GetEnv("LOG_FALLBACK_PATH", ""),
+			FallbackAfter: utils.GetEnvInt("LOG_FALLBACK_AFTER", 3),
+			FallbackRetry: time.Duration(utils.GetEnvInt("LOG_FALLBACK_RETRY_MS", 30000)) * time.Millisecond,
+
+			StrictKeyValues: utils.GetEnvBool("LOG_STRICT_KEY_VALUES", false),
 		},
 		Processing: RawProcessingConfig{
 			Input: RawInputConfig{
 				Topics:            parseTopics(utils.GetEnv("PROCESSING_INPUT_TOPICS", "input-topic")),
 				PollTimeout:       time.Duration(utils.GetEnvInt("PROCESSING_INPUT_POLL_TIMEOUT_MS", 1000)) * time.Millisecond,
 				ChannelBufferSize: utils.GetEnvInt("PROCESSING_INPUT_BUFFER_SIZE", 1000),
+				PollBackoff:       time.Duration(utils.GetEnvInt("PROCESSING_INPUT_POLL_BACKOFF_MS", 100)) * time.Millisecond,
+				PollMaxBackoff:    time.Duration(utils.GetEnvInt("PROCESSING_INPUT_POLL_MAX_BACKOFF_MS", 30000)) * time.Millisecond,
+				ReconnectAfter:    utils.GetEnvInt("PROCESSING_INPUT_RECONNECT_AFTER", 5),
+				MaxReconnects:     utils.GetEnvInt("PROCESSING_INPUT_MAX_RECONNECTS", 3),
 			},
 			Processor: RawProcessorConfig{
-				ProcessingDelay: time.Duration(utils.GetEnvInt("PROCESSING_DELAY_MS", 10)) * time.Millisecond,
+				ProcessingDelay: time.Duration(utils.GetEnvInt("PROCESSING_DELAY_MS", 0)) * time.Millisecond,
 				BatchSize:       utils.GetEnvInt("PROCESSING_BATCH_SIZE", 100),
+				BatchMode:       utils.GetEnvBool("PROCESSING_BATCH_MODE", false),
+				BatchLinger:     time.Duration(utils.GetEnvInt("PROCESSING_BATCH_LINGER_MS", 100)) * time.Millisecond,
+				Concurrency:     utils.GetEnvInt("PROCESSING_CONCURRENCY", 1),
+				OrderedByKey:    utils.GetEnvBool("PROCESSING_ORDERED_BY_KEY", false),
+				DedupWindow:     time.Duration(utils.GetEnvInt("PROCESSING_DEDUP_WINDOW_MS", 0)) * time.Millisecond,
+				DedupMaxEntries: utils.GetEnvInt("PROCESSING_DEDUP_MAX_ENTRIES", 10000),
+				DedupHeader:     utils.GetEnv("PROCESSING_DEDUP_HEADER", ""),
+				ErrorPolicy:     utils.GetEnv("PROCESSING_ERROR_POLICY", ErrorPolicyDrop),
+				MaxRetries:      utils.GetEnvInt("PROCESSING_MAX_RETRIES", 3),
+				DeadLetterTopic: utils.GetEnv("PROCESSING_DEAD_LETTER_TOPIC", ""),
 			},
 			Output: RawOutputConfig{
-				OutputTopic:       utils.GetEnv("PROCESSING_OUTPUT_TOPIC", "output-topic"),
-				BatchSize:         utils.GetEnvInt("PROCESSING_OUTPUT_BATCH_SIZE", 50),
-				FlushTimeout:      time.Duration(utils.GetEnvInt("PROCESSING_OUTPUT_FLUSH_TIMEOUT_MS", 5000)) * time.Millisecond,
-				ChannelBufferSize: utils.GetEnvInt("PROCESSING_OUTPUT_BUFFER_SIZE", 1000),
+				OutputTopic:          utils.GetEnv("PROCESSING_OUTPUT_TOPIC", "output-topic"),
+				BatchSize:            utils.GetEnvInt("PROCESSING_OUTPUT_BATCH_SIZE", 50),
+				FlushTimeout:         time.Duration(utils.GetEnvInt("PROCESSING_OUTPUT_FLUSH_TIMEOUT_MS", 5000)) * time.Millisecond,
+				ChannelBufferSize:    utils.GetEnvInt("PROCESSING_OUTPUT_BUFFER_SIZE", 1000),
+				DeadLetterTopic:      utils.GetEnv("PROCESSING_OUTPUT_DEAD_LETTER_TOPIC", ""),
+				DropLogLevel:         utils.GetEnv("PROCESSING_DROP_LOG_LEVEL", "error"),
+				MaxMessagesPerSecond: utils.GetEnvFloat("PROCESSING_OUTPUT_MAX_MESSAGES_PER_SECOND", 0),
+				Format:               utils.GetEnv("PROCESSING_OUTPUT_FORMAT", OutputFormatRaw),
+				Compression:          utils.GetEnv("PROCESSING_OUTPUT_COMPRESSION", CompressionNone),
+				Retry: RawRetryConfig{
+					MaxAttempts:    utils.GetEnvInt("PROCESSING_OUTPUT_MAX_ATTEMPTS", 3),
+					InitialBackoff: time.Duration(utils.GetEnvInt("PROCESSING_OUTPUT_RETRY_BACKOFF_MS", 100)) * time.Millisecond,
+					MaxBackoff:     time.Duration(utils.GetEnvInt("PROCESSING_OUTPUT_RETRY_MAX_BACKOFF_MS", 5000)) * time.Millisecond,
+					Jitter:         utils.GetEnvFloat("PROCESSING_OUTPUT_RETRY_JITTER", 0.2),
+				},
 			},
 			Channels: RawChannelConfig{
-				InputBufferSize:  utils.GetEnvInt("PROCESSING_CHANNELS_INPUT_BUFFER_SIZE", 1000),
-				OutputBufferSize: utils.GetEnvInt("PROCESSING_CHANNELS_OUTPUT_BUFFER_SIZE", 1000),
+				InputBufferSize:    utils.GetEnvInt("PROCESSING_CHANNELS_INPUT_BUFFER_SIZE", 1000),
+				OutputBufferSize:   utils.GetEnvInt("PROCESSING_CHANNELS_OUTPUT_BUFFER_SIZE", 1000),
+				BackpressurePolicy: utils.GetEnv("PROCESSING_CHANNELS_BACKPRESSURE_POLICY", BackpressureBlock),
+				DrainTimeout:       time.Duration(utils.GetEnvInt("PROCESSING_CHANNELS_DRAIN_TIMEOUT_MS", 5000)) * time.Millisecond,
+			},
+			Health: RawHealthConfig{
+				MaxMissedPolls: utils.GetEnvInt("PROCESSING_HEALTH_MAX_MISSED_POLLS", 5),
+				MaxErrorRate:   utils.GetEnvFloat("PROCESSING_HEALTH_MAX_ERROR_RATE", 0.5),
+			},
+			Filter: RawFilterConfig{
+				RulesFile: utils.GetEnv("PROCESSING_FILTER_RULES_FILE", ""),
+				Mode:      utils.GetEnv("PROCESSING_FILTER_MODE", FilterModeDrop),
 			},
+			Validation: RawValidationConfig{
+				Schemas: parseSchemas(utils.GetEnv("PROCESSING_VALIDATION_SCHEMAS", "")),
+			},
+			StatsInterval:   time.Duration(utils.GetEnvInt("PROCESSING_STATS_INTERVAL_MS", 60000)) * time.Millisecond,
+			ShutdownTimeout: time.Duration(utils.GetEnvInt("PROCESSING_SHUTDOWN_TIMEOUT_MS", 15000)) * time.Millisecond,
 			PloggerConfig: RawLoggingConfig{
 				Level:       utils.GetEnv("PROCESSING_PLOGGER_LEVEL", "info"),
 				FileName:    utils.GetEnv("PROCESSING_PLOGGER_FILE_NAME", "/tmp/cratos-pipeline.log"),
 				LoggerName:  utils.GetEnv("PROCESSING_PLOGGER_LOGGER_NAME", "pipeline"),
 				ServiceName: utils.GetEnv("PROCESSING_PLOGGER_SERVICE_NAME", "cratos"),
+				Output:      utils.GetEnv("PROCESSING_PLOGGER_OUTPUT", logging.OutputFile),
 			},
 		},
 	}
@@ -118,7 +386,7 @@ func LoadConfig() *RawConfig {
 	return config
 }
 
-// parseTopics parses comma-separated topics from a string
+// parseTopics parses comma-separated values (topics, paths) from a string
 func parseTopics(topicsStr string) []string {
 	if topicsStr == "" {
 		return []string{}
@@ -130,24 +398,26 @@ func parseTopics(topicsStr string) []string {
 	return topics
 }
 
-// LoadConfigFromFile loads configuration from a YAML file with optional environment variable overrides
-func LoadConfigFromFile(configPath string) (*RawConfig, error) {
-	// Read the config file
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("error reading config file %s: %w", configPath, err)
+// parseSchemas parses a comma-separated list of name=value pairs, such as
+// topic=path or component=level. A pair without "=" is kept with an empty
+// value so validation reports it.
+func parseSchemas(schemasStr string) map[string]string {
+	if schemasStr == "" {
+		return nil
 	}
-
-	// Parse YAML
-	config := &RawConfig{}
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("error parsing YAML config file %s: %w", configPath, err)
+	schemas := make(map[string]string)
+	for _, pair := range strings.Split(schemasStr, ",") {
+		topic, path, _ := strings.Cut(pair, "=")
+		schemas[strings.TrimSpace(topic)] = strings.TrimSpace(path)
 	}
+	return schemas
+}
 
-	// Override with environment variables if they exist
-	overrideWithEnvVars(config)
-
-	return config, nil
+// LoadConfigFromFile loads configuration from a YAML file, merged with the
+// overlay for the APP_ENV profile if one exists, with optional environment
+// variable overrides
+func LoadConfigFromFile(configPath string) (*RawConfig, error) {
+	return LoadProfileConfigFromFile(configPath, ActiveProfile())
 }
 
 // LoadConfigWithDefaults loads configuration from file if it exists, falling back to environment variables and defaults
@@ -170,12 +440,84 @@ func overrideWithEnvVars(config *RawConfig) {
 	if port := utils.GetEnvInt("SERVER_PORT", -1); port != -1 {
 		config.Server.Port = port
 	}
+	if listen := utils.GetEnv("SERVER_LISTEN", ""); listen != "" {
+		config.Server.Listen = listen
+	}
+	if socketPath := utils.GetEnv("SERVER_SOCKET_PATH", ""); socketPath != "" {
+		config.Server.SocketPath = socketPath
+	}
+	if socketMode := utils.GetEnv("SERVER_SOCKET_MODE", ""); socketMode != "" {
+		config.Server.SocketMode = socketMode
+	}
 	if readTimeout := utils.GetEnvInt("SERVER_READ_TIMEOUT", -1); readTimeout != -1 {
 		config.Server.ReadTimeout = readTimeout
 	}
 	if writeTimeout := utils.GetEnvInt("SERVER_WRITE_TIMEOUT", -1); writeTimeout != -1 {
 		config.Server.WriteTimeout = writeTimeout
 	}
+	if idleTimeout := utils.GetEnvInt("SERVER_IDLE_TIMEOUT", -1); idleTimeout != -1 {
+		config.Server.IdleTimeout = idleTimeout
+	}
+	if readHeaderTimeout := utils.GetEnvInt("SERVER_READ_HEADER_TIMEOUT", -1); readHeaderTimeout != -1 {
+		config.Server.ReadHeaderTimeout = readHeaderTimeout
+	}
+	if maxHeaderBytes := utils.GetEnvInt("SERVER_MAX_HEADER_BYTES", -1); maxHeaderBytes != -1 {
+		config.Server.MaxHeaderBytes = maxHeaderBytes
+	}
+	if shutdownTimeout := utils.GetEnvInt("SERVER_SHUTDOWN_TIMEOUT", -1); shutdownTimeout != -1 {
+		config.Server.ShutdownTimeout = shutdownTimeout
+	}
+	if bindRetries := utils.GetEnvInt("SERVER_BIND_RETRIES", -1); bindRetries != -1 {
+		config.Server.BindRetries = bindRetries
+	}
+	if adminPort := utils.GetEnvInt("SERVER_ADMIN_PORT", -1); adminPort != -1 {
+		config.Server.AdminPort = adminPort
+	}
+	if accessLogLevel := utils.GetEnv("SERVER_ACCESS_LOG_LEVEL", ""); accessLogLevel != "" {
+		config.Server.AccessLog.Level = accessLogLevel
+	}
+	if quietPaths := utils.GetEnv("SERVER_ACCESS_LOG_QUIET_PATHS", ""); quietPaths != "" {
+		config.Server.AccessLog.QuietPaths = parseTopics(quietPaths)
+	}
+	if origins := utils.GetEnv("SERVER_CORS_ALLOWED_ORIGINS", ""); origins != "" {
+		config.Server.CORS.AllowedOrigins = parseTopics(origins)
+	}
+	if methods := utils.GetEnv("SERVER_CORS_ALLOWED_METHODS", ""); methods != "" {
+		config.Server.CORS.AllowedMethods = parseTopics(methods)
+	}
+	if headers := utils.GetEnv("SERVER_CORS_ALLOWED_HEADERS", ""); headers != "" {
+		config.Server.CORS.AllowedHeaders = parseTopics(headers)
+	}
+	if maxAge := utils.GetEnvInt("SERVER_CORS_MAX_AGE", -1); maxAge != -1 {
+		config.Server.CORS.MaxAge = maxAge
+	}
+	if utils.GetEnv("SERVER_CORS_ALLOW_CREDENTIALS", "") != "" {
+		config.Server.CORS.AllowCredentials = utils.GetEnvBool("SERVER_CORS_ALLOW_CREDENTIALS", config.Server.CORS.AllowCredentials)
+	}
+	if maxBodyBytes := utils.GetEnvInt("SERVER_MAX_BODY_BYTES", -1); maxBodyBytes != -1 {
+		config.Server.MaxBodyBytes = int64(maxBodyBytes)
+	}
+	if utils.GetEnv("SERVER_ENABLE_DEBUG", "") != "" {
+		config.Server.EnableDebug = utils.GetEnvBool("SERVER_ENABLE_DEBUG", config.Server.EnableDebug)
+	}
+	if utils.GetEnv("SERVER_ENABLE_METRICS", "") != "" {
+		config.Server.EnableMetrics = utils.GetEnvBool("SERVER_ENABLE_METRICS", config.Server.EnableMetrics)
+	}
+	if keys := utils.GetEnvOrFile("SERVER_API_KEYS", ""); keys != "" {
+		config.Server.APIKeys = parseTopics(keys)
+	}
+	if rps := utils.GetEnvFloat("SERVER_RATE_LIMIT_RPS", -1); rps != -1 {
+		config.Server.RateLimit.RequestsPerSecond = rps
+	}
+	if burst := utils.GetEnvInt("SERVER_RATE_LIMIT_BURST", -1); burst != -1 {
+		config.Server.RateLimit.Burst = burst
+	}
+	if utils.GetEnv("SERVER_RATE_LIMIT_BY_API_KEY", "") != "" {
+		config.Server.RateLimit.ByAPIKey = utils.GetEnvBool("SERVER_RATE_LIMIT_BY_API_KEY", config.Server.RateLimit.ByAPIKey)
+	}
+	if idleTimeout := utils.GetEnvInt("SERVER_RATE_LIMIT_IDLE_TIMEOUT", -1); idleTimeout != -1 {
+		config.Server.RateLimit.IdleTimeout = idleTimeout
+	}
 
 	// Logging configuration overrides
 	if level := utils.GetEnv("LOG_LEVEL", ""); level != "" {
@@ -190,6 +532,78 @@ func overrideWithEnvVars(config *RawConfig) {
 	if serviceName := utils.GetEnv("LOG_SERVICE_NAME", ""); serviceName != "" {
 		config.Logging.ServiceName = serviceName
 	}
+	if output := utils.GetEnv("LOG_OUTPUT", ""); output != "" {
+		config.Logging.Output = output
+	}
+	if outputs := utils.GetEnv("LOG_OUTPUTS", ""); outputs != "" {
+		config.Logging.Outputs = parseTopics(outputs)
+	}
+	if format := utils.GetEnv("LOG_FORMAT", ""); format != "" {
+		config.Logging.Format = format
+	}
+	if utils.GetEnv("LOG_COLOR", "") != "" {
+		config.Logging.Color = utils.GetEnvBool("LOG_COLOR", config.Logging.Color)
+	}
+	if utils.GetEnv("LOG_STRICT", "") != "" {
+		config.Logging.Strict = utils.GetEnvBool("LOG_STRICT", config.Logging.Strict)
+	}
+	if utils.GetEnv("LOG_ROTATE", "") != "" {
+		config.Logging.Rotate = utils.GetEnvBool("LOG_ROTATE", config.Logging.Rotate)
+	}
+	if maxSize := utils.GetEnvInt("LOG_MAX_SIZE_MB", -1); maxSize != -1 {
+		config.Logging.MaxSize = maxSize
+	}
+	if maxAge := utils.GetEnvInt("LOG_MAX_AGE_DAYS", -1); maxAge != -1 {
+		config.Logging.MaxAge = maxAge
+	}
+	if maxBackups := utils.GetEnvInt("LOG_MAX_BACKUPS", -1); maxBackups != -1 {
+		config.Logging.MaxBackups = maxBackups
+	}
+	if utils.GetEnv("LOG_COMPRESS", "") != "" {
+		config.Logging.Compress = utils.GetEnvBool("LOG_COMPRESS", config.Logging.Compress)
+	}
+	if utils.GetEnv("LOG_ASYNC", "") != "" {
+		config.Logging.Async = utils.GetEnvBool("LOG_ASYNC", config.Logging.Async)
+	}
+	if bufferSize := utils.GetEnvInt("LOG_BUFFER_SIZE", -1); bufferSize != -1 {
+		config.Logging.BufferSize = bufferSize
+	}
+	if overflow := utils.GetEnv("LOG_OVERFLOW", ""); overflow != "" {
+		config.Logging.Overflow = overflow
+	}
+	if contextFields := utils.GetEnv("LOG_CONTEXT_FIELDS", ""); contextFields != "" {
+		config.Logging.ContextFields = parseTopics(contextFields)
+	}
+	if redactFields := utils.GetEnv("LOG_REDACT_FIELDS", ""); redactFields != "" {
+		config.Logging.RedactFields = parseTopics(redactFields)
+	}
+	if overrides := utils.GetEnv("LOG_LEVEL_OVERRIDES", ""); overrides != "" {
+		config.Logging.LevelOverrides = parseSchemas(overrides)
+	}
+	if utils.GetEnv("LOG_INCLUDE_STACK", "") != "" {
+		config.Logging.IncludeStack = utils.GetEnvBool("LOG_INCLUDE_STACK", config.Logging.IncludeStack)
+	}
+	if stackCapture := utils.GetEnv("LOG_STACK_CAPTURE", ""); stackCapture != "" {
+		config.Logging.StackCapture = stackCapture
+	}
+	if timestampFormat := utils.GetEnv("LOG_TIMESTAMP_FORMAT", ""); timestampFormat != "" {
+		config.Logging.TimestampFormat = timestampFormat
+	}
+	if timeZone := utils.GetEnv("LOG_TIME_ZONE", ""); timeZone != "" {
+		config.Logging.TimeZone = timeZone
+	}
+	if fallbackPath := utils.GetEnv("LOG_FALLBACK_PATH", ""); fallbackPath != "" {
+		config.Logging.FallbackPath = fallbackPath
+	}
+	if fallbackAfter := utils.GetEnvInt("LOG_FALLBACK_AFTER", -1); fallbackAfter != -1 {
+		config.Logging.FallbackAfter = fallbackAfter
+	}
+	if fallbackRetry := utils.GetEnvInt("LOG_FALLBACK_RETRY_MS", -1); fallbackRetry != -1 {
+		config.Logging.FallbackRetry = time.Duration(fallbackRetry) * time.Millisecond
+	}
+	if utils.GetEnv("LOG_STRICT_KEY_VALUES", "") != "" {
+		config.Logging.StrictKeyValues = utils.GetEnvBool("LOG_STRICT_KEY_VALUES", config.Logging.StrictKeyValues)
+	}
 
 	// Processing configuration overrides
 	if topics := utils.GetEnv("PROCESSING_INPUT_TOPICS", ""); topics != "" {
@@ -201,12 +615,54 @@ func overrideWithEnvVars(config *RawConfig) {
 	if bufferSize := utils.GetEnvInt("PROCESSING_INPUT_BUFFER_SIZE", -1); bufferSize != -1 {
 		config.Processing.Input.ChannelBufferSize = bufferSize
 	}
+	if backoff := utils.GetEnvInt("PROCESSING_INPUT_POLL_BACKOFF_MS", -1); backoff != -1 {
+		config.Processing.Input.PollBackoff = time.Duration(backoff) * time.Millisecond
+	}
+	if maxBackoff := utils.GetEnvInt("PROCESSING_INPUT_POLL_MAX_BACKOFF_MS", -1); maxBackoff != -1 {
+		config.Processing.Input.PollMaxBackoff = time.Duration(maxBackoff) * time.Millisecond
+	}
+	if reconnectAfter := utils.GetEnvInt("PROCESSING_INPUT_RECONNECT_AFTER", -1); reconnectAfter != -1 {
+		config.Processing.Input.ReconnectAfter = reconnectAfter
+	}
+	if maxReconnects := utils.GetEnvInt("PROCESSING_INPUT_MAX_RECONNECTS", -1); maxReconnects != -1 {
+		config.Processing.Input.MaxReconnects = maxReconnects
+	}
 	if delay := utils.GetEnvInt("PROCESSING_DELAY_MS", -1); delay != -1 {
 		config.Processing.Processor.ProcessingDelay = time.Duration(delay) * time.Millisecond
 	}
 	if batchSize := utils.GetEnvInt("PROCESSING_BATCH_SIZE", -1); batchSize != -1 {
 		config.Processing.Processor.BatchSize = batchSize
 	}
+	if utils.GetEnv("PROCESSING_BATCH_MODE", "") != "" {
+		config.Processing.Processor.BatchMode = utils.GetEnvBool("PROCESSING_BATCH_MODE", config.Processing.Processor.BatchMode)
+	}
+	if linger := utils.GetEnvInt("PROCESSING_BATCH_LINGER_MS", -1); linger != -1 {
+		config.Processing.Processor.BatchLinger = time.Duration(linger) * time.Millisecond
+	}
+	if concurrency := utils.GetEnvInt("PROCESSING_CONCURRENCY", -1); concurrency != -1 {
+		config.Processing.Processor.Concurrency = concurrency
+	}
+	if utils.GetEnv("PROCESSING_ORDERED_BY_KEY", "") != "" {
+		config.Processing.Processor.OrderedByKey = utils.GetEnvBool("PROCESSING_ORDERED_BY_KEY", config.Processing.Processor.OrderedByKey)
+	}
+	if window := utils.GetEnvInt("PROCESSING_DEDUP_WINDOW_MS", -1); window != -1 {
+		config.Processing.Processor.DedupWindow = time.Duration(window) * time.Millisecond
+	}
+	if maxEntries := utils.GetEnvInt("PROCESSING_DEDUP_MAX_ENTRIES", -1); maxEntries != -1 {
+		config.Processing.Processor.DedupMaxEntries = maxEntries
+	}
+	if header := utils.GetEnv("PROCESSING_DEDUP_HEADER", ""); header != "" {
+		config.Processing.Processor.DedupHeader = header
+	}
+	if errorPolicy := utils.GetEnv("PROCESSING_ERROR_POLICY", ""); errorPolicy != "" {
+		config.Processing.Processor.ErrorPolicy = errorPolicy
+	}
+	if maxRetries := utils.GetEnvInt("PROCESSING_MAX_RETRIES", -1); maxRetries != -1 {
+		config.Processing.Processor.MaxRetries = maxRetries
+	}
+	if deadLetterTopic := utils.GetEnv("PROCESSING_DEAD_LETTER_TOPIC", ""); deadLetterTopic != "" {
+		config.Processing.Processor.DeadLetterTopic = deadLetterTopic
+	}
 	if outputTopic := utils.GetEnv("PROCESSING_OUTPUT_TOPIC", ""); outputTopic != "" {
 		config.Processing.Output.OutputTopic = outputTopic
 	}
@@ -219,12 +675,66 @@ func overrideWithEnvVars(config *RawConfig) {
 	if outputBufferSize := utils.GetEnvInt("PROCESSING_OUTPUT_BUFFER_SIZE", -1); outputBufferSize != -1 {
 		config.Processing.Output.ChannelBufferSize = outputBufferSize
 	}
+	if deadLetterTopic := utils.GetEnv("PROCESSING_OUTPUT_DEAD_LETTER_TOPIC", ""); deadLetterTopic != "" {
+		config.Processing.Output.DeadLetterTopic = deadLetterTopic
+	}
+	if dropLogLevel := utils.GetEnv("PROCESSING_DROP_LOG_LEVEL", ""); dropLogLevel != "" {
+		config.Processing.Output.DropLogLevel = dropLogLevel
+	}
+	if rate := utils.GetEnvFloat("PROCESSING_OUTPUT_MAX_MESSAGES_PER_SECOND", -1); rate != -1 {
+		config.Processing.Output.MaxMessagesPerSecond = rate
+	}
+	if format := utils.GetEnv("PROCESSING_OUTPUT_FORMAT", ""); format != "" {
+		config.Processing.Output.Format = format
+	}
+	if compression := utils.GetEnv("PROCESSING_OUTPUT_COMPRESSION", ""); compression != "" {
+		config.Processing.Output.Compression = compression
+	}
+	if maxAttempts := utils.GetEnvInt("PROCESSING_OUTPUT_MAX_ATTEMPTS", -1); maxAttempts != -1 {
+		config.Processing.Output.Retry.MaxAttempts = maxAttempts
+	}
+	if backoff := utils.GetEnvInt("PROCESSING_OUTPUT_RETRY_BACKOFF_MS", -1); backoff != -1 {
+		config.Processing.Output.Retry.InitialBackoff = time.Duration(backoff) * time.Millisecond
+	}
+	if maxBackoff := utils.GetEnvInt("PROCESSING_OUTPUT_RETRY_MAX_BACKOFF_MS", -1); maxBackoff != -1 {
+		config.Processing.Output.Retry.MaxBackoff = time.Duration(maxBackoff) * time.Millisecond
+	}
+	if jitter := utils.GetEnvFloat("PROCESSING_OUTPUT_RETRY_JITTER", -1); jitter != -1 {
+		config.Processing.Output.Retry.Jitter = jitter
+	}
 	if inputBufferSize := utils.GetEnvInt("PROCESSING_CHANNELS_INPUT_BUFFER_SIZE", -1); inputBufferSize != -1 {
 		config.Processing.Channels.InputBufferSize = inputBufferSize
 	}
 	if outputChannelBufferSize := utils.GetEnvInt("PROCESSING_CHANNELS_OUTPUT_BUFFER_SIZE", -1); outputChannelBufferSize != -1 {
 		config.Processing.Channels.OutputBufferSize = outputChannelBufferSize
 	}
+	if policy := utils.GetEnv("PROCESSING_CHANNELS_BACKPRESSURE_POLICY", ""); policy != "" {
+		config.Processing.Channels.BackpressurePolicy = policy
+	}
+	if drainTimeout := utils.GetEnvInt("PROCESSING_CHANNELS_DRAIN_TIMEOUT_MS", -1); drainTimeout != -1 {
+		config.Processing.Channels.DrainTimeout = time.Duration(drainTimeout) * time.Millisecond
+	}
+	if statsInterval := utils.GetEnvInt("PROCESSING_STATS_INTERVAL_MS", -1); statsInterval != -1 {
+		config.Processing.StatsInterval = time.Duration(statsInterval) * time.Millisecond
+	}
+	if shutdownTimeout := utils.GetEnvInt("PROCESSING_SHUTDOWN_TIMEOUT_MS", -1); shutdownTimeout != -1 {
+		config.Processing.ShutdownTimeout = time.Duration(shutdownTimeout) * time.Millisecond
+	}
+	if missedPolls := utils.GetEnvInt("PROCESSING_HEALTH_MAX_MISSED_POLLS", -1); missedPolls != -1 {
+		config.Processing.Health.MaxMissedPolls = missedPolls
+	}
+	if errorRate := utils.GetEnvFloat("PROCESSING_HEALTH_MAX_ERROR_RATE", -1); errorRate != -1 {
+		config.Processing.Health.MaxErrorRate = errorRate
+	}
+	if rulesFile := utils.GetEnv("PROCESSING_FILTER_RULES_FILE", ""); rulesFile != "" {
+		config.Processing.Filter.RulesFile = rulesFile
+	}
+	if mode := utils.GetEnv("PROCESSING_FILTER_MODE", ""); mode != "" {
+		config.Processing.Filter.Mode = mode
+	}
+	if schemas := utils.GetEnv("PROCESSING_VALIDATION_SCHEMAS", ""); schemas != "" {
+		config.Processing.Validation.Schemas = parseSchemas(schemas)
+	}
 
 	// Pipeline logger configuration overrides
 	if ploggerLevel := utils.GetEnv("PROCESSING_PLOGGER_LEVEL", ""); ploggerLevel != "" {
@@ -239,26 +749,25 @@ func overrideWithEnvVars(config *RawConfig) {
 	if ploggerServiceName := utils.GetEnv("PROCESSING_PLOGGER_SERVICE_NAME", ""); ploggerServiceName != "" {
 		config.Processing.PloggerConfig.ServiceName = ploggerServiceName
 	}
+	if ploggerOutput := utils.GetEnv("PROCESSING_PLOGGER_OUTPUT", ""); ploggerOutput != "" {
+		config.Processing.PloggerConfig.Output = ploggerOutput
+	}
 }
 
+// warningOutput receives configuration warnings emitted before the logger exists
+var warningOutput io.Writer = os.Stderr
+
 // convertLogLevel converts a string log level to logging.Level
 func convertLogLevel(levelStr string) logging.Level {
-	switch strings.ToLower(levelStr) {
-	case "debug":
-		return logging.DebugLevel
-	case "info":
+	if levelStr == "" {
 		return logging.InfoLevel
-	case "warn":
-		return logging.WarnLevel
-	case "error":
-		return logging.ErrorLevel
-	case "fatal":
-		return logging.FatalLevel
-	case "panic":
-		return logging.PanicLevel
-	default:
-		return logging.InfoLevel // Default to info if invalid level
 	}
+	level, err := logging.ParseLevel(levelStr)
+	if err != nil {
+		fmt.Fprintf(warningOutput, "WARNING: invalid log level %q, falling back to info\n", levelStr)
+		return logging.InfoLevel
+	}
+	return level
 }
 
 // ConvertLoggingConfig converts LoggingConfig to logging.LoggerConfig
@@ -268,5 +777,91 @@ func (cfg RawLoggingConfig) ConvertToLoggerConfig() logging.LoggerConfig {
 		FilePath:    cfg.FileName,
 		LoggerName:  cfg.LoggerName,
 		ServiceName: cfg.ServiceName,
+		Output:      cfg.Output,
+		Outputs:     cfg.Outputs,
+		Format:      cfg.Format,
+		Color:       cfg.Color,
+		Strict:      cfg.Strict,
+
+		IsLogRotatable: cfg.Rotate,
+		MaxSize:        cfg.MaxSize,
+		MaxAge:         cfg.MaxAge,
+		MaxBackups:     cfg.MaxBackups,
+		Compress:       cfg.Compress,
+
+		Async:      cfg.Async,
+		BufferSize: cfg.BufferSize,
+		Overflow:   cfg.Overflow,
+
+		ContextKeys: cfg.ContextFields,
+		Redact:      cfg.RedactFields,
+
+		LevelOverrides: convertLevelOverrides(cfg.LevelOverrides),
+
+		IncludeStack: cfg.IncludeStack,
+		StackCapture: cfg.StackCapture,
+
+		TimestampFormat: cfg.TimestampFormat,
+		TimeZone:        cfg.TimeZone,
+
+		FallbackPath:  cfg.FallbackPath,
+		FallbackAfter: cfg.FallbackAfter,
+		FallbackRetry: cfg.FallbackRetry,
+
+		StrictKeyValues: cfg.StrictKeyValues,
+	}
+}
+
+// convertLevelOverrides converts the level names of overrides, falling back
+// to info for invalid ones like convertLogLevel
+func convertLevelOverrides(overrides map[string]string) map[string]logging.Level {
+	if len(overrides) == 0 {
+		return nil
+	}
+	levels := make(map[string]logging.Level, len(overrides))
+	for name, level := range overrides {
+		levels[name] = convertLogLevel(level)
 	}
+	return levels
+}
+
+// WritesFile reports whether the logger writes to FileName, alone or next
+// to a standard stream
+func (cfg RawLoggingConfig) WritesFile() bool {
+	if len(cfg.Outputs) > 0 {
+		return slices.Contains(cfg.Outputs, logging.OutputFile)
+	}
+	return cfg.Output == "" || cfg.Output == logging.OutputFile
+}
+
+// IsUnix reports whether the server listens on a Unix domain socket. An
+// unset listener type means tcp.
+func (cfg RawServerConfig) IsUnix() bool {
+	return cfg.Listen == ListenUnix
+}
+
+// SocketFileMode parses SocketMode as octal permissions, defaulting to 0660
+// when unset
+func (cfg RawServerConfig) SocketFileMode() (os.FileMode, error) {
+	if cfg.SocketMode == "" {
+		return 0660, nil
+	}
+	mode, err := strconv.ParseUint(cfg.SocketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q, want octal permissions such as 0660", cfg.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// DroppedLogLevel returns the level for logging dropped messages, error when unset
+func (cfg RawOutputConfig) DroppedLogLevel() logging.Level {
+	if cfg.DropLogLevel == "" {
+		return logging.ErrorLevel
+	}
+	return convertLogLevel(cfg.DropLogLevel)
+}
+
+// LogLevel returns the access log level for successful requests as a logging.Level
+func (cfg RawAccessLogConfig) LogLevel() logging.Level {
+	return convertLogLevel(cfg.Level)
 }
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"sharedgomodule/logging"
 )
 
 func TestLoadConfigDefaults(t *testing.T) {
@@ -287,3 +292,242 @@ func TestOverrideWithEnvVarsAllFields(t *testing.T) {
 		}
 	}
 }
+
+func TestAccessLogConfig(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.AccessLog.Level != "info" {
+		t.Errorf("Expected access log level 'info', got %s", config.Server.AccessLog.Level)
+	}
+	if !reflect.DeepEqual(config.Server.AccessLog.QuietPaths, []string{"/health", "/livez", "/readyz"}) {
+		t.Errorf("Expected quiet paths [/health /livez /readyz], got %v", config.Server.AccessLog.QuietPaths)
+	}
+
+	t.Setenv("SERVER_ACCESS_LOG_LEVEL", "warn")
+	t.Setenv("SERVER_ACCESS_LOG_QUIET_PATHS", "/health, /metrics")
+	overrideWithEnvVars(config)
+
+	if config.Server.AccessLog.LogLevel() != logging.WarnLevel {
+		t.Errorf("Expected access log level warn, got %v", config.Server.AccessLog.LogLevel())
+	}
+	if len(config.Server.AccessLog.QuietPaths) != 2 || config.Server.AccessLog.QuietPaths[1] != "/metrics" {
+		t.Errorf("Expected quiet paths [/health /metrics], got %v", config.Server.AccessLog.QuietPaths)
+	}
+}
+
+func TestCORSConfig(t *testing.T) {
+	config := LoadConfig()
+	if len(config.Server.CORS.AllowedOrigins) != 0 {
+		t.Errorf("Expected CORS to be disabled by default, got origins %v", config.Server.CORS.AllowedOrigins)
+	}
+
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "cors.yaml")
+	configContent := `
+server:
+  cors:
+    allowedOrigins: ["https://a.example.com", "https://b.example.com"]
+    maxAge: 120
+    allowCredentials: true
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	t.Setenv("SERVER_CORS_MAX_AGE", "60")
+	fileConfig, err := LoadConfigFromFile(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigFromFile() error = %v", err)
+	}
+
+	cors := fileConfig.Server.CORS
+	if len(cors.AllowedOrigins) != 2 || cors.AllowedOrigins[1] != "https://b.example.com" {
+		t.Errorf("Expected two allowed origins, got %v", cors.AllowedOrigins)
+	}
+	if !cors.AllowCredentials {
+		t.Error("Expected allowCredentials to be true")
+	}
+	if cors.MaxAge != 60 {
+		t.Errorf("Expected env override max age 60, got %d", cors.MaxAge)
+	}
+}
+
+func TestRateLimitConfigEnvOverride(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.RateLimit.RequestsPerSecond != 0 {
+		t.Errorf("Expected rate limiting disabled by default, got %v rps", config.Server.RateLimit.RequestsPerSecond)
+	}
+
+	t.Setenv("SERVER_RATE_LIMIT_RPS", "12.5")
+	t.Setenv("SERVER_RATE_LIMIT_BURST", "30")
+	t.Setenv("SERVER_RATE_LIMIT_BY_API_KEY", "true")
+	overrideWithEnvVars(config)
+
+	if config.Server.RateLimit.RequestsPerSecond != 12.5 {
+		t.Errorf("Expected 12.5 rps, got %v", config.Server.RateLimit.RequestsPerSecond)
+	}
+	if config.Server.RateLimit.Burst != 30 {
+		t.Errorf("Expected burst 30, got %d", config.Server.RateLimit.Burst)
+	}
+	if !config.Server.RateLimit.ByAPIKey {
+		t.Error("Expected byApiKey to be true")
+	}
+}
+
+func TestAPIKeysEnvOverride(t *testing.T) {
+	config := LoadConfig()
+	if len(config.Server.APIKeys) != 0 {
+		t.Errorf("Expected no API keys by default, got %v", config.Server.APIKeys)
+	}
+
+	t.Setenv("SERVER_API_KEYS", "key-one, key-two")
+	overrideWithEnvVars(config)
+
+	if len(config.Server.APIKeys) != 2 || config.Server.APIKeys[0] != "key-one" || config.Server.APIKeys[1] != "key-two" {
+		t.Errorf("Expected [key-one key-two], got %v", config.Server.APIKeys)
+	}
+}
+
+func TestAPIKeysFromFile(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "api-keys")
+	if err := os.WriteFile(keysFile, []byte("file-key-one,file-key-two\n"), 0600); err != nil {
+		t.Fatalf("Failed to write keys file: %v", err)
+	}
+	t.Setenv("SERVER_API_KEYS", "env-key")
+	t.Setenv("SERVER_API_KEYS_FILE", keysFile)
+
+	config := LoadConfig()
+	if want := []string{"file-key-one", "file-key-two"}; !reflect.DeepEqual(config.Server.APIKeys, want) {
+		t.Errorf("Expected API keys %v from SERVER_API_KEYS_FILE, got %v", want, config.Server.APIKeys)
+	}
+
+	config.Server.APIKeys = nil
+	overrideWithEnvVars(config)
+	if len(config.Server.APIKeys) != 2 {
+		t.Errorf("Expected overrideWithEnvVars to read SERVER_API_KEYS_FILE, got %v", config.Server.APIKeys)
+	}
+}
+
+func TestMaxBodyBytesConfig(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.MaxBodyBytes != 1<<20 {
+		t.Errorf("Expected default max body bytes 1 MiB, got %d", config.Server.MaxBodyBytes)
+	}
+
+	t.Setenv("SERVER_MAX_BODY_BYTES", "2048")
+	overrideWithEnvVars(config)
+
+	if config.Server.MaxBodyBytes != 2048 {
+		t.Errorf("Expected max body bytes 2048, got %d", config.Server.MaxBodyBytes)
+	}
+}
+
+func TestEnableDebugConfig(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.EnableDebug {
+		t.Error("Expected debug endpoints to be disabled by default")
+	}
+
+	t.Setenv("SERVER_ENABLE_DEBUG", "true")
+	overrideWithEnvVars(config)
+
+	if !config.Server.EnableDebug {
+		t.Error("Expected SERVER_ENABLE_DEBUG=true to enable debug endpoints")
+	}
+}
+
+func TestEnableMetricsConfig(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.EnableMetrics {
+		t.Error("Expected the metrics endpoint to be disabled by default")
+	}
+
+	t.Setenv("SERVER_ENABLE_METRICS", "true")
+	overrideWithEnvVars(config)
+
+	if !config.Server.EnableMetrics {
+		t.Error("Expected SERVER_ENABLE_METRICS=true to enable the metrics endpoint")
+	}
+}
+
+func TestServerTuningConfig(t *testing.T) {
+	config := LoadConfig()
+	if config.Server.IdleTimeout != 0 || config.Server.ReadHeaderTimeout != 0 || config.Server.MaxHeaderBytes != 0 {
+		t.Errorf("Expected net/http defaults for server tuning, got %+v", config.Server)
+	}
+	if config.Server.ShutdownTimeout != 10 {
+		t.Errorf("Expected default shutdown timeout of 10, got %d", config.Server.ShutdownTimeout)
+	}
+
+	t.Setenv("SERVER_IDLE_TIMEOUT", "90")
+	t.Setenv("SERVER_READ_HEADER_TIMEOUT", "3")
+	t.Setenv("SERVER_MAX_HEADER_BYTES", "8192")
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "45")
+	overrideWithEnvVars(config)
+
+	if config.Server.IdleTimeout != 90 {
+		t.Errorf("Expected idle timeout 90, got %d", config.Server.IdleTimeout)
+	}
+	if config.Server.ReadHeaderTimeout != 3 {
+		t.Errorf("Expected read header timeout 3, got %d", config.Server.ReadHeaderTimeout)
+	}
+	if config.Server.MaxHeaderBytes != 8192 {
+		t.Errorf("Expected max header bytes 8192, got %d", config.Server.MaxHeaderBytes)
+	}
+	if config.Server.ShutdownTimeout != 45 {
+		t.Errorf("Expected shutdown timeout 45, got %d", config.Server.ShutdownTimeout)
+	}
+}
+
+func TestConvertLogLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    logging.Level
+		warning bool
+	}{
+		{"debug", logging.DebugLevel, false},
+		{"info", logging.InfoLevel, false},
+		{"WARN", logging.WarnLevel, false},
+		{"warning", logging.WarnLevel, false},
+		{"error", logging.ErrorLevel, false},
+		{"fatal", logging.FatalLevel, false},
+		{"panic", logging.PanicLevel, false},
+		{"", logging.InfoLevel, false},
+		{"verbose", logging.InfoLevel, true},
+		{"!!", logging.InfoLevel, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var buf bytes.Buffer
+			warningOutput = &buf
+			defer func() { warningOutput = os.Stderr }()
+
+			if got := convertLogLevel(tt.input); got != tt.want {
+				t.Errorf("convertLogLevel(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if gotWarning := strings.Contains(buf.String(), "invalid log level"); gotWarning != tt.warning {
+				t.Errorf("convertLogLevel(%q) warning = %q, want warning %v", tt.input, buf.String(), tt.warning)
+			}
+		})
+	}
+}
+
+func TestSocketFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{"", 0660, false},
+		{"0600", 0600, false},
+		{"755", 0755, false},
+		{"0999", 0, true},
+		{"01777", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := RawServerConfig{SocketMode: tt.mode}.SocketFileMode()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("SocketFileMode(%q) = %o, %v; want %o, error %v", tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Diff returns the dotted yaml paths of every field that differs between
+// old and new, e.g. "server.rateLimit.burst". Slices are compared as a whole.
+func Diff(old, new *RawConfig) []string {
+	var changed []string
+	diffValues(reflect.ValueOf(*old), reflect.ValueOf(*new), "", &changed)
+	return changed
+}
+
+func diffValues(old, new reflect.Value, path string, changed *[]string) {
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*changed = append(*changed, path)
+		}
+		return
+	}
+
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		diffValues(old.Field(i), new.Field(i), name, changed)
+	}
+}
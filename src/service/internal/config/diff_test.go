@@ -0,0 +1,24 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := LoadConfig()
+	if changed := Diff(old, old); len(changed) != 0 {
+		t.Errorf("Diff() of identical configs = %v, want none", changed)
+	}
+
+	new := *old
+	new.Server.Port = old.Server.Port + 1
+	new.Server.CORS.AllowedOrigins = []string{"https://example.com"}
+	new.Processing.Processor.ProcessingDelay = old.Processing.Processor.ProcessingDelay + time.Second
+
+	want := []string{"server.port", "server.cors.allowedOrigins", "processing.processor.processingDelay"}
+	if got := Diff(old, &new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
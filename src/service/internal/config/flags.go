@@ -0,0 +1,71 @@
+package config
+
+import (
+	"flag"
+	"io"
+)
+
+// Overrides holds configuration values supplied on the command line.
+// Empty strings and a zero port mean the flag was not given.
+//
+// Precedence, highest first: flags, environment variables, config file,
+// built-in defaults.
+type Overrides struct {
+	ConfigPath string // Config file to load instead of the default location
+	Env        string // Environment profile; overrides APP_ENV
+	Host       string
+	Port       int
+	LogLevel   string
+	LogFile    string
+}
+
+// ParseFlags parses the service command-line flags from args, which should
+// not include the program name. Usage and parse errors are written to output.
+func ParseFlags(args []string, output io.Writer) (Overrides, error) {
+	var o Overrides
+	fs := flag.NewFlagSet("service", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&o.ConfigPath, "config", "", "path to the config file (default $SERVICE_HOME/conf/config.yaml)")
+	fs.StringVar(&o.Env, "env", "", "environment profile selecting the config.<env>.yaml overlay, overrides APP_ENV")
+	fs.StringVar(&o.Host, "host", "", "HTTP server host, overrides SERVER_HOST and server.host")
+	fs.IntVar(&o.Port, "port", 0, "HTTP server port, overrides SERVER_PORT and server.port")
+	fs.StringVar(&o.LogLevel, "log-level", "", "log level, overrides LOG_LEVEL and logging.level")
+	fs.StringVar(&o.LogFile, "log-file", "", "log file path, overrides LOG_FILE_NAME and logging.fileName")
+	if err := fs.Parse(args); err != nil {
+		return Overrides{}, err
+	}
+	return o, nil
+}
+
+// Profile returns the environment profile selected by --env or APP_ENV
+func (o Overrides) Profile() string {
+	if o.Env != "" {
+		return o.Env
+	}
+	return ActiveProfile()
+}
+
+// Apply overwrites the fields of cfg that were set on the command line
+func (o Overrides) Apply(cfg *RawConfig) {
+	if o.Host != "" {
+		cfg.Server.Host = o.Host
+	}
+	if o.Port != 0 {
+		cfg.Server.Port = o.Port
+	}
+	if o.LogLevel != "" {
+		cfg.Logging.Level = o.LogLevel
+	}
+	if o.LogFile != "" {
+		cfg.Logging.FileName = o.LogFile
+	}
+}
+
+// LoadConfigWithOverrides loads configuration from configPath and the
+// selected profile overlay, falling back to environment variables and
+// defaults like LoadConfigWithDefaults, and then applies the command-line
+// overrides on top
+func LoadConfigWithOverrides(configPath string, o Overrides) *RawConfig {
+	config, _ := LoadConfigWithSource(configPath, o)
+	return config
+}
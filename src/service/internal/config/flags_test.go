@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	o, err := ParseFlags([]string{"--config", "/etc/service.yaml", "--host", "0.0.0.0", "--port", "9000", "--log-level", "debug", "--log-file", "/tmp/svc.log"}, io.Discard)
+	if err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+	want := Overrides{ConfigPath: "/etc/service.yaml", Host: "0.0.0.0", Port: 9000, LogLevel: "debug", LogFile: "/tmp/svc.log"}
+	if o != want {
+		t.Errorf("ParseFlags() = %+v, want %+v", o, want)
+	}
+
+	if o, err := ParseFlags(nil, io.Discard); err != nil || o != (Overrides{}) {
+		t.Errorf("ParseFlags(nil) = %+v, %v, want zero value", o, err)
+	}
+	if _, err := ParseFlags([]string{"--port", "abc"}, io.Discard); err == nil {
+		t.Error("Expected an error for a non-numeric --port")
+	}
+	if _, err := ParseFlags([]string{"--help"}, io.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("ParseFlags(--help) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestLoadConfigWithOverridesPrecedence(t *testing.T) {
+	configContent := `
+server:
+  host: "file.example.com"
+  port: 7777
+  readTimeout: 11
+logging:
+  level: "warn"
+  fileName: "file.log"
+`
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// Env beats the file for host and log level; flags beat both for port
+	// and log level. Fields set only in the file keep their file value.
+	t.Setenv("SERVER_HOST", "env.example.com")
+	t.Setenv("SERVER_PORT", "8888")
+	t.Setenv("LOG_LEVEL", "error")
+
+	config := LoadConfigWithOverrides(configFile, Overrides{Port: 9999, LogLevel: "debug"})
+
+	if config.Server.Host != "env.example.com" {
+		t.Errorf("host = %q, want env value %q", config.Server.Host, "env.example.com")
+	}
+	if config.Server.Port != 9999 {
+		t.Errorf("port = %d, want flag value 9999", config.Server.Port)
+	}
+	if config.Logging.Level != "debug" {
+		t.Errorf("log level = %q, want flag value %q", config.Logging.Level, "debug")
+	}
+	if config.Logging.FileName != "file.log" {
+		t.Errorf("log file = %q, want file value %q", config.Logging.FileName, "file.log")
+	}
+	if config.Server.ReadTimeout != 11 {
+		t.Errorf("read timeout = %d, want file value 11", config.Server.ReadTimeout)
+	}
+}
+
+func TestLoadConfigWithOverridesMissingFile(t *testing.T) {
+	t.Setenv("SERVER_PORT", "8888")
+
+	config := LoadConfigWithOverrides(filepath.Join(t.TempDir(), "missing.yaml"), Overrides{Host: "flag.example.com"})
+
+	if config.Server.Host != "flag.example.com" {
+		t.Errorf("host = %q, want flag value %q", config.Server.Host, "flag.example.com")
+	}
+	if config.Server.Port != 8888 {
+		t.Errorf("port = %d, want env value 8888", config.Server.Port)
+	}
+	if config.Logging.Level != "info" {
+		t.Errorf("log level = %q, want default %q", config.Logging.Level, "info")
+	}
+}
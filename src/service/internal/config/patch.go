@@ -0,0 +1,35 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatchProcessing returns base with the settings in patch applied. patch is a
+// JSON object shaped like the processing section of the configuration file,
+// with the same field names and durations written as strings such as "250ms".
+// Settings it leaves out keep their values in base, and schemas it names are
+// added to the base ones. Unknown fields and mistyped values are errors.
+func PatchProcessing(base RawProcessingConfig, patch []byte) (RawProcessingConfig, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &object); err != nil || object == nil {
+		return base, errors.New("processing settings must be a JSON object")
+	}
+
+	// Decoding fills base in place, so it must not share the schema map
+	base.Validation.Schemas = maps.Clone(base.Validation.Schemas)
+
+	// JSON is YAML, and decoding it as YAML reads durations the way the
+	// configuration file does
+	decoder := yaml.NewDecoder(bytes.NewReader(patch))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&base); err != nil {
+		return base, fmt.Errorf("invalid processing settings: %w", err)
+	}
+	return base, nil
+}
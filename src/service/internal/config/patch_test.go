@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPatchProcessing(t *testing.T) {
+	base := LoadConfig().Processing
+	base.Validation.Schemas = map[string]string{"orders": "/schemas/orders.json"}
+
+	patched, err := PatchProcessing(base, []byte(`{
+		"output": {"batchSize": 10, "flushTimeout": "250ms"},
+		"validation": {"schemas": {"payments": "/schemas/payments.json"}}
+	}`))
+	if err != nil {
+		t.Fatalf("PatchProcessing() returned error: %v", err)
+	}
+	if patched.Output.BatchSize != 10 || patched.Output.FlushTimeout != 250*time.Millisecond {
+		t.Errorf("Expected the output settings patched, got %+v", patched.Output)
+	}
+	if patched.Output.OutputTopic != base.Output.OutputTopic || patched.Processor != base.Processor {
+		t.Error("Expected settings left out of the patch to keep their values")
+	}
+	if len(patched.Validation.Schemas) != 2 || len(base.Validation.Schemas) != 1 {
+		t.Errorf("Expected the schemas added without changing the base, got %v and %v", patched.Validation.Schemas, base.Validation.Schemas)
+	}
+}
+
+func TestPatchProcessingErrors(t *testing.T) {
+	base := LoadConfig().Processing
+	for patch, want := range map[string]string{
+		`{"output": {"topic": "x"}}`:        "field topic not found",
+		`{"output": {"batchSize": "many"}}`: "cannot unmarshal",
+		`"output"`:                          "must be a JSON object",
+		`null`:                              "must be a JSON object",
+	} {
+		if _, err := PatchProcessing(base, []byte(patch)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("PatchProcessing(%s) error = %v, want one containing %q", patch, err, want)
+		}
+	}
+}
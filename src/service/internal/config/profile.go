@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sharedgomodule/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActiveProfile returns the environment profile selected by APP_ENV, or an
+// empty string when no profile is selected
+func ActiveProfile() string {
+	return strings.TrimSpace(utils.GetEnv("APP_ENV", ""))
+}
+
+// ProfilePath returns the overlay file for profile next to configPath, e.g.
+// conf/config.yaml with profile "prod" gives conf/config.prod.yaml
+func ProfilePath(configPath, profile string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + profile + ext
+}
+
+// ResolveProfile returns the config files that are loaded for profile, in
+// merge order: the base file followed by the profile overlay if it exists.
+// Files that do not exist are left out.
+func ResolveProfile(configPath, profile string) []string {
+	var files []string
+	if fileExists(configPath) {
+		files = append(files, configPath)
+	}
+	if profile != "" {
+		if overlay := ProfilePath(configPath, profile); fileExists(overlay) {
+			files = append(files, overlay)
+		}
+	}
+	return files
+}
+
+// LoadProfileConfigFromFile loads the base config file, merges the overlay
+// for profile on top of it and then applies environment variable overrides.
+//
+// Merging is done field by field on the YAML documents: mappings are merged
+// recursively, while scalars and lists in the overlay replace the base value
+// entirely. A missing overlay is not an error; a missing base file is.
+func LoadProfileConfigFromFile(configPath, profile string) (*RawConfig, error) {
+	config, err := loadProfileFiles(configPath, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Override with environment variables if they exist
+	overrideWithEnvVars(config)
+
+	return config, nil
+}
+
+// loadProfileFiles loads and merges the base and profile config files
+// without applying environment variable overrides
+func loadProfileFiles(configPath, profile string) (*RawConfig, error) {
+	merged, err := readYAMLMap(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		overlayPath := ProfilePath(configPath, profile)
+		if fileExists(overlayPath) {
+			overlay, err := readYAMLMap(overlayPath)
+			if err != nil {
+				return nil, err
+			}
+			merged = mergeYAMLMaps(merged, overlay)
+		}
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("error merging config files for profile %q: %w", profile, err)
+	}
+	config := &RawConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing YAML config file %s: %w", configPath, err)
+	}
+	return config, nil
+}
+
+// readYAMLMap reads a YAML file as a generic mapping; an empty file yields an
+// empty mapping
+func readYAMLMap(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("error parsing YAML config file %s: %w", path, err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// mergeYAMLMaps merges overlay into base. Nested mappings merge key by key;
+// any other overlay value, including lists, replaces the base value.
+func mergeYAMLMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	for key, value := range overlay {
+		if overlayMap, ok := value.(map[string]interface{}); ok {
+			if baseMap, ok := base[key].(map[string]interface{}); ok {
+				base[key] = mergeYAMLMaps(baseMap, overlayMap)
+				continue
+			}
+		}
+		base[key] = value
+	}
+	return base
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const profileBaseConfig = `
+server:
+  host: "base.example.com"
+  port: 8080
+  readTimeout: 10
+  cors:
+    allowedOrigins: ["https://a.example.com", "https://b.example.com"]
+    maxAge: 600
+logging:
+  level: "info"
+  fileName: "main.log"
+processing:
+  processor:
+    processingDelay: 10ms
+    batchSize: 100
+`
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestProfilePath(t *testing.T) {
+	if got := ProfilePath("/conf/config.yaml", "prod"); got != "/conf/config.prod.yaml" {
+		t.Errorf("ProfilePath() = %q, want %q", got, "/conf/config.prod.yaml")
+	}
+}
+
+func TestLoadProfileConfigMergeRules(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+	writeConfigFile(t, filepath.Join(dir, "config.prod.yaml"), `
+server:
+  port: 9090
+  cors:
+    allowedOrigins: ["https://prod.example.com"]
+logging:
+  level: "warn"
+processing:
+  processor:
+    processingDelay: 50ms
+`)
+
+	config, err := LoadProfileConfigFromFile(base, "prod")
+	if err != nil {
+		t.Fatalf("LoadProfileConfigFromFile() returned error: %v", err)
+	}
+
+	// Scalars replace
+	if config.Server.Port != 9090 || config.Logging.Level != "warn" {
+		t.Errorf("Expected overlay scalars, got port %d and level %q", config.Server.Port, config.Logging.Level)
+	}
+	if config.Processing.Processor.ProcessingDelay != 50*time.Millisecond {
+		t.Errorf("Expected overlay processing delay 50ms, got %v", config.Processing.Processor.ProcessingDelay)
+	}
+	// Lists replace rather than append
+	if want := []string{"https://prod.example.com"}; !reflect.DeepEqual(config.Server.CORS.AllowedOrigins, want) {
+		t.Errorf("Expected allowed origins %v, got %v", want, config.Server.CORS.AllowedOrigins)
+	}
+	// Maps merge, so fields absent from the overlay keep their base value
+	if config.Server.Host != "base.example.com" || config.Server.ReadTimeout != 10 || config.Server.CORS.MaxAge != 600 {
+		t.Errorf("Expected base values for fields missing from the overlay, got %+v", config.Server)
+	}
+	if config.Logging.FileName != "main.log" || config.Processing.Processor.BatchSize != 100 {
+		t.Errorf("Expected base logging file and batch size, got %q and %d", config.Logging.FileName, config.Processing.Processor.BatchSize)
+	}
+}
+
+func TestLoadProfileConfigEnvOverridesOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+	writeConfigFile(t, filepath.Join(dir, "config.staging.yaml"), "server:\n  port: 9090\n")
+	t.Setenv("SERVER_PORT", "7070")
+
+	config, err := LoadProfileConfigFromFile(base, "staging")
+	if err != nil {
+		t.Fatalf("LoadProfileConfigFromFile() returned error: %v", err)
+	}
+	if config.Server.Port != 7070 {
+		t.Errorf("Expected env var to win over the overlay, got port %d", config.Server.Port)
+	}
+}
+
+func TestLoadProfileConfigMissingOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+
+	config, err := LoadProfileConfigFromFile(base, "dev")
+	if err != nil {
+		t.Fatalf("LoadProfileConfigFromFile() returned error: %v", err)
+	}
+	if config.Server.Port != 8080 {
+		t.Errorf("Expected base port 8080, got %d", config.Server.Port)
+	}
+
+	if _, err := LoadProfileConfigFromFile(filepath.Join(dir, "missing.yaml"), "dev"); err == nil {
+		t.Error("Expected an error for a missing base file")
+	}
+}
+
+func TestLoadConfigFromFileUsesAppEnv(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+	writeConfigFile(t, filepath.Join(dir, "config.prod.yaml"), "logging:\n  level: \"error\"\n")
+	t.Setenv("APP_ENV", "prod")
+
+	config, err := LoadConfigFromFile(base)
+	if err != nil {
+		t.Fatalf("LoadConfigFromFile() returned error: %v", err)
+	}
+	if config.Logging.Level != "error" {
+		t.Errorf("Expected the prod overlay to be applied, got level %q", config.Logging.Level)
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	overlay := filepath.Join(dir, "config.prod.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+	writeConfigFile(t, overlay, "server:\n  port: 9090\n")
+
+	tests := []struct {
+		profile string
+		want    []string
+	}{
+		{"", []string{base}},
+		{"prod", []string{base, overlay}},
+		{"dev", []string{base}},
+	}
+	for _, tt := range tests {
+		if got := ResolveProfile(base, tt.profile); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ResolveProfile(%q) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+
+	if got := ResolveProfile(filepath.Join(dir, "missing.yaml"), "prod"); len(got) != 0 {
+		t.Errorf("ResolveProfile() for a missing base = %v, want none", got)
+	}
+}
+
+func TestOverridesProfile(t *testing.T) {
+	t.Setenv("APP_ENV", "staging")
+	if got := (Overrides{}).Profile(); got != "staging" {
+		t.Errorf("Profile() = %q, want APP_ENV value %q", got, "staging")
+	}
+	if got := (Overrides{Env: "prod"}).Profile(); got != "prod" {
+		t.Errorf("Profile() = %q, want --env value %q", got, "prod")
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces the value of every non-empty field tagged
+// `secret:"true"` in Redact output
+const RedactedValue = "***"
+
+// Redact returns cfg as a tree of maps keyed by yaml field name, suitable for
+// JSON encoding, with secret fields replaced by RedactedValue. Durations are
+// rendered in their string form, e.g. "10ms".
+func Redact(cfg *RawConfig) map[string]interface{} {
+	return redactStruct(reflect.ValueOf(*cfg))
+}
+
+func redactStruct(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true":
+			if value.IsZero() || (value.Kind() == reflect.Slice && value.Len() == 0) {
+				out[name] = value.Interface()
+			} else {
+				out[name] = RedactedValue
+			}
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			out[name] = value.Interface().(time.Duration).String()
+		case value.Kind() == reflect.Struct:
+			out[name] = redactStruct(value)
+		default:
+			out[name] = value.Interface()
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// sensitiveFieldName matches field names that look like they hold secrets
+var sensitiveFieldName = regexp.MustCompile(`(?i)key|password|secret|token|credential`)
+
+// TestSecretFieldsAreTagged fails when a string field whose name suggests a
+// secret is added without the secret tag, so it cannot leak through Redact
+func TestSecretFieldsAreTagged(t *testing.T) {
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			fieldPath := path + "." + field.Name
+			if field.Type.Kind() == reflect.Struct {
+				walk(field.Type, fieldPath)
+				continue
+			}
+			holdsText := field.Type.Kind() == reflect.String ||
+				(field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String)
+			if holdsText && sensitiveFieldName.MatchString(field.Name) && field.Tag.Get("secret") != "true" {
+				t.Errorf("%s looks sensitive but is not tagged secret:\"true\"", fieldPath)
+			}
+		}
+	}
+	walk(reflect.TypeOf(RawConfig{}), "RawConfig")
+}
+
+func TestRedact(t *testing.T) {
+	config := LoadConfig()
+	config.Server.APIKeys = []string{"super-secret-key"}
+
+	redacted := Redact(config)
+	server := redacted["server"].(map[string]interface{})
+	if server["apiKeys"] != RedactedValue {
+		t.Errorf("apiKeys = %v, want %q", server["apiKeys"], RedactedValue)
+	}
+	if server["port"] != config.Server.Port {
+		t.Errorf("port = %v, want %d", server["port"], config.Server.Port)
+	}
+	processor := redacted["processing"].(map[string]interface{})["processor"].(map[string]interface{})
+	if processor["processingDelay"] != config.Processing.Processor.ProcessingDelay.String() {
+		t.Errorf("processingDelay = %v, want %q", processor["processingDelay"], config.Processing.Processor.ProcessingDelay.String())
+	}
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("Failed to marshal redacted config: %v", err)
+	}
+	if strings.Contains(string(data), "super-secret-key") {
+		t.Errorf("redacted config leaks the API key: %s", data)
+	}
+	if config.Server.APIKeys[0] != "super-secret-key" {
+		t.Error("Redact must not modify the config")
+	}
+}
+
+func TestRedactKeepsEmptySecrets(t *testing.T) {
+	config := LoadConfig()
+	config.Server.APIKeys = nil
+
+	server := Redact(config)["server"].(map[string]interface{})
+	if keys, ok := server["apiKeys"].([]string); !ok || len(keys) != 0 {
+		t.Errorf("apiKeys = %#v, want an empty list so disabled auth stays visible", server["apiKeys"])
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+// Source records where the effective configuration came from
+type Source struct {
+	Files         []string `json:"files"`               // Config files merged, in order; empty when defaults were used
+	Profile       string   `json:"profile,omitempty"`   // Environment profile from APP_ENV or --env
+	LoadError     string   `json:"loadError,omitempty"` // Why the config files could not be used, if they could not
+	EnvOverrides  []string `json:"envOverrides"`        // Fields whose file value was replaced by an environment variable
+	FlagOverrides []string `json:"flagOverrides"`       // Fields set by command-line flags
+}
+
+// LoadConfigWithSource behaves like LoadConfigWithOverrides and also reports
+// which files were loaded and which fields environment variables and flags
+// overrode. When the config files cannot be loaded the defaults are used and
+// LoadError says why; environment overrides are then not tracked separately.
+func LoadConfigWithSource(configPath string, o Overrides) (*RawConfig, Source) {
+	source := Source{Profile: o.Profile()}
+
+	config, err := loadProfileFiles(configPath, source.Profile)
+	if err != nil {
+		source.LoadError = err.Error()
+		config = LoadConfig()
+	} else {
+		source.Files = ResolveProfile(configPath, source.Profile)
+		// overrideWithEnvVars replaces values rather than mutating them, so
+		// a shallow copy is enough to diff against
+		fileConfig := *config
+		overrideWithEnvVars(config)
+		source.EnvOverrides = Diff(&fileConfig, config)
+	}
+
+	beforeFlags := *config
+	o.Apply(config)
+	source.FlagOverrides = Diff(&beforeFlags, config)
+
+	return config, source
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigWithSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	overlay := filepath.Join(dir, "config.prod.yaml")
+	writeConfigFile(t, base, profileBaseConfig)
+	writeConfigFile(t, overlay, "server:\n  readTimeout: 20\n")
+	t.Setenv("SERVER_HOST", "env.example.com")
+
+	config, source := LoadConfigWithSource(base, Overrides{Env: "prod", Port: 9999})
+
+	if config.Server.Host != "env.example.com" || config.Server.Port != 9999 || config.Server.ReadTimeout != 20 {
+		t.Errorf("unexpected effective server config: %+v", config.Server)
+	}
+	want := Source{
+		Files:         []string{base, overlay},
+		Profile:       "prod",
+		EnvOverrides:  []string{"server.host"},
+		FlagOverrides: []string{"server.port"},
+	}
+	if !reflect.DeepEqual(source, want) {
+		t.Errorf("source = %+v, want %+v", source, want)
+	}
+}
+
+func TestLoadConfigWithSourceMissingFile(t *testing.T) {
+	config, source := LoadConfigWithSource(filepath.Join(t.TempDir(), "missing.yaml"), Overrides{})
+
+	if config.Server.Port != 8080 {
+		t.Errorf("Expected default port 8080, got %d", config.Server.Port)
+	}
+	if source.LoadError == "" || len(source.Files) != 0 {
+		t.Errorf("Expected a load error and no files, got %+v", source)
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"sharedgomodule/logging"
+)
+
+// validLogLevels lists the level names reported in validation errors;
+// logging.ParseLevel also accepts aliases such as warning
+var validLogLevels = []string{"debug", "info", "warn", "error", "fatal", "panic"}
+
+// Validate checks the configuration for values that would otherwise fail at
+// runtime in confusing ways. Every violation is reported, joined into a
+// single error with one line per problem.
+func (c *RawConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	server := c.Server
+	check(server.Listen == "" || server.Listen == ListenTCP || server.Listen == ListenUnix, "server.listen %q must be %s or %s", server.Listen, ListenTCP, ListenUnix)
+	if server.IsUnix() {
+		check(strings.TrimSpace(server.SocketPath) != "", "server.socketPath must be set when server.listen is %s", ListenUnix)
+	}
+	if _, err := server.SocketFileMode(); err != nil {
+		errs = append(errs, fmt.Errorf("server.socketMode: %w", err))
+	}
+	check(strings.TrimSpace(server.Host) != "", "server.host must not be empty")
+	check(server.Port >= 1 && server.Port <= 65535, "server.port must be between 1 and 65535, got %d", server.Port)
+	check(server.AdminPort >= 0 && server.AdminPort <= 65535, "server.adminPort must be between 0 and 65535, got %d", server.AdminPort)
+	if server.AdminPort != 0 && !server.IsUnix() {
+		check(server.AdminPort != server.Port, "server.adminPort must differ from server.port %d", server.Port)
+	}
+	check(server.ReadTimeout > 0, "server.readTimeout must be positive, got %d", server.ReadTimeout)
+	check(server.WriteTimeout > 0, "server.writeTimeout must be positive, got %d", server.WriteTimeout)
+	check(server.IdleTimeout >= 0, "server.idleTimeout must not be negative, got %d", server.IdleTimeout)
+	check(server.ReadHeaderTimeout >= 0, "server.readHeaderTimeout must not be negative, got %d", server.ReadHeaderTimeout)
+	check(server.MaxHeaderBytes >= 0, "server.maxHeaderBytes must not be negative, got %d", server.MaxHeaderBytes)
+	check(server.ShutdownTimeout >= 0, "server.shutdownTimeout must not be negative, got %d", server.ShutdownTimeout)
+	check(server.BindRetries >= 0, "server.bindRetries must not be negative, got %d", server.BindRetries)
+	check(server.MaxBodyBytes >= 0, "server.maxBodyBytes must not be negative, got %d", server.MaxBodyBytes)
+	check(server.CORS.MaxAge >= 0, "server.cors.maxAge must not be negative, got %d", server.CORS.MaxAge)
+	check(server.RateLimit.RequestsPerSecond >= 0, "server.rateLimit.requestsPerSecond must not be negative, got %v", server.RateLimit.RequestsPerSecond)
+	check(server.RateLimit.Burst >= 0, "server.rateLimit.burst must not be negative, got %d", server.RateLimit.Burst)
+	check(server.RateLimit.IdleTimeout >= 0, "server.rateLimit.idleTimeout must not be negative, got %d", server.RateLimit.IdleTimeout)
+	check(isValidLogLevel(server.AccessLog.Level), "server.accessLog.level %q is not one of %s", server.AccessLog.Level, strings.Join(validLogLevels, ", "))
+
+	check(isValidLogLevel(c.Logging.Level), "logging.level %q is not one of %s", c.Logging.Level, strings.Join(validLogLevels, ", "))
+	checkLogOutputs(check, "logging", c.Logging)
+	check(!c.Logging.WritesFile() || strings.TrimSpace(c.Logging.FileName) != "", "logging.fileName must not be empty")
+
+	// An unset processing logger level falls back to info
+	if level := c.Processing.PloggerConfig.Level; level != "" {
+		check(isValidLogLevel(level), "processing.logging.level %q is not one of %s", level, strings.Join(validLogLevels, ", "))
+	}
+	checkLogOutputs(check, "processing.logging", c.Processing.PloggerConfig)
+	check(c.Processing.Input.PollTimeout >= 0, "processing.input.pollTimeout must not be negative, got %v", c.Processing.Input.PollTimeout)
+	input := c.Processing.Input
+	check(input.PollBackoff >= 0, "processing.input.pollBackoff must not be negative, got %v", input.PollBackoff)
+	check(input.PollMaxBackoff >= 0, "processing.input.pollMaxBackoff must not be negative, got %v", input.PollMaxBackoff)
+	if input.PollMaxBackoff > 0 {
+		check(input.PollMaxBackoff >= input.PollBackoff, "processing.input.pollMaxBackoff %v must not be less than pollBackoff %v", input.PollMaxBackoff, input.PollBackoff)
+	}
+	check(input.ReconnectAfter >= 0, "processing.input.reconnectAfter must not be negative, got %d", input.ReconnectAfter)
+	check(input.MaxReconnects >= 0, "processing.input.maxReconnects must not be negative, got %d", input.MaxReconnects)
+	check(c.Processing.Processor.ProcessingDelay >= 0, "processing.processor.processingDelay must not be negative, got %v", c.Processing.Processor.ProcessingDelay)
+	processor := c.Processing.Processor
+	check(processor.ErrorPolicy == "" || processor.ErrorPolicy == ErrorPolicyDrop || processor.ErrorPolicy == ErrorPolicyRetry || processor.ErrorPolicy == ErrorPolicyDeadLetter,
+		"processing.processor.errorPolicy %q must be %s, %s or %s", processor.ErrorPolicy, ErrorPolicyDrop, ErrorPolicyRetry, ErrorPolicyDeadLetter)
+	check(processor.BatchLinger >= 0, "processing.processor.batchLinger must not be negative, got %v", processor.BatchLinger)
+	check(processor.Concurrency >= 0, "processing.processor.concurrency must not be negative, got %d", processor.Concurrency)
+	check(processor.MaxRetries >= 0, "processing.processor.maxRetries must not be negative, got %d", processor.MaxRetries)
+	check(processor.DedupWindow >= 0, "processing.processor.dedupWindow must not be negative, got %v", processor.DedupWindow)
+	check(processor.DedupMaxEntries >= 0, "processing.processor.dedupMaxEntries must not be negative, got %d", processor.DedupMaxEntries)
+	if processor.ErrorPolicy == ErrorPolicyDeadLetter {
+		check(strings.TrimSpace(processor.DeadLetterTopic) != "" || strings.TrimSpace(c.Processing.Output.DeadLetterTopic) != "",
+			"processing.processor.deadLetterTopic must be set when errorPolicy is %s, unless processing.output.deadLetterTopic is", ErrorPolicyDeadLetter)
+	}
+	check(c.Processing.Output.FlushTimeout >= 0, "processing.output.flushTimeout must not be negative, got %v", c.Processing.Output.FlushTimeout)
+	if level := strings.ToLower(c.Processing.Output.DropLogLevel); level != "" {
+		check(level == "debug" || level == "info" || level == "warn" || level == "error", "processing.output.dropLogLevel %q must be debug, info, warn or error", c.Processing.Output.DropLogLevel)
+	}
+	policy := c.Processing.Channels.BackpressurePolicy
+	check(policy == "" || policy == BackpressureBlock || policy == BackpressureDropNewest || policy == BackpressureDropOldest,
+		"processing.channels.backpressurePolicy %q must be %s, %s or %s", policy, BackpressureBlock, BackpressureDropNewest, BackpressureDropOldest)
+	check(c.Processing.Channels.DrainTimeout >= 0, "processing.channels.drainTimeout must not be negative, got %v", c.Processing.Channels.DrainTimeout)
+	check(c.Processing.StatsInterval >= 0, "processing.statsInterval must not be negative, got %v", c.Processing.StatsInterval)
+	check(c.Processing.ShutdownTimeout >= 0, "processing.shutdownTimeout must not be negative, got %v", c.Processing.ShutdownTimeout)
+	retry := c.Processing.Output.Retry
+	check(retry.MaxAttempts >= 0, "processing.output.retry.maxAttempts must not be negative, got %d", retry.MaxAttempts)
+	check(retry.InitialBackoff >= 0, "processing.output.retry.initialBackoff must not be negative, got %v", retry.InitialBackoff)
+	check(retry.MaxBackoff >= 0, "processing.output.retry.maxBackoff must not be negative, got %v", retry.MaxBackoff)
+	if retry.MaxBackoff > 0 {
+		check(retry.MaxBackoff >= retry.InitialBackoff, "processing.output.retry.maxBackoff %v must not be less than initialBackoff %v", retry.MaxBackoff, retry.InitialBackoff)
+	}
+	check(retry.Jitter >= 0 && retry.Jitter <= 1, "processing.output.retry.jitter must be between 0 and 1, got %v", retry.Jitter)
+	for _, topic := range sortedKeys(c.Processing.Validation.Schemas) {
+		check(topic != "" && strings.TrimSpace(c.Processing.Validation.Schemas[topic]) != "", "processing.validation.schemas entry %q must map a topic to a schema file", topic)
+	}
+	if format := c.Processing.Output.Format; format != "" {
+		check(format == OutputFormatRaw || format == OutputFormatJSONEnvelope, "processing.output.format %q must be %s or %s", format, OutputFormatRaw, OutputFormatJSONEnvelope)
+	}
+	if compression := c.Processing.Output.Compression; compression != "" {
+		check(compression == CompressionNone || compression == CompressionGzip, "processing.output.compression %q must be %s or %s", compression, CompressionNone, CompressionGzip)
+	}
+	check(c.Processing.Output.MaxMessagesPerSecond >= 0, "processing.output.maxMessagesPerSecond must not be negative, got %v", c.Processing.Output.MaxMessagesPerSecond)
+	health := c.Processing.Health
+	check(health.MaxMissedPolls >= 0, "processing.health.maxMissedPolls must not be negative, got %d", health.MaxMissedPolls)
+	check(health.MaxErrorRate >= 0 && health.MaxErrorRate <= 1, "processing.health.maxErrorRate must be between 0 and 1, got %v", health.MaxErrorRate)
+	mode := c.Processing.Filter.Mode
+	check(mode == "" || mode == FilterModeDrop || mode == FilterModePass,
+		"processing.filter.mode %q must be %s or %s", mode, FilterModeDrop, FilterModePass)
+
+	return errors.Join(errs...)
+}
+
+// isValidLogLevel reports whether level names a recognized log level
+func isValidLogLevel(level string) bool {
+	_, err := logging.ParseLevel(level)
+	return err == nil
+}
+
+// checkLogOutputs checks the outputs, format, rotation limits, async buffer,
+// redaction patterns, level overrides, stack capture, timestamps and file
+// fallback of the logger configured under prefix
+func checkLogOutputs(check func(bool, string, ...interface{}), prefix string, cfg RawLoggingConfig) {
+	check(isValidLogOutput(cfg.Output), "%s.output %q must be %s, %s or %s", prefix, cfg.Output, logging.OutputFile, logging.OutputStdout, logging.OutputStderr)
+	for _, output := range cfg.Outputs {
+		check(output != "" && isValidLogOutput(output), "%s.outputs %q must be %s, %s or %s", prefix, output, logging.OutputFile, logging.OutputStdout, logging.OutputStderr)
+	}
+	check(cfg.Format == "" || cfg.Format == logging.FormatJSON || cfg.Format == logging.FormatText || cfg.Format == logging.FormatConsole,
+		"%s.format %q must be %s, %s or %s", prefix, cfg.Format, logging.FormatJSON, logging.FormatText, logging.FormatConsole)
+	check(cfg.MaxSize >= 0, "%s.maxSize must not be negative, got %d", prefix, cfg.MaxSize)
+	check(cfg.MaxAge >= 0, "%s.maxAge must not be negative, got %d", prefix, cfg.MaxAge)
+	check(cfg.MaxBackups >= 0, "%s.maxBackups must not be negative, got %d", prefix, cfg.MaxBackups)
+	for _, pattern := range cfg.RedactFields {
+		_, err := path.Match(pattern, "")
+		check(err == nil, "%s.redactFields %q is not a valid pattern", prefix, pattern)
+	}
+	for _, name := range sortedKeys(cfg.LevelOverrides) {
+		check(name != "" && isValidLogLevel(cfg.LevelOverrides[name]), "%s.levelOverrides entry %q must map a component to a valid log level, got %q", prefix, name, cfg.LevelOverrides[name])
+	}
+	check(cfg.StackCapture == "" || cfg.StackCapture == logging.StackCaptureLog || cfg.StackCapture == logging.StackCaptureWithError,
+		"%s.stackCapture %q must be %s or %s", prefix, cfg.StackCapture, logging.StackCaptureLog, logging.StackCaptureWithError)
+	if err := logging.ValidateTimestamp(cfg.TimestampFormat, cfg.TimeZone); err != nil {
+		check(false, "%s: %v", prefix, err)
+	}
+	check(cfg.FallbackAfter >= 0, "%s.fallbackAfter must not be negative, got %d", prefix, cfg.FallbackAfter)
+	check(cfg.FallbackRetry >= 0, "%s.fallbackRetry must not be negative, got %v", prefix, cfg.FallbackRetry)
+	check(cfg.BufferSize >= 0, "%s.bufferSize must not be negative, got %d", prefix, cfg.BufferSize)
+	check(cfg.Overflow == "" || cfg.Overflow == logging.OverflowBlock || cfg.Overflow == logging.OverflowDrop,
+		"%s.overflow %q must be %s or %s", prefix, cfg.Overflow, logging.OverflowBlock, logging.OverflowDrop)
+}
+
+// isValidLogOutput reports whether output selects a known log output. Empty
+// means a file.
+func isValidLogOutput(output string) bool {
+	switch output {
+	case "", logging.OutputFile, logging.OutputStdout, logging.OutputStderr:
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order, so violations are reported in a
+// stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
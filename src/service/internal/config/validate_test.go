@@ -0,0 +1,220 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	if err := LoadConfig().Validate(); err != nil {
+		t.Errorf("Expected default configuration to be valid, got:\n%v", err)
+	}
+}
+
+func TestValidateRepositoryConfig(t *testing.T) {
+	config, err := LoadConfigFromFile("../../../../conf/config.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load repository config: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected conf/config.yaml to be valid, got:\n%v", err)
+	}
+}
+
+func TestValidateSingleViolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*RawConfig)
+		message string
+	}{
+		{"port too large", func(c *RawConfig) { c.Server.Port = 99999 }, "server.port must be between 1 and 65535, got 99999"},
+		{"port zero", func(c *RawConfig) { c.Server.Port = 0 }, "server.port must be between 1 and 65535, got 0"},
+		{"admin port too large", func(c *RawConfig) { c.Server.AdminPort = 70000 }, "server.adminPort must be between 0 and 65535, got 70000"},
+		{"admin port same as port", func(c *RawConfig) { c.Server.AdminPort = c.Server.Port }, "server.adminPort must differ from server.port 8080"},
+		{"empty host", func(c *RawConfig) { c.Server.Host = " " }, "server.host must not be empty"},
+		{"zero read timeout", func(c *RawConfig) { c.Server.ReadTimeout = 0 }, "server.readTimeout must be positive, got 0"},
+		{"negative write timeout", func(c *RawConfig) { c.Server.WriteTimeout = -1 }, "server.writeTimeout must be positive, got -1"},
+		{"unknown log level", func(c *RawConfig) { c.Logging.Level = "verbose" }, `logging.level "verbose" is not one of`},
+		{"negative log backups", func(c *RawConfig) { c.Logging.MaxBackups = -1 }, "logging.maxBackups must not be negative, got -1"},
+		{"negative log buffer", func(c *RawConfig) { c.Logging.BufferSize = -1 }, "logging.bufferSize must not be negative, got -1"},
+		{"bad redact pattern", func(c *RawConfig) { c.Logging.RedactFields = []string{"[unclosed"} }, `logging.redactFields "[unclosed" is not a valid pattern`},
+		{"unknown override level", func(c *RawConfig) { c.Logging.LevelOverrides = map[string]string{"processing": "loud"} }, `logging.levelOverrides entry "processing" must map a component to a valid log level, got "loud"`},
+		{"unknown stack capture", func(c *RawConfig) { c.Logging.StackCapture = "panic" }, `logging.stackCapture "panic" must be log or with_error`},
+		{"unknown time zone", func(c *RawConfig) { c.Logging.TimeZone = "Mars/Olympus" }, `logging: time zone "Mars/Olympus" must be UTC, Local or an IANA zone name`},
+		{"negative fallback threshold", func(c *RawConfig) { c.Logging.FallbackAfter = -2 }, "logging.fallbackAfter must not be negative, got -2"},
+		{"unknown log overflow", func(c *RawConfig) { c.Logging.Overflow = "spill" }, `logging.overflow "spill" must be block or drop`},
+		{"negative burst", func(c *RawConfig) { c.Server.RateLimit.Burst = -5 }, "server.rateLimit.burst must not be negative, got -5"},
+		{"unknown error policy", func(c *RawConfig) { c.Processing.Processor.ErrorPolicy = "ignore" }, `processing.processor.errorPolicy "ignore" must be drop, retry or deadletter`},
+		{"negative batch linger", func(c *RawConfig) { c.Processing.Processor.BatchLinger = -time.Second }, "processing.processor.batchLinger must not be negative, got -1s"},
+		{"output retry jitter too large", func(c *RawConfig) { c.Processing.Output.Retry.Jitter = 1.5 }, "processing.output.retry.jitter must be between 0 and 1, got 1.5"},
+		{"negative drain timeout", func(c *RawConfig) { c.Processing.Channels.DrainTimeout = -time.Second }, "processing.channels.drainTimeout must not be negative, got -1s"},
+		{"poll max backoff below backoff", func(c *RawConfig) { c.Processing.Input.PollMaxBackoff = time.Millisecond }, "processing.input.pollMaxBackoff 1ms must not be less than pollBackoff 100ms"},
+		{"negative reconnect threshold", func(c *RawConfig) { c.Processing.Input.ReconnectAfter = -1 }, "processing.input.reconnectAfter must not be negative, got -1"},
+		{"negative stats interval", func(c *RawConfig) { c.Processing.StatsInterval = -time.Second }, "processing.statsInterval must not be negative, got -1s"},
+		{"negative pipeline shutdown timeout", func(c *RawConfig) { c.Processing.ShutdownTimeout = -time.Second }, "processing.shutdownTimeout must not be negative, got -1s"},
+		{"negative health missed polls", func(c *RawConfig) { c.Processing.Health.MaxMissedPolls = -1 }, "processing.health.maxMissedPolls must not be negative, got -1"},
+		{"health error rate too large", func(c *RawConfig) { c.Processing.Health.MaxErrorRate = 2 }, "processing.health.maxErrorRate must be between 0 and 1, got 2"},
+		{"unknown filter mode", func(c *RawConfig) { c.Processing.Filter.Mode = "keep" }, `processing.filter.mode "keep" must be drop or pass`},
+		{"schema without file", func(c *RawConfig) { c.Processing.Validation.Schemas = map[string]string{"orders": ""} }, `processing.validation.schemas entry "orders" must map a topic to a schema file`},
+		{"unknown output format", func(c *RawConfig) { c.Processing.Output.Format = "avro" }, `processing.output.format "avro" must be raw or json-envelope`},
+		{"unknown output compression", func(c *RawConfig) { c.Processing.Output.Compression = "zstd" }, `processing.output.compression "zstd" must be none or gzip`},
+		{"negative output rate", func(c *RawConfig) { c.Processing.Output.MaxMessagesPerSecond = -1 }, "processing.output.maxMessagesPerSecond must not be negative, got -1"},
+		{"negative dedup window", func(c *RawConfig) { c.Processing.Processor.DedupWindow = -time.Second }, "processing.processor.dedupWindow must not be negative, got -1s"},
+		{"output max backoff below initial", func(c *RawConfig) { c.Processing.Output.Retry.MaxBackoff = time.Millisecond },
+			"processing.output.retry.maxBackoff 1ms must not be less than initialBackoff 100ms"},
+		{"fatal drop log level", func(c *RawConfig) { c.Processing.Output.DropLogLevel = "fatal" }, `processing.output.dropLogLevel "fatal" must be debug, info, warn or error`},
+		{"unknown backpressure policy", func(c *RawConfig) { c.Processing.Channels.BackpressurePolicy = "spill" }, `processing.channels.backpressurePolicy "spill" must be block, drop_newest or drop_oldest`},
+		{"dead letter without topic", func(c *RawConfig) { c.Processing.Processor.ErrorPolicy = ErrorPolicyDeadLetter }, "processing.processor.deadLetterTopic must be set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := LoadConfig()
+			tt.mutate(config)
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestValidateAggregatesAllViolations(t *testing.T) {
+	config := LoadConfig()
+	config.Server.Host = ""
+	config.Server.Port = 70000
+	config.Server.ReadTimeout = 0
+	config.Logging.Level = "loud"
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error, got nil")
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 violations, got %d:\n%v", len(lines), err)
+	}
+	for i, prefix := range []string{"server.host", "server.port", "server.readTimeout", "logging.level"} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Violation %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestValidateListener(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*RawConfig)
+		message string
+	}{
+		{"unknown listener", func(c *RawConfig) { c.Server.Listen = "udp" }, `server.listen "udp" must be tcp or unix`},
+		{"unix without path", func(c *RawConfig) { c.Server.Listen = ListenUnix }, "server.socketPath must be set when server.listen is unix"},
+		{"bad socket mode", func(c *RawConfig) { c.Server.SocketMode = "rw" }, `server.socketMode: invalid socket mode "rw"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := LoadConfig()
+			tt.mutate(config)
+			err := config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+
+	config := LoadConfig()
+	config.Server.Listen = ListenUnix
+	config.Server.SocketPath = "/run/service/api.sock"
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() for a unix listener returned error: %v", err)
+	}
+}
+
+func TestValidateLogOutput(t *testing.T) {
+	config := LoadConfig()
+	config.Logging.Output = "syslog"
+	config.Processing.PloggerConfig.Output = "syslog"
+	err := config.Validate()
+	for _, message := range []string{`logging.output "syslog" must be file, stdout or stderr`, `processing.logging.output "syslog" must be file, stdout or stderr`} {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Validate() error = %v, want %q", err, message)
+		}
+	}
+
+	// Standard streams need no file name
+	config = LoadConfig()
+	config.Logging.Output = "stdout"
+	config.Logging.FileName = ""
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() for stdout logging returned error: %v", err)
+	}
+	config.Logging.Output = ""
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "logging.fileName must not be empty") {
+		t.Errorf("Validate() error = %v, want an empty file name violation", err)
+	}
+	config.Logging.Outputs = []string{"file", "stdout"}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "logging.fileName must not be empty") {
+		t.Errorf("Validate() error = %v, want an empty file name violation for outputs including file", err)
+	}
+
+	config = LoadConfig()
+	config.Logging.Outputs = []string{"stdout", "kafka"}
+	config.Logging.Format = "xml"
+	err = config.Validate()
+	for _, message := range []string{`logging.outputs "kafka" must be file, stdout or stderr`, `logging.format "xml" must be json, text or console`} {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Validate() error = %v, want %q", err, message)
+		}
+	}
+}
+
+func TestConvertToLoggerConfigOutputs(t *testing.T) {
+	cfg := RawLoggingConfig{FileName: "main.log", Outputs: []string{"file", "stdout"}, Format: "console", Color: true, Strict: true, Rotate: true, MaxSize: 10, MaxBackups: 3, Async: true, BufferSize: 64, Overflow: "drop", ContextFields: []string{"request_id", "scenario"}, RedactFields: []string{"*_secret"}, LevelOverrides: map[string]string{"processing": "debug"}, IncludeStack: true, StackCapture: "with_error", TimestampFormat: "RFC3339Nano", TimeZone: "Local", FallbackPath: "fallback.log", FallbackAfter: 5, FallbackRetry: time.Second, StrictKeyValues: true}
+	converted := cfg.ConvertToLoggerConfig()
+	if !reflect.DeepEqual(converted.Outputs, cfg.Outputs) || converted.Format != "console" || !converted.Color || !converted.Strict {
+		t.Errorf("Expected outputs, format, color and strict to carry over, got %+v", converted)
+	}
+	if !converted.IsLogRotatable || converted.MaxSize != 10 || converted.MaxBackups != 3 {
+		t.Errorf("Expected the rotation settings to carry over, got %+v", converted)
+	}
+	if !converted.Async || converted.BufferSize != 64 || converted.Overflow != "drop" {
+		t.Errorf("Expected the async settings to carry over, got %+v", converted)
+	}
+	if !reflect.DeepEqual(converted.ContextKeys, cfg.ContextFields) {
+		t.Errorf("Expected the context keys to carry over, got %v", converted.ContextKeys)
+	}
+	if !reflect.DeepEqual(converted.Redact, cfg.RedactFields) {
+		t.Errorf("Expected the redaction patterns to carry over, got %v", converted.Redact)
+	}
+	if !reflect.DeepEqual(converted.LevelOverrides, map[string]logging.Level{"processing": logging.DebugLevel}) {
+		t.Errorf("Expected the level overrides to carry over, got %v", converted.LevelOverrides)
+	}
+	if !converted.IncludeStack || converted.StackCapture != logging.StackCaptureWithError {
+		t.Errorf("Expected the stack settings to carry over, got %+v", converted)
+	}
+	if converted.TimestampFormat != logging.TimestampRFC3339Nano || converted.TimeZone != "Local" {
+		t.Errorf("Expected the timestamp settings to carry over, got %+v", converted)
+	}
+	if converted.FallbackPath != "fallback.log" || converted.FallbackAfter != 5 || converted.FallbackRetry != time.Second {
+		t.Errorf("Expected the fallback settings to carry over, got %+v", converted)
+	}
+	if !converted.StrictKeyValues {
+		t.Error("Expected strictKeyValues to carry over")
+	}
+	if !cfg.WritesFile() {
+		t.Error("Expected outputs including file to write the log file")
+	}
+	if (RawLoggingConfig{Outputs: []string{"stderr"}}).WritesFile() {
+		t.Error("Expected stderr alone not to write a log file")
+	}
+}
@@ -0,0 +1,335 @@
+// Package metrics is a small metrics registry served in the Prometheus text
+// exposition format. It supports labelled counters and histograms updated by
+// the code that owns them, and collectors that read their values from
+// elsewhere, such as the pipeline counters, when the registry is scraped.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric types, as written on the TYPE line of a family
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefaultBuckets are histogram bucket upper bounds in seconds suited to
+// request and flush latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Sample is one value of a collected family, with its label values in the
+// order of the family's label names
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CollectFunc returns the current samples of a collected family
+type CollectFunc func() []Sample
+
+// family is a registered metric family
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families by name. It is safe for concurrent use.
+type Registry struct {
+	mutex    sync.RWMutex
+	families map[string]family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register adds f under name. Registering a name twice is a programming
+// error and panics.
+func (r *Registry) register(name string, f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, exists := r.families[name]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.families[name] = f
+}
+
+// NewCounterVec registers a counter family with the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, labelNames: labelNames}, values: make(map[string]*counterValue)}
+	r.register(name, c)
+	return c
+}
+
+// NewHistogramVec registers a histogram family with the given bucket upper
+// bounds, in increasing order, and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{desc: desc{name: name, help: help, labelNames: labelNames}, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.register(name, h)
+	return h
+}
+
+// Collect registers a family of the given type whose samples are read from
+// collect on every scrape
+func (r *Registry) Collect(name, help, metricType string, labelNames []string, collect CollectFunc) {
+	r.register(name, &collector{desc: desc{name: name, help: help, labelNames: labelNames}, metricType: metricType, collect: collect})
+}
+
+// WriteTo writes every family in the text exposition format, sorted by name
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mutex.RUnlock()
+
+	counted := &countingWriter{w: w}
+	buffered := bufio.NewWriter(counted)
+	for _, f := range families {
+		f.write(buffered)
+	}
+	err := buffered.Flush()
+	return counted.n, err
+}
+
+// Handler returns an http.Handler serving the registry
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteTo(w)
+	})
+}
+
+// desc names a family and its labels
+type desc struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+// writeHeader writes the HELP and TYPE lines
+func (d desc) writeHeader(w *bufio.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, metricType)
+}
+
+// writeSample writes one sample line. extra is an additional label pair, such
+// as the le label of a histogram bucket, and is skipped when empty.
+func (d desc) writeSample(w *bufio.Writer, suffix string, labelValues []string, extra [2]string, value float64) {
+	w.WriteString(d.name)
+	w.WriteString(suffix)
+	pairs := make([]string, 0, len(labelValues)+1)
+	for i, value := range labelValues {
+		pairs = append(pairs, d.labelNames[i]+`="`+escapeLabel(value)+`"`)
+	}
+	if extra[0] != "" {
+		pairs = append(pairs, extra[0]+`="`+extra[1]+`"`)
+	}
+	if len(pairs) > 0 {
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	w.WriteString(" " + formatValue(value) + "\n")
+}
+
+// key joins label values into a map key, checking their number
+func (d desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", d.name, len(d.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// CounterVec is a counter family partitioned by label values
+type CounterVec struct {
+	desc
+	mutex  sync.RWMutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc adds one to the counter with the given label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter with the given
+// label values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = value
+	}
+	value.value += delta
+}
+
+// Value returns the counter with the given label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if value, ok := c.values[key]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w, TypeCounter)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		c.writeSample(w, "", value.labelValues, [2]string{}, value.value)
+	}
+}
+
+// HistogramVec is a histogram family partitioned by label values
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mutex   sync.RWMutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // Per bucket, not cumulative; the last is +Inf
+	sum         float64
+	count       uint64
+}
+
+// Observe records value in the histogram with the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	histogram, ok := h.values[key]
+	if !ok {
+		histogram = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets)+1)}
+		h.values[key] = histogram
+	}
+	histogram.counts[sort.SearchFloat64s(h.buckets, value)]++
+	histogram.sum += value
+	histogram.count++
+}
+
+// Count returns how many values the histogram with the given label values
+// has recorded
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if histogram, ok := h.values[key]; ok {
+		return histogram.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w, TypeHistogram)
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for _, key := range sortedKeys(h.values) {
+		histogram := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += histogram.counts[i]
+			h.writeSample(w, "_bucket", histogram.labelValues, [2]string{"le", formatValue(bound)}, float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", histogram.labelValues, [2]string{"le", "+Inf"}, float64(histogram.count))
+		h.writeSample(w, "_sum", histogram.labelValues, [2]string{}, histogram.sum)
+		h.writeSample(w, "_count", histogram.labelValues, [2]string{}, float64(histogram.count))
+	}
+}
+
+// collector is a family whose samples are read on every scrape
+type collector struct {
+	desc
+	metricType string
+	collect    CollectFunc
+}
+
+func (c *collector) write(w *bufio.Writer) {
+	c.writeHeader(w, c.metricType)
+	for _, sample := range c.collect() {
+		c.key(sample.LabelValues)
+		c.writeSample(w, "", sample.LabelValues, [2]string{}, sample.Value)
+	}
+}
+
+// sortedKeys returns the keys of values in order, so series are written in
+// a stable order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatValue formats a sample value the way Prometheus parses it
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// countingWriter counts the bytes written for WriteTo
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
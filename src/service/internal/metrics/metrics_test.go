@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("test_requests_total", "Requests handled.", "method", "status")
+	latency := registry.NewHistogramVec("test_latency_seconds", "Request latency.", []float64{0.1, 1})
+	registry.Collect("test_fill_ratio", "How full a queue is.", TypeGauge, []string{"stage"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"output"}, Value: 0.5}, {LabelValues: []string{"in\"put"}, Value: 0.25}}
+	})
+
+	requests.Inc("GET", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "400")
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(3)
+
+	var out strings.Builder
+	if _, err := registry.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() returned error: %v", err)
+	}
+	want := `# HELP test_fill_ratio How full a queue is.
+# TYPE test_fill_ratio gauge
+test_fill_ratio{stage="output"} 0.5
+test_fill_ratio{stage="in\"put"} 0.25
+# HELP test_latency_seconds Request latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.1"} 1
+test_latency_seconds_bucket{le="1"} 2
+test_latency_seconds_bucket{le="+Inf"} 3
+test_latency_seconds_sum 3.55
+test_latency_seconds_count 3
+# HELP test_requests_total Requests handled.
+# TYPE test_requests_total counter
+test_requests_total{method="GET",status="200"} 3
+test_requests_total{method="POST",status="400"} 1
+`
+	if out.String() != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if requests.Value("GET", "200") != 3 || latency.Count() != 3 {
+		t.Errorf("Expected 3 GET requests and 3 observations, got %v and %d", requests.Value("GET", "200"), latency.Count())
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("test_total", "Test counter.").Inc()
+
+	rr := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	if rr.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Expected content type %q, got %q", ContentType, rr.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rr.Body.String(), "test_total 1\n") {
+		t.Errorf("Expected the counter in the response, got:\n%s", rr.Body.String())
+	}
+}
+
+func TestRegistryRejectsMisuse(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_total", "Test counter.", "stage")
+
+	for name, misuse := range map[string]func(){
+		"duplicate name":     func() { registry.NewCounterVec("test_total", "Again.") },
+		"wrong label values": func() { counter.Inc("a", "b") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			misuse()
+		})
+	}
+}
+
+func TestCounterVecConcurrentUpdates(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_total", "Test counter.", "stage")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.Inc("consumed")
+			}
+			registry.WriteTo(&strings.Builder{})
+		}()
+	}
+	wg.Wait()
+	if got := counter.Value("consumed"); got != 8000 {
+		t.Errorf("Expected 8000, got %v", got)
+	}
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"sharedgomodule/messagebus"
 )
 
 // MessageType defines the type of message being passed through the channels
@@ -14,11 +16,59 @@ const (
 	ChannelMessageTypeControl ChannelMessageType = "control"
 )
 
-// ChannelMessage represents a common message structure for channel communication
+// ChannelMessage represents a common message structure for channel communication.
+// Messages read from the message bus carry their topic, key, headers, partition
+// and offset; messages created inside the pipeline leave them empty.
+// CorrelationID follows a data message from input to output for log tracing.
+// Sources holds the consumed messages a data message was derived from, so
+// their offsets can be committed once it has been published.
 type ChannelMessage struct {
 	Type      ChannelMessageType `json:"type"`
 	Timestamp time.Time          `json:"timestamp"`
 	Data      []byte             `json:"data"`
+	Topic     string             `json:"topic,omitempty"`
+	Key       string             `json:"key,omitempty"`
+	Headers   map[string]string  `json:"headers,omitempty"`
+	Partition int32              `json:"partition,omitempty"`
+	Offset    int64              `json:"offset,omitempty"`
+
+	CorrelationID string                `json:"correlation_id,omitempty"`
+	Sources       []*messagebus.Message `json:"-"`
+
+	acknowledger Acknowledger // Receives Ack and Nack; nil ignores them
+}
+
+// Acknowledger receives the Ack or Nack of a message from whoever is handling
+// it. The pipeline installs one while a MessageProcessor runs; only the
+// first settlement of each processing attempt counts, so acknowledging a
+// message twice is safe.
+type Acknowledger interface {
+	Ack()
+	Nack(err error, requeue bool)
+}
+
+// SetAcknowledger installs the acknowledger that Ack and Nack report to
+func (m *ChannelMessage) SetAcknowledger(acknowledger Acknowledger) {
+	m.acknowledger = acknowledger
+}
+
+// Ack settles the message as handled, so its offset can be committed once
+// any output has been published. It does nothing on a message without an
+// acknowledger.
+func (m *ChannelMessage) Ack() {
+	if m.acknowledger != nil {
+		m.acknowledger.Ack()
+	}
+}
+
+// Nack settles the message as failed with err. With requeue the message is
+// processed again, as a transient failure; without it the message is
+// rejected for good and dead-lettered. It does nothing on a message without
+// an acknowledger.
+func (m *ChannelMessage) Nack(err error, requeue bool) {
+	if m.acknowledger != nil {
+		m.acknowledger.Nack(err, requeue)
+	}
 }
 
 // NewChannelMessage creates a new channel message with the given type and data
@@ -52,20 +102,98 @@ func (m *ChannelMessage) IsControlMessage() bool {
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code,omitempty"`
+	Error   string `json:"error" xml:"error"`
+	Message string `json:"message,omitempty" xml:"message,omitempty"`
+	Code    int    `json:"code,omitempty" xml:"code,omitempty"`
+}
+
+// ValidationErrorResponse is an error response that also lists each problem
+// found, such as every invalid field of a request
+type ValidationErrorResponse struct {
+	ErrorResponse
+	Details []string `json:"details" xml:"details>detail"`
 }
 
-// SuccessResponse represents a success response
+// SuccessResponse represents a success response. Data is encoded for XML by
+// MarshalXML since encoding/xml cannot handle maps.
 type SuccessResponse struct {
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message" xml:"message"`
+	Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
+}
+
+// StatsResponse is the data of the statistics response. Status is the
+// pipeline state: not_started, running, degraded, stopped or failed. The counters
+// cover the current pipeline and start from zero when it is restarted.
+type StatsResponse struct {
+	Status        string `json:"status" xml:"status"`
+	TotalMessages int64  `json:"total_messages" xml:"total_messages"` // Messages consumed, kept for older clients
+	Consumed      int64  `json:"messages_consumed" xml:"messages_consumed"`
+	Processed     int64  `json:"messages_processed" xml:"messages_processed"`
+	Published     int64  `json:"messages_published" xml:"messages_published"`
+	Failed        int64  `json:"messages_failed" xml:"messages_failed"`
+	Dropped       int64  `json:"messages_dropped" xml:"messages_dropped"`
+	// Pipeline holds the per-stage detail reported by the pipeline; it is
+	// omitted when there is no pipeline
+	Pipeline map[string]interface{} `json:"pipeline,omitempty" xml:"pipeline,omitempty"`
 }
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Version   string    `json:"version"`
+	Status    string          `json:"status" xml:"status"`
+	Timestamp time.Time       `json:"timestamp" xml:"timestamp"`
+	Version   string          `json:"version" xml:"version"`
+	Services  []ServiceHealth `json:"services,omitempty" xml:"services>service,omitempty"`
+	Details   HealthDetails   `json:"details,omitempty" xml:"details,omitempty"`
+}
+
+// HealthDetails maps sub-components, such as the stages of the processing
+// pipeline, to their status
+type HealthDetails map[string]string
+
+// TopicsRequest is the body of a request replacing the input topics
+type TopicsRequest struct {
+	Topics []string `json:"topics" xml:"topics>topic"`
+}
+
+// LogLevel is the body of a log level request and response, e.g. debug.
+// Component, when set, names the component or logger whose level it is.
+type LogLevel struct {
+	Level     string `json:"level" xml:"level"`
+	Component string `json:"component,omitempty" xml:"component,omitempty"`
+}
+
+// DeadLetter describes a message the pipeline published to a dead-letter
+// topic. PayloadPreview holds the start of the payload; Size is its length.
+type DeadLetter struct {
+	ID             uint64    `json:"id" xml:"id"`
+	Topic          string    `json:"topic" xml:"topic"`
+	SourceTopic    string    `json:"source_topic" xml:"source_topic"`
+	Key            string    `json:"key,omitempty" xml:"key,omitempty"`
+	Error          string    `json:"error" xml:"error"`
+	Attempts       int       `json:"attempts" xml:"attempts"`
+	FailedAt       time.Time `json:"failed_at" xml:"failed_at"`
+	Size           int       `json:"size" xml:"size"`
+	PayloadPreview string    `json:"payload_preview" xml:"payload_preview"`
+}
+
+// DeadLetterReplayRequest is the body of a request replaying dead letters,
+// selected by ID
+type DeadLetterReplayRequest struct {
+	IDs []uint64 `json:"ids" xml:"ids>id"`
+}
+
+// DeadLetterReplayResult reports which dead letters were republished to
+// their source topic. Failed maps the ID of each dead letter that could not
+// be to the reason.
+type DeadLetterReplayResult struct {
+	Replayed []uint64          `json:"replayed" xml:"replayed>id"`
+	NotFound []uint64          `json:"not_found" xml:"not_found>id"`
+	Failed   map[uint64]string `json:"failed" xml:"-"`
+}
+
+// ServiceHealth represents the health of a single registered service
+type ServiceHealth struct {
+	Name   string `json:"name" xml:"name"`
+	Status string `json:"status" xml:"status"`
+	Error  string `json:"error,omitempty" xml:"error,omitempty"`
 }
@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -109,6 +112,36 @@ func TestChannelMessageMethods(t *testing.T) {
 	}
 }
 
+// recordingAcknowledger records the settlements it receives
+type recordingAcknowledger struct {
+	acks    int
+	nacks   []error
+	requeue []bool
+}
+
+func (r *recordingAcknowledger) Ack() { r.acks++ }
+
+func (r *recordingAcknowledger) Nack(err error, requeue bool) {
+	r.nacks = append(r.nacks, err)
+	r.requeue = append(r.requeue, requeue)
+}
+
+func TestChannelMessageAckNack(t *testing.T) {
+	msg := NewDataMessage([]byte("data"), "test")
+	// Without an acknowledger settling does nothing
+	msg.Ack()
+	msg.Nack(errors.New("ignored"), true)
+
+	acknowledger := &recordingAcknowledger{}
+	msg.SetAcknowledger(acknowledger)
+	msg.Ack()
+	cause := errors.New("bad payload")
+	msg.Nack(cause, false)
+	if acknowledger.acks != 1 || len(acknowledger.nacks) != 1 || acknowledger.nacks[0] != cause || acknowledger.requeue[0] {
+		t.Errorf("Expected one ack and one nack without requeue, got %+v", acknowledger)
+	}
+}
+
 func TestErrorResponse(t *testing.T) {
 	t.Run("creates error response with all fields", func(t *testing.T) {
 		err := ErrorResponse{
@@ -420,3 +453,73 @@ func TestJSONSerialization(t *testing.T) {
 		}
 	})
 }
+
+func TestXMLSerialization(t *testing.T) {
+	t.Run("SuccessResponse encodes map data as elements", func(t *testing.T) {
+		resp := SuccessResponse{
+			Message: "ok",
+			Data: map[string]interface{}{
+				"total_messages": 3,
+				"topics":         []string{"a", "b"},
+				"bad key":        true,
+			},
+		}
+
+		body, err := xml.Marshal(resp)
+		if err != nil {
+			t.Fatalf("Failed to marshal SuccessResponse: %v", err)
+		}
+
+		expected := `<SuccessResponse><message>ok</message><data>` +
+			`<entry key="bad key">true</entry>` +
+			`<topics><item>a</item><item>b</item></topics>` +
+			`<total_messages>3</total_messages>` +
+			`</data></SuccessResponse>`
+		if string(body) != expected {
+			t.Errorf("Unexpected XML:\n got: %s\nwant: %s", body, expected)
+		}
+	})
+
+	t.Run("SuccessResponse omits nil data", func(t *testing.T) {
+		body, err := xml.Marshal(SuccessResponse{Message: "ok"})
+		if err != nil {
+			t.Fatalf("Failed to marshal SuccessResponse: %v", err)
+		}
+		if string(body) != `<SuccessResponse><message>ok</message></SuccessResponse>` {
+			t.Errorf("Unexpected XML: %s", body)
+		}
+	})
+
+	t.Run("HealthResponse encodes details as elements", func(t *testing.T) {
+		resp := HealthResponse{Status: "degraded", Details: HealthDetails{"processor": "healthy", "input": "no successful poll for 5s"}}
+		body, err := xml.Marshal(resp)
+		if err != nil {
+			t.Fatalf("Failed to marshal HealthResponse: %v", err)
+		}
+		expected := `<details><input>no successful poll for 5s</input><processor>healthy</processor></details>`
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected %s in %s", expected, body)
+		}
+
+		body, _ = xml.Marshal(HealthResponse{Status: "healthy"})
+		if strings.Contains(string(body), "details") {
+			t.Errorf("Expected no details element without details, got %s", body)
+		}
+	})
+
+	t.Run("ErrorResponse round trips", func(t *testing.T) {
+		resp := ErrorResponse{Error: "test_error", Code: 500}
+		body, err := xml.Marshal(resp)
+		if err != nil {
+			t.Fatalf("Failed to marshal ErrorResponse: %v", err)
+		}
+
+		var unmarshaled ErrorResponse
+		if err := xml.Unmarshal(body, &unmarshaled); err != nil {
+			t.Fatalf("Failed to unmarshal ErrorResponse: %v", err)
+		}
+		if unmarshaled != resp {
+			t.Errorf("Expected %+v, got %+v", resp, unmarshaled)
+		}
+	})
+}
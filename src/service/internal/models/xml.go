@@ -0,0 +1,115 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"unicode"
+)
+
+// xmlItemElement names the elements of encoded arrays
+const xmlItemElement = "item"
+
+// MarshalXML encodes the response with Data converted through its JSON form,
+// so the XML mirrors the JSON body: objects become nested elements named after
+// their keys and arrays become repeated <item> elements.
+func (r SuccessResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(r.Message, xml.StartElement{Name: xml.Name{Local: "message"}}); err != nil {
+		return err
+	}
+
+	if r.Data != nil {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if err := encodeXMLValue(e, "data", value); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// MarshalXML encodes the details as elements named after their keys, in key
+// order
+func (d HealthDetails) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	value := make(map[string]interface{}, len(d))
+	for k, v := range d {
+		value[k] = v
+	}
+	return encodeXMLValue(e, start.Name.Local, value)
+}
+
+// encodeXMLValue writes a decoded JSON value as an element called name. Object
+// keys that are not valid XML names are written as <entry key="...">.
+func encodeXMLValue(e *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encodeXMLValue(e, k, v[k]); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case []interface{}:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := encodeXMLValue(e, xmlItemElement, item); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case nil:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	default:
+		return e.EncodeElement(v, start)
+	}
+}
+
+// isXMLName reports whether s can be used as an XML element name
+func isXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package processing
+
+import (
+	"errors"
+	"sync"
+)
+
+// errNacked stands in for the cause of a Nack made without an error
+var errNacked = errors.New("message nacked by processor")
+
+// nackError is the failure of a processing attempt whose message was
+// nacked. A requeued message is retried; a rejected one is dead-lettered.
+type nackError struct {
+	err     error
+	requeue bool
+}
+
+func (e *nackError) Error() string { return e.err.Error() }
+
+func (e *nackError) Unwrap() error { return e.err }
+
+// rejected reports whether err is a Nack without requeue
+func rejected(err error) bool {
+	var nack *nackError
+	return errors.As(err, &nack) && !nack.requeue
+}
+
+// requeued reports whether err is a Nack with requeue
+func requeued(err error) bool {
+	var nack *nackError
+	return errors.As(err, &nack) && nack.requeue
+}
+
+type ackOutcome int
+
+const (
+	ackNone ackOutcome = iota
+	ackAcked
+	ackNacked
+)
+
+// settlement is the acknowledger the processor installs on a data message
+// while its MessageProcessor runs. It keeps the first Ack or Nack of each
+// attempt and ignores the rest, as well as any made between attempts.
+type settlement struct {
+	mutex   sync.Mutex
+	open    bool
+	outcome ackOutcome
+	err     error
+	requeue bool
+}
+
+// Ack implements models.Acknowledger
+func (s *settlement) Ack() {
+	s.settle(ackAcked, nil, false)
+}
+
+// Nack implements models.Acknowledger
+func (s *settlement) Nack(err error, requeue bool) {
+	if err == nil {
+		err = errNacked
+	}
+	s.settle(ackNacked, err, requeue)
+}
+
+func (s *settlement) settle(outcome ackOutcome, err error, requeue bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.open || s.outcome != ackNone {
+		return
+	}
+	s.outcome, s.err, s.requeue = outcome, err, requeue
+}
+
+// begin opens the settlement for a new attempt
+func (s *settlement) begin() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.open, s.outcome, s.err, s.requeue = true, ackNone, nil, false
+}
+
+// end closes the attempt and returns its result: err, the error the
+// MessageProcessor returned, unless the message was settled. An Ack clears
+// the error and a Nack replaces it with a nackError.
+func (s *settlement) end(err error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.open = false
+	switch s.outcome {
+	case ackAcked:
+		return nil
+	case ackNacked:
+		return &nackError{err: s.err, requeue: s.requeue}
+	}
+	return err
+}
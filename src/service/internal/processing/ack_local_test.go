@@ -0,0 +1,99 @@
+//go:build local
+
+package processing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/messagebus"
+)
+
+// startAckPipeline starts a pipeline over the local message bus whose
+// message processor is mp, with a dead-letter topic and the drop policy
+func startAckPipeline(t *testing.T, name string, mp MessageProcessorFunc) (*Pipeline, ProcConfig) {
+	t.Helper()
+	settings := localConfig(name)
+	settings.Processor.MaxRetries = 2
+	settings.Output.DeadLetterTopic = freshTopic(name + "-dead")
+	pipeline := startLocalPipeline(t, settings, func(pipeline *Pipeline) { pipeline.SetMessageProcessor(mp) })
+	sendRecords(t, settings.Input.Topics[0], 1)
+	return pipeline, settings
+}
+
+// awaitCommit waits until offset is committed on topic
+func awaitCommit(t *testing.T, pipeline *Pipeline, topic string, offset int64) {
+	t.Helper()
+	consumer := pipeline.inputHandler.consumer.(*messagebus.LocalConsumer)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if committed, ok := consumer.Committed(topic); ok && committed == offset {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected offset %d committed on %s", offset, topic)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPipelineAckCommitsOffset(t *testing.T) {
+	pipeline, settings := startAckPipeline(t, "ack-test", func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		msg.Ack()
+		msg.Ack() // A second ack is ignored
+		return nil, errors.New("ignored after Ack")
+	})
+
+	awaitCommit(t, pipeline, settings.Input.Topics[0], 0)
+	if stats := pipeline.processor.GetStats(); stats["processing_errors"] != int64(0) {
+		t.Errorf("Expected an acked message not to count as an error, got %v", stats["processing_errors"])
+	}
+}
+
+func TestPipelineNackWithRequeueRetries(t *testing.T) {
+	var calls atomic.Int64
+	pipeline, settings := startAckPipeline(t, "nack-requeue-test", func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		if calls.Add(1) == 1 {
+			msg.Nack(errors.New("downstream busy"), true)
+			return nil, nil
+		}
+		msg.Ack()
+		return models.NewDataMessage(msg.Data, "test"), nil
+	})
+
+	output := subscribe(t, settings.Output.OutputTopic)
+	if message, err := output.Poll(5 * time.Second); err != nil || message == nil {
+		t.Fatalf("Expected the requeued message to be published: %v", err)
+	}
+	awaitCommit(t, pipeline, settings.Input.Topics[0], 0)
+	if stats := pipeline.processor.GetStats(); calls.Load() != 2 || stats["requeued"] != int64(1) {
+		t.Errorf("Expected 2 attempts and 1 requeue, got %d and %v", calls.Load(), stats["requeued"])
+	}
+}
+
+func TestPipelineNackWithoutRequeueDeadLetters(t *testing.T) {
+	var calls atomic.Int64
+	pipeline, settings := startAckPipeline(t, "nack-reject-test", func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		calls.Add(1)
+		msg.Nack(errors.New("invalid order"), false)
+		msg.Nack(errors.New("ignored"), true)
+		return nil, nil
+	})
+
+	dead := subscribe(t, settings.Output.DeadLetterTopic)
+	message, err := dead.Poll(5 * time.Second)
+	if err != nil || message == nil {
+		t.Fatalf("Expected the rejected message on the dead-letter topic: %v", err)
+	}
+	if message.Headers[HeaderDeadLetterError] != "invalid order" || message.Headers[HeaderDeadLetterAttempts] != "1" {
+		t.Errorf("Unexpected dead-letter headers %v", message.Headers)
+	}
+	awaitCommit(t, pipeline, settings.Input.Topics[0], 0)
+	if stats := pipeline.processor.GetStats(); calls.Load() != 1 || stats["rejected"] != int64(1) {
+		t.Errorf("Expected 1 attempt and 1 rejection, got %d and %v", calls.Load(), stats["rejected"])
+	}
+}
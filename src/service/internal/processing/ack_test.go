@@ -0,0 +1,44 @@
+package processing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSettlement(t *testing.T) {
+	acks := &settlement{}
+	returned := errors.New("returned")
+
+	acks.Ack() // Before an attempt: ignored
+	acks.begin()
+	if err := acks.end(returned); err != returned {
+		t.Errorf("Expected the returned error without a settlement, got %v", err)
+	}
+
+	acks.begin()
+	acks.Ack()
+	acks.Nack(errors.New("too late"), false)
+	if err := acks.end(returned); err != nil {
+		t.Errorf("Expected the first settlement, Ack, to clear the error, got %v", err)
+	}
+
+	acks.begin()
+	acks.Nack(nil, true)
+	acks.Ack()
+	err := acks.end(nil)
+	if !requeued(err) || rejected(err) || !errors.Is(err, errNacked) {
+		t.Errorf("Expected a requeued nack, got %v", err)
+	}
+
+	acks.begin()
+	cause := errors.New("invalid")
+	acks.Nack(cause, false)
+	err = acks.end(nil)
+	if !rejected(err) || !errors.Is(err, cause) || err.Error() != "invalid" {
+		t.Errorf("Expected a rejected nack of %v, got %v", cause, err)
+	}
+	acks.Ack() // After the attempt: ignored
+	if err := acks.end(nil); !rejected(err) {
+		t.Errorf("Expected a late Ack to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// dropLogInterval spaces out the warnings about backpressure drops, which
+// can otherwise come once per message
+const dropLogInterval = 10 * time.Second
+
+// channelWriter sends messages on a pipeline channel, applying a
+// backpressure policy when the channel is full
+type channelWriter struct {
+	ch      chan *models.ChannelMessage
+	policy  string // config.Backpressure*; empty means block
+	logger  logging.Logger
+	offsets *offsetTracker // Releases the sources of dropped messages; may be nil
+	dropped atomic.Int64
+	lastLog atomic.Int64 // Unix nanoseconds of the last drop warning
+}
+
+func newChannelWriter(ch chan *models.ChannelMessage, policy string, logger logging.Logger) *channelWriter {
+	return &channelWriter{ch: ch, policy: policy, logger: logger}
+}
+
+// send writes message to the channel. Under the block policy it waits for
+// room and returns false if ctx is done first; the drop policies never wait.
+func (w *channelWriter) send(ctx context.Context, message *models.ChannelMessage) bool {
+	switch w.policy {
+	case config.BackpressureDropNewest:
+		select {
+		case w.ch <- message:
+		default:
+			w.drop(message)
+		}
+		return true
+
+	case config.BackpressureDropOldest:
+		for {
+			select {
+			case w.ch <- message:
+				return true
+			default:
+			}
+			// The reader may empty the channel in between, so only take
+			// a message if one is there
+			select {
+			case oldest := <-w.ch:
+				w.drop(oldest)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case w.ch <- message:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// drop counts a discarded message and logs a warning at most once per
+// dropLogInterval. The policy discarded it, so its sources are done with.
+func (w *channelWriter) drop(message *models.ChannelMessage) {
+	w.offsets.release(message.Sources)
+	total := w.dropped.Add(1)
+	now := time.Now().UnixNano()
+	last := w.lastLog.Load()
+	if now-last < int64(dropLogInterval) || !w.lastLog.CompareAndSwap(last, now) {
+		return
+	}
+	w.logger.Warnw("Channel full, dropping message", "policy", w.policy, "key", message.Key, "type", message.Type, "backpressure_drops", total)
+}
+
+// validateBackpressure checks the backpressure policy
+func (c ChannelConfig) validateBackpressure() error {
+	switch c.BackpressurePolicy {
+	case "", config.BackpressureBlock, config.BackpressureDropNewest, config.BackpressureDropOldest:
+		return nil
+	default:
+		return fmt.Errorf("unknown backpressure policy %q", c.BackpressurePolicy)
+	}
+}
@@ -0,0 +1,145 @@
+package processing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+func TestChannelWriterPolicies(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantQueued  []string
+		wantDropped int64
+		wantSent    bool // Whether the send that found the channel full succeeded
+	}{
+		{config.BackpressureBlock, []string{"1", "2"}, 0, false},
+		{config.BackpressureDropNewest, []string{"1", "2"}, 2, true},
+		{config.BackpressureDropOldest, []string{"3", "4"}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ch := make(chan *models.ChannelMessage, 2)
+			writer := newChannelWriter(ch, tt.policy, logging.NewNopLogger())
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			sent := true
+			for _, data := range []string{"1", "2", "3", "4"} {
+				sent = writer.send(ctx, models.NewDataMessage([]byte(data), "test"))
+			}
+			if sent != tt.wantSent {
+				t.Errorf("Expected the last send to return %v, got %v", tt.wantSent, sent)
+			}
+
+			close(ch)
+			var queued []string
+			for message := range ch {
+				queued = append(queued, string(message.Data))
+			}
+			if len(queued) != len(tt.wantQueued) || queued[0] != tt.wantQueued[0] || queued[1] != tt.wantQueued[1] {
+				t.Errorf("Expected %v queued, got %v", tt.wantQueued, queued)
+			}
+			if writer.dropped.Load() != tt.wantDropped {
+				t.Errorf("Expected %d drops, got %d", tt.wantDropped, writer.dropped.Load())
+			}
+		})
+	}
+}
+
+func TestInputHandlerBackpressureDropsWithSlowReader(t *testing.T) {
+	consumer := newQueueConsumer()
+	handler := NewInputHandlerWithConsumer(InputConfig{
+		Topics:            []string{"orders"},
+		PollTimeout:       5 * time.Millisecond,
+		ChannelBufferSize: 1,
+	}, consumer, logging.NewNopLogger())
+	handler.input.policy = config.BackpressureDropNewest
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	defer handler.Stop()
+
+	// Nothing reads the input channel until every message has been polled
+	for _, key := range []string{"a", "b", "c"} {
+		consumer.messages <- &messagebus.Message{Topic: "orders", Key: key, Value: []byte(key)}
+	}
+	deadline := time.Now().Add(time.Second)
+	for handler.GetStats()["backpressure_drops"] != int64(2) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if drops := handler.GetStats()["backpressure_drops"]; drops != int64(2) {
+		t.Fatalf("Expected 2 backpressure drops, got %v", drops)
+	}
+	if msg := <-handler.GetInputChannel(); msg.Key != "a" {
+		t.Errorf("Expected the first message to be kept, got %q", msg.Key)
+	}
+}
+
+func TestProcessorBackpressureDropOldestWithSlowReader(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 10)
+	outputCh := make(chan *models.ChannelMessage, 2)
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1}, logging.NewNopLogger(), inputCh, outputCh, MessageProcessorFunc(
+		func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) { return msg, nil }))
+	processor.output.policy = config.BackpressureDropOldest
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	for _, data := range []string{"1", "2", "3", "4", "5"} {
+		inputCh <- models.NewDataMessage([]byte(data), "test")
+	}
+	deadline := time.Now().Add(time.Second)
+	for processor.GetStats()["messages_processed"] != int64(5) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+
+	if drops := processor.GetStats()["backpressure_drops"]; drops != int64(3) {
+		t.Errorf("Expected 3 backpressure drops, got %v", drops)
+	}
+	if first := <-outputCh; string(first.Data) != "4" {
+		t.Errorf("Expected the newest messages to be kept, got %q first", first.Data)
+	}
+}
+
+func TestProcessorBackpressureBlocksWithSlowReader(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 10)
+	outputCh := make(chan *models.ChannelMessage, 1)
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1}, logging.NewNopLogger(), inputCh, outputCh, MessageProcessorFunc(
+		func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) { return msg, nil }))
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	for _, data := range []string{"1", "2", "3"} {
+		inputCh <- models.NewDataMessage([]byte(data), "test")
+	}
+	// The reader is slow but nothing is lost
+	for _, want := range []string{"1", "2", "3"} {
+		time.Sleep(10 * time.Millisecond)
+		select {
+		case msg := <-outputCh:
+			if string(msg.Data) != want {
+				t.Errorf("Expected message %s, got %s", want, msg.Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected message %s", want)
+		}
+	}
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if drops := processor.GetStats()["backpressure_drops"]; drops != int64(0) {
+		t.Errorf("Expected no drops under the block policy, got %v", drops)
+	}
+}
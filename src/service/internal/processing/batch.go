@@ -0,0 +1,160 @@
+package processing
+
+import (
+	"context"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/messagebus"
+)
+
+// BatchMessageProcessor processes data messages in batches, e.g. to make
+// bulk writes downstream. It is used when the processor runs in batch mode.
+// The returned messages are sent to the output channel; they need not
+// correspond one to one with the input. An error hands every message in the
+// batch to the configured error policy, with the retry policy retrying the
+// whole batch. The context carries no correlation ID, so outputs are traced
+// only if the batch processor sets their CorrelationID.
+type BatchMessageProcessor interface {
+	ProcessBatch(ctx context.Context, msgs []*models.ChannelMessage) ([]*models.ChannelMessage, error)
+}
+
+// BatchMessageProcessorFunc adapts an ordinary function to a BatchMessageProcessor
+type BatchMessageProcessorFunc func(ctx context.Context, msgs []*models.ChannelMessage) ([]*models.ChannelMessage, error)
+
+// ProcessBatch calls f(ctx, msgs)
+func (f BatchMessageProcessorFunc) ProcessBatch(ctx context.Context, msgs []*models.ChannelMessage) ([]*models.ChannelMessage, error) {
+	return f(ctx, msgs)
+}
+
+// batchLoop accumulates data messages into batches of up to BatchSize and
+// processes a batch when it is full, when BatchLinger has passed since its
+// first message, before forwarding a control message and on shutdown
+func (p *Processor) batchLoop(worker int, input <-chan *models.ChannelMessage) {
+	defer p.workers.Done()
+	defer p.recoverWorker(worker)
+
+	var batch []*models.ChannelMessage
+	linger := time.NewTimer(time.Hour)
+	linger.Stop()
+	defer linger.Stop()
+
+	flush := func() {
+		linger.Stop()
+		p.processBatch(p.ctx, batch)
+		batch = nil
+	}
+
+	for {
+		select {
+		case <-p.quit:
+			flush()
+			p.logger.Infow("Processor batch loop stopped", "worker", worker)
+			return
+
+		case <-linger.C:
+			flush()
+
+		case message := <-input:
+			p.dequeued()
+			if !message.IsDataMessage() {
+				// Keep control messages ordered after the data before them
+				flush()
+				p.forwardControl(message)
+				continue
+			}
+
+			if p.screenOut(message, messageLogger(p.logger, message)) {
+				continue
+			}
+			batch = append(batch, message)
+			config := p.currentConfig()
+			if len(batch) >= config.BatchSize {
+				flush()
+			} else if len(batch) == 1 {
+				linger.Reset(config.lingerTimeout())
+			}
+		}
+	}
+}
+
+// processBatch runs batch through the batch processor, or through the
+// message processor one message at a time when none is set
+func (p *Processor) processBatch(ctx context.Context, batch []*models.ChannelMessage) {
+	if len(batch) == 0 {
+		return
+	}
+	p.logger.Debugw("Processing batch", "size", len(batch))
+	p.batches.Add(1)
+	p.batched.Add(int64(len(batch)))
+
+	if p.batcher == nil {
+		for _, message := range batch {
+			if err := p.processMessage(message); err != nil {
+				p.logger.Errorw("Error processing message", "error", err)
+			}
+		}
+		return
+	}
+
+	var outputs []*models.ChannelMessage
+	attempts, err := p.retry(ctx, p.logger, func(ctx context.Context) error {
+		var err error
+		outputs, err = p.batcher.ProcessBatch(ctx, batch)
+		return err
+	})
+	if err != nil {
+		p.logger.Errorw("Error processing batch", "size", len(batch), "error", err)
+		p.errors.Add(1)
+		for _, message := range batch {
+			p.handleFailure(message, err, attempts)
+		}
+		return
+	}
+
+	p.sendBatchOutputs(batch, outputs)
+	p.processed.Add(int64(len(batch)))
+}
+
+// sendBatchOutputs sends the outputs of a processed batch. Outputs need not
+// match inputs one to one, so each output carries the sources of the whole
+// batch, which are done with once every output is.
+func (p *Processor) sendBatchOutputs(batch, outputs []*models.ChannelMessage) {
+	var sources []*messagebus.Message
+	for _, message := range batch {
+		sources = append(sources, message.Sources...)
+	}
+	var sent []*models.ChannelMessage
+	for _, output := range outputs {
+		if output != nil {
+			sent = append(sent, output)
+		}
+	}
+	if len(sent) == 0 {
+		p.offsets.release(sources)
+		return
+	}
+
+	p.offsets.retain(sources, len(sent)-1)
+	for _, output := range sent {
+		output.Sources = sources
+		p.output.send(context.Background(), output)
+	}
+}
+
+// averageBatchSize returns the mean number of messages per processed batch
+func (p *Processor) averageBatchSize() float64 {
+	batches := p.batches.Load()
+	if batches == 0 {
+		return 0
+	}
+	return float64(p.batched.Load()) / float64(batches)
+}
+
+// averageBatchFill returns the mean batch size as a fraction of batchSize
+func (p *Processor) averageBatchFill(batchSize int) float64 {
+	if batchSize <= 0 {
+		return 0
+	}
+	return p.averageBatchSize() / float64(batchSize)
+}
@@ -0,0 +1,178 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// recordingBatcher echoes each batch and reports its size on sizes
+func recordingBatcher(sizes chan<- int) BatchMessageProcessor {
+	return BatchMessageProcessorFunc(func(ctx context.Context, msgs []*models.ChannelMessage) ([]*models.ChannelMessage, error) {
+		sizes <- len(msgs)
+		return msgs, nil
+	})
+}
+
+// startBatchProcessor starts a batch-mode processor reading from a fresh
+// input channel and returns it with the channels
+func startBatchProcessor(t *testing.T, settings ProcessorConfig, batcher BatchMessageProcessor) (*Processor, chan *models.ChannelMessage, chan *models.ChannelMessage) {
+	t.Helper()
+	inputCh := make(chan *models.ChannelMessage, 100)
+	outputCh := make(chan *models.ChannelMessage, 100)
+	settings.BatchMode = true
+	processor := NewProcessor(settings, logging.NewNopLogger(), inputCh, outputCh, nil)
+	processor.batcher = batcher
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	return processor, inputCh, outputCh
+}
+
+func expectBatch(t *testing.T, sizes <-chan int, want int, within time.Duration) {
+	t.Helper()
+	select {
+	case got := <-sizes:
+		if got != want {
+			t.Errorf("Expected a batch of %d messages, got %d", want, got)
+		}
+	case <-time.After(within):
+		t.Fatalf("Expected a batch of %d messages within %v", want, within)
+	}
+}
+
+func TestBatchProcessorFullBatches(t *testing.T) {
+	sizes := make(chan int, 10)
+	processor, inputCh, outputCh := startBatchProcessor(t, ProcessorConfig{BatchSize: 3, BatchLinger: time.Hour}, recordingBatcher(sizes))
+
+	for i := 0; i < 6; i++ {
+		inputCh <- models.NewDataMessage([]byte{byte(i)}, "test")
+	}
+	expectBatch(t, sizes, 3, time.Second)
+	expectBatch(t, sizes, 3, time.Second)
+
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Failed to stop processor: %v", err)
+	}
+	if len(outputCh) != 6 {
+		t.Errorf("Expected 6 output messages, got %d", len(outputCh))
+	}
+	stats := processor.GetStats()
+	if stats["batches_processed"] != int64(2) || stats["messages_processed"] != int64(6) {
+		t.Errorf("Expected 2 batches and 6 messages, got %v and %v", stats["batches_processed"], stats["messages_processed"])
+	}
+	if stats["average_batch_size"] != 3.0 || stats["average_batch_fill"] != 1.0 {
+		t.Errorf("Expected full batches in stats, got size %v fill %v", stats["average_batch_size"], stats["average_batch_fill"])
+	}
+}
+
+func TestBatchProcessorLingerFlush(t *testing.T) {
+	sizes := make(chan int, 10)
+	processor, inputCh, _ := startBatchProcessor(t, ProcessorConfig{BatchSize: 10, BatchLinger: 20 * time.Millisecond}, recordingBatcher(sizes))
+	defer processor.Stop()
+
+	inputCh <- models.NewDataMessage([]byte("a"), "test")
+	inputCh <- models.NewDataMessage([]byte("b"), "test")
+	expectBatch(t, sizes, 2, time.Second)
+
+	stats := processor.GetStats()
+	if stats["average_batch_fill"] != 0.2 {
+		t.Errorf("Expected average batch fill 0.2, got %v", stats["average_batch_fill"])
+	}
+}
+
+func TestBatchProcessorShutdownFlush(t *testing.T) {
+	sizes := make(chan int, 10)
+	processor, inputCh, outputCh := startBatchProcessor(t, ProcessorConfig{BatchSize: 10, BatchLinger: time.Hour}, recordingBatcher(sizes))
+
+	inputCh <- models.NewDataMessage([]byte("a"), "test")
+	inputCh <- models.NewDataMessage([]byte("b"), "test")
+	inputCh <- models.NewDataMessage([]byte("c"), "test")
+	// Let the worker take the messages off the channel before stopping
+	deadline := time.Now().Add(time.Second)
+	for len(inputCh) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Failed to stop processor: %v", err)
+	}
+	expectBatch(t, sizes, 3, time.Second)
+	if len(outputCh) != 3 {
+		t.Errorf("Expected the partial batch on the output channel, got %d messages", len(outputCh))
+	}
+}
+
+func TestBatchProcessorControlMessageFlushes(t *testing.T) {
+	sizes := make(chan int, 10)
+	processor, inputCh, outputCh := startBatchProcessor(t, ProcessorConfig{BatchSize: 10, BatchLinger: time.Hour}, recordingBatcher(sizes))
+	defer processor.Stop()
+
+	inputCh <- models.NewDataMessage([]byte("a"), "test")
+	inputCh <- models.NewControlMessage([]byte("ctl"), "test")
+	expectBatch(t, sizes, 1, time.Second)
+
+	for _, want := range []models.ChannelMessageType{models.ChannelMessageTypeData, models.ChannelMessageTypeControl} {
+		select {
+		case msg := <-outputCh:
+			if msg.Type != want {
+				t.Errorf("Expected %v message, got %v", want, msg.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a %v message on the output channel", want)
+		}
+	}
+}
+
+func TestBatchProcessorFailure(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = 100 * time.Millisecond }()
+
+	calls := 0
+	failing := BatchMessageProcessorFunc(func(ctx context.Context, msgs []*models.ChannelMessage) ([]*models.ChannelMessage, error) {
+		calls++
+		return nil, errors.New("boom")
+	})
+	processor, inputCh, outputCh := startBatchProcessor(t, ProcessorConfig{BatchSize: 2, ErrorPolicy: config.ErrorPolicyRetry, MaxRetries: 2}, failing)
+
+	inputCh <- models.NewDataMessage([]byte("a"), "test")
+	inputCh <- models.NewDataMessage([]byte("b"), "test")
+	deadline := time.Now().Add(time.Second)
+	for processor.GetStats()["dropped"] != int64(2) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Failed to stop processor: %v", err)
+	}
+
+	stats := processor.GetStats()
+	if calls != 3 || stats["retries"] != int64(2) || stats["dropped"] != int64(2) {
+		t.Errorf("Expected 3 attempts, 2 retries and 2 dropped messages, got %d, %v and %v", calls, stats["retries"], stats["dropped"])
+	}
+	if len(outputCh) != 0 {
+		t.Errorf("Expected no output for a failed batch, got %d messages", len(outputCh))
+	}
+}
+
+func TestBatchProcessorFallsBackToMessageProcessor(t *testing.T) {
+	processor, inputCh, outputCh := startBatchProcessor(t, ProcessorConfig{BatchSize: 2, BatchLinger: time.Hour}, nil)
+	defer processor.Stop()
+
+	inputCh <- models.NewDataMessage([]byte(`{"id":"1"}`), "test")
+	inputCh <- models.NewDataMessage([]byte(`{"id":"2"}`), "test")
+	for i := 0; i < 2; i++ {
+		select {
+		case <-outputCh:
+		case <-time.After(time.Second):
+			t.Fatalf("Received %d of 2 messages", i)
+		}
+	}
+	if stats := processor.GetStats(); stats["batches_processed"] != int64(1) {
+		t.Errorf("Expected 1 batch, got %v", stats["batches_processed"])
+	}
+}
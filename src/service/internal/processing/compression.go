@@ -0,0 +1,93 @@
+package processing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"servicegomodule/internal/config"
+)
+
+// HeaderContentEncoding is set on published messages whose payload was
+// compressed, naming the compression used
+const HeaderContentEncoding = "content-encoding"
+
+// validateCompression checks the output compression setting
+func (c OutputConfig) validateCompression() error {
+	switch c.Compression {
+	case "", config.CompressionNone, config.CompressionGzip:
+		return nil
+	default:
+		return fmt.Errorf("unknown output compression %q", c.Compression)
+	}
+}
+
+// gzipCompressor compresses published payloads, reusing gzip writers and
+// buffers across messages. It is safe for concurrent use. The methods of a
+// nil compressor do nothing.
+type gzipCompressor struct {
+	writers sync.Pool // *gzip.Writer
+	buffers sync.Pool // *bytes.Buffer
+
+	bytesIn  atomic.Int64 // Payload bytes before compression
+	bytesOut atomic.Int64 // Payload bytes after compression
+}
+
+// newCompressor returns the compressor for a compression setting, or nil
+// when payloads are published uncompressed
+func newCompressor(compression string) *gzipCompressor {
+	if compression != config.CompressionGzip {
+		return nil
+	}
+	return &gzipCompressor{}
+}
+
+// compress returns data gzip compressed
+func (c *gzipCompressor) compress(data []byte) ([]byte, error) {
+	buffer, _ := c.buffers.Get().(*bytes.Buffer)
+	if buffer == nil {
+		buffer = new(bytes.Buffer)
+	}
+	buffer.Reset()
+	defer c.buffers.Put(buffer)
+
+	writer, _ := c.writers.Get().(*gzip.Writer)
+	if writer == nil {
+		writer = gzip.NewWriter(buffer)
+	} else {
+		writer.Reset(buffer)
+	}
+	defer c.writers.Put(writer)
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+
+	// The buffer goes back to the pool, so the result needs its own copy
+	compressed := append([]byte(nil), buffer.Bytes()...)
+	c.bytesIn.Add(int64(len(data)))
+	c.bytesOut.Add(int64(len(compressed)))
+	return compressed, nil
+}
+
+// ratio returns the compressed size as a fraction of the original size, or 0
+// before anything was compressed
+func (c *gzipCompressor) ratio() float64 {
+	if c == nil || c.bytesIn.Load() == 0 {
+		return 0
+	}
+	return float64(c.bytesOut.Load()) / float64(c.bytesIn.Load())
+}
+
+// stats returns the byte counters
+func (c *gzipCompressor) stats() (in, out int64) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.bytesIn.Load(), c.bytesOut.Load()
+}
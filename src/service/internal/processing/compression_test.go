@@ -0,0 +1,99 @@
+package processing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip payload: %v", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress payload: %v", err)
+	}
+	return decompressed
+}
+
+func TestGzipCompressorRoundTrip(t *testing.T) {
+	compressor := newCompressor(config.CompressionGzip)
+	payload := []byte(strings.Repeat(`{"field":"value"},`, 1000))
+
+	// Run concurrently so pooled writers and buffers are shared
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			compressed, err := compressor.compress(payload)
+			if err != nil {
+				t.Errorf("compress() returned error: %v", err)
+				return
+			}
+			if !bytes.Equal(gunzip(t, compressed), payload) {
+				t.Error("Expected the payload to round-trip")
+			}
+		}()
+	}
+	wg.Wait()
+
+	in, out := compressor.stats()
+	if in != int64(8*len(payload)) || out <= 0 || out >= in {
+		t.Errorf("Expected %d bytes in and fewer out, got %d and %d", 8*len(payload), in, out)
+	}
+	if ratio := compressor.ratio(); ratio <= 0 || ratio >= 0.1 {
+		t.Errorf("Expected a small compression ratio for a repetitive payload, got %v", ratio)
+	}
+}
+
+func TestNewCompressorDisabled(t *testing.T) {
+	for _, compression := range []string{"", config.CompressionNone} {
+		if newCompressor(compression) != nil {
+			t.Errorf("Expected no compressor for %q", compression)
+		}
+	}
+	var disabled *gzipCompressor
+	if in, out := disabled.stats(); in != 0 || out != 0 || disabled.ratio() != 0 {
+		t.Error("Expected a nil compressor to report nothing")
+	}
+}
+
+func TestOutputHandlerCompression(t *testing.T) {
+	payload := `{"id":"o-1","notes":"` + strings.Repeat("x", 500) + `"}`
+	for _, compression := range []string{config.CompressionNone, config.CompressionGzip} {
+		t.Run(compression, func(t *testing.T) {
+			producer := &mockProducerForOutput{}
+			handler := NewOutputHandlerWithProducer(OutputConfig{OutputTopic: "out", BatchSize: 1, Compression: compression}, producer, logging.NewNopLogger())
+			if _, err := handler.sendWithRetry(models.NewDataMessage([]byte(payload), "test")); err != nil {
+				t.Fatalf("sendWithRetry() returned error: %v", err)
+			}
+
+			published := producer.messages[0]
+			encoding, marked := published.Headers[HeaderContentEncoding]
+			if compression == config.CompressionNone {
+				if marked || string(published.Value) != payload {
+					t.Errorf("Expected the payload published as is without %s, got headers %v", HeaderContentEncoding, published.Headers)
+				}
+				return
+			}
+			if encoding != config.CompressionGzip || string(gunzip(t, published.Value)) != payload {
+				t.Errorf("Expected a gzip payload marked with %s, got headers %v", HeaderContentEncoding, published.Headers)
+			}
+			stats := handler.GetStats()
+			if stats["compression_bytes_in"] != int64(len(payload)) || stats["compression_bytes_out"] != int64(len(published.Value)) {
+				t.Errorf("Expected the byte counters in the stats, got %v in and %v out", stats["compression_bytes_in"], stats["compression_bytes_out"])
+			}
+		})
+	}
+}
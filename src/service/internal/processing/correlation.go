@@ -0,0 +1,61 @@
+package processing
+
+import (
+	"context"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/utils"
+)
+
+// HeaderCorrelationID is the message header carrying the correlation ID that
+// ties together the log lines a message produces across the pipeline
+const HeaderCorrelationID = "correlation_id"
+
+// correlationIDKey is the context key under which the correlation ID of the
+// message being processed is stored
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID of the message being
+// processed, or an empty string when ctx carries none. MessageProcessor
+// implementations can use it to tag their own log lines.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// correlationIDFromHeaders returns the correlation ID in headers, generating
+// a new one when the header is missing
+func correlationIDFromHeaders(headers map[string]string) (string, error) {
+	if id := headers[HeaderCorrelationID]; id != "" {
+		return id, nil
+	}
+	return utils.NewUUID()
+}
+
+// messageLogger returns logger with the correlation ID of message attached
+func messageLogger(logger logging.Logger, message *models.ChannelMessage) logging.Logger {
+	if message.CorrelationID == "" {
+		return logger
+	}
+	return logger.WithField(HeaderCorrelationID, message.CorrelationID)
+}
+
+// headersWithCorrelationID returns the headers to publish message with: its
+// own headers plus the correlation ID. The message headers are not modified.
+func headersWithCorrelationID(message *models.ChannelMessage) map[string]string {
+	if message.CorrelationID == "" || message.Headers[HeaderCorrelationID] == message.CorrelationID {
+		return message.Headers
+	}
+	headers := make(map[string]string, len(message.Headers)+1)
+	for k, v := range message.Headers {
+		headers[k] = v
+	}
+	headers[HeaderCorrelationID] = message.CorrelationID
+	return headers
+}
@@ -0,0 +1,83 @@
+//go:build local
+
+package processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// TestPipelineCorrelationID pushes a message with a known correlation ID
+// through the pipeline over the local message bus and follows it to the
+// output message, the processor context and the pipeline log
+func TestPipelineCorrelationID(t *testing.T) {
+	correlationID := fmt.Sprintf("corr-%d", time.Now().UnixNano())
+	settings := localConfig("correlation-test")
+	logFile := filepath.Join(t.TempDir(), "pipeline.log")
+	settings.LoggerConfig = logging.LoggerConfig{Level: logging.DebugLevel, FilePath: logFile, LoggerName: "pipeline", ServiceName: "test"}
+
+	seen := make(chan string, 2)
+	pipeline := startLocalPipeline(t, settings, func(pipeline *Pipeline) {
+		pipeline.SetMessageProcessor(MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+			seen <- CorrelationIDFromContext(ctx)
+			return models.NewDataMessage(msg.Data, "test"), nil
+		}))
+	})
+
+	sendMessages(t,
+		&messagebus.Message{Topic: settings.Input.Topics[0], Key: "known", Value: []byte("a"), Headers: map[string]string{HeaderCorrelationID: correlationID}},
+		&messagebus.Message{Topic: settings.Input.Topics[0], Key: "generated", Value: []byte("b")})
+
+	output := subscribe(t, settings.Output.OutputTopic)
+	ids := make(map[string]string)
+	for len(ids) < 2 {
+		message, err := output.Poll(5 * time.Second)
+		if err != nil || message == nil {
+			t.Fatalf("Expected 2 processed messages, got %v (%v)", ids, err)
+		}
+		ids[message.Key] = message.Headers[HeaderCorrelationID]
+	}
+	if ids["known"] != correlationID {
+		t.Errorf("Expected the known correlation ID on the output, got %q", ids["known"])
+	}
+	if ids["generated"] == "" || ids["generated"] == ids["known"] {
+		t.Errorf("Expected a generated correlation ID on the output, got %q", ids["generated"])
+	}
+	for i := 0; i < 2; i++ {
+		if id := <-seen; id != ids["known"] && id != ids["generated"] {
+			t.Errorf("Message processor saw unexpected correlation ID %q", id)
+		}
+	}
+
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	logged, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read the pipeline log: %v", err)
+	}
+	for _, line := range []string{"Received kafka data message", "Processing message", "Message sent successfully"} {
+		if !logContains(string(logged), line, `"correlation_id":"`+correlationID+`"`) {
+			t.Errorf("Expected %q to be logged with the correlation ID", line)
+		}
+	}
+}
+
+// logContains reports whether a line of log mentions both message and field
+func logContains(log, message, field string) bool {
+	for _, line := range strings.Split(log, "\n") {
+		if strings.Contains(line, message) && strings.Contains(line, field) {
+			return true
+		}
+	}
+	return false
+}
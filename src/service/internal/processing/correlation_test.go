@@ -0,0 +1,67 @@
+package processing
+
+import (
+	"context"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestCorrelationIDContext(t *testing.T) {
+	if id := CorrelationIDFromContext(context.Background()); id != "" {
+		t.Errorf("Expected no correlation ID on a bare context, got %q", id)
+	}
+	ctx := WithCorrelationID(context.Background(), "abc-123")
+	if id := CorrelationIDFromContext(ctx); id != "abc-123" {
+		t.Errorf("CorrelationIDFromContext() = %q, want abc-123", id)
+	}
+}
+
+func TestCorrelationIDFromHeaders(t *testing.T) {
+	if id, err := correlationIDFromHeaders(map[string]string{HeaderCorrelationID: "abc-123"}); err != nil || id != "abc-123" {
+		t.Errorf("Expected the header value, got %q (%v)", id, err)
+	}
+
+	first, err := correlationIDFromHeaders(nil)
+	if err != nil || first == "" {
+		t.Fatalf("Expected a generated ID, got %q (%v)", first, err)
+	}
+	if second, _ := correlationIDFromHeaders(nil); second == first {
+		t.Errorf("Expected distinct generated IDs, got %q twice", first)
+	}
+}
+
+func TestHeadersWithCorrelationID(t *testing.T) {
+	message := &models.ChannelMessage{Headers: map[string]string{"trace": "1"}, CorrelationID: "abc-123"}
+	headers := headersWithCorrelationID(message)
+	if headers[HeaderCorrelationID] != "abc-123" || headers["trace"] != "1" {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+	if _, ok := message.Headers[HeaderCorrelationID]; ok {
+		t.Error("Expected the message headers to be left unchanged")
+	}
+
+	if headers := headersWithCorrelationID(&models.ChannelMessage{}); headers != nil {
+		t.Errorf("Expected no headers without a correlation ID, got %v", headers)
+	}
+}
+
+func TestDeadLetterMessageKeepsCorrelationID(t *testing.T) {
+	message := &models.ChannelMessage{Data: []byte("x"), CorrelationID: "abc-123"}
+	deadLetter := newDeadLetterMessage(message, "dead", context.Canceled, 1)
+	if deadLetter.Headers[HeaderCorrelationID] != "abc-123" {
+		t.Errorf("Expected the correlation ID on the dead-letter message, got %v", deadLetter.Headers)
+	}
+}
+
+func TestOutputHandlerWritesCorrelationID(t *testing.T) {
+	producer := &mockProducerForOutput{}
+	handler := NewOutputHandlerWithProducer(OutputConfig{OutputTopic: "out", BatchSize: 1}, producer, logging.NewNopLogger())
+	if _, err := handler.sendWithRetry(&models.ChannelMessage{Data: []byte("x"), CorrelationID: "abc-123"}); err != nil {
+		t.Fatalf("sendWithRetry() returned error: %v", err)
+	}
+	if len(producer.messages) != 1 || producer.messages[0].Headers[HeaderCorrelationID] != "abc-123" {
+		t.Errorf("Expected the correlation ID header on the published message, got %v", producer.messages)
+	}
+}
@@ -0,0 +1,119 @@
+package processing
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// deadLetterAttempts caps the sends of one message to the dead-letter topic,
+// so a dead-letter topic that is itself unavailable cannot stall the pipeline
+const deadLetterAttempts = 3
+
+// Headers added to dead-lettered messages
+const (
+	HeaderDeadLetterError       = "error"
+	HeaderDeadLetterSourceTopic = "source_topic"
+	HeaderDeadLetterAttempts    = "attempts"
+	HeaderDeadLetterFailedAt    = "failed_at"
+)
+
+// deadLetterQueue takes the messages a pipeline stage gives up on. With a
+// producer it publishes them to the dead-letter topic; without one, or when
+// that publish fails too, it drops them with a log line at dropLevel.
+type deadLetterQueue struct {
+	producer  messagebus.Producer
+	topic     string
+	dropLevel logging.Level
+	logger    logging.Logger
+	log       *deadLetterLog // Keeps published dead letters for replay; may be nil
+
+	published atomic.Int64
+	failures  atomic.Int64 // Messages whose dead-letter publish failed
+	dropped   atomic.Int64
+}
+
+// newDeadLetterQueue creates a queue publishing to topic, or one that only
+// drops when topic is empty
+func newDeadLetterQueue(topic string, dropLevel logging.Level, logger logging.Logger) *deadLetterQueue {
+	q := &deadLetterQueue{topic: topic, dropLevel: dropLevel, logger: logger}
+	if topic != "" {
+		q.producer = messagebus.NewProducer("kafka-producer.yaml")
+	}
+	return q
+}
+
+// handle dead-letters message, which failed with cause after the given number
+// of attempts, or drops it. It reports whether the message was dead-lettered.
+func (q *deadLetterQueue) handle(message *models.ChannelMessage, cause error, attempts int) bool {
+	if q.producer == nil {
+		q.drop(message, cause)
+		return false
+	}
+
+	deadLetter := newDeadLetterMessage(message, q.topic, cause, attempts)
+	var err error
+	for attempt := 1; attempt <= deadLetterAttempts; attempt++ {
+		if _, _, err = q.producer.Send(context.Background(), deadLetter); err == nil {
+			q.published.Add(1)
+			q.log.add(deadLetter, message.Topic, cause, attempts)
+			return true
+		}
+	}
+	q.failures.Add(1)
+	messageLogger(q.logger, message).Errorw("Failed to publish to dead-letter topic", "key", message.Key, "topic", q.topic, "attempts", deadLetterAttempts, "error", err)
+	q.drop(message, cause)
+	return false
+}
+
+// enabled reports whether the queue publishes to a dead-letter topic
+func (q *deadLetterQueue) enabled() bool {
+	return q.producer != nil
+}
+
+// drop discards message, logging why it failed
+func (q *deadLetterQueue) drop(message *models.ChannelMessage, cause error) {
+	q.dropped.Add(1)
+	level := q.dropLevel
+	if level > logging.ErrorLevel {
+		// Fatal and panic would take the service down over one message
+		level = logging.ErrorLevel
+	}
+	messageLogger(q.logger, message).Logw(level, "Dropping failed message", "key", message.Key, "source_topic", message.Topic, "size", len(message.Data), "error", cause)
+}
+
+// close closes the producer, if any
+func (q *deadLetterQueue) close() error {
+	if q.producer == nil {
+		return nil
+	}
+	return q.producer.Close()
+}
+
+// newDeadLetterMessage wraps a failed message for the dead-letter topic,
+// keeping its key, payload and headers and recording why, where and when it
+// failed
+func newDeadLetterMessage(message *models.ChannelMessage, topic string, cause error, attempts int) *messagebus.Message {
+	headers := make(map[string]string, len(message.Headers)+5)
+	for k, v := range headersWithCorrelationID(message) {
+		headers[k] = v
+	}
+	headers[HeaderDeadLetterError] = cause.Error()
+	headers[HeaderDeadLetterAttempts] = strconv.Itoa(attempts)
+	headers[HeaderDeadLetterFailedAt] = time.Now().UTC().Format(time.RFC3339Nano)
+	if message.Topic != "" {
+		headers[HeaderDeadLetterSourceTopic] = message.Topic
+	}
+
+	return &messagebus.Message{
+		Topic:   topic,
+		Key:     message.Key,
+		Value:   message.Data,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,60 @@
+//go:build local
+
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"sharedgomodule/messagebus"
+)
+
+// TestPipelineDeadLettersPoisonMessages runs a poison message between two
+// good ones through the pipeline over the local message bus
+func TestPipelineDeadLettersPoisonMessages(t *testing.T) {
+	settings := localConfig("dlq-test")
+	settings.Processor.ErrorPolicy = config.ErrorPolicyDeadLetter
+	settings.Output.DeadLetterTopic = freshTopic("dlq-test-dead")
+	pipeline := startLocalPipeline(t, settings)
+
+	var messages []*messagebus.Message
+	for i, value := range []string{`{"id":"good-1","data":{}}`, `not json`, `{"id":"good-2","data":{}}`} {
+		messages = append(messages, &messagebus.Message{Topic: settings.Input.Topics[0], Key: fmt.Sprintf("k%d", i), Value: []byte(value)})
+	}
+	sendMessages(t, messages...)
+
+	output := subscribe(t, settings.Output.OutputTopic)
+	for _, want := range []string{"good-1", "good-2"} {
+		message, err := output.Poll(5 * time.Second)
+		if err != nil || message == nil {
+			t.Fatalf("No processed message on %s: %v", settings.Output.OutputTopic, err)
+		}
+		var record ProcessingRecord
+		if err := json.Unmarshal(message.Value, &record); err != nil || record.ID != want {
+			t.Errorf("Expected processed record %s, got %s (%v)", want, message.Value, err)
+		}
+	}
+
+	dead := subscribe(t, settings.Output.DeadLetterTopic)
+	message, err := dead.Poll(5 * time.Second)
+	if err != nil || message == nil {
+		t.Fatalf("No message on the dead-letter topic: %v", err)
+	}
+	if message.Key != "k1" || string(message.Value) != "not json" {
+		t.Errorf("Expected the poison message on the dead-letter topic, got key %q value %q", message.Key, message.Value)
+	}
+	if message.Headers[HeaderDeadLetterSourceTopic] != settings.Input.Topics[0] || message.Headers[HeaderDeadLetterAttempts] != "1" ||
+		message.Headers[HeaderDeadLetterError] == "" || message.Headers[HeaderDeadLetterFailedAt] == "" {
+		t.Errorf("Unexpected dead-letter headers %v", message.Headers)
+	}
+
+	if err := pipeline.Stop(); err != nil {
+		t.Errorf("Stop() returned error: %v", err)
+	}
+	if stats := pipeline.processor.GetStats(); stats["dead_lettered"] != int64(1) || stats["messages_processed"] != int64(2) {
+		t.Errorf("Expected 2 processed and 1 dead-lettered, got %v and %v", stats["messages_processed"], stats["dead_lettered"])
+	}
+}
@@ -0,0 +1,66 @@
+package processing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestDeadLetterQueuePublishes(t *testing.T) {
+	producer := &mockProducerForOutput{}
+	queue := &deadLetterQueue{producer: producer, topic: "dlq", dropLevel: logging.ErrorLevel, logger: logging.NewNopLogger()}
+
+	message := models.NewDataMessage([]byte("poison"), "test")
+	message.Key = "k1"
+	message.Topic = "orders"
+	message.Headers = map[string]string{"trace": "t1"}
+	queue.handle(message, errors.New("boom"), 4)
+
+	if len(producer.messages) != 1 {
+		t.Fatalf("Expected 1 dead-lettered message, got %d", len(producer.messages))
+	}
+	dl := producer.messages[0]
+	if dl.Topic != "dlq" || dl.Key != "k1" || string(dl.Value) != "poison" {
+		t.Errorf("Unexpected dead-letter message %+v", dl)
+	}
+	for header, want := range map[string]string{"trace": "t1", HeaderDeadLetterError: "boom", HeaderDeadLetterSourceTopic: "orders", HeaderDeadLetterAttempts: "4"} {
+		if dl.Headers[header] != want {
+			t.Errorf("Expected header %s = %q, got %q", header, want, dl.Headers[header])
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, dl.Headers[HeaderDeadLetterFailedAt]); err != nil {
+		t.Errorf("Expected an RFC 3339 %s header, got %q", HeaderDeadLetterFailedAt, dl.Headers[HeaderDeadLetterFailedAt])
+	}
+	if queue.published.Load() != 1 || queue.dropped.Load() != 0 {
+		t.Errorf("Expected 1 published and none dropped, got %d and %d", queue.published.Load(), queue.dropped.Load())
+	}
+}
+
+func TestDeadLetterQueueCapsAttempts(t *testing.T) {
+	producer := &mockProducerForOutput{sendErr: errors.New("dlq unavailable")}
+	queue := &deadLetterQueue{producer: producer, topic: "dlq", dropLevel: logging.ErrorLevel, logger: logging.NewNopLogger()}
+
+	queue.handle(models.NewDataMessage([]byte("poison"), "test"), errors.New("boom"), 1)
+
+	if producer.attempts != deadLetterAttempts {
+		t.Errorf("Expected %d dead-letter attempts, got %d", deadLetterAttempts, producer.attempts)
+	}
+	if queue.failures.Load() != 1 || queue.dropped.Load() != 1 || queue.published.Load() != 0 {
+		t.Errorf("Expected 1 failure and 1 drop, got %d failures, %d dropped, %d published", queue.failures.Load(), queue.dropped.Load(), queue.published.Load())
+	}
+}
+
+func TestDeadLetterQueueWithoutTopicDrops(t *testing.T) {
+	queue := newDeadLetterQueue("", logging.WarnLevel, logging.NewNopLogger())
+	queue.handle(models.NewDataMessage([]byte("poison"), "test"), errors.New("boom"), 1)
+
+	if queue.dropped.Load() != 1 {
+		t.Errorf("Expected the message to be dropped, got %d", queue.dropped.Load())
+	}
+	if err := queue.close(); err != nil {
+		t.Errorf("close() returned error: %v", err)
+	}
+}
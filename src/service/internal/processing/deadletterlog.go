@@ -0,0 +1,230 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/messagebus"
+)
+
+// Dead letter log limits
+const (
+	deadLetterLogSize     = 100 // Recent dead letters kept for inspection and replay
+	deadLetterPreviewSize = 256 // Payload bytes shown in a DeadLetter preview
+)
+
+// HeaderReplayCount is set on replayed messages to the number of times they
+// have been replayed from the dead-letter topic
+const HeaderReplayCount = "replay_count"
+
+// ErrPipelineNotRunning is returned by ReplayDeadLetters on a pipeline that
+// is not running
+var ErrPipelineNotRunning = errors.New("pipeline is not running")
+
+// failureHeaders are removed from dead letters before they are replayed
+var failureHeaders = []string{
+	HeaderDeadLetterError,
+	HeaderDeadLetterSourceTopic,
+	HeaderDeadLetterAttempts,
+	HeaderDeadLetterFailedAt,
+	HeaderValidationError,
+}
+
+// deadLetterLog keeps the most recent dead letters published by a pipeline,
+// so they can be listed and replayed without consuming the dead-letter
+// topics. It is shared by the processor and output dead-letter queues and
+// carried over by Rebuild. The methods of a nil log do nothing.
+type deadLetterLog struct {
+	mutex   sync.Mutex
+	entries []*deadLetterEntry // Oldest first
+	nextID  uint64
+
+	replayed       atomic.Int64
+	replayFailures atomic.Int64
+}
+
+// deadLetterEntry is a logged dead letter with the message published for it
+type deadLetterEntry struct {
+	letter  models.DeadLetter
+	message *messagebus.Message
+}
+
+// add logs deadLetter, published for a message from sourceTopic, dropping
+// the oldest entry when the log is full
+func (l *deadLetterLog) add(deadLetter *messagebus.Message, sourceTopic string, cause error, attempts int) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.nextID++
+	l.entries = append(l.entries, &deadLetterEntry{
+		letter: models.DeadLetter{
+			ID:             l.nextID,
+			Topic:          deadLetter.Topic,
+			SourceTopic:    sourceTopic,
+			Key:            deadLetter.Key,
+			Error:          cause.Error(),
+			Attempts:       attempts,
+			FailedAt:       time.Now().UTC(),
+			Size:           len(deadLetter.Value),
+			PayloadPreview: payloadPreview(deadLetter.Value),
+		},
+		message: deadLetter,
+	})
+	if len(l.entries) > deadLetterLogSize {
+		l.entries = l.entries[len(l.entries)-deadLetterLogSize:]
+	}
+}
+
+// recent returns up to limit logged dead letters, newest first
+func (l *deadLetterLog) recent(limit int) []models.DeadLetter {
+	letters := []models.DeadLetter{}
+	if l == nil {
+		return letters
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for i := len(l.entries) - 1; i >= 0 && len(letters) < limit; i-- {
+		letters = append(letters, l.entries[i].letter)
+	}
+	return letters
+}
+
+// lookup returns the entry with id, or nil if it is not logged
+func (l *deadLetterLog) lookup(id uint64) *deadLetterEntry {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, entry := range l.entries {
+		if entry.letter.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// remove drops the entry with id from the log
+func (l *deadLetterLog) remove(id uint64) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i, entry := range l.entries {
+		if entry.letter.ID == id {
+			l.entries = append(l.entries[:i:i], l.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// size returns the number of logged dead letters
+func (l *deadLetterLog) size() int {
+	if l == nil {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.entries)
+}
+
+func (l *deadLetterLog) stats() map[string]interface{} {
+	if l == nil {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"logged":          l.size(),
+		"replayed":        l.replayed.Load(),
+		"replay_failures": l.replayFailures.Load(),
+	}
+}
+
+// payloadPreview returns the start of payload as text, cut on a rune
+// boundary
+func payloadPreview(payload []byte) string {
+	if len(payload) <= deadLetterPreviewSize {
+		return strings.ToValidUTF8(string(payload), "�")
+	}
+	cut := deadLetterPreviewSize
+	for cut > 0 && !utf8.RuneStart(payload[cut]) {
+		cut--
+	}
+	return strings.ToValidUTF8(string(payload[:cut]), "�") + "..."
+}
+
+// replayMessage rebuilds the original message from a dead letter: it goes
+// to its source topic with the failure headers removed and the replay count
+// raised
+func replayMessage(entry *deadLetterEntry) *messagebus.Message {
+	headers := make(map[string]string, len(entry.message.Headers))
+	for k, v := range entry.message.Headers {
+		headers[k] = v
+	}
+	for _, header := range failureHeaders {
+		delete(headers, header)
+	}
+	replays, _ := strconv.Atoi(headers[HeaderReplayCount])
+	headers[HeaderReplayCount] = strconv.Itoa(replays + 1)
+
+	return &messagebus.Message{
+		Topic:   entry.letter.SourceTopic,
+		Key:     entry.message.Key,
+		Value:   entry.message.Value,
+		Headers: headers,
+	}
+}
+
+// DeadLetters returns up to limit of the most recent dead letters the
+// pipeline published, newest first. Only the last 100 are kept, and
+// replayed ones are removed.
+func (p *Pipeline) DeadLetters(limit int) []models.DeadLetter {
+	return p.deadLetters.recent(limit)
+}
+
+// ReplayDeadLetters republishes the logged dead letters with the given IDs
+// to their source topics through the output producer, so they are consumed
+// and processed again. Replayed dead letters are removed from the log; ones
+// that fail stay for another attempt. It returns ErrPipelineNotRunning
+// unless the pipeline is running.
+func (p *Pipeline) ReplayDeadLetters(ctx context.Context, ids []uint64) (models.DeadLetterReplayResult, error) {
+	result := models.DeadLetterReplayResult{Replayed: []uint64{}, NotFound: []uint64{}, Failed: map[uint64]string{}}
+	if state := p.state.Load().(PipelineState); state != PipelineRunning && state != PipelineDegraded {
+		return result, fmt.Errorf("cannot replay dead letters while the pipeline is %s: %w", state, ErrPipelineNotRunning)
+	}
+
+	for _, id := range ids {
+		entry := p.deadLetters.lookup(id)
+		switch {
+		case entry == nil:
+			result.NotFound = append(result.NotFound, id)
+		case entry.letter.SourceTopic == "":
+			result.Failed[id] = "dead letter has no source topic"
+		default:
+			message := replayMessage(entry)
+			if _, _, err := p.outputHandler.producer.Send(ctx, message); err != nil {
+				p.deadLetters.replayFailures.Add(1)
+				p.logger.Warnw("Failed to replay dead letter", "id", id, "topic", message.Topic, "error", err)
+				result.Failed[id] = err.Error()
+				continue
+			}
+			p.deadLetters.remove(id)
+			p.deadLetters.replayed.Add(1)
+			p.logger.Infow("Replayed dead letter", "id", id, "topic", message.Topic, "key", message.Key, "replays", message.Headers[HeaderReplayCount])
+			result.Replayed = append(result.Replayed, id)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package processing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/messagebus"
+)
+
+func TestDeadLetterLog(t *testing.T) {
+	log := &deadLetterLog{}
+	for i := 0; i < deadLetterLogSize+5; i++ {
+		log.add(&messagebus.Message{Topic: "dlq", Key: fmt.Sprintf("k%d", i), Value: []byte("{}")}, "orders", errors.New("bad"), 1)
+	}
+
+	if n := log.size(); n != deadLetterLogSize {
+		t.Fatalf("Expected the log to hold %d dead letters, got %d", deadLetterLogSize, n)
+	}
+	recent := log.recent(2)
+	if len(recent) != 2 || recent[0].ID != deadLetterLogSize+5 || recent[1].ID != deadLetterLogSize+4 {
+		t.Fatalf("Expected the newest two dead letters first, got %+v", recent)
+	}
+	if recent[0].SourceTopic != "orders" || recent[0].Topic != "dlq" || recent[0].Error != "bad" || recent[0].PayloadPreview != "{}" {
+		t.Errorf("Unexpected dead letter %+v", recent[0])
+	}
+	if log.lookup(1) != nil {
+		t.Error("Expected the oldest dead letters to be dropped")
+	}
+
+	log.remove(recent[0].ID)
+	if log.lookup(recent[0].ID) != nil || log.size() != deadLetterLogSize-1 {
+		t.Error("Expected the removed dead letter to leave the log")
+	}
+
+	var missing *deadLetterLog
+	missing.add(&messagebus.Message{}, "orders", errors.New("bad"), 1)
+	if letters := missing.recent(5); letters == nil || len(letters) != 0 {
+		t.Errorf("Expected an empty list from a nil log, got %v", letters)
+	}
+}
+
+func TestPayloadPreview(t *testing.T) {
+	if got := payloadPreview([]byte("short")); got != "short" {
+		t.Errorf("payloadPreview() = %q, want %q", got, "short")
+	}
+	// A multi-byte rune straddling the cut is left out whole
+	long := strings.Repeat("a", deadLetterPreviewSize-1) + "é" + "tail"
+	if got := payloadPreview([]byte(long)); got != strings.Repeat("a", deadLetterPreviewSize-1)+"..." {
+		t.Errorf("payloadPreview() = %q", got)
+	}
+}
+
+func TestReplayMessage(t *testing.T) {
+	failed := &models.ChannelMessage{
+		Topic:   "orders",
+		Key:     "order-1",
+		Data:    []byte("{}"),
+		Headers: map[string]string{"trace": "abc", HeaderValidationError: "invalid"},
+	}
+	entry := &deadLetterEntry{message: newDeadLetterMessage(failed, "dlq", errors.New("bad"), 2)}
+	entry.letter.SourceTopic = "orders"
+
+	message := replayMessage(entry)
+	if message.Topic != "orders" || message.Key != "order-1" || string(message.Value) != "{}" {
+		t.Errorf("Expected the original message back on its source topic, got %+v", message)
+	}
+	for _, header := range failureHeaders {
+		if _, ok := message.Headers[header]; ok {
+			t.Errorf("Expected header %s to be stripped, got %v", header, message.Headers)
+		}
+	}
+	if message.Headers["trace"] != "abc" || message.Headers[HeaderReplayCount] != "1" {
+		t.Errorf("Expected the original headers and a replay count of 1, got %v", message.Headers)
+	}
+
+	entry.message = message
+	if again := replayMessage(entry); again.Headers[HeaderReplayCount] != "2" {
+		t.Errorf("Expected the replay count to go up, got %v", again.Headers)
+	}
+}
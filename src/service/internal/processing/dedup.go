@@ -0,0 +1,143 @@
+package processing
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"servicegomodule/internal/models"
+)
+
+// defaultDedupMaxEntries caps the duplicate cache when no limit is set
+const defaultDedupMaxEntries = 10000
+
+// dedupEnabled reports whether duplicate messages are suppressed
+func (c ProcessorConfig) dedupEnabled() bool {
+	return c.DedupWindow > 0
+}
+
+// dedupMaxEntries returns the most message identities the duplicate cache
+// holds
+func (c ProcessorConfig) dedupMaxEntries() int {
+	if c.DedupMaxEntries <= 0 {
+		return defaultDedupMaxEntries
+	}
+	return c.DedupMaxEntries
+}
+
+// validateDedup checks the duplicate suppression settings
+func (c ProcessorConfig) validateDedup() error {
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("processor dedup window must not be negative")
+	}
+	if c.DedupMaxEntries < 0 {
+		return fmt.Errorf("processor dedup max entries must not be negative")
+	}
+	return nil
+}
+
+// dedupCache remembers the identities of recent messages for window,
+// holding at most maxEntries of them; once full it forgets the one seen
+// longest ago. It is safe for concurrent use by the processor workers.
+type dedupCache struct {
+	window     time.Duration
+	maxEntries int
+	header     string // Header holding the message identity; empty uses the key
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   list.List // *dedupEntry, least recently seen first
+
+	duplicates atomic.Int64
+	now        func() time.Time // Replaced in tests
+}
+
+type dedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+func newDedupCache(settings ProcessorConfig) *dedupCache {
+	return &dedupCache{
+		window:     settings.DedupWindow,
+		maxEntries: settings.dedupMaxEntries(),
+		header:     settings.DedupHeader,
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// identity returns the value messages are deduplicated on, or "" if message
+// has none
+func (c *dedupCache) identity(message *models.ChannelMessage) string {
+	if c.header != "" {
+		return message.Headers[c.header]
+	}
+	return message.Key
+}
+
+// duplicate records message and reports whether a message with the same
+// identity was already recorded within the window. Messages without an
+// identity are never duplicates. The methods of a nil cache do nothing.
+func (c *dedupCache) duplicate(message *models.ChannelMessage) bool {
+	if c == nil {
+		return false
+	}
+	id := c.identity(message)
+	if id == "" {
+		return false
+	}
+	now := c.now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[id]; ok {
+		entry := element.Value.(*dedupEntry)
+		if now.Sub(entry.seen) < c.window {
+			c.duplicates.Add(1)
+			return true
+		}
+		// Seen too long ago to count; start a new window
+		entry.seen = now
+		c.order.MoveToBack(element)
+		return false
+	}
+
+	c.entries[id] = c.order.PushBack(&dedupEntry{id: id, seen: now})
+	c.evict(now)
+	return false
+}
+
+// evict drops expired entries and, past maxEntries, the least recently seen
+// ones. Entries are ordered by when they were seen, so both come off the
+// front.
+func (c *dedupCache) evict(now time.Time) {
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		entry := front.Value.(*dedupEntry)
+		if c.order.Len() <= c.maxEntries && now.Sub(entry.seen) < c.window {
+			return
+		}
+		c.order.Remove(front)
+		delete(c.entries, entry.id)
+	}
+}
+
+// dropped returns the number of duplicates found
+func (c *dedupCache) dropped() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.duplicates.Load()
+}
+
+// size returns the number of identities held
+func (c *dedupCache) size() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
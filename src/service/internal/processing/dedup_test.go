@@ -0,0 +1,161 @@
+package processing
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// newTestDedupCache returns a dedup cache whose clock is read from *now
+func newTestDedupCache(settings ProcessorConfig, now *time.Time) *dedupCache {
+	cache := newDedupCache(settings)
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func dedupMessage(key string) *models.ChannelMessage {
+	message := models.NewDataMessage([]byte(`{}`), "test")
+	message.Key = key
+	return message
+}
+
+func TestDedupCacheWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newTestDedupCache(ProcessorConfig{DedupWindow: time.Minute}, &now)
+
+	if cache.duplicate(dedupMessage("a")) {
+		t.Fatal("Expected the first message not to be a duplicate")
+	}
+	now = now.Add(59 * time.Second)
+	if !cache.duplicate(dedupMessage("a")) {
+		t.Error("Expected an exact duplicate inside the window to be dropped")
+	}
+	if cache.duplicate(dedupMessage("b")) {
+		t.Error("Expected a different key not to be a duplicate")
+	}
+
+	// The window runs from the first delivery; duplicates don't extend it
+	now = now.Add(time.Second)
+	if cache.duplicate(dedupMessage("a")) {
+		t.Error("Expected an exact duplicate outside the window to pass")
+	}
+	now = now.Add(time.Second)
+	if !cache.duplicate(dedupMessage("a")) {
+		t.Error("Expected a message passed after the window to start a new one")
+	}
+	if cache.dropped() != 2 {
+		t.Errorf("Expected 2 duplicates counted, got %d", cache.dropped())
+	}
+}
+
+func TestDedupCacheMaxEntries(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newTestDedupCache(ProcessorConfig{DedupWindow: time.Hour, DedupMaxEntries: 2}, &now)
+
+	for _, key := range []string{"a", "b", "c"} {
+		cache.duplicate(dedupMessage(key))
+		now = now.Add(time.Second)
+	}
+	if cache.size() != 2 {
+		t.Errorf("Expected the cache capped at 2 entries, got %d", cache.size())
+	}
+	if !cache.duplicate(dedupMessage("c")) {
+		t.Error("Expected a recent key to still be remembered")
+	}
+	if cache.duplicate(dedupMessage("a")) {
+		t.Error("Expected the least recently seen key to have been forgotten")
+	}
+}
+
+func TestDedupCacheExpiresEntries(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newTestDedupCache(ProcessorConfig{DedupWindow: time.Second}, &now)
+
+	cache.duplicate(dedupMessage("a"))
+	cache.duplicate(dedupMessage("b"))
+	now = now.Add(2 * time.Second)
+	cache.duplicate(dedupMessage("c"))
+	if cache.size() != 1 {
+		t.Errorf("Expected expired entries to be dropped, got %d entries", cache.size())
+	}
+}
+
+func TestDedupCacheIdentity(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := newTestDedupCache(ProcessorConfig{DedupWindow: time.Minute, DedupHeader: "message-id"}, &now)
+
+	first := dedupMessage("a")
+	first.Headers = map[string]string{"message-id": "1"}
+	second := dedupMessage("b")
+	second.Headers = map[string]string{"message-id": "1"}
+	if cache.duplicate(first) || !cache.duplicate(second) {
+		t.Error("Expected messages with the same header value to be duplicates regardless of key")
+	}
+	if cache.duplicate(dedupMessage("a")) || cache.duplicate(dedupMessage("a")) {
+		t.Error("Expected messages without the header never to be duplicates")
+	}
+
+	var nilCache *dedupCache
+	if nilCache.duplicate(first) || nilCache.size() != 0 || nilCache.dropped() != 0 {
+		t.Error("Expected a nil cache to do nothing")
+	}
+}
+
+func TestProcessorDropsDuplicates(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 4)
+	outputCh := make(chan *models.ChannelMessage, 4)
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1, Concurrency: 2, DedupWindow: time.Minute}, logging.NewNopLogger(), inputCh, outputCh, MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return msg, nil
+	}))
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	defer processor.Stop()
+
+	for _, key := range []string{"a", "a", "b", "a"} {
+		inputCh <- dedupMessage(key)
+	}
+	seen := map[string]int{}
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-outputCh:
+			seen[message.Key]++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected 2 messages on the output channel, got %v", seen)
+		}
+	}
+	select {
+	case message := <-outputCh:
+		t.Errorf("Expected duplicates to be dropped, got another message with key %q", message.Key)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if seen["a"] != 1 || seen["b"] != 1 {
+		t.Errorf("Expected one message per key, got %v", seen)
+	}
+
+	stats := processor.GetStats()
+	if stats["duplicates_dropped"] != int64(2) || stats["dedup_entries"] != 2 {
+		t.Errorf("Expected 2 duplicates dropped and 2 entries, got %v and %v", stats["duplicates_dropped"], stats["dedup_entries"])
+	}
+}
+
+func BenchmarkDedupCache(b *testing.B) {
+	cache := newDedupCache(ProcessorConfig{DedupWindow: time.Minute})
+	messages := make([]*models.ChannelMessage, 4*defaultDedupMaxEntries)
+	for i := range messages {
+		messages[i] = dedupMessage(strconv.Itoa(i))
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.duplicate(messages[i%len(messages)])
+			i++
+		}
+	})
+}
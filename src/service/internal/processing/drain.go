@@ -0,0 +1,101 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultDrainTimeout is used when ChannelConfig.DrainTimeout is not set
+const defaultDrainTimeout = 5 * time.Second
+
+// drainPollInterval is how often Stop checks whether the input channel is empty
+const drainPollInterval = 10 * time.Millisecond
+
+// DrainTimeoutError is returned by Pipeline.Stop when the input channel did
+// not empty within the drain timeout. The abandoned messages were consumed
+// but never processed.
+type DrainTimeoutError struct {
+	Timeout   time.Duration
+	Abandoned int
+}
+
+func (e *DrainTimeoutError) Error() string {
+	return fmt.Sprintf("drain timed out after %s with %d messages abandoned", e.Timeout, e.Abandoned)
+}
+
+// ShutdownTimeoutError is returned by Pipeline.StopContext when the context
+// is done before the pipeline stopped. InFlight messages were consumed but
+// their offsets not committed, so they are redelivered after a restart.
+type ShutdownTimeoutError struct {
+	InFlight int
+	Err      error
+}
+
+func (e *ShutdownTimeoutError) Error() string {
+	return fmt.Sprintf("pipeline did not stop in time with %d messages in flight: %v", e.InFlight, e.Err)
+}
+
+func (e *ShutdownTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// drainTimeout returns how long Stop waits for the input channel to empty
+func (c ChannelConfig) drainTimeout() time.Duration {
+	if c.DrainTimeout <= 0 {
+		return defaultDrainTimeout
+	}
+	return c.DrainTimeout
+}
+
+// DrainTimeout returns how long Stop waits for queued input to be processed
+func (p *Pipeline) DrainTimeout() time.Duration {
+	return p.config.Channels.drainTimeout()
+}
+
+// undrained returns the number of messages waiting for a processor worker,
+// on the input channel or in the worker queues of ordered-by-key mode
+func (p *Pipeline) undrained() int {
+	return len(p.inputHandler.inputCh) + int(p.processor.queued.Load())
+}
+
+// awaitInputDrained waits until the processor workers have taken every
+// message off the input channel and their queues, or timeout passes. It
+// reports whether everything was taken.
+func (p *Pipeline) awaitInputDrained(timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for p.undrained() > 0 {
+		select {
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// StopContext stops the pipeline like Stop, but gives up waiting once ctx is
+// done and returns a *ShutdownTimeoutError with the number of messages still
+// in flight. The stop carries on in the background, with the message
+// handlers' context cancelled.
+func (p *Pipeline) StopContext(ctx context.Context) error {
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- p.Stop()
+	}()
+
+	select {
+	case err := <-stopped:
+		return err
+	case <-ctx.Done():
+		// Out of time, so stop waiting for the message handlers too
+		p.processor.cancel()
+		inFlight := p.inputHandler.offsets.uncommitted()
+		p.logger.Errorw("Pipeline did not stop in time", "in_flight", inFlight, "error", ctx.Err())
+		return &ShutdownTimeoutError{InFlight: inFlight, Err: ctx.Err()}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build local
+
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sharedgomodule/messagebus"
+)
+
+// blockedProducer holds every Send until release is closed, like a broker
+// that stopped answering
+type blockedProducer struct {
+	mockProducerForOutput
+	release chan struct{}
+}
+
+func (b *blockedProducer) Send(ctx context.Context, message *messagebus.Message) (int32, int64, error) {
+	<-b.release
+	return b.mockProducerForOutput.Send(ctx, message)
+}
+
+func TestPipelineStopContextReportsInFlightMessages(t *testing.T) {
+	producer := &blockedProducer{release: make(chan struct{})}
+	pipeline, topic := startOffsetsPipeline(t, "shutdown-test", producer)
+	t.Cleanup(func() { close(producer.release) })
+	sendRecords(t, topic, 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pipeline.inputHandler.offsets.uncommitted() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the record to be consumed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := pipeline.StopContext(ctx)
+	var timeoutErr *ShutdownTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected a ShutdownTimeoutError, got %v", err)
+	}
+	if timeoutErr.InFlight != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected 1 message in flight after the deadline, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// newDrainTestPipeline returns a pipeline reading from an empty topic and
+// publishing to a mock producer, with handler as its message processor
+func newDrainTestPipeline(t *testing.T, drainTimeout time.Duration, handler MessageProcessorFunc) (*Pipeline, *mockProducerForOutput) {
+	t.Helper()
+	settings := DefaultConfig(nil)
+	settings.Input.Topics = []string{fmt.Sprintf("drain-test-%d", time.Now().UnixNano())}
+	settings.Input.PollTimeout = 10 * time.Millisecond
+	settings.Channels.DrainTimeout = drainTimeout
+
+	pipeline := NewPipeline(settings, logging.NewNopLogger())
+	producer := &mockProducerForOutput{}
+	pipeline.outputHandler.producer = producer
+	pipeline.SetMessageProcessor(handler)
+	return pipeline, producer
+}
+
+// fillInput queues count data messages on the pipeline input channel
+func fillInput(pipeline *Pipeline, count int) {
+	for i := 0; i < count; i++ {
+		pipeline.inputHandler.inputCh <- models.NewDataMessage([]byte(fmt.Sprintf("m%d", i)), "test")
+	}
+}
+
+func TestPipelineStopDrainsQueuedMessages(t *testing.T) {
+	pipeline, producer := newDrainTestPipeline(t, 5*time.Second, func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		time.Sleep(time.Millisecond)
+		return models.NewDataMessage(msg.Data, "test"), nil
+	})
+	fillInput(pipeline, 100)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	// The output batch size is 50 with a 5s flush timeout, so the last
+	// messages only reach the producer through the final flush
+	if len(producer.messages) != 100 {
+		t.Errorf("Expected all 100 queued messages to be published, got %d", len(producer.messages))
+	}
+}
+
+func TestPipelineStopReportsAbandonedMessages(t *testing.T) {
+	release := make(chan struct{})
+	pipeline, producer := newDrainTestPipeline(t, 20*time.Millisecond, func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		<-release
+		return models.NewDataMessage(msg.Data, "test"), nil
+	})
+	fillInput(pipeline, 10)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	// Unblock the processor once the drain has timed out
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+	}()
+	err := pipeline.Stop()
+
+	var drainErr *DrainTimeoutError
+	if !errors.As(err, &drainErr) {
+		t.Fatalf("Expected a DrainTimeoutError, got %v", err)
+	}
+	if drainErr.Abandoned == 0 || drainErr.Abandoned+len(producer.messages) != 10 {
+		t.Errorf("Expected published and abandoned messages to add up to 10, got %d and %d", len(producer.messages), drainErr.Abandoned)
+	}
+	if drainErr.Timeout != 20*time.Millisecond {
+		t.Errorf("Expected the configured timeout in the error, got %v", drainErr.Timeout)
+	}
+}
+
+func TestChannelConfigDrainTimeout(t *testing.T) {
+	if timeout := (ChannelConfig{}).drainTimeout(); timeout != defaultDrainTimeout {
+		t.Errorf("Expected an unset drain timeout to default to %v, got %v", defaultDrainTimeout, timeout)
+	}
+	if timeout := (ChannelConfig{DrainTimeout: time.Second}).drainTimeout(); timeout != time.Second {
+		t.Errorf("Expected the configured drain timeout, got %v", timeout)
+	}
+}
+
+func TestPipelineStopContext(t *testing.T) {
+	pipeline, _ := newDrainTestPipeline(t, time.Second, func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return models.NewDataMessage(msg.Data, "test"), nil
+	})
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pipeline.StopContext(ctx); err != nil {
+		t.Errorf("StopContext() returned error: %v", err)
+	}
+	if state := pipeline.Metrics().State; state != PipelineStopped {
+		t.Errorf("Expected the pipeline to be stopped, got %s", state)
+	}
+}
+
+func TestPipelineStopFinishesInFlightMessage(t *testing.T) {
+	started, seen := make(chan struct{}), make(chan error, 1)
+	pipeline, producer := newDrainTestPipeline(t, 5*time.Second, func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		seen <- ctx.Err()
+		return models.NewDataMessage(msg.Data, "test"), nil
+	})
+	fillInput(pipeline, 1)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	<-started
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if err := <-seen; err != nil {
+		t.Errorf("Expected the in-flight message to keep a live context, got %v", err)
+	}
+	if len(producer.messages) != 1 {
+		t.Errorf("Expected the in-flight message to be published, got %d", len(producer.messages))
+	}
+}
+
+func TestPipelineStopCancelsHandlersAfterDrainTimeout(t *testing.T) {
+	started := make(chan struct{})
+	pipeline, producer := newDrainTestPipeline(t, 50*time.Millisecond, func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	fillInput(pipeline, 1)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	<-started
+	stopped := make(chan error, 1)
+	go func() { stopped <- pipeline.Stop() }()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Stop to cancel the handler once the drain timeout passed")
+	}
+	if len(producer.messages) != 0 {
+		t.Errorf("Expected nothing published, got %d", len(producer.messages))
+	}
+}
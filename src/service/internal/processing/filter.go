@@ -0,0 +1,202 @@
+package processing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"ruleenginelib"
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+)
+
+// ErrInvalidRules is returned when a filter rules file cannot be read or
+// parsed
+var ErrInvalidRules = errors.New("invalid filter rules")
+
+// FilterConfig holds the rule-based message filter settings
+type FilterConfig struct {
+	RulesFile string // JSON rule blocks in the ruleenginelib format; empty disables the filter
+	Mode      string // config.FilterModeDrop or FilterModePass; empty means drop
+}
+
+// validate checks the filter mode
+func (c FilterConfig) validate() error {
+	switch c.Mode {
+	case "", config.FilterModeDrop, config.FilterModePass:
+		return nil
+	default:
+		return fmt.Errorf("unknown filter mode %q", c.Mode)
+	}
+}
+
+// messageFilter decides which data messages reach the message processor by
+// evaluating their decoded JSON payload against a set of rules. Without a
+// rules file every message passes.
+type messageFilter struct {
+	// mutex guards the settings and serializes evaluation, since
+	// ruleenginelib keeps its evaluation options in a package variable
+	mutex    sync.Mutex
+	settings FilterConfig
+	engine   *ruleenginelib.RuleEngine // nil when the filter is disabled
+	rules    int
+
+	evaluated atomic.Int64
+	matched   atomic.Int64
+	dropped   atomic.Int64
+	invalid   atomic.Int64 // Payloads that could not be evaluated
+}
+
+// load replaces the filter settings and rules with those of settings. On
+// error the previous rules stay in effect. It returns the number of rule
+// blocks loaded.
+func (f *messageFilter) load(settings FilterConfig) (int, error) {
+	if err := settings.validate(); err != nil {
+		return 0, err
+	}
+	var engine *ruleenginelib.RuleEngine
+	rules := 0
+	if settings.RulesFile != "" {
+		var err error
+		if engine, rules, err = loadRules(settings.RulesFile); err != nil {
+			return 0, err
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.settings = settings
+	f.engine = engine
+	f.rules = rules
+	return rules, nil
+}
+
+// loadRules reads a rules file holding a rule block or a list of them into a
+// new rule engine
+func loadRules(path string) (*ruleenginelib.RuleEngine, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	var blocks []ruleenginelib.RuleBlock
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var block ruleenginelib.RuleBlock
+		err = json.Unmarshal(data, &block)
+		blocks = append(blocks, block)
+	} else {
+		err = json.Unmarshal(data, &blocks)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s: %v", ErrInvalidRules, path, err)
+	}
+
+	engine := ruleenginelib.NewRuleEngineInstance(nil)
+	for i, block := range blocks {
+		if err := validateRuleBlock(block); err != nil {
+			return nil, 0, fmt.Errorf("%w: %s: rule block %d: %v", ErrInvalidRules, path, i, err)
+		}
+		id := block.UUID
+		if id == "" {
+			id = fmt.Sprintf("rule-%d", i)
+		}
+		if _, exists := engine.RuleMap[id]; exists {
+			return nil, 0, fmt.Errorf("%w: %s: duplicate rule uuid %q", ErrInvalidRules, path, id)
+		}
+		engine.RuleMap[id] = block
+	}
+	return engine, len(blocks), nil
+}
+
+// validateRuleBlock rejects the conditions ruleenginelib would panic on for
+// every message: missing values and unknown operators
+func validateRuleBlock(block ruleenginelib.RuleBlock) error {
+	for _, entry := range block.RuleEntries {
+		if entry == nil {
+			return errors.New("empty rule entry")
+		}
+		conditionals := append(append([]ruleenginelib.AstConditional(nil), entry.Condition.Any...), entry.Condition.All...)
+		for _, conditional := range conditionals {
+			if conditional.Value == nil {
+				return fmt.Errorf("condition on %q has no value", conditional.Fact)
+			}
+			// Every operator accepts two numbers, so only an unknown one fails
+			if _, err := ruleenginelib.EvaluateOperator(0, 0, conditional.Operator); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keep reports whether message passes the filter. A payload that is not a
+// JSON object, or that the rules cannot be evaluated against, is an error.
+func (f *messageFilter) keep(message *models.ChannelMessage) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.engine == nil {
+		return true, nil
+	}
+
+	f.evaluated.Add(1)
+	var data ruleenginelib.Data
+	if err := json.Unmarshal(message.Data, &data); err != nil || data == nil {
+		f.invalid.Add(1)
+		return false, fmt.Errorf("filter: payload is not a JSON object: %v", err)
+	}
+	matched, err := f.evaluate(data)
+	if err != nil {
+		f.invalid.Add(1)
+		return false, err
+	}
+
+	if matched {
+		f.matched.Add(1)
+	}
+	keep := matched == (f.settings.Mode == config.FilterModePass)
+	if !keep {
+		f.dropped.Add(1)
+	}
+	return keep, nil
+}
+
+// evaluate runs the rules against data, turning a panic in ruleenginelib,
+// such as comparing a string with a number, into an error
+func (f *messageFilter) evaluate(data ruleenginelib.Data) (matched bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("filter: evaluating rules: %v", r)
+		}
+	}()
+	matched, _, _ = f.engine.EvaluateRules(data)
+	return matched, nil
+}
+
+// stats returns the filter settings and counters
+func (f *messageFilter) stats() map[string]interface{} {
+	f.mutex.Lock()
+	settings, rules := f.settings, f.rules
+	f.mutex.Unlock()
+
+	mode := settings.Mode
+	if mode == "" {
+		mode = config.FilterModeDrop
+	}
+	return map[string]interface{}{
+		"enabled":    settings.RulesFile != "",
+		"rules_file": settings.RulesFile,
+		"mode":       mode,
+		"rules":      rules,
+		"evaluated":  f.evaluated.Load(),
+		"matched":    f.matched.Load(),
+		"dropped":    f.dropped.Load(),
+		"invalid":    f.invalid.Load(),
+	}
+}
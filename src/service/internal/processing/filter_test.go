@@ -0,0 +1,183 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// noiseRules matches payloads whose type is "noise"
+const noiseRules = `{"uuid":"noise","payload":[{"condition":{"all":[{"identifier":"type","operator":"eq","value":"noise"}]}}]}`
+
+// writeRulesFile writes rules to a file in a temporary directory
+func writeRulesFile(t *testing.T, rules string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+	return path
+}
+
+func TestMessageFilterKeep(t *testing.T) {
+	rulesFile := writeRulesFile(t, noiseRules)
+	tests := []struct {
+		name    string
+		mode    string
+		payload string
+		keep    bool
+		wantErr bool
+	}{
+		{"drop mode match", config.FilterModeDrop, `{"type":"noise"}`, false, false},
+		{"drop mode no match", config.FilterModeDrop, `{"type":"order"}`, true, false},
+		{"pass mode match", config.FilterModePass, `{"type":"noise"}`, true, false},
+		{"pass mode no match", config.FilterModePass, `{"type":"order"}`, false, false},
+		{"default mode drops matches", "", `{"type":"noise"}`, false, false},
+		{"invalid JSON", config.FilterModeDrop, `not json`, false, true},
+		{"JSON array", config.FilterModeDrop, `[1,2]`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &messageFilter{}
+			if _, err := filter.load(FilterConfig{RulesFile: rulesFile, Mode: tt.mode}); err != nil {
+				t.Fatalf("load() returned error: %v", err)
+			}
+			keep, err := filter.keep(models.NewDataMessage([]byte(tt.payload), "test"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("keep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if keep != tt.keep {
+				t.Errorf("keep() = %v, want %v", keep, tt.keep)
+			}
+		})
+	}
+}
+
+func TestMessageFilterDisabled(t *testing.T) {
+	for _, filter := range []*messageFilter{nil, {}} {
+		if keep, err := filter.keep(models.NewDataMessage([]byte(`not json`), "test")); !keep || err != nil {
+			t.Errorf("Expected a disabled filter to keep everything, got %v, %v", keep, err)
+		}
+	}
+}
+
+func TestMessageFilterEvaluationPanic(t *testing.T) {
+	rules := `{"uuid":"level","payload":[{"condition":{"all":[{"identifier":"level","operator":"gt","value":3}]}}]}`
+	filter := &messageFilter{}
+	if _, err := filter.load(FilterConfig{RulesFile: writeRulesFile(t, rules)}); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+
+	// Comparing a string with a number makes ruleenginelib panic
+	if _, err := filter.keep(models.NewDataMessage([]byte(`{"level":"high"}`), "test")); err == nil {
+		t.Error("Expected an error for a payload the rules cannot be evaluated against")
+	}
+	if stats := filter.stats(); stats["invalid"] != int64(1) {
+		t.Errorf("Expected one invalid payload in stats, got %v", stats["invalid"])
+	}
+}
+
+func TestMessageFilterLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		count int
+	}{
+		{"single block", noiseRules, 1},
+		{"list of blocks", `[` + noiseRules + `,{"payload":[]}]`, 2},
+		{"malformed JSON", `{"uuid":`, 0},
+		{"unknown operator", `{"payload":[{"condition":{"all":[{"identifier":"a","operator":"like","value":"x"}]}}]}`, 0},
+		{"missing value", `{"payload":[{"condition":{"any":[{"identifier":"a","operator":"eq"}]}}]}`, 0},
+		{"duplicate uuid", `[` + noiseRules + `,` + noiseRules + `]`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &messageFilter{}
+			count, err := filter.load(FilterConfig{RulesFile: writeRulesFile(t, tt.rules)})
+			if tt.count == 0 {
+				if !errors.Is(err, ErrInvalidRules) {
+					t.Errorf("Expected ErrInvalidRules, got %v", err)
+				}
+				return
+			}
+			if err != nil || count != tt.count {
+				t.Errorf("load() = %d, %v, want %d rule blocks", count, err, tt.count)
+			}
+		})
+	}
+
+	if _, err := (&messageFilter{}).load(FilterConfig{RulesFile: "/nonexistent/rules.json"}); !errors.Is(err, ErrInvalidRules) {
+		t.Errorf("Expected ErrInvalidRules for a missing file, got %v", err)
+	}
+	if _, err := (&messageFilter{}).load(FilterConfig{Mode: "keep"}); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestMessageFilterReloadKeepsRulesOnError(t *testing.T) {
+	filter := &messageFilter{}
+	settings := FilterConfig{RulesFile: writeRulesFile(t, noiseRules)}
+	if _, err := filter.load(settings); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+	if err := os.WriteFile(settings.RulesFile, []byte(`{"uuid":`), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite rules file: %v", err)
+	}
+	if _, err := filter.load(settings); err == nil {
+		t.Fatal("Expected reloading a malformed file to fail")
+	}
+
+	if keep, _ := filter.keep(models.NewDataMessage([]byte(`{"type":"noise"}`), "test")); keep {
+		t.Error("Expected the previous rules to stay in effect")
+	}
+}
+
+func TestProcessorFilterStage(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 3)
+	outputCh := make(chan *models.ChannelMessage, 3)
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1}, logging.NewNopLogger(), inputCh, outputCh, MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return msg, nil
+	}))
+	processor.filter = &messageFilter{}
+	if _, err := processor.filter.load(FilterConfig{RulesFile: writeRulesFile(t, noiseRules)}); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	defer processor.Stop()
+
+	for _, payload := range []string{`{"type":"noise"}`, `not json`, `{"type":"order"}`} {
+		inputCh <- models.NewDataMessage([]byte(payload), "test")
+	}
+	select {
+	case message := <-outputCh:
+		if string(message.Data) != `{"type":"order"}` {
+			t.Errorf("Expected only the unmatched message through, got %s", message.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the unmatched message on the output channel")
+	}
+
+	// The invalid payload went to the drop error policy
+	deadline := time.Now().Add(time.Second)
+	for processor.deadLetter.dropped.Load() < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := processor.filter.stats()
+	if stats["dropped"] != int64(1) || stats["matched"] != int64(1) || stats["invalid"] != int64(1) {
+		t.Errorf("Expected one match dropped and one invalid payload, got %v", stats)
+	}
+	if processor.deadLetter.dropped.Load() != 1 || processor.errors.Load() != 1 {
+		t.Errorf("Expected the invalid payload handled by the error policy, got %d dropped and %d errors", processor.deadLetter.dropped.Load(), processor.errors.Load())
+	}
+}
@@ -0,0 +1,79 @@
+package processing
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pipeline components reported by CheckHealth
+const (
+	HealthComponentPipeline  = "pipeline"
+	HealthComponentInput     = "input"
+	HealthComponentProcessor = "processor"
+)
+
+// defaultMaxMissedPolls is used when HealthConfig.MaxMissedPolls is not set
+const defaultMaxMissedPolls = 5
+
+// HealthConfig holds the thresholds CheckHealth applies to a running pipeline
+type HealthConfig struct {
+	MaxMissedPolls int     // Poll timeouts without a successful poll before the input is unhealthy; 0 means 5
+	MaxErrorRate   float64 // Fraction of messages failing, over the rate window, above which the processor is unhealthy; 0 disables the check
+}
+
+// missedPolls returns the number of poll timeouts the input may go without
+// a successful poll
+func (c HealthConfig) missedPolls() int {
+	if c.MaxMissedPolls <= 0 {
+		return defaultMaxMissedPolls
+	}
+	return c.MaxMissedPolls
+}
+
+// validate checks the health thresholds
+func (c HealthConfig) validate() error {
+	if c.MaxMissedPolls < 0 {
+		return fmt.Errorf("health max missed polls must not be negative")
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate > 1 {
+		return fmt.Errorf("health max error rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// CheckHealth reports the health of each pipeline component, keyed by
+// HealthComponentPipeline, HealthComponentInput and HealthComponentProcessor,
+// with a nil error for a healthy component. A pipeline that is not running
+// is unhealthy; callers that stopped it on purpose should not ask. A
+// degraded pipeline reports the last poll error.
+func (p *Pipeline) CheckHealth() map[string]error {
+	results := map[string]error{
+		HealthComponentPipeline:  nil,
+		HealthComponentInput:     nil,
+		HealthComponentProcessor: nil,
+	}
+
+	state := p.state.Load().(PipelineState)
+	if state == PipelineDegraded {
+		lastError, _ := p.inputHandler.lastPollError.Load().(string)
+		results[HealthComponentPipeline] = fmt.Errorf("pipeline is %s: %s", state, lastError)
+		return results
+	}
+	if state != PipelineRunning {
+		results[HealthComponentPipeline] = fmt.Errorf("pipeline is %s", state)
+		return results
+	}
+
+	limit := time.Duration(p.config.Health.missedPolls()) * p.config.Input.PollTimeout
+	if since := p.inputHandler.sinceLastPoll(); since > limit {
+		results[HealthComponentInput] = fmt.Errorf("no successful poll for %s", since.Round(time.Millisecond))
+	}
+
+	if threshold := p.config.Health.MaxErrorRate; threshold > 0 {
+		failing, processing := p.failRate.Rate(), p.processRate.Rate()
+		if total := failing + processing; total > 0 && failing/total > threshold {
+			results[HealthComponentProcessor] = fmt.Errorf("%.0f%% of messages failing, above the %.0f%% threshold", 100*failing/total, 100*threshold)
+		}
+	}
+	return results
+}
@@ -0,0 +1,67 @@
+package processing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+)
+
+func TestPipelineCheckHealth(t *testing.T) {
+	settings := DefaultConfig(nil)
+	settings.Input.PollTimeout = 10 * time.Millisecond
+	settings.Health = HealthConfig{MaxMissedPolls: 3, MaxErrorRate: 0.5}
+	pipeline := NewPipeline(settings, logging.NewNopLogger())
+
+	results := pipeline.CheckHealth()
+	if err := results[HealthComponentPipeline]; err == nil || err.Error() != "pipeline is stopped" {
+		t.Errorf("Expected a stopped pipeline to be unhealthy, got %v", err)
+	}
+
+	// Simulate a running pipeline whose consumer polled just now
+	pipeline.state.Store(PipelineRunning)
+	pipeline.inputHandler.lastPoll.Store(time.Now().UnixNano())
+	for component, err := range pipeline.CheckHealth() {
+		if err != nil {
+			t.Errorf("Expected %s to be healthy, got %v", component, err)
+		}
+	}
+
+	// Three poll timeouts without a successful poll
+	pipeline.inputHandler.lastPoll.Store(time.Now().Add(-50 * time.Millisecond).UnixNano())
+	if err := pipeline.CheckHealth()[HealthComponentInput]; err == nil || !strings.Contains(err.Error(), "no successful poll") {
+		t.Errorf("Expected a stalled consumer to be unhealthy, got %v", err)
+	}
+	pipeline.inputHandler.lastPoll.Store(time.Now().UnixNano())
+
+	// Three failures for every success
+	pipeline.failRate.update(30, time.Second)
+	pipeline.processRate.update(10, time.Second)
+	if err := pipeline.CheckHealth()[HealthComponentProcessor]; err == nil || !strings.Contains(err.Error(), "75% of messages failing") {
+		t.Errorf("Expected a high error rate to be unhealthy, got %v", err)
+	}
+	pipeline.config.Health.MaxErrorRate = 0
+	if err := pipeline.CheckHealth()[HealthComponentProcessor]; err != nil {
+		t.Errorf("Expected a zero threshold to disable the error rate check, got %v", err)
+	}
+
+	pipeline.state.Store(PipelineFailed)
+	if err := pipeline.CheckHealth()[HealthComponentPipeline]; err == nil || err.Error() != "pipeline is failed" {
+		t.Errorf("Expected a failed pipeline to be unhealthy, got %v", err)
+	}
+}
+
+func TestHealthConfig(t *testing.T) {
+	if polls := (HealthConfig{}).missedPolls(); polls != defaultMaxMissedPolls {
+		t.Errorf("Expected an unset missed poll limit to default to %d, got %d", defaultMaxMissedPolls, polls)
+	}
+	for _, invalid := range []HealthConfig{{MaxMissedPolls: -1}, {MaxErrorRate: -0.1}, {MaxErrorRate: 1.5}} {
+		if err := invalid.validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+	if err := defaultHealthConfig.validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+}
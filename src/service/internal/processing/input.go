@@ -1,11 +1,15 @@
 package processing
 
 import (
-	"servicegomodule/internal/models"
 	"context"
+	"errors"
 	"fmt"
+	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
 	"sharedgomodule/messagebus"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,28 +18,104 @@ type InputConfig struct {
 	Topics            []string      `json:"topics"`
 	PollTimeout       time.Duration `json:"pollTimeout"`
 	ChannelBufferSize int           `json:"channelBufferSize"`
+
+	// Poll failure recovery; see recoverPoll
+	PollBackoff    time.Duration `json:"pollBackoff"`    // Wait after a failed poll, doubled per consecutive failure. 0 means 100ms
+	PollMaxBackoff time.Duration `json:"pollMaxBackoff"` // Cap on the wait between failed polls. 0 means 30s
+	ReconnectAfter int           `json:"reconnectAfter"` // Consecutive failed polls before the consumer is recreated. 0 means 5
+	MaxReconnects  int           `json:"maxReconnects"`  // Reconnects without a successful poll before the pipeline is degraded. 0 means 3
 }
 
 // InputHandler handles input processing - reads from Kafka and writes to input channel
 type InputHandler struct {
-	consumer messagebus.Consumer
-	config   InputConfig
-	logger   logging.Logger
-	inputCh  chan *models.ChannelMessage
-	ctx      context.Context
-	cancel   context.CancelFunc
+	consumer  messagebus.Consumer
+	config    InputConfig
+	logger    logging.Logger
+	inputCh   chan *models.ChannelMessage
+	input     *channelWriter // Writes to inputCh under the backpressure policy
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}  // Closed when the consume loop exits
+	onFailure FailureHandler // Notified when the consume loop dies
+	offsets   *offsetTracker // Commits consumed offsets once the pipeline is done with them
+
+	topicsMutex sync.RWMutex     // guards config.Topics, which UpdateTopics replaces, and done, which Start sets
+	updates     chan topicUpdate // Resubscribe requests handled by the consume loop
+
+	consumed atomic.Int64
+	lastPoll atomic.Int64 // Unix nanoseconds of the last poll that returned without error
+
+	newConsumer func() (messagebus.Consumer, error) // Recreates the consumer on reconnect; nil resubscribes the existing one
+	recovery    pollRecovery                        // Consecutive poll failures, owned by the consume loop
+	onDegraded  func(err error)                     // Notified when reconnecting has not restored polling
+	onRecovered func()                              // Notified when polling works again after onDegraded
+
+	pollErrors        atomic.Int64
+	reconnects        atomic.Int64 // Consumers recreated, or resubscribed without a factory
+	reconnectFailures atomic.Int64
+	lastPollError     atomic.Value // string
+	degraded          atomic.Bool
+
+	topicStats *topicStats // Per-topic counters, shared with the processor and output handler
+}
+
+// topicUpdate asks the consume loop to resubscribe to topics
+type topicUpdate struct {
+	topics []string
+	result chan error
+}
+
+// ErrInvalidTopics is returned by UpdateTopics for an empty topic list or an
+// empty topic name
+var ErrInvalidTopics = errors.New("invalid topic list")
+
+// validateTopics checks a topic list for UpdateTopics
+func validateTopics(topics []string) error {
+	if len(topics) == 0 {
+		return fmt.Errorf("%w: at least one topic is required", ErrInvalidTopics)
+	}
+	for _, topic := range topics {
+		if strings.TrimSpace(topic) == "" {
+			return fmt.Errorf("%w: topic names must not be empty", ErrInvalidTopics)
+		}
+	}
+	return nil
 }
 
-// NewInputHandler creates a new input handler
+// Consumer settings of NewInputHandler. Path resolution of the file is
+// handled by the messagebus config loader.
+const (
+	consumerConfigFile = "kafka-consumer.yaml"
+	consumerGroup      = "recordConsGroup"
+)
+
+// NewInputHandler creates a new input handler. Its consumer is recreated
+// from the same configuration when polling keeps failing.
 func NewInputHandler(config InputConfig, logger logging.Logger) *InputHandler {
-	// Use simple filename - path resolution is handled by messagebus config loader
-	consumer := messagebus.NewConsumer("kafka-consumer.yaml", "recordConsGroup")
+	consumer := messagebus.NewConsumer(consumerConfigFile, consumerGroup)
 
+	handler := NewInputHandlerWithConsumer(config, consumer, logger)
+	handler.newConsumer = newBusConsumer
+	return handler
+}
+
+// NewInputHandlerWithConsumer creates an input handler reading from consumer,
+// e.g. a local bus consumer in tests. The handler takes ownership of the
+// consumer and closes it on Stop. Offsets are committed only as the messages
+// read from the input channel are released through the handler's tracker,
+// which the pipeline hands to the processor and output handler.
+func NewInputHandlerWithConsumer(config InputConfig, consumer messagebus.Consumer, logger logging.Logger) *InputHandler {
+	inputCh := make(chan *models.ChannelMessage, config.ChannelBufferSize)
 	return &InputHandler{
 		consumer: consumer,
 		config:   config,
 		logger:   logger,
-		inputCh:  make(chan *models.ChannelMessage, config.ChannelBufferSize),
+		inputCh:  inputCh,
+		input:    newChannelWriter(inputCh, "", logger),
+		updates:  make(chan topicUpdate),
+		offsets:  newOffsetTracker(consumer, logger),
+
+		topicStats: newTopicStats(),
 	}
 }
 
@@ -46,30 +126,33 @@ func (i *InputHandler) GetInputChannel() <-chan *models.ChannelMessage {
 
 // Start starts the input handler
 func (i *InputHandler) Start() error {
-	i.logger.Infow("Starting input handler", "topics", i.config.Topics)
+	topics := i.Topics()
+	i.logger.Infow("Starting input handler", "topics", topics)
 
 	// Create context for cancellation
 	i.ctx, i.cancel = context.WithCancel(context.Background())
 
 	// Subscribe to topics
-	if err := i.consumer.Subscribe(i.config.Topics); err != nil {
+	if err := i.consumer.Subscribe(topics); err != nil {
 		i.logger.Errorf("failed to subscribe to topics: %w", err)
 		return fmt.Errorf("failed to subscribe to topics: %w", err)
 	}
 
 	// Start consuming in a goroutine
+	i.lastPoll.Store(time.Now().UnixNano())
+	i.topicsMutex.Lock()
+	i.done = make(chan struct{})
+	i.topicsMutex.Unlock()
 	go i.consumeLoop()
 
 	return nil
 }
 
-// Stop stops the input handler
+// Stop stops the input handler and closes the consumer, after which no
+// more offsets are committed
 func (i *InputHandler) Stop() error {
 	i.logger.Info("Stopping input handler")
-
-	if i.cancel != nil {
-		i.cancel()
-	}
+	i.stopConsuming()
 
 	if i.consumer != nil {
 		if err := i.consumer.Close(); err != nil {
@@ -81,11 +164,37 @@ func (i *InputHandler) Stop() error {
 	return nil
 }
 
+// stopConsuming stops the consume loop but leaves the consumer open, so
+// offsets can still be committed while the rest of the pipeline drains.
+// It may be called more than once.
+func (i *InputHandler) stopConsuming() {
+	if i.cancel != nil {
+		i.cancel()
+	}
+	// Wait for an in-flight poll to return so the consumer is not closed
+	// underneath it
+	if done := i.doneChannel(); done != nil {
+		<-done
+	}
+}
+
+// doneChannel returns the channel closed when the consume loop exits, nil
+// before Start
+func (i *InputHandler) doneChannel() chan struct{} {
+	i.topicsMutex.RLock()
+	defer i.topicsMutex.RUnlock()
+	return i.done
+}
+
 // consumeLoop continuously polls for messages and forwards to input channel
 func (i *InputHandler) consumeLoop() {
+	defer close(i.done)
 	defer func() {
 		if r := recover(); r != nil {
 			i.logger.Errorw("Input handler panic recovered", "panic", r)
+			if i.onFailure != nil {
+				i.onFailure(fmt.Errorf("input handler stopped after panic: %v", r))
+			}
 		}
 	}()
 
@@ -94,38 +203,143 @@ func (i *InputHandler) consumeLoop() {
 		case <-i.ctx.Done():
 			i.logger.Info("Input handler consume loop stopped")
 			return
+		case update := <-i.updates:
+			update.result <- i.resubscribe(update.topics)
 		default:
 			// Poll for messages
 			message, err := i.consumer.Poll(i.config.PollTimeout)
 			if err != nil {
-				i.logger.Warnw("Error polling for messages", "error", err)
+				if !i.recoverPoll(err) {
+					i.logger.Info("Input handler consume loop stopped")
+					return
+				}
 				continue
 			}
+			i.pollSucceeded()
+			i.lastPoll.Store(time.Now().UnixNano())
 
 			if message != nil {
-				i.logger.Debugw("Received kafka data message", "size", len(message.Value))
-
-				// Create a ChannelMessage from the Kafka message
-				channelMsg := models.NewDataMessage(message.Value, "kafka")
-
-				i.inputCh <- channelMsg
-				i.logger.Debug("Message sent to input channel")
+				i.consumed.Add(1)
+				i.topicStats.consumed(message.Topic)
+				channelMsg := channelMessageFromBus(message)
+				id, err := correlationIDFromHeaders(message.Headers)
+				if err != nil {
+					i.logger.Warnw("Failed to generate correlation ID", "error", err)
+				}
+				channelMsg.CorrelationID = id
+				channelMsg.Sources = []*messagebus.Message{message}
+				logger := messageLogger(i.logger, channelMsg)
+				logger.Debugw("Received kafka data message", "size", len(message.Value), "topic", message.Topic, "offset", message.Offset)
 
-				// Commit the message
-				if err := i.consumer.Commit(context.Background(), message); err != nil {
-					i.logger.Warnw("Failed to commit message", "error", err)
+				// The offset is committed once the pipeline is done with the
+				// message; one abandoned here is consumed again after a restart
+				i.offsets.track(message)
+				// Wait on a full channel under the block policy, but not past Stop
+				if !i.input.send(i.ctx, channelMsg) {
+					i.logger.Info("Input handler consume loop stopped")
+					return
 				}
+				logger.Debug("Message sent to input channel")
 			}
 		}
 	}
 }
 
-// GetStats returns statistics about the input handler
+// sinceLastPoll returns how long ago a poll last returned without error, or
+// since Start if none has
+func (i *InputHandler) sinceLastPoll() time.Duration {
+	return time.Since(time.Unix(0, i.lastPoll.Load()))
+}
+
+// Topics returns the topics the handler is subscribed to
+func (i *InputHandler) Topics() []string {
+	i.topicsMutex.RLock()
+	defer i.topicsMutex.RUnlock()
+	return i.config.Topics
+}
+
+// UpdateTopics replaces the subscribed topics. On a running handler the
+// consume loop resubscribes between polls, so every message polled before
+// the switch has already been forwarded. Topics in both lists carry on from
+// their committed position, so messages still in flight on them may be
+// consumed twice. The call waits for the current poll to finish. Before
+// Start, or after Stop, it only changes the topics Start subscribes to.
+func (i *InputHandler) UpdateTopics(topics []string) error {
+	if err := validateTopics(topics); err != nil {
+		return err
+	}
+	topics = append([]string(nil), topics...)
+
+	done := i.doneChannel()
+	if done == nil {
+		i.setTopics(topics)
+		return nil
+	}
+
+	update := topicUpdate{topics: topics, result: make(chan error, 1)}
+	select {
+	case i.updates <- update:
+		return <-update.result
+	case <-done:
+		i.setTopics(topics)
+		return nil
+	}
+}
+
+// resubscribe switches the consumer to topics; it runs on the consume loop
+func (i *InputHandler) resubscribe(topics []string) error {
+	previous := i.Topics()
+	if err := i.consumer.Subscribe(topics); err != nil {
+		i.logger.Errorw("Failed to resubscribe input topics", "topics", topics, "error", err)
+		return fmt.Errorf("failed to subscribe to topics: %w", err)
+	}
+	i.setTopics(topics)
+	i.logger.Infow("Input topics updated", "previous", previous, "topics", topics)
+	return nil
+}
+
+func (i *InputHandler) setTopics(topics []string) {
+	i.topicsMutex.Lock()
+	defer i.topicsMutex.Unlock()
+	i.config.Topics = topics
+}
+
+// channelMessageFromBus converts a message bus message into a data message,
+// keeping its delivery metadata
+func channelMessageFromBus(message *messagebus.Message) *models.ChannelMessage {
+	channelMsg := models.NewDataMessage(message.Value, "kafka")
+	channelMsg.Topic = message.Topic
+	channelMsg.Key = message.Key
+	channelMsg.Headers = message.Headers
+	channelMsg.Partition = message.Partition
+	channelMsg.Offset = message.Offset
+	return channelMsg
+}
+
+// GetStats returns statistics about the input handler. Under "topics" it
+// breaks the counters down by topic: whether the topic is subscribed, the
+// messages consumed from it, when the last one arrived and how many of them
+// failed processing or publishing. The status is "degraded" while polls keep
+// failing after reconnecting.
 func (i *InputHandler) GetStats() map[string]interface{} {
+	status := "running"
+	if i.degraded.Load() {
+		status = "degraded"
+	}
+	lastPollError, _ := i.lastPollError.Load().(string)
 	return map[string]interface{}{
-		"status":              "running",
-		"topics":              i.config.Topics,
+		"status":              status,
+		"topics":              i.topicStats.snapshot(i.Topics()),
 		"poll_timeout":        i.config.PollTimeout.String(),
 		"channel_buffer_size": i.config.ChannelBufferSize,
+		"messages_consumed":   i.consumed.Load(),
+		"backpressure_drops":  i.input.dropped.Load(),
+		"offset_commits":      i.offsets.committed.Load(),
+		"commit_errors":       i.offsets.commitErrors.Load(),
+		"uncommitted":         i.offsets.uncommitted(),
+		"poll_errors":         i.pollErrors.Load(),
+		"reconnects":          i.reconnects.Load(),
+		"reconnect_failures":  i.reconnectFailures.Load(),
+		"last_poll_error":     lastPollError,
 	}
 }
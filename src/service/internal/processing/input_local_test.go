@@ -0,0 +1,136 @@
+//go:build local
+
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"sharedgomodule/messagebus"
+)
+
+// TestPipelineUpdateTopics swaps the input topics of a running pipeline over
+// the local message bus, keeping one topic and replacing the other
+func TestPipelineUpdateTopics(t *testing.T) {
+	kept, dropped, added := freshTopic("topics-test-kept"), freshTopic("topics-test-dropped"), freshTopic("topics-test-added")
+	settings := localConfig("topics-test")
+	settings.Input.Topics = []string{kept, dropped}
+	pipeline := startLocalPipeline(t, settings)
+
+	send := func(topic, id string) {
+		t.Helper()
+		sendMessages(t, &messagebus.Message{Topic: topic, Value: []byte(fmt.Sprintf(`{"id":%q,"data":{}}`, id))})
+	}
+	output := subscribe(t, settings.Output.OutputTopic)
+	receive := func(count int) map[string]bool {
+		t.Helper()
+		ids := make(map[string]bool)
+		for len(ids) < count {
+			message, err := output.Poll(5 * time.Second)
+			if err != nil || message == nil {
+				t.Fatalf("Expected %d processed messages, got %v (%v)", count, ids, err)
+			}
+			var record ProcessingRecord
+			if err := json.Unmarshal(message.Value, &record); err != nil {
+				t.Fatalf("Failed to decode processed record %s: %v", message.Value, err)
+			}
+			ids[record.ID] = true
+		}
+		return ids
+	}
+
+	send(kept, "kept-1")
+	send(dropped, "dropped-1")
+	receive(2)
+
+	if err := pipeline.UpdateTopics([]string{kept, added}); err != nil {
+		t.Fatalf("UpdateTopics() returned error: %v", err)
+	}
+	send(kept, "kept-2")
+	send(dropped, "dropped-2")
+	send(added, "added-1")
+
+	ids := receive(2)
+	if !ids["kept-2"] || !ids["added-1"] {
+		t.Errorf("Expected messages from the kept and added topics, got %v", ids)
+	}
+	if message, _ := output.Poll(500 * time.Millisecond); message != nil {
+		t.Errorf("Expected nothing from the removed topic, got %s", message.Value)
+	}
+
+	topics := pipeline.GetStats()["input_stats"].(map[string]interface{})["topics"].(map[string]interface{})
+	for topic, subscribed := range map[string]bool{kept: true, dropped: false, added: true} {
+		stats, ok := topics[topic].(map[string]interface{})
+		if !ok || stats["subscribed"] != subscribed {
+			t.Errorf("Expected stats for %s with subscribed = %v, got %v", topic, subscribed, topics[topic])
+		}
+	}
+}
+
+// TestPipelineMetricsLive runs messages through the pipeline over the local
+// message bus and checks the counters, rates and state
+func TestPipelineMetricsLive(t *testing.T) {
+	original := rateInterval
+	rateInterval = 50 * time.Millisecond
+	defer func() { rateInterval = original }()
+
+	settings := localConfig("metrics-test")
+	pipeline := startLocalPipeline(t, settings)
+	if state := pipeline.Metrics().State; state != PipelineRunning {
+		t.Errorf("Expected a running pipeline, got %s", state)
+	}
+	sendRecords(t, settings.Input.Topics[0], 3)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pipeline.Metrics().Published < 3 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(2 * rateInterval)
+
+	metrics := pipeline.Metrics()
+	if metrics.Consumed != 3 || metrics.Processed != 3 || metrics.Published != 3 || metrics.Failed != 0 {
+		t.Errorf("Expected 3 messages through every stage, got %+v", metrics)
+	}
+	if metrics.ConsumeRate <= 0 || metrics.PublishRate <= 0 {
+		t.Errorf("Expected positive rates, got %+v", metrics)
+	}
+
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if state := pipeline.Metrics().State; state != PipelineStopped {
+		t.Errorf("Expected a stopped pipeline, got %s", state)
+	}
+}
+
+// TestPipelineTopicStats runs messages from two topics through the pipeline
+// over the local message bus and checks that each topic is counted on its own
+func TestPipelineTopicStats(t *testing.T) {
+	busy, quiet := freshTopic("topic-stats-busy"), freshTopic("topic-stats-quiet")
+	settings := localConfig("topic-stats")
+	settings.Input.Topics = []string{busy, quiet}
+	pipeline := startLocalPipeline(t, settings)
+
+	sendRecords(t, busy, 3)
+	sendMessages(t,
+		&messagebus.Message{Topic: quiet, Value: []byte(`{"id":"quiet-1","data":{}}`)},
+		&messagebus.Message{Topic: quiet, Value: []byte("not json")})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for metrics := pipeline.Metrics(); (metrics.Published < 4 || metrics.Failed < 1) && time.Now().Before(deadline); metrics = pipeline.Metrics() {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	topics := pipeline.GetStats()["input_stats"].(map[string]interface{})["topics"].(map[string]interface{})
+	for topic, want := range map[string][2]int64{busy: {3, 0}, quiet: {2, 1}} {
+		stats := topics[topic].(map[string]interface{})
+		if stats["messages_consumed"] != want[0] || stats["errors"] != want[1] {
+			t.Errorf("Expected %s to count %d consumed and %d errors, got %v", topic, want[0], want[1], stats)
+		}
+		if stats["last_message_at"] == nil {
+			t.Errorf("Expected a last message time for %s", topic)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package processing
 
 import (
 	"context"
+	"errors"
 	"sharedgomodule/logging"
 	"sharedgomodule/messagebus"
 	"testing"
@@ -14,6 +15,7 @@ type mockConsumer struct {
 	pollMessage      *messagebus.Message
 	pollError        error
 	commitError      error
+	committed        []int64 // Offsets passed to successful commits
 	closeError       error
 	closed           bool
 	subscribeError   error
@@ -35,7 +37,11 @@ func (m *mockConsumer) Poll(timeout time.Duration) (*messagebus.Message, error)
 }
 
 func (m *mockConsumer) Commit(ctx context.Context, message *messagebus.Message) error {
-	return m.commitError
+	if m.commitError != nil {
+		return m.commitError
+	}
+	m.committed = append(m.committed, message.Offset)
+	return nil
 }
 
 func (m *mockConsumer) Close() error {
@@ -61,42 +67,6 @@ func (m *mockProducerForInput) Close() error {
 	return nil
 }
 
-// mockLoggerForInput implements the logging.Logger interface for input tests
-type mockLoggerForInput struct{}
-
-func (m *mockLoggerForInput) SetLevel(level logging.Level)                           { /* mock */ }
-func (m *mockLoggerForInput) GetLevel() logging.Level                                { return logging.InfoLevel }
-func (m *mockLoggerForInput) IsLevelEnabled(level logging.Level) bool                { return true }
-func (m *mockLoggerForInput) Debug(msg string)                                       { /* mock */ }
-func (m *mockLoggerForInput) Info(msg string)                                        { /* mock */ }
-func (m *mockLoggerForInput) Warn(msg string)                                        { /* mock */ }
-func (m *mockLoggerForInput) Error(msg string)                                       { /* mock */ }
-func (m *mockLoggerForInput) Fatal(msg string)                                       { /* mock */ }
-func (m *mockLoggerForInput) Panic(msg string)                                       { /* mock */ }
-func (m *mockLoggerForInput) Debugf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForInput) Infof(format string, args ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) Warnf(format string, args ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) Errorf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForInput) Fatalf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForInput) Panicf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForInput) Debugw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) Infow(msg string, fields ...interface{})                { /* mock */ }
-func (m *mockLoggerForInput) Warnw(msg string, fields ...interface{})                { /* mock */ }
-func (m *mockLoggerForInput) Errorw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) Fatalw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) Panicw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForInput) WithFields(fields logging.Fields) logging.Logger        { return m }
-func (m *mockLoggerForInput) WithField(key string, value interface{}) logging.Logger { return m }
-func (m *mockLoggerForInput) WithError(err error) logging.Logger                     { return m }
-func (m *mockLoggerForInput) WithContext(ctx context.Context) logging.Logger         { return m }
-func (m *mockLoggerForInput) Log(level logging.Level, msg string)                    { /* mock */ }
-func (m *mockLoggerForInput) Logf(level logging.Level, format string, args ...interface{}) { /* mock */
-}
-func (m *mockLoggerForInput) Logw(level logging.Level, msg string, keysAndValues ...interface{}) { /* mock */
-}
-func (m *mockLoggerForInput) Clone() logging.Logger { return &mockLoggerForInput{} }
-func (m *mockLoggerForInput) Close() error          { return nil }
-
 func TestInputConfig(t *testing.T) {
 	config := InputConfig{
 		Topics:            []string{"test-topic-1", "test-topic-2"},
@@ -124,7 +94,7 @@ func TestNewInputHandler(t *testing.T) {
 		PollTimeout:       1 * time.Second,
 		ChannelBufferSize: 50,
 	}
-	logger := &mockLoggerForInput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewInputHandler(config, logger)
 
@@ -151,7 +121,7 @@ func TestInputHandlerGetInputChannel(t *testing.T) {
 		PollTimeout:       1 * time.Second,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForInput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewInputHandler(config, logger)
 	channel := handler.GetInputChannel()
@@ -175,7 +145,7 @@ func TestInputHandlerGetStats(t *testing.T) {
 		PollTimeout:       3 * time.Second,
 		ChannelBufferSize: 200,
 	}
-	logger := &mockLoggerForInput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewInputHandler(config, logger)
 	stats := handler.GetStats()
@@ -191,8 +161,8 @@ func TestInputHandlerGetStats(t *testing.T) {
 	if topics, ok := stats["topics"]; !ok {
 		t.Error("Expected topics in stats")
 	} else {
-		topicsSlice, ok := topics.([]string)
-		if !ok || len(topicsSlice) != 2 {
+		topicsMap, ok := topics.(map[string]interface{})
+		if !ok || len(topicsMap) != 2 || topicsMap["topic1"] == nil || topicsMap["topic2"] == nil {
 			t.Errorf("Expected 2 topics in stats, got %v", topics)
 		}
 	}
@@ -216,7 +186,7 @@ func TestInputHandlerStartSuccess(t *testing.T) {
 		PollTimeout:       100 * time.Millisecond,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForInput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewInputHandler(config, logger)
 
@@ -247,7 +217,7 @@ func TestInputHandlerStop(t *testing.T) {
 		PollTimeout:       100 * time.Millisecond,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForInput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewInputHandler(config, logger)
 
@@ -318,7 +288,7 @@ func TestInputConfigEdgeCases(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			logger := &mockLoggerForInput{}
+			logger := logging.NewNopLogger()
 
 			handler := NewInputHandler(tc.config, logger)
 			if handler == nil && tc.valid {
@@ -340,3 +310,172 @@ func TestInputConfigEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// queueConsumer serves queued messages, waiting up to the poll timeout for one
+type queueConsumer struct {
+	messages chan *messagebus.Message
+	closed   chan struct{}
+}
+
+func newQueueConsumer() *queueConsumer {
+	return &queueConsumer{messages: make(chan *messagebus.Message, 10), closed: make(chan struct{})}
+}
+
+func (q *queueConsumer) Subscribe(topics []string) error { return nil }
+
+func (q *queueConsumer) Poll(timeout time.Duration) (*messagebus.Message, error) {
+	select {
+	case message := <-q.messages:
+		return message, nil
+	case <-time.After(timeout):
+		return nil, nil
+	}
+}
+
+func (q *queueConsumer) Commit(ctx context.Context, message *messagebus.Message) error { return nil }
+
+func (q *queueConsumer) Close() error {
+	close(q.closed)
+	return nil
+}
+
+func TestInputHandlerForwardsMessageMetadata(t *testing.T) {
+	consumer := newQueueConsumer()
+	handler := NewInputHandlerWithConsumer(InputConfig{
+		Topics:            []string{"orders"},
+		PollTimeout:       20 * time.Millisecond,
+		ChannelBufferSize: 1,
+	}, consumer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	defer handler.Stop()
+
+	consumer.messages <- &messagebus.Message{
+		Topic:     "orders",
+		Key:       "order-1",
+		Value:     []byte(`{"id":"1"}`),
+		Headers:   map[string]string{"trace": "abc"},
+		Partition: 3,
+		Offset:    42,
+	}
+
+	select {
+	case msg := <-handler.GetInputChannel():
+		if !msg.IsDataMessage() || string(msg.Data) != `{"id":"1"}` {
+			t.Errorf("Expected data message with the record, got %+v", msg)
+		}
+		if msg.Topic != "orders" || msg.Key != "order-1" || msg.Headers["trace"] != "abc" || msg.Partition != 3 || msg.Offset != 42 {
+			t.Errorf("Expected bus metadata to be preserved, got %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a message on the input channel")
+	}
+}
+
+func TestInputHandlerStopWithFullChannel(t *testing.T) {
+	consumer := newQueueConsumer()
+	handler := NewInputHandlerWithConsumer(InputConfig{
+		Topics:            []string{"orders"},
+		PollTimeout:       20 * time.Millisecond,
+		ChannelBufferSize: 1,
+	}, consumer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	// Nothing reads the input channel, so the second message blocks the loop
+	consumer.messages <- &messagebus.Message{Value: []byte("1")}
+	consumer.messages <- &messagebus.Message{Value: []byte("2")}
+	time.Sleep(50 * time.Millisecond)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- handler.Stop() }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop() returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop() did not return while the consume loop was blocked")
+	}
+
+	select {
+	case <-consumer.closed:
+	default:
+		t.Error("Expected consumer to be closed")
+	}
+}
+
+func TestInputHandlerUpdateTopics(t *testing.T) {
+	consumer := &mockConsumer{}
+	handler := NewInputHandlerWithConsumer(InputConfig{
+		Topics:            []string{"old-topic"},
+		PollTimeout:       10 * time.Millisecond,
+		ChannelBufferSize: 10,
+	}, consumer, logging.NewNopLogger())
+
+	for _, topics := range [][]string{nil, {"a", " "}} {
+		if err := handler.UpdateTopics(topics); !errors.Is(err, ErrInvalidTopics) {
+			t.Errorf("UpdateTopics(%q) = %v, want ErrInvalidTopics", topics, err)
+		}
+	}
+
+	// Before Start only the configured topics change
+	if err := handler.UpdateTopics([]string{"first"}); err != nil {
+		t.Fatalf("UpdateTopics() before Start returned error: %v", err)
+	}
+	if consumer.subscribedTopics != nil {
+		t.Errorf("Expected no subscription before Start, got %v", consumer.subscribedTopics)
+	}
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	if len(consumer.subscribedTopics) != 1 || consumer.subscribedTopics[0] != "first" {
+		t.Errorf("Expected Start to subscribe to the updated topics, got %v", consumer.subscribedTopics)
+	}
+
+	if err := handler.UpdateTopics([]string{"first", "second"}); err != nil {
+		t.Fatalf("UpdateTopics() returned error: %v", err)
+	}
+	if len(consumer.subscribedTopics) != 2 {
+		t.Errorf("Expected the consumer to be resubscribed, got %v", consumer.subscribedTopics)
+	}
+	if topics := handler.GetStats()["topics"].(map[string]interface{}); len(topics) != 2 || topics["second"] == nil {
+		t.Errorf("Expected stats to show the new topics, got %v", topics)
+	}
+
+	consumer.subscribeError = errors.New("subscribe failed")
+	if err := handler.UpdateTopics([]string{"third"}); err == nil {
+		t.Error("Expected a failed resubscribe to be reported")
+	}
+	if topics := handler.Topics(); len(topics) != 2 {
+		t.Errorf("Expected a failed resubscribe to keep the topics, got %v", topics)
+	}
+
+	handler.Stop()
+	if err := handler.UpdateTopics([]string{"fourth"}); err != nil {
+		t.Errorf("UpdateTopics() after Stop returned error: %v", err)
+	}
+	if topics := handler.Topics(); len(topics) != 1 || topics[0] != "fourth" {
+		t.Errorf("Expected a stopped handler to record the topics, got %v", topics)
+	}
+}
+
+func TestInputHandlerUpdateTopicsDuringStart(t *testing.T) {
+	handler := NewInputHandlerWithConsumer(InputConfig{
+		Topics:            []string{"old-topic"},
+		PollTimeout:       10 * time.Millisecond,
+		ChannelBufferSize: 10,
+	}, &mockConsumer{}, logging.NewNopLogger())
+
+	updated := make(chan error, 1)
+	go func() { updated <- handler.UpdateTopics([]string{"new-topic"}) }()
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	if err := <-updated; err != nil {
+		t.Errorf("UpdateTopics() during Start returned error: %v", err)
+	}
+	handler.Stop()
+}
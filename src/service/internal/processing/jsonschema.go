@@ -0,0 +1,324 @@
+package processing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is a compiled JSON Schema. Only the subset of keywords below is
+// supported; compileSchema rejects any other validation keyword rather than
+// silently ignoring it.
+type jsonSchema struct {
+	types            []string // Allowed JSON types; empty allows any
+	properties       map[string]*jsonSchema
+	required         []string
+	additional       *jsonSchema // Schema for properties not listed in properties
+	noAdditional     bool        // additionalProperties: false
+	items            *jsonSchema
+	minItems         *int
+	maxItems         *int
+	enum             []interface{}
+	constValue       interface{}
+	hasConst         bool
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	minLength        *int
+	maxLength        *int
+	pattern          *regexp.Regexp
+}
+
+// annotationKeywords carry no validation and are accepted as is
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true,
+}
+
+// schemaTypes are the values the type keyword accepts
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// compileSchema parses a JSON Schema document
+func compileSchema(data []byte) (*jsonSchema, error) {
+	return compileSchemaAt(data, "$")
+}
+
+func compileSchemaAt(data []byte, path string) (*jsonSchema, error) {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return nil, fmt.Errorf("%s: schema must be an object: %v", path, err)
+	}
+
+	schema := &jsonSchema{}
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := schema.compileKeyword(name, keywords[name], path); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// compileKeyword sets the constraint of one keyword from its raw value
+func (s *jsonSchema) compileKeyword(name string, raw json.RawMessage, path string) error {
+	var err error
+	switch name {
+	case "type":
+		err = s.compileType(raw)
+	case "properties":
+		var properties map[string]json.RawMessage
+		if err = json.Unmarshal(raw, &properties); err == nil {
+			s.properties = make(map[string]*jsonSchema, len(properties))
+			for property, value := range properties {
+				if s.properties[property], err = compileSchemaAt(value, path+"."+property); err != nil {
+					return err
+				}
+			}
+		}
+	case "required":
+		err = json.Unmarshal(raw, &s.required)
+	case "additionalProperties":
+		var allowed bool
+		if json.Unmarshal(raw, &allowed) == nil {
+			s.noAdditional = !allowed
+			return nil
+		}
+		s.additional, err = compileSchemaAt(raw, path+".*")
+		return err
+	case "items":
+		s.items, err = compileSchemaAt(raw, path+"[]")
+		return err
+	case "minItems":
+		s.minItems, err = unmarshalCount(raw)
+	case "maxItems":
+		s.maxItems, err = unmarshalCount(raw)
+	case "enum":
+		err = json.Unmarshal(raw, &s.enum)
+	case "const":
+		s.hasConst = true
+		err = json.Unmarshal(raw, &s.constValue)
+	case "minimum":
+		s.minimum, err = unmarshalNumber(raw)
+	case "maximum":
+		s.maximum, err = unmarshalNumber(raw)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = unmarshalNumber(raw)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = unmarshalNumber(raw)
+	case "minLength":
+		s.minLength, err = unmarshalCount(raw)
+	case "maxLength":
+		s.maxLength, err = unmarshalCount(raw)
+	case "pattern":
+		var pattern string
+		if err = json.Unmarshal(raw, &pattern); err == nil {
+			s.pattern, err = regexp.Compile(pattern)
+		}
+	default:
+		if annotationKeywords[name] {
+			return nil
+		}
+		return fmt.Errorf("%s: unsupported schema keyword %q", path, name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: invalid %s: %v", path, name, err)
+	}
+	return nil
+}
+
+// compileType accepts a type name or a list of them
+func (s *jsonSchema) compileType(raw json.RawMessage) error {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		s.types = []string{single}
+	} else if err := json.Unmarshal(raw, &s.types); err != nil {
+		return err
+	}
+	for _, name := range s.types {
+		if !schemaTypes[name] {
+			return fmt.Errorf("unknown type %q", name)
+		}
+	}
+	return nil
+}
+
+func unmarshalCount(raw json.RawMessage) (*int, error) {
+	var count int
+	if err := json.Unmarshal(raw, &count); err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("must not be negative")
+	}
+	return &count, nil
+}
+
+func unmarshalNumber(raw json.RawMessage) (*float64, error) {
+	var number float64
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return nil, err
+	}
+	return &number, nil
+}
+
+// validate checks a decoded JSON value against the schema and returns the
+// first violation found
+func (s *jsonSchema) validate(value interface{}) error {
+	return s.validateAt(value, "$")
+}
+
+func (s *jsonSchema) validateAt(value interface{}, path string) error {
+	if len(s.types) > 0 && !s.allowsType(value) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.types, " or "), jsonType(value))
+	}
+	if s.hasConst && !reflect.DeepEqual(value, s.constValue) {
+		return fmt.Errorf("%s: must be %v", path, s.constValue)
+	}
+	if s.enum != nil && !s.inEnum(value) {
+		return fmt.Errorf("%s: %v is not one of the allowed values", path, value)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(value, path)
+	case []interface{}:
+		return s.validateArray(value, path)
+	case string:
+		return s.validateString(value, path)
+	case float64:
+		return s.validateNumber(value, path)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(object map[string]interface{}, path string) error {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, listed := s.properties[name]
+		switch {
+		case listed:
+		case s.noAdditional:
+			return fmt.Errorf("%s: unexpected property %q", path, name)
+		case s.additional != nil:
+			property = s.additional
+		default:
+			continue
+		}
+		if err := property.validateAt(object[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(array []interface{}, path string) error {
+	if s.minItems != nil && len(array) < *s.minItems {
+		return fmt.Errorf("%s: expected at least %d items, got %d", path, *s.minItems, len(array))
+	}
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		return fmt.Errorf("%s: expected at most %d items, got %d", path, *s.maxItems, len(array))
+	}
+	if s.items != nil {
+		for i, item := range array {
+			if err := s.items.validateAt(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(value, path string) error {
+	length := utf8.RuneCountInString(value)
+	if s.minLength != nil && length < *s.minLength {
+		return fmt.Errorf("%s: expected at least %d characters, got %d", path, *s.minLength, length)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		return fmt.Errorf("%s: expected at most %d characters, got %d", path, *s.maxLength, length)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		return fmt.Errorf("%s: %q does not match pattern %s", path, value, s.pattern)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateNumber(value float64, path string) error {
+	switch {
+	case s.minimum != nil && value < *s.minimum:
+		return fmt.Errorf("%s: %v is less than the minimum %v", path, value, *s.minimum)
+	case s.maximum != nil && value > *s.maximum:
+		return fmt.Errorf("%s: %v is greater than the maximum %v", path, value, *s.maximum)
+	case s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum:
+		return fmt.Errorf("%s: %v must be greater than %v", path, value, *s.exclusiveMinimum)
+	case s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum:
+		return fmt.Errorf("%s: %v must be less than %v", path, value, *s.exclusiveMaximum)
+	}
+	return nil
+}
+
+// allowsType reports whether value has one of the schema types
+func (s *jsonSchema) allowsType(value interface{}) bool {
+	actual := jsonType(value)
+	for _, name := range s.types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *jsonSchema) inEnum(value interface{}) bool {
+	for _, allowed := range s.enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a value decoded by encoding/json,
+// reporting whole numbers as integer
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build local
+
+package processing
+
+import (
+	"errors"
+	"testing"
+
+	"sharedgomodule/logging"
+)
+
+// TestPipelineStartStopStart starts a pipeline over the local message bus,
+// stops it and checks that it refuses to start again while a rebuilt one
+// starts
+func TestPipelineStartStopStart(t *testing.T) {
+	pipeline := statsPipeline(0, logging.NewTestLogger())
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	if err := pipeline.Start(); !errors.Is(err, ErrPipelineRunning) {
+		t.Errorf("Second Start() error = %v, want ErrPipelineRunning", err)
+	}
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if err := pipeline.Start(); !errors.Is(err, ErrPipelineStopped) {
+		t.Errorf("Start() after Stop() error = %v, want ErrPipelineStopped", err)
+	}
+	if !pipeline.Started() {
+		t.Error("Expected a stopped pipeline to report Started once it has run")
+	}
+	if err := pipeline.Stop(); err != nil {
+		t.Errorf("Second Stop() returned error: %v", err)
+	}
+
+	rebuilt := pipeline.Rebuild(pipeline.config)
+	if err := rebuilt.Start(); err != nil {
+		t.Fatalf("Start() on the rebuilt pipeline returned error: %v", err)
+	}
+	defer rebuilt.Stop()
+	if state := rebuilt.Metrics().State; state != PipelineRunning {
+		t.Errorf("Expected the rebuilt pipeline to be running, got %s", state)
+	}
+}
@@ -0,0 +1,25 @@
+package processing
+
+import (
+	"context"
+
+	"servicegomodule/internal/models"
+)
+
+// MessageProcessor transforms a data message on its way from the input to
+// the output topic. Returning a nil message with a nil error drops the
+// message; returning an error hands it to the configured error policy.
+// Alternatively Process may settle msg with msg.Ack, or with msg.Nack to
+// retry it or reject it to the dead-letter topic; a settlement overrides the
+// returned error. Control messages bypass the MessageProcessor.
+type MessageProcessor interface {
+	Process(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error)
+}
+
+// MessageProcessorFunc adapts an ordinary function to a MessageProcessor
+type MessageProcessorFunc func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error)
+
+// Process calls f(ctx, msg)
+func (f MessageProcessorFunc) Process(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+	return f(ctx, msg)
+}
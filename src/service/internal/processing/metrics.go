@@ -0,0 +1,95 @@
+package processing
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// PipelineState is the lifecycle state reported by Pipeline.Metrics
+type PipelineState string
+
+const (
+	// PipelineStopped is the state before Start and after Stop
+	PipelineStopped PipelineState = "stopped"
+	// PipelineRunning is the state between Start and Stop
+	PipelineRunning PipelineState = "running"
+	// PipelineFailed is the state after a stage stopped unexpectedly
+	PipelineFailed PipelineState = "failed"
+	// PipelineDegraded is the state of a running pipeline whose input keeps
+	// failing to poll after reconnecting. It returns to running once a poll
+	// succeeds.
+	PipelineDegraded PipelineState = "degraded"
+)
+
+// rateWindow is the period the message rates are averaged over
+const rateWindow = time.Minute
+
+// rateInterval is how often the message rates are updated; tests shorten it
+var rateInterval = 5 * time.Second
+
+// PipelineMetrics is a snapshot of the pipeline counters, suitable for
+// exporting to a monitoring system. Counters only grow over the life of a
+// pipeline; rates are per second, exponentially weighted over a minute.
+type PipelineMetrics struct {
+	State         PipelineState `json:"state"`
+	Consumed      int64         `json:"messages_consumed"`  // Read from the input topics
+	Processed     int64         `json:"messages_processed"` // Passed through the message processor
+	Published     int64         `json:"messages_published"` // Sent to the output topic
+	Failed        int64         `json:"messages_failed"`    // Processing failures and failed publishes
+	Dropped       int64         `json:"messages_dropped"`   // Discarded by the error or backpressure policy
+	ConsumeRate   float64       `json:"consume_rate"`
+	ProcessRate   float64       `json:"process_rate"`
+	PublishRate   float64       `json:"publish_rate"`
+	InputChannel  ChannelFill   `json:"input_channel"`
+	OutputChannel ChannelFill   `json:"output_channel"`
+}
+
+// ChannelFill reports how full a buffered pipeline channel is
+type ChannelFill struct {
+	Length   int     `json:"length"`
+	Capacity int     `json:"capacity"`
+	Fill     float64 `json:"fill"` // Length over capacity, from 0 to 1
+}
+
+// channelFill measures a channel with the given length and capacity
+func channelFill(length, capacity int) ChannelFill {
+	fill := ChannelFill{Length: length, Capacity: capacity}
+	if capacity > 0 {
+		fill.Fill = float64(length) / float64(capacity)
+	}
+	return fill
+}
+
+// rateMeter turns a growing counter into an exponentially weighted moving
+// average rate. It is safe for concurrent use.
+type rateMeter struct {
+	mutex  sync.Mutex
+	last   int64
+	rate   float64
+	primed bool
+}
+
+// update records the counter value after interval has passed since the last
+// update
+func (m *rateMeter) update(count int64, interval time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current := float64(count-m.last) / interval.Seconds()
+	m.last = count
+	if !m.primed {
+		m.rate = current
+		m.primed = true
+		return
+	}
+	alpha := 1 - math.Exp(-interval.Seconds()/rateWindow.Seconds())
+	m.rate += alpha * (current - m.rate)
+}
+
+// Rate returns the current rate per second
+func (m *rateMeter) Rate() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.rate
+}
@@ -0,0 +1,110 @@
+package processing
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+func TestRateMeter(t *testing.T) {
+	var meter rateMeter
+	meter.update(50, 5*time.Second)
+	if rate := meter.Rate(); rate != 10 {
+		t.Fatalf("Expected the first update to set the rate to 10/s, got %v", rate)
+	}
+
+	// A steady 20/s moves the average part of the way towards 20
+	meter.update(150, 5*time.Second)
+	alpha := 1 - math.Exp(-5.0/60.0)
+	if want := 10 + alpha*10; math.Abs(meter.Rate()-want) > 1e-9 {
+		t.Errorf("Rate() = %v, want %v", meter.Rate(), want)
+	}
+	for i := 0; i < 200; i++ {
+		meter.update(150+int64(i+1)*100, 5*time.Second)
+	}
+	if math.Abs(meter.Rate()-20) > 0.01 {
+		t.Errorf("Expected the rate to settle at 20/s, got %v", meter.Rate())
+	}
+}
+
+func TestChannelFill(t *testing.T) {
+	if fill := channelFill(25, 100); fill.Fill != 0.25 || fill.Length != 25 || fill.Capacity != 100 {
+		t.Errorf("Unexpected fill %+v", fill)
+	}
+	if fill := channelFill(0, 0); fill.Fill != 0 {
+		t.Errorf("Expected an unbuffered channel to report no fill, got %+v", fill)
+	}
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	metrics := pipeline.Metrics()
+	if metrics.State != PipelineStopped || metrics.Consumed != 0 || metrics.InputChannel.Capacity != 1000 {
+		t.Errorf("Unexpected metrics for a new pipeline %+v", metrics)
+	}
+
+	pipeline.inputHandler.consumed.Add(3)
+	pipeline.processor.processed.Add(2)
+	pipeline.processor.errors.Add(1)
+	pipeline.outputHandler.messagesSent.Add(2)
+	pipeline.outputHandler.sendErrors.Add(1)
+	pipeline.processor.deadLetter.dropped.Add(1)
+	pipeline.inputHandler.input.dropped.Add(1)
+	pipeline.outputHandler.outputCh <- models.NewDataMessage(nil, "test")
+
+	metrics = pipeline.Metrics()
+	if metrics.Consumed != 3 || metrics.Processed != 2 || metrics.Published != 2 || metrics.Failed != 2 || metrics.Dropped != 2 {
+		t.Errorf("Unexpected counters %+v", metrics)
+	}
+	if metrics.OutputChannel.Length != 1 {
+		t.Errorf("Expected one message on the output channel, got %+v", metrics.OutputChannel)
+	}
+	if stats := pipeline.GetStats(); stats["pipeline_status"] != PipelineStopped || stats["metrics"] != metrics {
+		t.Errorf("Expected GetStats to include the metrics, got %v", stats)
+	}
+}
+
+func TestPipelineStageFailureSetsState(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	var notified error
+	pipeline.SetFailureHandler(func(err error) { notified = err })
+
+	pipeline.outputHandler.onFailure(errors.New("boom"))
+	if pipeline.Metrics().State != PipelineFailed || notified == nil {
+		t.Errorf("Expected a failed pipeline and a notification, got %s and %v", pipeline.Metrics().State, notified)
+	}
+}
+
+// TestPipelineMetricsConcurrentReads reads the metrics while the counters
+// and rates are being updated; run with -race
+func TestPipelineMetricsConcurrentReads(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				pipeline.inputHandler.consumed.Add(1)
+				pipeline.processor.processed.Add(1)
+				pipeline.processRate.update(pipeline.processor.processed.Load(), time.Second)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				pipeline.GetStats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if metrics := pipeline.Metrics(); metrics.Consumed != 4000 || metrics.Processed != 4000 {
+		t.Errorf("Expected 4000 consumed and processed, got %+v", metrics)
+	}
+}
@@ -0,0 +1,204 @@
+package processing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// offsetTracker commits consumed offsets once the pipeline is done with
+// their messages, giving at-least-once delivery: a message is done when its
+// outputs have been published or dead-lettered, or when the configured
+// policy discards it. Messages may finish out of order, so each partition
+// commits only up to its oldest unfinished message. A message that is lost,
+// such as an output that could be neither published nor dead-lettered, stops
+// the commits of its partition, so that it and everything after it are
+// consumed again after a restart.
+//
+// The methods of a nil tracker do nothing.
+type offsetTracker struct {
+	consumer messagebus.Consumer
+	logger   logging.Logger
+
+	mutex      sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+
+	committed    atomic.Int64
+	commitErrors atomic.Int64
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffsets holds the unfinished messages of one partition in the
+// order they were consumed
+type partitionOffsets struct {
+	pending  []*pendingOffset
+	byOffset map[int64]*pendingOffset
+	blocked  bool // A message was lost; nothing more is committed
+}
+
+// pendingOffset is a consumed message waiting for its outputs. refs counts
+// the outputs still in flight.
+type pendingOffset struct {
+	message *messagebus.Message
+	refs    int
+}
+
+func newOffsetTracker(consumer messagebus.Consumer, logger logging.Logger) *offsetTracker {
+	return &offsetTracker{
+		consumer:   consumer,
+		logger:     logger,
+		partitions: make(map[topicPartition]*partitionOffsets),
+	}
+}
+
+// track records a consumed message with one output in flight. Messages must
+// be tracked in the order they are consumed. A message consumed again while
+// still in flight, such as after the consumer reconnects, adds an output to
+// its existing entry.
+func (t *offsetTracker) track(message *messagebus.Message) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := topicPartition{message.Topic, message.Partition}
+	offsets := t.partitions[key]
+	if offsets == nil {
+		offsets = &partitionOffsets{byOffset: make(map[int64]*pendingOffset)}
+		t.partitions[key] = offsets
+	}
+	if offsets.blocked {
+		return
+	}
+	if entry := offsets.byOffset[message.Offset]; entry != nil {
+		entry.refs++
+		return
+	}
+	entry := &pendingOffset{message: message, refs: 1}
+	offsets.pending = append(offsets.pending, entry)
+	offsets.byOffset[message.Offset] = entry
+}
+
+// retain adds n outputs in flight to each source message, for a message
+// whose processing produced n+1 outputs
+func (t *offsetTracker) retain(sources []*messagebus.Message, n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, source := range sources {
+		if entry := t.lookup(source); entry != nil {
+			entry.refs += n
+		}
+	}
+}
+
+// release finishes one output of each source message and commits whatever
+// has become committable
+func (t *offsetTracker) release(sources []*messagebus.Message) {
+	if t == nil || len(sources) == 0 {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	touched := make(map[topicPartition]bool)
+	for _, source := range sources {
+		if entry := t.lookup(source); entry != nil {
+			entry.refs--
+			touched[topicPartition{source.Topic, source.Partition}] = true
+		}
+	}
+	for key := range touched {
+		t.commit(key)
+	}
+}
+
+// fail records that the source messages were lost, which stops the commits
+// of their partitions
+func (t *offsetTracker) fail(sources []*messagebus.Message) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, source := range sources {
+		offsets := t.partitions[topicPartition{source.Topic, source.Partition}]
+		if offsets == nil || offsets.blocked {
+			continue
+		}
+		// Commit what finished before the lost message first
+		t.commit(topicPartition{source.Topic, source.Partition})
+		offsets.blocked = true
+		offsets.pending = nil
+		offsets.byOffset = nil
+		t.logger.Warnw("Message lost, stopping offset commits for its partition until restart", "topic", source.Topic, "partition", source.Partition, "offset", source.Offset)
+	}
+}
+
+// lookup returns the pending entry of message, or nil if it is not tracked
+func (t *offsetTracker) lookup(message *messagebus.Message) *pendingOffset {
+	offsets := t.partitions[topicPartition{message.Topic, message.Partition}]
+	if offsets == nil || offsets.blocked {
+		return nil
+	}
+	return offsets.byOffset[message.Offset]
+}
+
+// commit commits the finished messages at the front of a partition
+func (t *offsetTracker) commit(key topicPartition) {
+	offsets := t.partitions[key]
+	var last *messagebus.Message
+	for len(offsets.pending) > 0 && offsets.pending[0].refs <= 0 {
+		last = offsets.pending[0].message
+		delete(offsets.byOffset, last.Offset)
+		offsets.pending = offsets.pending[1:]
+	}
+	if last == nil {
+		return
+	}
+
+	if err := t.consumer.Commit(context.Background(), last); err != nil {
+		t.commitErrors.Add(1)
+		t.logger.Warnw("Failed to commit offset", "topic", last.Topic, "partition", last.Partition, "offset", last.Offset, "error", err)
+		return
+	}
+	t.committed.Add(1)
+}
+
+// setConsumer switches the commits to consumer, which replaces the one the
+// tracker was created with
+func (t *offsetTracker) setConsumer(consumer messagebus.Consumer) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.consumer = consumer
+}
+
+// uncommitted returns the number of tracked messages not yet committed
+func (t *offsetTracker) uncommitted() int {
+	if t == nil {
+		return 0
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	count := 0
+	for _, offsets := range t.partitions {
+		count += len(offsets.pending)
+	}
+	return count
+}
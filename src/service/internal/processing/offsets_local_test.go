@@ -0,0 +1,75 @@
+//go:build local
+
+package processing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"sharedgomodule/messagebus"
+)
+
+// startOffsetsPipeline starts a pipeline over the local message bus reading
+// a fresh topic, with the output producer replaced by producer if not nil
+func startOffsetsPipeline(t *testing.T, name string, producer messagebus.Producer) (*Pipeline, string) {
+	t.Helper()
+	settings := localConfig(name)
+	settings.Output.Retry = RetryConfig{MaxAttempts: 1}
+	pipeline := startLocalPipeline(t, settings, func(pipeline *Pipeline) {
+		if producer != nil {
+			pipeline.outputHandler.producer = producer
+		}
+	})
+	return pipeline, settings.Input.Topics[0]
+}
+
+// awaitOutputs waits until the output handler has tried to publish count
+// messages
+func awaitOutputs(t *testing.T, pipeline *Pipeline, count int64) {
+	t.Helper()
+	output := pipeline.outputHandler
+	deadline := time.Now().Add(5 * time.Second)
+	for output.messagesSent.Load()+output.sendErrors.Load() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d messages at the output, got %d sent and %d failed", count, output.messagesSent.Load(), output.sendErrors.Load())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPipelineCommitsPublishedOffsets(t *testing.T) {
+	pipeline, topic := startOffsetsPipeline(t, "offsets-test", nil)
+	sendRecords(t, topic, 3)
+	awaitOutputs(t, pipeline, 3)
+
+	// The offset is committed just after the publish is counted
+	consumer := pipeline.inputHandler.consumer.(*messagebus.LocalConsumer)
+	deadline := time.Now().Add(time.Second)
+	offset, ok := consumer.Committed(topic)
+	for (!ok || offset != 2) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		offset, ok = consumer.Committed(topic)
+	}
+	if !ok || offset != 2 {
+		t.Errorf("Expected offset 2 committed, got %d (committed: %v)", offset, ok)
+	}
+}
+
+func TestPipelineDoesNotCommitFailedPublish(t *testing.T) {
+	// The first publish fails and there is no dead-letter topic to take it
+	producer := &mockProducerForOutput{sendErr: errors.New("broker unavailable"), failures: 1}
+	pipeline, topic := startOffsetsPipeline(t, "offsets-fail-test", producer)
+	sendRecords(t, topic, 2)
+	awaitOutputs(t, pipeline, 2)
+
+	if pipeline.outputHandler.messagesSent.Load() != 1 {
+		t.Fatalf("Expected the second message published, got %d sent", pipeline.outputHandler.messagesSent.Load())
+	}
+	// Stopping waits for the output handler, so any commit has been made
+	pipeline.Stop()
+	consumer := pipeline.inputHandler.consumer.(*messagebus.LocalConsumer)
+	if offset, ok := consumer.Committed(topic); ok {
+		t.Errorf("Expected no commit past the failed message, got offset %d", offset)
+	}
+}
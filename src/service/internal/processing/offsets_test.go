@@ -0,0 +1,152 @@
+package processing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// consumed returns count messages on one partition of topic, starting at
+// offset 0
+func consumed(topic string, partition int32, count int) []*messagebus.Message {
+	messages := make([]*messagebus.Message, count)
+	for i := range messages {
+		messages[i] = &messagebus.Message{Topic: topic, Partition: partition, Offset: int64(i)}
+	}
+	return messages
+}
+
+func TestOffsetTrackerCommitsInOrder(t *testing.T) {
+	consumer := &mockConsumer{}
+	tracker := newOffsetTracker(consumer, logging.NewNopLogger())
+	messages := consumed("in", 0, 4)
+	for _, message := range messages {
+		tracker.track(message)
+	}
+
+	// Later messages finishing first must wait for the earlier ones
+	tracker.release(messages[2:3])
+	tracker.release(messages[1:2])
+	if len(consumer.committed) != 0 {
+		t.Fatalf("Expected no commit before offset 0 is done, got %v", consumer.committed)
+	}
+
+	tracker.release(messages[0:1])
+	tracker.release(messages[3:4])
+	if want := []int64{2, 3}; !reflect.DeepEqual(consumer.committed, want) {
+		t.Errorf("Expected commits %v, got %v", want, consumer.committed)
+	}
+	if n := tracker.uncommitted(); n != 0 {
+		t.Errorf("Expected nothing uncommitted, got %d", n)
+	}
+}
+
+func TestOffsetTrackerPartitionsAreIndependent(t *testing.T) {
+	consumer := &mockConsumer{}
+	tracker := newOffsetTracker(consumer, logging.NewNopLogger())
+	first, second := consumed("in", 0, 2), consumed("in", 1, 1)
+	for _, message := range append(first, second...) {
+		tracker.track(message)
+	}
+
+	tracker.release(second)
+	if want := []int64{0}; !reflect.DeepEqual(consumer.committed, want) {
+		t.Errorf("Expected partition 1 to commit without waiting for partition 0, got %v", consumer.committed)
+	}
+	if n := tracker.uncommitted(); n != 2 {
+		t.Errorf("Expected 2 uncommitted, got %d", n)
+	}
+}
+
+func TestOffsetTrackerRetain(t *testing.T) {
+	consumer := &mockConsumer{}
+	tracker := newOffsetTracker(consumer, logging.NewNopLogger())
+	messages := consumed("in", 0, 2)
+	for _, message := range messages {
+		tracker.track(message)
+	}
+
+	// A batch of both messages produced three outputs
+	tracker.retain(messages, 2)
+	tracker.release(messages)
+	tracker.release(messages)
+	if len(consumer.committed) != 0 {
+		t.Fatalf("Expected no commit with an output in flight, got %v", consumer.committed)
+	}
+	tracker.release(messages)
+	if want := []int64{1}; !reflect.DeepEqual(consumer.committed, want) {
+		t.Errorf("Expected commits %v, got %v", want, consumer.committed)
+	}
+}
+
+func TestOffsetTrackerFailStopsCommits(t *testing.T) {
+	consumer := &mockConsumer{}
+	tracker := newOffsetTracker(consumer, logging.NewNopLogger())
+	messages := consumed("in", 0, 4)
+	for _, message := range messages[:3] {
+		tracker.track(message)
+	}
+
+	tracker.release(messages[0:1])
+	tracker.release(messages[2:3])
+	tracker.fail(messages[1:2])
+	tracker.track(messages[3])
+	tracker.release(messages[3:4])
+
+	if want := []int64{0}; !reflect.DeepEqual(consumer.committed, want) {
+		t.Errorf("Expected only the offset before the lost message committed, got %v", consumer.committed)
+	}
+	if n := tracker.uncommitted(); n != 0 {
+		t.Errorf("Expected a blocked partition to stop tracking, got %d uncommitted", n)
+	}
+}
+
+func TestOffsetTrackerCommitError(t *testing.T) {
+	consumer := &mockConsumer{commitError: errors.New("broker unavailable")}
+	tracker := newOffsetTracker(consumer, logging.NewNopLogger())
+	messages := consumed("in", 0, 1)
+	tracker.track(messages[0])
+	tracker.release(messages)
+
+	if tracker.commitErrors.Load() != 1 || tracker.committed.Load() != 0 {
+		t.Errorf("Expected one commit error, got %d errors and %d commits", tracker.commitErrors.Load(), tracker.committed.Load())
+	}
+}
+
+func TestOffsetTrackerNil(t *testing.T) {
+	var tracker *offsetTracker
+	messages := consumed("in", 0, 1)
+	tracker.track(messages[0])
+	tracker.retain(messages, 1)
+	tracker.release(messages)
+	tracker.fail(messages)
+	if n := tracker.uncommitted(); n != 0 {
+		t.Errorf("Expected a nil tracker to track nothing, got %d", n)
+	}
+}
+
+func TestOffsetTrackerRedeliveryAfterReconnect(t *testing.T) {
+	original, replacement := &mockConsumer{}, &mockConsumer{}
+	tracker := newOffsetTracker(original, logging.NewNopLogger())
+	messages := consumed("in", 0, 2)
+	tracker.track(messages[0])
+	tracker.track(messages[1])
+
+	// The new consumer delivers offset 1 again while the first copy is in flight
+	tracker.setConsumer(replacement)
+	again := &messagebus.Message{Topic: "in", Partition: 0, Offset: 1}
+	tracker.track(again)
+
+	tracker.release(messages[0:1])
+	tracker.release(messages[1:2])
+	if len(replacement.committed) != 1 {
+		t.Fatalf("Expected offset 1 to wait for its second copy, got %v", replacement.committed)
+	}
+	tracker.release([]*messagebus.Message{again})
+	if want := []int64{0, 1}; !reflect.DeepEqual(replacement.committed, want) || len(original.committed) != 0 {
+		t.Errorf("Expected commits %v on the new consumer only, got %v and %v", want, replacement.committed, original.committed)
+	}
+}
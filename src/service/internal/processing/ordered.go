@@ -0,0 +1,73 @@
+package processing
+
+import (
+	"hash/fnv"
+
+	"servicegomodule/internal/models"
+)
+
+// queueSize returns the capacity of each worker queue in ordered-by-key
+// mode, sharing the input buffer between the workers
+func queueSize(inputBuffer, workers int) int {
+	if size := inputBuffer / workers; size > 0 {
+		return size
+	}
+	return 1
+}
+
+// dispatchLoop moves messages from the input channel to the worker queues in
+// ordered-by-key mode. A full queue holds up every key behind it, which keeps
+// each key in order.
+func (p *Processor) dispatchLoop() {
+	defer p.workers.Done()
+	defer p.recoverWorker(-1)
+
+	for {
+		select {
+		case <-p.quit:
+			p.logger.Info("Processor dispatch loop stopped")
+			return
+		case message := <-p.inputCh:
+			p.queued.Add(1)
+			select {
+			case p.queues[p.route(message)] <- message:
+			case <-p.quit:
+				p.queued.Add(-1)
+				p.logger.Info("Processor dispatch loop stopped")
+				return
+			}
+		}
+	}
+}
+
+// route returns the worker queue for message: the same queue for every
+// message with the same key, and the next queue in turn for one without
+func (p *Processor) route(message *models.ChannelMessage) int {
+	workers := uint64(len(p.queues))
+	if message.Key == "" {
+		return int((p.nextQueue.Add(1) - 1) % workers)
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(message.Key))
+	return int(uint64(hash.Sum32()) % workers)
+}
+
+// dequeued records that a worker took a message off its queue
+func (p *Processor) dequeued() {
+	if p.queues != nil {
+		p.queued.Add(-1)
+	}
+}
+
+// queueDepths returns the number of messages waiting in each worker queue, or
+// nil outside ordered-by-key mode
+func (p *Processor) queueDepths() []int {
+	if p.queues == nil {
+		return nil
+	}
+	depths := make([]int, len(p.queues))
+	for i, queue := range p.queues {
+		depths[i] = len(queue)
+	}
+	return depths
+}
@@ -1,11 +1,15 @@
 package processing
 
 import (
-	"servicegomodule/internal/models"
 	"context"
 	"fmt"
+	"math/rand"
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
 	"sharedgomodule/messagebus"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,46 +18,171 @@ type OutputConfig struct {
 	BatchSize         int           `json:"batchSize"`
 	FlushTimeout      time.Duration `json:"flushTimeout"`
 	ChannelBufferSize int           `json:"channelBufferSize"`
+	Retry             RetryConfig   `json:"retry"`
+	DeadLetterTopic   string        `json:"deadLetterTopic"` // Receives messages that cannot be published; empty drops them
+	DropLogLevel      logging.Level `json:"dropLogLevel"`    // Level at which dropped messages are logged
+	Format            string        `json:"format"`          // config.OutputFormatRaw or OutputFormatJSONEnvelope; empty means raw
+	Compression       string        `json:"compression"`     // config.CompressionNone or CompressionGzip; empty means none
+
+	// MaxMessagesPerSecond caps how fast messages are published; 0 means
+	// unlimited. UpdateConfig changes it, along with BatchSize and
+	// FlushTimeout, while the handler runs.
+	MaxMessagesPerSecond float64 `json:"maxMessagesPerSecond"`
+}
+
+// RetryConfig is the retry policy for failed publishes. The wait before
+// retry n is InitialBackoff doubled n-1 times, capped at MaxBackoff and
+// randomized by up to Jitter of itself.
+type RetryConfig struct {
+	MaxAttempts    int           `json:"maxAttempts"` // Attempts including the first; values below 1 mean 1
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff"` // 0 means no cap
+	Jitter         float64       `json:"jitter"`     // Fraction between 0 and 1
+}
+
+// attempts returns the number of times a message is sent before giving up
+func (c RetryConfig) attempts() int {
+	if c.MaxAttempts < 1 {
+		return 1
+	}
+	return c.MaxAttempts
+}
+
+// backoff returns how long to wait before retry number retry, starting at 1
+func (c RetryConfig) backoff(retry int) time.Duration {
+	wait := c.InitialBackoff
+	for i := 1; i < retry && (c.MaxBackoff <= 0 || wait < c.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	if c.Jitter > 0 {
+		wait += time.Duration((rand.Float64()*2 - 1) * c.Jitter * float64(wait))
+	}
+	return wait
 }
 
 type OutputHandler struct {
-	config   OutputConfig
-	producer messagebus.Producer
-	logger   logging.Logger
-	outputCh chan *models.ChannelMessage
-	ctx      context.Context
-	cancel   context.CancelFunc
+	config    OutputConfig
+	producer  messagebus.Producer
+	logger    logging.Logger
+	outputCh  chan *models.ChannelMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}  // Closed when the produce loop exits
+	onFailure FailureHandler // Notified when the produce loop dies
+	onFlush   FlushObserver  // Told about each batch flush; may be nil
+
+	deadLetter *deadLetterQueue // Takes messages that exhaust their retries
+	offsets    *offsetTracker   // Commits the sources of published messages; may be nil
+	throttle   *throttle        // Limits the publish rate; nil when unlimited
+	serializer Serializer       // Encodes published payloads
+	compressor *gzipCompressor  // Compresses encoded payloads; nil when compression is off
+	topicStats *topicStats      // Counts failed publishes by source topic; may be nil
+
+	tuningMutex  sync.RWMutex  // guards the batch size, flush timeout and rate limit in config, and throttle
+	reconfigured chan struct{} // Wakes the produce loop to pick up a new flush timeout
+
+	messagesSent atomic.Int64
+	sendErrors   atomic.Int64
+	retries      atomic.Int64
 }
 
 func NewOutputHandler(config OutputConfig, logger logging.Logger) *OutputHandler {
-	ctx, cancel := context.WithCancel(context.Background())
 	producer := messagebus.NewProducer("kafka-producer.yaml")
 
+	return NewOutputHandlerWithProducer(config, producer, logger)
+}
+
+// NewOutputHandlerWithProducer creates an output handler publishing through
+// producer, e.g. a local bus producer in tests. The handler takes ownership
+// of the producer and closes it on Stop.
+func NewOutputHandlerWithProducer(config OutputConfig, producer messagebus.Producer, logger logging.Logger) *OutputHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+	serializer, err := NewSerializer(config.Format)
+	if err != nil {
+		logger.Warnw("Unknown output format, publishing payloads raw", "format", config.Format)
+		serializer = RawSerializer{}
+	}
+
 	return &OutputHandler{
-		config:   config,
-		producer: producer,
-		logger:   logger,
-		outputCh: make(chan *models.ChannelMessage, config.ChannelBufferSize),
-		ctx:      ctx,
-		cancel:   cancel,
+		config:     config,
+		producer:   producer,
+		logger:     logger,
+		outputCh:   make(chan *models.ChannelMessage, config.ChannelBufferSize),
+		deadLetter: &deadLetterQueue{dropLevel: logging.ErrorLevel, logger: logger},
+		throttle:   newThrottle(config.MaxMessagesPerSecond),
+		serializer: serializer,
+		compressor: newCompressor(config.Compression),
+		ctx:        ctx,
+		cancel:     cancel,
+
+		reconfigured: make(chan struct{}, 1),
+	}
+}
+
+// UpdateConfig applies the batch size, flush timeout and publish rate limit
+// of config to the handler, running or not. A new rate limit starts with a
+// fresh allowance and its throttle counters carry on; the other fields of
+// config are ignored.
+func (o *OutputHandler) UpdateConfig(config OutputConfig) {
+	o.tuningMutex.Lock()
+	o.config.BatchSize = config.BatchSize
+	o.config.FlushTimeout = config.FlushTimeout
+	if config.MaxMessagesPerSecond != o.config.MaxMessagesPerSecond {
+		o.config.MaxMessagesPerSecond = config.MaxMessagesPerSecond
+		previous := o.throttle
+		o.throttle = newThrottle(config.MaxMessagesPerSecond)
+		if o.throttle != nil && previous != nil {
+			o.throttle.throttled.Store(previous.throttled.Load())
+			o.throttle.waited.Store(previous.waited.Load())
+		}
+	}
+	o.tuningMutex.Unlock()
+
+	select {
+	case o.reconfigured <- struct{}{}:
+	default:
 	}
 }
 
+// tuning returns the settings UpdateConfig may change
+func (o *OutputHandler) tuning() (batchSize int, flushTimeout time.Duration, limit *throttle) {
+	o.tuningMutex.RLock()
+	defer o.tuningMutex.RUnlock()
+	return o.config.BatchSize, o.config.FlushTimeout, o.throttle
+}
+
 // GetOutputChannel returns the output channel for the processor to write to
 func (o *OutputHandler) GetOutputChannel() chan<- *models.ChannelMessage {
 	return o.outputCh
 }
 
 func (o *OutputHandler) Start() error {
-	o.logger.Infow("Starting output handler", "topic", o.config.OutputTopic, "batch_size", o.config.BatchSize)
+	o.tuningMutex.RLock()
+	o.logger.Infow("Starting output handler", "topic", o.config.OutputTopic, "batch_size", o.config.BatchSize, "max_messages_per_second", o.config.MaxMessagesPerSecond)
+	o.tuningMutex.RUnlock()
 
+	o.done = make(chan struct{})
 	go o.produceLoop()
 	return nil
 }
 
+// Stop flushes buffered messages, including any still queued on the output
+// channel, and then closes the producer. The final flush is not throttled.
 func (o *OutputHandler) Stop() error {
 	o.logger.Info("Stopping output handler")
 	o.cancel()
+	if o.done != nil {
+		<-o.done
+	}
+
+	if o.deadLetter != nil {
+		if err := o.deadLetter.close(); err != nil {
+			o.logger.Errorw("Error closing dead-letter producer", "error", err)
+		}
+	}
 
 	if o.producer != nil {
 		if err := o.producer.Close(); err != nil {
@@ -66,25 +195,36 @@ func (o *OutputHandler) Stop() error {
 }
 
 func (o *OutputHandler) produceLoop() {
+	defer close(o.done)
 	defer func() {
 		if r := recover(); r != nil {
 			o.logger.Errorw("Output handler panic recovered", "panic", r)
+			if o.onFailure != nil {
+				o.onFailure(fmt.Errorf("output handler stopped after panic: %v", r))
+			}
 		}
 	}()
 
-	batch := make([]*models.ChannelMessage, 0, o.config.BatchSize)
-	flushTicker := time.NewTicker(o.config.FlushTimeout)
+	batchSize, flushTimeout, _ := o.tuning()
+	batch := make([]*models.ChannelMessage, 0, batchSize)
+	flushTicker := time.NewTicker(flushTimeout)
 	defer flushTicker.Stop()
 
 	for {
 		select {
 		case <-o.ctx.Done():
-			if len(batch) > 0 {
-				o.flushBatch(batch)
-			}
+			o.flushBatch(o.drain(batch))
 			o.logger.Info("Output handler produce loop stopped")
 			return
 
+		case <-o.reconfigured:
+			batchSize, flushTimeout, _ = o.tuning()
+			flushTicker.Reset(flushTimeout)
+			if len(batch) >= batchSize {
+				o.flushBatch(batch)
+				batch = batch[:0]
+			}
+
 		case <-flushTicker.C:
 			if len(batch) > 0 {
 				o.flushBatch(batch)
@@ -95,7 +235,7 @@ func (o *OutputHandler) produceLoop() {
 			batch = append(batch, message)
 			o.logger.Debugw("Added message to batch", "batch_size", len(batch), "type", message.Type)
 
-			if len(batch) >= o.config.BatchSize {
+			if len(batch) >= batchSize {
 				o.flushBatch(batch)
 				batch = batch[:0]
 			}
@@ -110,36 +250,138 @@ func (o *OutputHandler) flushBatch(batch []*models.ChannelMessage) {
 
 	o.logger.Debugw("Flushing batch to Kafka", "batch_size", len(batch), "topic", o.config.OutputTopic)
 
+	began := time.Now()
+	_, _, limit := o.tuning()
+	failed := 0
 	for i, message := range batch {
-		if err := o.sendMessage(message); err != nil {
-			o.logger.Errorw("Failed to send message", "error", err, "batch_index", i)
+		limit.wait(o.ctx)
+		if attempts, err := o.sendWithRetry(message); err != nil {
+			failed++
+			o.sendErrors.Add(1)
+			o.topicStats.failed(message.Topic)
+			messageLogger(o.logger, message).Errorw("Failed to send message", "error", err, "key", message.Key, "batch_index", i)
+			if o.deadLetter.handle(message, err, attempts) {
+				o.offsets.release(message.Sources)
+			} else {
+				o.offsets.fail(message.Sources)
+			}
+			continue
 		}
+		o.messagesSent.Add(1)
+		o.offsets.release(message.Sources)
 	}
 
-	o.logger.Debugw("Batch flushed successfully", "messages_sent", len(batch))
+	o.logger.Debugw("Batch flushed", "messages_sent", len(batch)-failed, "send_errors", failed)
+	if o.onFlush != nil {
+		o.onFlush(len(batch), time.Since(began))
+	}
 }
 
-func (o *OutputHandler) sendMessage(channelMsg *models.ChannelMessage) error {
+// drain appends the messages already queued on the output channel to batch
+// without waiting for more
+func (o *OutputHandler) drain(batch []*models.ChannelMessage) []*models.ChannelMessage {
+	for {
+		select {
+		case message := <-o.outputCh:
+			batch = append(batch, message)
+		default:
+			return batch
+		}
+	}
+}
 
-	message := &messagebus.Message{
-		Topic: o.config.OutputTopic,
-		Value: channelMsg.Data,
+// sendWithRetry encodes message and sends it, retrying failed sends under
+// the retry policy. A message that cannot be encoded is not retried. Once
+// Stop has been called it gives up instead of waiting for a retry, so the
+// final flush costs at most one attempt per message. It returns the number
+// of attempts made.
+func (o *OutputHandler) sendWithRetry(message *models.ChannelMessage) (int, error) {
+	busMessage, err := o.encode(message)
+	if err != nil {
+		return 1, err
 	}
 
+	attempts := o.config.Retry.attempts()
+	for attempt := 1; ; attempt++ {
+		err := o.sendMessage(message, busMessage)
+		if err == nil || attempt >= attempts {
+			return attempt, err
+		}
+
+		wait := o.config.Retry.backoff(attempt)
+		messageLogger(o.logger, message).Warnw("Send failed, retrying", "error", err, "key", message.Key, "attempt", attempt, "backoff", wait)
+		select {
+		case <-time.After(wait):
+		case <-o.ctx.Done():
+			return attempt, err
+		}
+		o.retries.Add(1)
+	}
+}
+
+// encode builds the message to publish for channelMsg: its payload encoded
+// by the serializer and compressed if configured, with the content type,
+// content encoding and correlation ID added to its headers
+func (o *OutputHandler) encode(channelMsg *models.ChannelMessage) (*messagebus.Message, error) {
+	payload, contentType, err := o.serializer.Marshal(channelMsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	source := headersWithCorrelationID(channelMsg)
+	headers := make(map[string]string, len(source)+2)
+	for k, v := range source {
+		headers[k] = v
+	}
+	headers[HeaderContentType] = contentType
+	if o.compressor != nil {
+		if payload, err = o.compressor.compress(payload); err != nil {
+			return nil, err
+		}
+		headers[HeaderContentEncoding] = config.CompressionGzip
+	}
+
+	return &messagebus.Message{
+		Topic:   o.config.OutputTopic,
+		Key:     channelMsg.Key,
+		Value:   payload,
+		Headers: headers,
+	}, nil
+}
+
+func (o *OutputHandler) sendMessage(channelMsg *models.ChannelMessage, message *messagebus.Message) error {
 	_, _, err := o.producer.Send(context.Background(), message)
 	if err != nil {
 		return fmt.Errorf("failed to send message to topic %s: %w", o.config.OutputTopic, err)
 	}
 
-	o.logger.Debugw("Message sent successfully", "topic", o.config.OutputTopic, "size", len(channelMsg.Data))
+	messageLogger(o.logger, channelMsg).Debugw("Message sent successfully", "topic", o.config.OutputTopic, "key", channelMsg.Key, "size", len(message.Value))
 	return nil
 }
 
 func (o *OutputHandler) GetStats() map[string]interface{} {
+	bytesIn, bytesOut := o.compressor.stats()
+	o.tuningMutex.RLock()
+	defer o.tuningMutex.RUnlock()
 	return map[string]interface{}{
-		"status":        "running",
-		"output_topic":  o.config.OutputTopic,
-		"batch_size":    o.config.BatchSize,
-		"flush_timeout": o.config.FlushTimeout.String(),
+		"status":                  "running",
+		"output_topic":            o.config.OutputTopic,
+		"format":                  o.config.Format,
+		"compression":             o.config.Compression,
+		"compression_bytes_in":    bytesIn,
+		"compression_bytes_out":   bytesOut,
+		"compression_ratio":       o.compressor.ratio(),
+		"batch_size":              o.config.BatchSize,
+		"flush_timeout":           o.config.FlushTimeout.String(),
+		"messages_sent":           o.messagesSent.Load(),
+		"send_errors":             o.sendErrors.Load(),
+		"retries":                 o.retries.Load(),
+		"dead_lettered":           o.deadLetter.published.Load(),
+		"dead_letter_failures":    o.deadLetter.failures.Load(),
+		"dropped":                 o.deadLetter.dropped.Load(),
+		"max_attempts":            o.config.Retry.attempts(),
+		"max_messages_per_second": o.config.MaxMessagesPerSecond,
+		"throttled_messages":      o.throttle.throttledCount(),
+		"throttle_wait_ms":        o.throttle.waitTime().Milliseconds(),
 	}
 }
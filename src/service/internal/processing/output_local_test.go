@@ -0,0 +1,93 @@
+//go:build local
+
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// startLocalOutput starts an output handler publishing to a fresh topic on
+// the local bus and returns it with a consumer subscribed to that topic
+func startLocalOutput(t *testing.T, batchSize int, flushTimeout time.Duration) (*OutputHandler, messagebus.Consumer) {
+	t.Helper()
+	topic := freshTopic("output-test")
+	handler := NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:       topic,
+		BatchSize:         batchSize,
+		FlushTimeout:      flushTimeout,
+		ChannelBufferSize: 10,
+	}, messagebus.NewProducer("kafka-producer.yaml"), logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	consumer := messagebus.NewConsumer("kafka-consumer.yaml", "output-test")
+	if err := consumer.Subscribe([]string{topic}); err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		handler.Stop()
+		consumer.Close()
+	})
+	return handler, consumer
+}
+
+func keyedMessage(key string) *models.ChannelMessage {
+	msg := models.NewDataMessage([]byte("value-"+key), "test")
+	msg.Key = key
+	msg.Headers = map[string]string{"trace": "t-" + key}
+	return msg
+}
+
+// expectPublished polls for the next message and checks its key and headers
+func expectPublished(t *testing.T, consumer messagebus.Consumer, key string) {
+	t.Helper()
+	message, err := consumer.Poll(2 * time.Second)
+	if err != nil || message == nil {
+		t.Fatalf("Expected message %q to be published, got %v, %v", key, message, err)
+	}
+	if message.Key != key || message.Headers["trace"] != "t-"+key || string(message.Value) != "value-"+key {
+		t.Errorf("Published message = %+v, want key %q with its headers", message, key)
+	}
+}
+
+func TestOutputHandlerFlushesFullBatch(t *testing.T) {
+	handler, consumer := startLocalOutput(t, 2, time.Hour)
+
+	handler.GetOutputChannel() <- keyedMessage("a")
+	if message, _ := consumer.Poll(300 * time.Millisecond); message != nil {
+		t.Fatalf("Expected no publish before the batch is full, got %+v", message)
+	}
+
+	handler.GetOutputChannel() <- keyedMessage("b")
+	expectPublished(t, consumer, "a")
+	expectPublished(t, consumer, "b")
+}
+
+func TestOutputHandlerFlushesOnTimeout(t *testing.T) {
+	handler, consumer := startLocalOutput(t, 100, 50*time.Millisecond)
+
+	handler.GetOutputChannel() <- keyedMessage("a")
+	expectPublished(t, consumer, "a")
+}
+
+func TestOutputHandlerStopFlushesBufferedMessages(t *testing.T) {
+	handler, consumer := startLocalOutput(t, 100, time.Hour)
+
+	handler.GetOutputChannel() <- keyedMessage("a")
+	handler.GetOutputChannel() <- keyedMessage("b")
+	if err := handler.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+
+	expectPublished(t, consumer, "a")
+	expectPublished(t, consumer, "b")
+	if sent := handler.GetStats()["messages_sent"]; sent != int64(2) {
+		t.Errorf("messages_sent = %v, want 2", sent)
+	}
+}
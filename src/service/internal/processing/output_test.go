@@ -1,10 +1,12 @@
 package processing
 
 import (
-	"servicegomodule/internal/models"
 	"context"
+	"errors"
+	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
 	"sharedgomodule/messagebus"
+	"strings"
 	"testing"
 	"time"
 )
@@ -14,10 +16,13 @@ type mockProducerForOutput struct {
 	messages []messagebus.Message
 	closed   bool
 	sendErr  error
+	failures int // Sends that fail with sendErr before the rest succeed; 0 fails every send while sendErr is set
+	attempts int
 }
 
 func (m *mockProducerForOutput) Send(ctx context.Context, message *messagebus.Message) (partition int32, offset int64, err error) {
-	if m.sendErr != nil {
+	m.attempts++
+	if m.sendErr != nil && (m.failures == 0 || m.attempts <= m.failures) {
 		return 0, 0, m.sendErr
 	}
 	m.messages = append(m.messages, *message)
@@ -45,42 +50,6 @@ func (m *mockProducerForOutput) Close() error {
 	return nil
 }
 
-// Mock logger for output tests - matches logging.Logger interface exactly
-type mockLoggerForOutput struct{}
-
-func (m *mockLoggerForOutput) SetLevel(level logging.Level)                           { /* mock */ }
-func (m *mockLoggerForOutput) GetLevel() logging.Level                                { return logging.InfoLevel }
-func (m *mockLoggerForOutput) IsLevelEnabled(level logging.Level) bool                { return true }
-func (m *mockLoggerForOutput) Debug(msg string)                                       { /* mock */ }
-func (m *mockLoggerForOutput) Info(msg string)                                        { /* mock */ }
-func (m *mockLoggerForOutput) Warn(msg string)                                        { /* mock */ }
-func (m *mockLoggerForOutput) Error(msg string)                                       { /* mock */ }
-func (m *mockLoggerForOutput) Fatal(msg string)                                       { /* mock */ }
-func (m *mockLoggerForOutput) Panic(msg string)                                       { /* mock */ }
-func (m *mockLoggerForOutput) Debugf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForOutput) Infof(format string, args ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) Warnf(format string, args ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) Errorf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForOutput) Fatalf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForOutput) Panicf(format string, args ...interface{})              { /* mock */ }
-func (m *mockLoggerForOutput) Debugw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) Infow(msg string, fields ...interface{})                { /* mock */ }
-func (m *mockLoggerForOutput) Warnw(msg string, fields ...interface{})                { /* mock */ }
-func (m *mockLoggerForOutput) Errorw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) Fatalw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) Panicw(msg string, fields ...interface{})               { /* mock */ }
-func (m *mockLoggerForOutput) WithFields(fields logging.Fields) logging.Logger        { return m }
-func (m *mockLoggerForOutput) WithField(key string, value interface{}) logging.Logger { return m }
-func (m *mockLoggerForOutput) WithError(err error) logging.Logger                     { return m }
-func (m *mockLoggerForOutput) WithContext(ctx context.Context) logging.Logger         { return m }
-func (m *mockLoggerForOutput) Log(level logging.Level, msg string)                    { /* mock */ }
-func (m *mockLoggerForOutput) Logf(level logging.Level, format string, args ...interface{}) { /* mock */
-}
-func (m *mockLoggerForOutput) Logw(level logging.Level, msg string, keysAndValues ...interface{}) { /* mock */
-}
-func (m *mockLoggerForOutput) Clone() logging.Logger { return &mockLoggerForOutput{} }
-func (m *mockLoggerForOutput) Close() error          { return nil }
-
 func TestOutputConfig(t *testing.T) {
 	config := OutputConfig{
 		OutputTopic:       "test-output-topic",
@@ -110,7 +79,7 @@ func TestOutputHandlerGetOutputChannel(t *testing.T) {
 		FlushTimeout:      1 * time.Second,
 		ChannelBufferSize: 50,
 	}
-	logger := &mockLoggerForOutput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 	channel := handler.GetOutputChannel()
@@ -136,7 +105,7 @@ func TestOutputHandlerStartSuccess(t *testing.T) {
 		FlushTimeout:      100 * time.Millisecond,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForOutput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 
@@ -162,7 +131,7 @@ func TestOutputHandlerStop(t *testing.T) {
 		FlushTimeout:      100 * time.Millisecond,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForOutput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 
@@ -187,7 +156,7 @@ func TestOutputHandlerBatching(t *testing.T) {
 		FlushTimeout:      100 * time.Millisecond,
 		ChannelBufferSize: 10,
 	}
-	logger := &mockLoggerForOutput{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 	err := handler.Start()
@@ -211,3 +180,169 @@ func TestOutputHandlerBatching(t *testing.T) {
 	// can process messages without errors
 	// Note: Integration tests should verify actual message sending
 }
+
+func TestOutputHandlerCountsSendErrors(t *testing.T) {
+	producer := &mockProducerForOutput{sendErr: errors.New("broker unavailable")}
+	handler := NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:       "error-topic",
+		BatchSize:         10,
+		FlushTimeout:      time.Hour,
+		ChannelBufferSize: 10,
+	}, producer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+
+	handler.GetOutputChannel() <- models.NewDataMessage([]byte("lost"), "test")
+	if err := handler.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+
+	stats := handler.GetStats()
+	if stats["send_errors"] != int64(1) || stats["messages_sent"] != int64(0) {
+		t.Errorf("Expected 1 send error and no messages sent, got %v and %v", stats["send_errors"], stats["messages_sent"])
+	}
+	if !producer.closed {
+		t.Error("Expected producer to be closed on Stop")
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	retry := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for n, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 50: time.Second} {
+		if got := retry.backoff(n); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	retry.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := retry.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Expected jittered backoff within 50%% of 100ms, got %v", got)
+		}
+	}
+
+	if attempts := (RetryConfig{}).attempts(); attempts != 1 {
+		t.Errorf("Expected an unset policy to make 1 attempt, got %d", attempts)
+	}
+}
+
+// sendThroughHandler publishes one message through a handler with the given
+// retry policy and dead-letter producer and returns the stopped handler's stats
+func sendThroughHandler(t *testing.T, producer, deadLetter *mockProducerForOutput, retry RetryConfig) map[string]interface{} {
+	t.Helper()
+	handler := NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:       "retry-topic",
+		BatchSize:         1,
+		FlushTimeout:      time.Hour,
+		ChannelBufferSize: 10,
+		Retry:             retry,
+	}, producer, logging.NewNopLogger())
+	if deadLetter != nil {
+		handler.deadLetter.producer = deadLetter
+		handler.deadLetter.topic = "dlq"
+	}
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+
+	message := models.NewDataMessage([]byte("payload"), "test")
+	message.Key = "k1"
+	handler.GetOutputChannel() <- message
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		stats := handler.GetStats()
+		if stats["messages_sent"] != int64(0) || stats["send_errors"] != int64(0) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := handler.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	return handler.GetStats()
+}
+
+func TestOutputHandlerRetriesFailedSends(t *testing.T) {
+	producer := &mockProducerForOutput{sendErr: errors.New("broker unavailable"), failures: 2}
+	stats := sendThroughHandler(t, producer, nil, RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	if producer.attempts != 3 || len(producer.messages) != 1 {
+		t.Errorf("Expected 3 attempts and 1 published message, got %d and %d", producer.attempts, len(producer.messages))
+	}
+	if stats["retries"] != int64(2) || stats["messages_sent"] != int64(1) || stats["send_errors"] != int64(0) {
+		t.Errorf("Expected 2 retries, 1 sent and no errors, got %v, %v and %v", stats["retries"], stats["messages_sent"], stats["send_errors"])
+	}
+}
+
+func TestOutputHandlerDeadLettersExhaustedSends(t *testing.T) {
+	producer := &mockProducerForOutput{sendErr: errors.New("broker unavailable")}
+	deadLetter := &mockProducerForOutput{}
+	stats := sendThroughHandler(t, producer, deadLetter, RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+
+	if producer.attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", producer.attempts)
+	}
+	if stats["retries"] != int64(1) || stats["send_errors"] != int64(1) || stats["dead_lettered"] != int64(1) || stats["dropped"] != int64(0) {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+	if len(deadLetter.messages) != 1 {
+		t.Fatalf("Expected 1 dead-lettered message, got %d", len(deadLetter.messages))
+	}
+	if dl := deadLetter.messages[0]; dl.Topic != "dlq" || dl.Key != "k1" || !strings.Contains(dl.Headers["error"], "broker unavailable") {
+		t.Errorf("Unexpected dead-letter message %+v", dl)
+	}
+	if !deadLetter.closed {
+		t.Error("Expected the dead-letter producer to be closed on Stop")
+	}
+}
+
+func TestOutputHandlerStopInterruptsBackoff(t *testing.T) {
+	producer := &mockProducerForOutput{sendErr: errors.New("broker unavailable")}
+	handler := NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:       "retry-topic",
+		BatchSize:         1,
+		FlushTimeout:      time.Hour,
+		ChannelBufferSize: 10,
+		Retry:             RetryConfig{MaxAttempts: 10, InitialBackoff: time.Hour},
+	}, producer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+	handler.GetOutputChannel() <- models.NewDataMessage([]byte("payload"), "test")
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	if err := handler.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Stop to interrupt the backoff, took %v", elapsed)
+	}
+	if stats := handler.GetStats(); stats["dropped"] != int64(1) {
+		t.Errorf("Expected the message to be dropped, got %v", stats["dropped"])
+	}
+}
+
+func TestPipelineFlushObserver(t *testing.T) {
+	var flushes []int
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	pipeline.SetFlushObserver(func(messages int, duration time.Duration) {
+		if duration < 0 {
+			t.Errorf("Expected a non-negative flush duration, got %v", duration)
+		}
+		flushes = append(flushes, messages)
+	})
+	rebuilt := pipeline.Rebuild(DefaultConfig(nil))
+	rebuilt.outputHandler.producer = &mockProducerForOutput{}
+
+	rebuilt.outputHandler.flushBatch(nil)
+	rebuilt.outputHandler.flushBatch([]*models.ChannelMessage{
+		models.NewDataMessage([]byte("a"), "test"),
+		models.NewDataMessage([]byte("b"), "test"),
+	})
+	if len(flushes) != 1 || flushes[0] != 2 {
+		t.Errorf("Expected one flush of 2 messages observed after Rebuild, got %v", flushes)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build local
+
+package processing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// freshTopic returns a topic named after name that no earlier run has used,
+// since the local bus keeps topics on disk across runs
+func freshTopic(name string) string {
+	return fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+}
+
+// localConfig returns pipeline settings for the local bus that read and
+// write fresh topics named after prefix and publish messages one at a time
+func localConfig(prefix string) ProcConfig {
+	settings := DefaultConfig(nil)
+	settings.Input.Topics = []string{freshTopic(prefix + "-input")}
+	settings.Input.PollTimeout = 100 * time.Millisecond
+	settings.Processor.ProcessingDelay = 0
+	settings.Output.OutputTopic = freshTopic(prefix + "-output")
+	settings.Output.BatchSize = 1
+	return settings
+}
+
+// startLocalPipeline starts a pipeline with settings once prepare has set it
+// up, and stops it when the test ends
+func startLocalPipeline(t *testing.T, settings ProcConfig, prepare ...func(*Pipeline)) *Pipeline {
+	t.Helper()
+	pipeline := NewPipeline(settings, logging.NewNopLogger())
+	for _, p := range prepare {
+		p(pipeline)
+	}
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	t.Cleanup(func() { pipeline.Stop() })
+	return pipeline
+}
+
+// sendMessages sends messages over the local bus
+func sendMessages(t *testing.T, messages ...*messagebus.Message) {
+	t.Helper()
+	producer := messagebus.NewProducer("kafka-producer.yaml")
+	defer producer.Close()
+	for _, message := range messages {
+		if _, _, err := producer.Send(context.Background(), message); err != nil {
+			t.Fatalf("Send() returned error: %v", err)
+		}
+	}
+}
+
+// sendRecords sends count processable records, m0 to m(count-1), to topic
+func sendRecords(t *testing.T, topic string, count int) {
+	t.Helper()
+	messages := make([]*messagebus.Message, count)
+	for i := range messages {
+		messages[i] = &messagebus.Message{Topic: topic, Value: []byte(fmt.Sprintf(`{"id":"m%d","data":{}}`, i))}
+	}
+	sendMessages(t, messages...)
+}
+
+// subscribe returns a consumer subscribed to topic, closed when the test ends
+func subscribe(t *testing.T, topic string) messagebus.Consumer {
+	t.Helper()
+	consumer := messagebus.NewConsumer("kafka-consumer.yaml", "local-test")
+	t.Cleanup(func() { consumer.Close() })
+	if err := consumer.Subscribe([]string{topic}); err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	return consumer
+}
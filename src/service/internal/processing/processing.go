@@ -1,11 +1,14 @@
 package processing
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"servicegomodule/internal/config"
 	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,14 +17,49 @@ type ProcConfig struct {
 	Processor    ProcessorConfig
 	Output       OutputConfig
 	Channels     ChannelConfig
+	Health       HealthConfig
+	Filter       FilterConfig
+	Validation   ValidationConfig
 	LoggerConfig logging.LoggerConfig
+
+	// StatsInterval is how often the running pipeline logs its counters on
+	// the pipeline logger; 0 disables the stats line
+	StatsInterval time.Duration
 }
 
 type ChannelConfig struct {
-	InputBufferSize  int
-	OutputBufferSize int
+	InputBufferSize    int
+	OutputBufferSize   int
+	BackpressurePolicy string        // config.BackpressureBlock, BackpressureDropNewest or BackpressureDropOldest; empty means block
+	DrainTimeout       time.Duration // Longest Stop waits for the processor to empty the input channel; 0 means 5s
 }
 
+// FailureHandler is notified when a pipeline stage stops unexpectedly
+type FailureHandler func(err error)
+
+// FlushObserver is told how many messages each output batch flush published
+// or failed and how long the flush took
+type FlushObserver func(messages int, duration time.Duration)
+
+// ErrPipelineRunning is returned by Start on a pipeline that is already running
+var ErrPipelineRunning = errors.New("pipeline is already running")
+
+// ErrPipelineStopped is returned by Start on a pipeline that has been
+// stopped. A stopped pipeline cannot be started again; Rebuild returns a new
+// one to start in its place.
+var ErrPipelineStopped = errors.New("pipeline has been stopped")
+
+// lifecycle is the phase of a pipeline, which only moves forward: created,
+// running, stopped. A failed Start moves a pipeline straight to stopped once
+// any stage was started.
+type lifecycle int
+
+const (
+	lifecycleCreated lifecycle = iota
+	lifecycleRunning
+	lifecycleStopped
+)
+
 type Pipeline struct {
 	config        ProcConfig
 	logger        logging.Logger // application logger
@@ -31,15 +69,79 @@ type Pipeline struct {
 	outputHandler *OutputHandler
 	inputCh       <-chan *models.ChannelMessage
 	outputCh      chan<- *models.ChannelMessage
+
+	// Installed by the setters and carried over by Rebuild
+	onFailure        FailureHandler
+	messageProcessor MessageProcessor
+	batchProcessor   BatchMessageProcessor
+	serializer       Serializer
+	onFlush          FlushObserver
+
+	deadLetters *deadLetterLog // Recent dead letters, carried over by Rebuild
+
+	lifecycleMutex sync.Mutex // Held by Start and Stop for their whole run
+	phase          lifecycle
+
+	state       atomic.Value // PipelineState
+	started     atomic.Bool  // Set once Start has succeeded
+	consumeRate rateMeter
+	processRate rateMeter
+	publishRate rateMeter
+	failRate    rateMeter
+	meterStop   chan struct{} // Closed by Stop to end the rate updates and stats reports
+	meterDone   chan struct{} // Closed when the rate updates have ended
+
+	statsDone chan struct{} // Closed when the stats reporter has ended; nil when it is disabled
 }
 
 func NewPipeline(config ProcConfig, logger logging.Logger) *Pipeline {
-	plogger := initPipelineLogger(config.LoggerConfig)
+	return newPipeline(config, logger, initPipelineLogger(config.LoggerConfig))
+}
+
+// Rebuild returns a new, stopped pipeline built from config that shares the
+// pipeline logger of p and keeps its failure handler, message processors,
+// serializer and flush observer. The pipeline logger settings in config are
+// not applied.
+func (p *Pipeline) Rebuild(config ProcConfig) *Pipeline {
+	next := newPipeline(config, p.logger, p.plogger)
+	if p.onFailure != nil {
+		next.SetFailureHandler(p.onFailure)
+	}
+	if p.messageProcessor != nil {
+		next.SetMessageProcessor(p.messageProcessor)
+	}
+	if p.batchProcessor != nil {
+		next.SetBatchProcessor(p.batchProcessor)
+	}
+	if p.serializer != nil {
+		next.SetSerializer(p.serializer)
+	}
+	if p.onFlush != nil {
+		next.SetFlushObserver(p.onFlush)
+	}
+	next.setDeadLetterLog(p.deadLetters)
+	return next
+}
+
+func newPipeline(config ProcConfig, logger, plogger logging.Logger) *Pipeline {
 	inputHandler := NewInputHandler(config.Input, plogger.WithField("component", "input"))
 	outputHandler := NewOutputHandler(config.Output, plogger.WithField("component", "output"))
-	processor := NewProcessor(config.Processor, plogger.WithField("component", "processor"), inputHandler.GetInputChannel(), outputHandler.GetOutputChannel())
-
-	return &Pipeline{
+	processor := NewProcessor(config.Processor, plogger.WithField("component", "processor"), inputHandler.GetInputChannel(), outputHandler.outputCh, nil)
+	inputHandler.input.policy = config.Channels.BackpressurePolicy
+	processor.output.policy = config.Channels.BackpressurePolicy
+	inputHandler.input.offsets = inputHandler.offsets
+	processor.output.offsets = inputHandler.offsets
+	processor.offsets = inputHandler.offsets
+	outputHandler.offsets = inputHandler.offsets
+	processor.topicStats = inputHandler.topicStats
+	outputHandler.topicStats = inputHandler.topicStats
+	processorTopic, outputTopic := config.deadLetterTopics()
+	processor.filter = &messageFilter{}
+	processor.validator = &schemaValidator{}
+	processor.deadLetter = newDeadLetterQueue(processorTopic, config.Output.DropLogLevel, plogger.WithField("component", "deadletter"))
+	outputHandler.deadLetter = newDeadLetterQueue(outputTopic, config.Output.DropLogLevel, plogger.WithField("component", "deadletter"))
+
+	p := &Pipeline{
 		config:        config,
 		logger:        logger,
 		plogger:       plogger,
@@ -49,65 +151,353 @@ func NewPipeline(config ProcConfig, logger logging.Logger) *Pipeline {
 		inputCh:       inputHandler.GetInputChannel(),
 		outputCh:      outputHandler.GetOutputChannel(),
 	}
+	p.setDeadLetterLog(&deadLetterLog{})
+	p.state.Store(PipelineStopped)
+	inputHandler.onFailure = p.stageFailed
+	inputHandler.onDegraded = p.inputDegraded
+	inputHandler.onRecovered = p.inputRecovered
+	processor.onFailure = p.stageFailed
+	outputHandler.onFailure = p.stageFailed
+	return p
+}
+
+// setDeadLetterLog makes both dead-letter queues record to log
+func (p *Pipeline) setDeadLetterLog(log *deadLetterLog) {
+	p.deadLetters = log
+	p.processor.deadLetter.log = log
+	p.outputHandler.deadLetter.log = log
 }
 
+// Start loads the filter rules and payload schemas and starts the output
+// handler, the processor and the input handler. It returns ErrPipelineRunning
+// if the pipeline is already running and ErrPipelineStopped once it has been
+// stopped; use Rebuild to restart a stopped pipeline. If a rules or schema
+// file cannot be loaded nothing is started and Start may be called again; if
+// a stage fails to start the pipeline is stopped.
 func (p *Pipeline) Start() error {
+	p.lifecycleMutex.Lock()
+	defer p.lifecycleMutex.Unlock()
+
+	switch p.phase {
+	case lifecycleRunning:
+		return ErrPipelineRunning
+	case lifecycleStopped:
+		return ErrPipelineStopped
+	}
+
 	p.logger.Info("Starting processing pipeline")
 
+	if _, err := p.processor.filter.load(p.config.Filter); err != nil {
+		return fmt.Errorf("failed to load filter rules: %w", err)
+	}
+	if _, err := p.processor.validator.load(p.config.Validation); err != nil {
+		return fmt.Errorf("failed to load payload schemas: %w", err)
+	}
+
 	if err := p.outputHandler.Start(); err != nil {
-		return fmt.Errorf("failed to start output handler: %w", err)
+		return p.abortStart(fmt.Errorf("failed to start output handler: %w", err))
 	}
 
 	if err := p.processor.Start(); err != nil {
-		p.outputHandler.Stop()
-		return fmt.Errorf("failed to start processor: %w", err)
+		return p.abortStart(fmt.Errorf("failed to start processor: %w", err))
 	}
 
 	if err := p.inputHandler.Start(); err != nil {
-		p.processor.Stop()
-		p.outputHandler.Stop()
-		return fmt.Errorf("failed to start input handler: %w", err)
+		return p.abortStart(fmt.Errorf("failed to start input handler: %w", err))
 	}
 
+	p.phase = lifecycleRunning
+	p.state.Store(PipelineRunning)
+	p.started.Store(true)
+	p.meterStop = make(chan struct{})
+	p.meterDone = make(chan struct{})
+	go p.meterLoop()
+	p.startStatsReporter()
+
 	p.logger.Info("Processing pipeline started successfully")
 	return nil
 }
 
+// Stop drains the pipeline: it stops the input handler polling so no new
+// messages arrive, waits up to the drain timeout for the processor to empty
+// the input channel, stops the processor once its in-flight messages are
+// done, cancelling their handler context if the drain timeout passes first,
+// and stops the output handler, which publishes everything still
+// batched or queued before closing the producer. The consumer is closed last,
+// once the offsets of everything published have been committed. Messages
+// left on the input channel when the drain timeout passes are abandoned,
+// reported in a *DrainTimeoutError and consumed again after a restart.
+// Stopping a pipeline that was never started releases its consumer and
+// producers. Only the first call stops anything; later calls return nil, and
+// calls made while the first is still stopping wait for it to finish.
 func (p *Pipeline) Stop() error {
+	p.lifecycleMutex.Lock()
+	defer p.lifecycleMutex.Unlock()
+
+	if p.phase == lifecycleStopped {
+		return nil
+	}
+	p.phase = lifecycleStopped
+	return p.stop()
+}
+
+// abortStart stops the stages already started by a failed Start and returns
+// err, the reason it failed. The pipeline cannot be started again.
+func (p *Pipeline) abortStart(err error) error {
+	p.phase = lifecycleStopped
+	if stopErr := p.stop(); stopErr != nil {
+		p.logger.Warnw("Errors cleaning up after failed pipeline start", "error", stopErr)
+	}
+	return err
+}
+
+func (p *Pipeline) stop() error {
 	p.logger.Info("Stopping processing pipeline")
+	if p.meterStop != nil {
+		close(p.meterStop)
+		<-p.meterDone
+	}
+	if p.statsDone != nil {
+		<-p.statsDone
+	}
 
 	var errs []error
 
-	if err := p.inputHandler.Stop(); err != nil {
-		errs = append(errs, fmt.Errorf("error stopping input handler: %w", err))
-	}
+	// The consumer stays open until the output handler has stopped, so the
+	// offsets of messages published during the drain are still committed
+	p.inputHandler.stopConsuming()
+
+	timeout := p.config.Channels.drainTimeout()
+	began := time.Now()
+	drained := p.awaitInputDrained(timeout)
 
-	if err := p.processor.Stop(); err != nil {
+	// Messages still being handled get what is left of the drain timeout
+	if err := p.processor.stop(timeout - time.Since(began)); err != nil {
 		errs = append(errs, fmt.Errorf("error stopping processor: %w", err))
 	}
+	if !drained {
+		abandoned := p.undrained()
+		p.logger.Warnw("Pipeline drain timed out, abandoning messages", "timeout", timeout, "abandoned", abandoned)
+		errs = append(errs, &DrainTimeoutError{Timeout: timeout, Abandoned: abandoned})
+	}
 
 	if err := p.outputHandler.Stop(); err != nil {
 		errs = append(errs, fmt.Errorf("error stopping output handler: %w", err))
 	}
 
+	if err := p.inputHandler.Stop(); err != nil {
+		errs = append(errs, fmt.Errorf("error stopping input handler: %w", err))
+	}
+
+	p.state.Store(PipelineStopped)
 	if len(errs) > 0 {
 		p.logger.Errorw("Errors occurred during pipeline shutdown", "error_count", len(errs))
-		return fmt.Errorf("pipeline shutdown errors: %v", errs)
+		return fmt.Errorf("pipeline shutdown errors: %w", errors.Join(errs...))
 	}
 
 	p.logger.Info("Processing pipeline stopped successfully")
 	return nil
 }
 
+// Started reports whether Start has succeeded on the pipeline, so it is
+// running or has run. A pipeline that was stopped without being started, or
+// whose Start failed, reports false.
+func (p *Pipeline) Started() bool {
+	return p.started.Load()
+}
+
+// PipelineLogger returns the logger the pipeline components derive their
+// loggers from, shared with the pipelines rebuilt from this one
+func (p *Pipeline) PipelineLogger() logging.Logger {
+	return p.plogger
+}
+
+// SetFailureHandler registers fn to be called when a pipeline stage stops
+// unexpectedly. It must be called before Start.
+func (p *Pipeline) SetFailureHandler(fn FailureHandler) {
+	p.onFailure = fn
+}
+
+// stageFailed marks the pipeline failed and notifies the failure handler
+func (p *Pipeline) stageFailed(err error) {
+	p.state.Store(PipelineFailed)
+	if p.onFailure != nil {
+		p.onFailure(err)
+	}
+}
+
+// inputDegraded marks a running pipeline degraded when the input handler
+// cannot restore polling
+func (p *Pipeline) inputDegraded(err error) {
+	if p.state.CompareAndSwap(PipelineRunning, PipelineDegraded) {
+		p.logger.Errorw("Processing pipeline degraded", "error", err)
+	}
+}
+
+// inputRecovered marks a degraded pipeline running again
+func (p *Pipeline) inputRecovered() {
+	if p.state.CompareAndSwap(PipelineDegraded, PipelineRunning) {
+		p.logger.Info("Processing pipeline recovered")
+	}
+}
+
+// meterLoop updates the message rates every rateInterval until Stop
+func (p *Pipeline) meterLoop() {
+	defer close(p.meterDone)
+	ticker := time.NewTicker(rateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.meterStop:
+			return
+		case <-ticker.C:
+			metrics := p.Metrics()
+			p.consumeRate.update(metrics.Consumed, rateInterval)
+			p.processRate.update(metrics.Processed, rateInterval)
+			p.publishRate.update(metrics.Published, rateInterval)
+			p.failRate.update(metrics.Failed, rateInterval)
+		}
+	}
+}
+
+// Metrics returns a snapshot of the pipeline counters, rates and channel
+// fill levels. It is safe to call while the pipeline is running.
+func (p *Pipeline) Metrics() PipelineMetrics {
+	input, processor, output := p.inputHandler, p.processor, p.outputHandler
+	return PipelineMetrics{
+		State:     p.state.Load().(PipelineState),
+		Consumed:  input.consumed.Load(),
+		Processed: processor.processed.Load(),
+		Published: output.messagesSent.Load(),
+		Failed:    processor.errors.Load() + output.sendErrors.Load(),
+		Dropped: processor.deadLetter.dropped.Load() + output.deadLetter.dropped.Load() +
+			input.input.dropped.Load() + processor.output.dropped.Load(),
+		ConsumeRate:   p.consumeRate.Rate(),
+		ProcessRate:   p.processRate.Rate(),
+		PublishRate:   p.publishRate.Rate(),
+		InputChannel:  channelFill(len(input.inputCh), cap(input.inputCh)),
+		OutputChannel: channelFill(len(output.outputCh), cap(output.outputCh)),
+	}
+}
+
+// TopicMetrics returns the counters of each input topic, keyed by topic.
+// Topics that are no longer subscribed keep their counters.
+func (p *Pipeline) TopicMetrics() map[string]TopicMetrics {
+	return p.inputHandler.topicStats.metrics(p.inputHandler.Topics())
+}
+
+// SetMessageProcessor replaces the built-in record transformation with mp.
+// It must be called before Start.
+func (p *Pipeline) SetMessageProcessor(mp MessageProcessor) {
+	p.messageProcessor = mp
+	p.processor.handler = mp
+}
+
+// SetBatchProcessor sets the processor used for batches in batch mode. Without
+// one, batches are passed through the message processor a message at a time.
+// It must be called before Start.
+func (p *Pipeline) SetBatchProcessor(bp BatchMessageProcessor) {
+	p.batchProcessor = bp
+	p.processor.batcher = bp
+}
+
+// SetSerializer replaces the serializer chosen by the output format with s,
+// e.g. a protobuf encoding. It must be called before Start.
+func (p *Pipeline) SetSerializer(s Serializer) {
+	p.serializer = s
+	p.outputHandler.serializer = s
+}
+
+// SetFlushObserver registers fn to be called after every output batch
+// flush, e.g. to record flush latency. It must be called before Start.
+func (p *Pipeline) SetFlushObserver(fn FlushObserver) {
+	p.onFlush = fn
+	p.outputHandler.onFlush = fn
+}
+
+// ApplyRuntimeConfig applies the settings of config that take effect on a
+// running pipeline: the processing delay, processor batch size and retries,
+// and the output batch size, flush timeout and publish rate limit. Its other
+// fields only take effect on a pipeline built from them.
+func (p *Pipeline) ApplyRuntimeConfig(config ProcConfig) {
+	processor := p.processor.currentConfig()
+	processor.ProcessingDelay = config.Processor.ProcessingDelay
+	processor.BatchSize = config.Processor.BatchSize
+	processor.MaxRetries = config.Processor.MaxRetries
+	p.processor.UpdateConfig(processor)
+	p.outputHandler.UpdateConfig(config.Output)
+	p.logger.Infow("Pipeline runtime configuration updated",
+		"batch_size", processor.BatchSize,
+		"processing_delay", processor.ProcessingDelay,
+		"max_retries", processor.MaxRetries,
+		"output_batch_size", config.Output.BatchSize,
+		"flush_timeout", config.Output.FlushTimeout,
+		"max_messages_per_second", config.Output.MaxMessagesPerSecond)
+}
+
+// ReloadFilter replaces the filter settings and reloads its rules file
+// without stopping the pipeline, returning the number of rule blocks loaded.
+// If the rules cannot be loaded the previous ones stay in effect and the
+// error wraps ErrInvalidRules.
+func (p *Pipeline) ReloadFilter(settings FilterConfig) (int, error) {
+	rules, err := p.processor.filter.load(settings)
+	if err != nil {
+		return 0, err
+	}
+	p.logger.Infow("Filter rules reloaded", "rules_file", settings.RulesFile, "mode", settings.Mode, "rules", rules)
+	return rules, nil
+}
+
+// ReloadSchemas replaces the payload schemas without stopping the pipeline,
+// returning the number of schemas loaded. If any schema cannot be loaded the
+// previous ones stay in effect and the error wraps ErrInvalidSchema.
+func (p *Pipeline) ReloadSchemas(settings ValidationConfig) (int, error) {
+	schemas, err := p.processor.validator.load(settings)
+	if err != nil {
+		return 0, err
+	}
+	p.logger.Infow("Payload schemas reloaded", "schemas", settings.Schemas)
+	return schemas, nil
+}
+
+// UpdateTopics resubscribes the input handler to topics without stopping the
+// processor or output handler. See InputHandler.UpdateTopics.
+func (p *Pipeline) UpdateTopics(topics []string) error {
+	if err := p.inputHandler.UpdateTopics(topics); err != nil {
+		return err
+	}
+	p.logger.Infow("Input topics updated", "topics", topics)
+	return nil
+}
+
 func (p *Pipeline) GetStats() map[string]interface{} {
 	return map[string]interface{}{
-		"pipeline_status": "running",
-		"input_stats":     p.inputHandler.GetStats(),
-		"processor_stats": p.processor.GetStats(),
-		"output_stats":    p.outputHandler.GetStats(),
+		"pipeline_status":   p.state.Load().(PipelineState),
+		"metrics":           p.Metrics(),
+		"input_stats":       p.inputHandler.GetStats(),
+		"processor_stats":   p.processor.GetStats(),
+		"filter_stats":      p.processor.filter.stats(),
+		"validation_stats":  p.processor.validator.stats(),
+		"output_stats":      p.outputHandler.GetStats(),
+		"dead_letter_stats": p.deadLetters.stats(),
 	}
 }
 
+// defaultHealthConfig is the health thresholds used without a configuration
+var defaultHealthConfig = HealthConfig{
+	MaxMissedPolls: defaultMaxMissedPolls,
+	MaxErrorRate:   0.5,
+}
+
+// defaultRetryConfig is the output retry policy used without a configuration
+var defaultRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Jitter:         0.2,
+}
+
 func DefaultConfig(cfg *config.RawConfig) ProcConfig {
 	if cfg == nil {
 		// Return hardcoded defaults if no config is provided
@@ -118,19 +508,25 @@ func DefaultConfig(cfg *config.RawConfig) ProcConfig {
 				ChannelBufferSize: 1000,
 			},
 			Processor: ProcessorConfig{
-				ProcessingDelay: 10 * time.Millisecond,
-				BatchSize:       100,
+				BatchSize:   100,
+				Concurrency: 1,
+				ErrorPolicy: config.ErrorPolicyDrop,
+				MaxRetries:  3,
 			},
 			Output: OutputConfig{
 				OutputTopic:       "output-topic",
 				BatchSize:         50,
 				FlushTimeout:      5 * time.Second,
 				ChannelBufferSize: 1000,
+				Retry:             defaultRetryConfig,
+				DropLogLevel:      logging.ErrorLevel,
 			},
 			Channels: ChannelConfig{
 				InputBufferSize:  1000,
 				OutputBufferSize: 1000,
 			},
+			Health:        defaultHealthConfig,
+			StatsInterval: time.Minute,
 			LoggerConfig: logging.LoggerConfig{
 				Level:         logging.InfoLevel,
 				FilePath:      "/tmp/cratos-pipeline.log",
@@ -152,24 +548,31 @@ func DefaultConfig(cfg *config.RawConfig) ProcConfig {
 				ChannelBufferSize: 1000,
 			},
 			Processor: ProcessorConfig{
-				ProcessingDelay: 10 * time.Millisecond,
-				BatchSize:       100,
+				BatchSize:   100,
+				Concurrency: 1,
+				ErrorPolicy: config.ErrorPolicyDrop,
+				MaxRetries:  3,
 			},
 			Output: OutputConfig{
 				OutputTopic:       "output-topic",
 				BatchSize:         50,
 				FlushTimeout:      5 * time.Second,
 				ChannelBufferSize: 1000,
+				Retry:             defaultRetryConfig,
+				DropLogLevel:      logging.ErrorLevel,
 			},
 			Channels: ChannelConfig{
 				InputBufferSize:  1000,
 				OutputBufferSize: 1000,
 			},
+			Health:        defaultHealthConfig,
+			StatsInterval: time.Minute,
 		}
 
 		// Use PloggerConfig if available, otherwise use defaults
-		if processing.PloggerConfig.FileName != "" {
+		if processing.PloggerConfig.FileName != "" || !processing.PloggerConfig.WritesFile() {
 			procConfig.LoggerConfig = processing.PloggerConfig.ConvertToLoggerConfig()
+			procConfig.LoggerConfig.ComponentName = "processing"
 		} else {
 			procConfig.LoggerConfig = logging.LoggerConfig{
 				Level:         logging.InfoLevel,
@@ -188,26 +591,66 @@ func DefaultConfig(cfg *config.RawConfig) ProcConfig {
 			Topics:            processing.Input.Topics,
 			PollTimeout:       processing.Input.PollTimeout,
 			ChannelBufferSize: processing.Input.ChannelBufferSize,
+			PollBackoff:       processing.Input.PollBackoff,
+			PollMaxBackoff:    processing.Input.PollMaxBackoff,
+			ReconnectAfter:    processing.Input.ReconnectAfter,
+			MaxReconnects:     processing.Input.MaxReconnects,
 		},
 		Processor: ProcessorConfig{
 			ProcessingDelay: processing.Processor.ProcessingDelay,
 			BatchSize:       processing.Processor.BatchSize,
+			BatchMode:       processing.Processor.BatchMode,
+			BatchLinger:     processing.Processor.BatchLinger,
+			Concurrency:     processing.Processor.Concurrency,
+			OrderedByKey:    processing.Processor.OrderedByKey,
+			DedupWindow:     processing.Processor.DedupWindow,
+			DedupMaxEntries: processing.Processor.DedupMaxEntries,
+			DedupHeader:     processing.Processor.DedupHeader,
+			ErrorPolicy:     processing.Processor.ErrorPolicy,
+			MaxRetries:      processing.Processor.MaxRetries,
+			DeadLetterTopic: processing.Processor.DeadLetterTopic,
 		},
 		Output: OutputConfig{
 			OutputTopic:       processing.Output.OutputTopic,
 			BatchSize:         processing.Output.BatchSize,
 			FlushTimeout:      processing.Output.FlushTimeout,
 			ChannelBufferSize: processing.Output.ChannelBufferSize,
+			Retry: RetryConfig{
+				MaxAttempts:    processing.Output.Retry.MaxAttempts,
+				InitialBackoff: processing.Output.Retry.InitialBackoff,
+				MaxBackoff:     processing.Output.Retry.MaxBackoff,
+				Jitter:         processing.Output.Retry.Jitter,
+			},
+			DeadLetterTopic:      processing.Output.DeadLetterTopic,
+			DropLogLevel:         processing.Output.DroppedLogLevel(),
+			MaxMessagesPerSecond: processing.Output.MaxMessagesPerSecond,
+			Format:               processing.Output.Format,
+			Compression:          processing.Output.Compression,
 		},
 		Channels: ChannelConfig{
-			InputBufferSize:  processing.Channels.InputBufferSize,
-			OutputBufferSize: processing.Channels.OutputBufferSize,
+			InputBufferSize:    processing.Channels.InputBufferSize,
+			OutputBufferSize:   processing.Channels.OutputBufferSize,
+			BackpressurePolicy: processing.Channels.BackpressurePolicy,
+			DrainTimeout:       processing.Channels.DrainTimeout,
 		},
+		Filter: FilterConfig{
+			RulesFile: processing.Filter.RulesFile,
+			Mode:      processing.Filter.Mode,
+		},
+		Validation: ValidationConfig{
+			Schemas: processing.Validation.Schemas,
+		},
+		Health: HealthConfig{
+			MaxMissedPolls: processing.Health.MaxMissedPolls,
+			MaxErrorRate:   processing.Health.MaxErrorRate,
+		},
+		StatsInterval: processing.StatsInterval,
 	}
 
 	// Handle PloggerConfig
-	if processing.PloggerConfig.FileName != "" {
+	if processing.PloggerConfig.FileName != "" || !processing.PloggerConfig.WritesFile() {
 		procConfig.LoggerConfig = processing.PloggerConfig.ConvertToLoggerConfig()
+		procConfig.LoggerConfig.ComponentName = "processing"
 	} else {
 		// Use default pipeline logger configuration
 		procConfig.LoggerConfig = logging.LoggerConfig{
@@ -222,6 +665,32 @@ func DefaultConfig(cfg *config.RawConfig) ProcConfig {
 	return procConfig
 }
 
+// deadLetterTopics returns the dead-letter topics for processing and publish
+// failures; an empty topic means those failures are dropped. Processing
+// failures are only dead-lettered under the deadletter policy, apart from
+// messages rejected with Nack, so the publish topic stands in for the
+// processing one under any policy. The processing topic stands in for the
+// publish one only under the deadletter policy.
+func (c ProcConfig) deadLetterTopics() (processor, output string) {
+	output = c.Output.DeadLetterTopic
+	processor = c.Processor.DeadLetterTopic
+	if processor == "" {
+		processor = output
+	}
+	if c.Processor.deadLetterEnabled() && output == "" {
+		output = processor
+	}
+	return processor, output
+}
+
+// validateDeadLetter checks that the deadletter policy has a topic
+func (c ProcConfig) validateDeadLetter() error {
+	if processor, _ := c.deadLetterTopics(); c.Processor.deadLetterEnabled() && processor == "" {
+		return fmt.Errorf("dead letter topic cannot be empty with the %s error policy", config.ErrorPolicyDeadLetter)
+	}
+	return nil
+}
+
 func ValidateConfig(config ProcConfig) error {
 	if len(config.Input.Topics) == 0 {
 		return fmt.Errorf("input topics cannot be empty")
@@ -232,10 +701,43 @@ func ValidateConfig(config ProcConfig) error {
 	if config.Input.ChannelBufferSize <= 0 {
 		return fmt.Errorf("input channel buffer size must be positive")
 	}
+	if config.Input.PollBackoff < 0 || config.Input.PollMaxBackoff < 0 {
+		return fmt.Errorf("poll backoff must not be negative")
+	}
+	if config.Input.ReconnectAfter < 0 || config.Input.MaxReconnects < 0 {
+		return fmt.Errorf("input reconnect limits must not be negative")
+	}
 
 	if config.Processor.BatchSize <= 0 {
 		return fmt.Errorf("processor batch size must be positive")
 	}
+	if config.Processor.BatchLinger < 0 {
+		return fmt.Errorf("processor batch linger must not be negative")
+	}
+	if err := config.Processor.validateErrorPolicy(); err != nil {
+		return err
+	}
+	if err := config.Processor.validateDedup(); err != nil {
+		return err
+	}
+	if err := config.validateDeadLetter(); err != nil {
+		return err
+	}
+	if err := config.Channels.validateBackpressure(); err != nil {
+		return err
+	}
+	if config.Channels.DrainTimeout < 0 {
+		return fmt.Errorf("drain timeout must not be negative")
+	}
+	if config.StatsInterval < 0 {
+		return fmt.Errorf("stats interval must not be negative")
+	}
+	if err := config.Health.validate(); err != nil {
+		return err
+	}
+	if err := config.Filter.validate(); err != nil {
+		return err
+	}
 
 	if config.Output.OutputTopic == "" {
 		return fmt.Errorf("output topic cannot be empty")
@@ -249,6 +751,21 @@ func ValidateConfig(config ProcConfig) error {
 	if config.Output.ChannelBufferSize <= 0 {
 		return fmt.Errorf("output channel buffer size must be positive")
 	}
+	if retry := config.Output.Retry; retry.MaxAttempts < 0 || retry.InitialBackoff < 0 || retry.MaxBackoff < 0 {
+		return fmt.Errorf("output retry settings must not be negative")
+	}
+	if retry := config.Output.Retry; retry.Jitter < 0 || retry.Jitter > 1 {
+		return fmt.Errorf("output retry jitter must be between 0 and 1")
+	}
+	if config.Output.MaxMessagesPerSecond < 0 {
+		return fmt.Errorf("output max messages per second must not be negative")
+	}
+	if _, err := NewSerializer(config.Output.Format); err != nil {
+		return err
+	}
+	if err := config.Output.validateCompression(); err != nil {
+		return err
+	}
 
 	if config.Channels.InputBufferSize <= 0 {
 		return fmt.Errorf("input buffer size must be positive")
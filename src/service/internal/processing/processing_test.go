@@ -1,49 +1,17 @@
 package processing
 
 import (
-	"servicegomodule/internal/models"
 	"context"
 	"encoding/json"
+	"errors"
+	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
 	"sharedgomodule/messagebus"
+	"sync"
 	"testing"
 	"time"
 )
 
-// mockLogger implements the logging.Logger interface for testing
-type mockLogger struct{}
-
-func (m *mockLogger) SetLevel(level logging.Level)                                       { /* mock */ }
-func (m *mockLogger) GetLevel() logging.Level                                            { return logging.InfoLevel }
-func (m *mockLogger) IsLevelEnabled(level logging.Level) bool                            { return true }
-func (m *mockLogger) Debug(msg string)                                                   { /* mock */ }
-func (m *mockLogger) Info(msg string)                                                    { /* mock */ }
-func (m *mockLogger) Warn(msg string)                                                    { /* mock */ }
-func (m *mockLogger) Error(msg string)                                                   { /* mock */ }
-func (m *mockLogger) Fatal(msg string)                                                   { /* mock */ }
-func (m *mockLogger) Panic(msg string)                                                   { /* mock */ }
-func (m *mockLogger) Debugf(format string, args ...interface{})                          { /* mock */ }
-func (m *mockLogger) Infof(format string, args ...interface{})                           { /* mock */ }
-func (m *mockLogger) Warnf(format string, args ...interface{})                           { /* mock */ }
-func (m *mockLogger) Errorf(format string, args ...interface{})                          { /* mock */ }
-func (m *mockLogger) Fatalf(format string, args ...interface{})                          { /* mock */ }
-func (m *mockLogger) Panicf(format string, args ...interface{})                          { /* mock */ }
-func (m *mockLogger) Debugw(msg string, fields ...interface{})                           { /* mock */ }
-func (m *mockLogger) Infow(msg string, fields ...interface{})                            { /* mock */ }
-func (m *mockLogger) Warnw(msg string, fields ...interface{})                            { /* mock */ }
-func (m *mockLogger) Errorw(msg string, fields ...interface{})                           { /* mock */ }
-func (m *mockLogger) Fatalw(msg string, fields ...interface{})                           { /* mock */ }
-func (m *mockLogger) Panicw(msg string, fields ...interface{})                           { /* mock */ }
-func (m *mockLogger) WithFields(fields logging.Fields) logging.Logger                    { return m }
-func (m *mockLogger) WithField(key string, value interface{}) logging.Logger             { return m }
-func (m *mockLogger) WithError(err error) logging.Logger                                 { return m }
-func (m *mockLogger) WithContext(ctx context.Context) logging.Logger                     { return m }
-func (m *mockLogger) Log(level logging.Level, msg string)                                { /* mock */ }
-func (m *mockLogger) Logf(level logging.Level, format string, args ...interface{})       { /* mock */ }
-func (m *mockLogger) Logw(level logging.Level, msg string, keysAndValues ...interface{}) { /* mock */ }
-func (m *mockLogger) Clone() logging.Logger                                              { return &mockLogger{} }
-func (m *mockLogger) Close() error                                                       { return nil }
-
 // mockProducer implements the messagebus.Producer interface for testing
 type mockProducer struct {
 	sentMessages []*messagebus.Message
@@ -95,11 +63,11 @@ func TestNewProcessor(t *testing.T) {
 		ProcessingDelay: 10 * time.Millisecond,
 		BatchSize:       100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	if processor == nil {
 		t.Fatal("Expected processor to be created, got nil")
@@ -114,11 +82,11 @@ func TestProcessorApplyProcessing(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	input := ProcessingRecord{
 		ID:        "test-record",
@@ -152,7 +120,7 @@ func TestNewOutputHandler(t *testing.T) {
 		FlushTimeout:      5 * time.Second,
 		ChannelBufferSize: 100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 
@@ -209,11 +177,11 @@ func TestProcessorGetStats(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	stats := processor.GetStats()
 	if stats == nil {
@@ -238,7 +206,7 @@ func TestOutputHandlerGetStats(t *testing.T) {
 		FlushTimeout:      5 * time.Second,
 		ChannelBufferSize: 100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 
 	handler := NewOutputHandler(config, logger)
 
@@ -266,11 +234,11 @@ func TestProcessorApplyProcessingEdgeCases(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       100,
 	}
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	// Test with empty data
 	input := ProcessingRecord{
@@ -307,7 +275,7 @@ func TestProcessorApplyProcessingEdgeCases(t *testing.T) {
 
 func TestSimpleNewPipeline(t *testing.T) {
 	config := DefaultConfig(nil)
-	logger := &mockLogger{}
+	logger := logging.NewNopLogger()
 
 	pipeline := NewPipeline(config, logger)
 
@@ -316,6 +284,148 @@ func TestSimpleNewPipeline(t *testing.T) {
 	}
 }
 
+func TestPipelineSetMessageProcessor(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	pipeline.SetMessageProcessor(MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return models.NewDataMessage([]byte("replaced"), "test"), nil
+	}))
+
+	output, err := pipeline.processor.handler.Process(context.Background(), models.NewDataMessage([]byte("original"), "test"))
+	if err != nil || string(output.Data) != "replaced" {
+		t.Errorf("Expected the supplied processor to be used, got %v, %v", output, err)
+	}
+}
+
+func TestValidateConfigErrorPolicy(t *testing.T) {
+	config := DefaultConfig(nil)
+	config.Processor.ErrorPolicy = "ignore"
+	if err := ValidateConfig(config); err == nil {
+		t.Error("Expected an unknown error policy to be rejected")
+	}
+
+	config.Processor.ErrorPolicy = "deadletter"
+	if err := ValidateConfig(config); err == nil {
+		t.Error("Expected the deadletter policy without a topic to be rejected")
+	}
+
+	config.Processor.DeadLetterTopic = "dlq"
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("Expected a deadletter policy with a topic to be valid, got %v", err)
+	}
+}
+
+func TestPipelineStopTwice(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("First Stop() returned error: %v", err)
+	}
+	if err := pipeline.Stop(); err != nil {
+		t.Errorf("Second Stop() returned error: %v", err)
+	}
+}
+
+func TestPipelineConcurrentStop(t *testing.T) {
+	recorder := logging.NewTestLogger()
+	pipeline := NewPipeline(DefaultConfig(nil), recorder)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- pipeline.Stop()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Stop() returned error: %v", err)
+		}
+	}
+	if stops := countMessages(recorder, "Stopping processing pipeline"); stops != 1 {
+		t.Errorf("Expected the pipeline to be stopped once, got %d", stops)
+	}
+}
+
+func TestPipelineStartAfterStop(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if err := pipeline.Start(); !errors.Is(err, ErrPipelineStopped) {
+		t.Errorf("Start() error = %v, want ErrPipelineStopped", err)
+	}
+	if state := pipeline.Metrics().State; state != PipelineStopped {
+		t.Errorf("Expected the pipeline to stay stopped, got %s", state)
+	}
+	if pipeline.Started() {
+		t.Error("Expected a pipeline stopped before Start not to report Started")
+	}
+}
+
+func TestPipelineRebuildKeepsProcessors(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	var failures []error
+	pipeline.SetFailureHandler(func(err error) { failures = append(failures, err) })
+	pipeline.SetMessageProcessor(MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return models.NewDataMessage([]byte("replaced"), "test"), nil
+	}))
+
+	settings := DefaultConfig(nil)
+	settings.Processor.BatchSize = 7
+	rebuilt := pipeline.Rebuild(settings)
+
+	if rebuilt == pipeline || rebuilt.plogger != pipeline.plogger {
+		t.Error("Expected a new pipeline sharing the pipeline logger")
+	}
+	if rebuilt.processor.currentConfig().BatchSize != 7 {
+		t.Errorf("Expected the rebuilt pipeline to use the new config, got batch size %d", rebuilt.processor.currentConfig().BatchSize)
+	}
+	output, err := rebuilt.processor.handler.Process(context.Background(), models.NewDataMessage([]byte("original"), "test"))
+	if err != nil || string(output.Data) != "replaced" {
+		t.Errorf("Expected the message processor to carry over, got %v, %v", output, err)
+	}
+	rebuilt.processor.onFailure(errors.New("boom"))
+	if len(failures) != 1 {
+		t.Error("Expected the failure handler to carry over")
+	}
+}
+
+func TestDeadLetterTopics(t *testing.T) {
+	tests := []struct {
+		name                      string
+		policy, processor, output string
+		wantProcessor, wantOutput string
+	}{
+		{"none", "drop", "", "", "", ""},
+		{"output only", "drop", "", "dlq", "dlq", "dlq"},
+		{"processor topic without the policy", "drop", "proc-dlq", "", "proc-dlq", ""},
+		{"processor policy only", "deadletter", "proc-dlq", "", "proc-dlq", "proc-dlq"},
+		{"processor policy falls back to output", "deadletter", "", "dlq", "dlq", "dlq"},
+		{"separate topics", "deadletter", "proc-dlq", "dlq", "proc-dlq", "dlq"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig(nil)
+			config.Processor.ErrorPolicy = tt.policy
+			config.Processor.DeadLetterTopic = tt.processor
+			config.Output.DeadLetterTopic = tt.output
+
+			processor, output := config.deadLetterTopics()
+			if processor != tt.wantProcessor || output != tt.wantOutput {
+				t.Errorf("deadLetterTopics() = %q, %q, want %q, %q", processor, output, tt.wantProcessor, tt.wantOutput)
+			}
+			if err := ValidateConfig(config); err != nil {
+				t.Errorf("Expected a valid configuration, got %v", err)
+			}
+		})
+	}
+}
+
 func TestProcessingRecordValidation(t *testing.T) {
 	// Test with various data types
 	testCases := []struct {
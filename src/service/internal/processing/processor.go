@@ -1,19 +1,82 @@
 package processing
 
 import (
-	"servicegomodule/internal/models"
 	"context"
 	"encoding/json"
 	"fmt"
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type ProcessorConfig struct {
+	// Deprecated: ProcessingDelay sleeps before transforming each record with
+	// the built-in processor. Use OutputConfig.MaxMessagesPerSecond to limit
+	// throughput instead.
 	ProcessingDelay time.Duration
+
 	BatchSize       int
+	BatchMode       bool          // Accumulate up to BatchSize messages, or until BatchLinger passes, and process them together
+	BatchLinger     time.Duration // Longest a partial batch waits in batch mode
+	Concurrency     int           // Number of workers; values below 1 mean 1
+	OrderedByKey    bool          // Route messages to workers by key so each key keeps its order
+	DedupWindow     time.Duration // Drop data messages whose identity was seen this recently; 0 disables
+	DedupMaxEntries int           // Most identities remembered for dedup; 0 means 10000
+	DedupHeader     string        // Header holding the dedup identity; empty uses the message key
+	ErrorPolicy     string        // config.ErrorPolicyDrop, ErrorPolicyRetry or ErrorPolicyDeadLetter; empty means drop
+	MaxRetries      int
+	DeadLetterTopic string
+}
+
+// validateErrorPolicy checks the error policy settings
+func (c ProcessorConfig) validateErrorPolicy() error {
+	switch c.ErrorPolicy {
+	case "", config.ErrorPolicyDrop, config.ErrorPolicyRetry, config.ErrorPolicyDeadLetter:
+	default:
+		return fmt.Errorf("unknown processor error policy %q", c.ErrorPolicy)
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("processor max retries must not be negative")
+	}
+	return nil
+}
+
+// defaultBatchLinger is used in batch mode when no linger timeout is set
+const defaultBatchLinger = 100 * time.Millisecond
+
+// lingerTimeout returns how long a partial batch waits in batch mode
+func (c ProcessorConfig) lingerTimeout() time.Duration {
+	if c.BatchLinger <= 0 {
+		return defaultBatchLinger
+	}
+	return c.BatchLinger
+}
+
+// workerCount returns the number of workers to run
+func (c ProcessorConfig) workerCount() int {
+	if c.Concurrency < 1 {
+		return 1
+	}
+	return c.Concurrency
+}
+
+// retryEnabled reports whether failed messages are retried
+func (c ProcessorConfig) retryEnabled() bool {
+	return c.ErrorPolicy == config.ErrorPolicyRetry
 }
 
+// deadLetterEnabled reports whether failed messages go to the dead-letter topic
+func (c ProcessorConfig) deadLetterEnabled() bool {
+	return c.ErrorPolicy == config.ErrorPolicyDeadLetter
+}
+
+// retryBackoff is the wait before the first retry under the retry policy;
+// later retries wait proportionally longer
+var retryBackoff = 100 * time.Millisecond
+
 type ProcessingRecord struct {
 	ID        string                 `json:"id"`
 	Timestamp time.Time              `json:"timestamp"`
@@ -22,52 +85,171 @@ type ProcessingRecord struct {
 }
 
 type Processor struct {
-	config   ProcessorConfig
-	logger   logging.Logger
-	inputCh  <-chan *models.ChannelMessage
-	outputCh chan<- *models.ChannelMessage
-	ctx      context.Context
-	cancel   context.CancelFunc
+	config     ProcessorConfig
+	mutex      sync.RWMutex // guards config, which may be updated on reload
+	logger     logging.Logger
+	handler    MessageProcessor
+	batcher    BatchMessageProcessor // Used in batch mode; nil applies handler to each message
+	filter     *messageFilter        // Drops data messages by rule before they are processed; nil passes all
+	dedup      *dedupCache           // Drops repeated data messages; nil when dedup is disabled
+	validator  *schemaValidator      // Rejects data messages whose payload fails their topic's schema; nil passes all
+	deadLetter *deadLetterQueue      // Takes failed messages; it only publishes under the deadletter policy
+	offsets    *offsetTracker        // Releases the sources of messages that produce no output; may be nil
+	topicStats *topicStats           // Counts failures by source topic; may be nil
+	inputCh    <-chan *models.ChannelMessage
+	output     *channelWriter  // Writes to the output channel under the backpressure policy
+	ctx        context.Context // Passed to the handlers; cancelled only once a stop runs out of time
+	cancel     context.CancelFunc
+	quit       chan struct{} // Closed by Stop to end the worker loops
+	quitOnce   sync.Once
+	workers    sync.WaitGroup // Tracks the running process loops
+	onFailure  FailureHandler // Notified when a process loop dies
+
+	queues    []chan *models.ChannelMessage // Per-worker queues in ordered-by-key mode
+	queued    atomic.Int64                  // Messages taken off the input channel but not yet by a worker
+	nextQueue atomic.Uint64                 // Round-robin position for messages without a key
+
+	processed atomic.Int64
+	errors    atomic.Int64
+	retries   atomic.Int64
+	batches   atomic.Int64
+	batched   atomic.Int64 // Messages processed in batches
+	requeued  atomic.Int64 // Attempts that ended in Nack with requeue
+	rejected  atomic.Int64 // Messages rejected with Nack without requeue
 }
 
-func NewProcessor(config ProcessorConfig, logger logging.Logger, inputCh <-chan *models.ChannelMessage, outputCh chan<- *models.ChannelMessage) *Processor {
+// NewProcessor creates a processor that runs data messages through handler.
+// A nil handler uses the built-in ProcessingRecord transformation.
+func NewProcessor(config ProcessorConfig, logger logging.Logger, inputCh <-chan *models.ChannelMessage, outputCh chan *models.ChannelMessage, handler MessageProcessor) *Processor {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Processor{
-		config:   config,
-		logger:   logger,
-		inputCh:  inputCh,
-		outputCh: outputCh,
-		ctx:      ctx,
-		cancel:   cancel,
+	p := &Processor{
+		config:     config,
+		logger:     logger,
+		handler:    handler,
+		deadLetter: &deadLetterQueue{dropLevel: logging.ErrorLevel, logger: logger},
+		inputCh:    inputCh,
+		output:     newChannelWriter(outputCh, "", logger),
+		ctx:        ctx,
+		cancel:     cancel,
+		quit:       make(chan struct{}),
+	}
+	if p.handler == nil {
+		p.handler = MessageProcessorFunc(p.processRecord)
 	}
+	return p
 }
 
+// Start starts the configured number of workers, each reading from the input
+// channel and writing to the output channel. With more than one worker,
+// messages may reach the output channel in a different order than they were
+// read; a single worker preserves order. In ordered-by-key mode the workers
+// read from their own queues instead, so messages with the same key keep
+// their order. The worker count, mode and dedup settings are fixed until the
+// next Start, which also forgets the messages seen so far.
 func (p *Processor) Start() error {
-	p.logger.Infow("Starting processor", "batch_size", p.config.BatchSize, "processing_delay", p.config.ProcessingDelay)
-	go p.processLoop()
+	config := p.currentConfig()
+	workers := config.workerCount()
+	p.logger.Infow("Starting processor", "batch_size", config.BatchSize, "processing_delay", config.ProcessingDelay, "error_policy", config.ErrorPolicy, "workers", workers, "ordered_by_key", config.OrderedByKey, "dedup_window", config.DedupWindow)
+
+	if config.ProcessingDelay > 0 {
+		p.logger.Warnw("Processing delay is deprecated, use processing.output.maxMessagesPerSecond to limit throughput", "processing_delay", config.ProcessingDelay)
+	}
+
+	p.dedup = nil
+	if config.dedupEnabled() {
+		p.dedup = newDedupCache(config)
+	}
+
+	inputs := make([]<-chan *models.ChannelMessage, workers)
+	for i := range inputs {
+		inputs[i] = p.inputCh
+	}
+	if config.OrderedByKey {
+		p.queues = make([]chan *models.ChannelMessage, workers)
+		for i := range p.queues {
+			p.queues[i] = make(chan *models.ChannelMessage, queueSize(cap(p.inputCh), workers))
+			inputs[i] = p.queues[i]
+		}
+		p.workers.Add(1)
+		go p.dispatchLoop()
+	}
+
+	for i := 0; i < workers; i++ {
+		p.workers.Add(1)
+		if config.BatchMode {
+			go p.batchLoop(i, inputs[i])
+		} else {
+			go p.processLoop(i, inputs[i])
+		}
+	}
 	return nil
 }
 
+// Stop stops the workers, waits for each to finish the message it is
+// handling, and closes the dead-letter producer, if any. Handlers still
+// running after the default drain timeout have their context cancelled.
 func (p *Processor) Stop() error {
+	return p.stop(defaultDrainTimeout)
+}
+
+// stop stops the workers like Stop, cancelling the handler context once
+// grace has passed. Until then a message being handled, or waiting between
+// retries, finishes with a live context instead of failing.
+func (p *Processor) stop(grace time.Duration) error {
 	p.logger.Info("Stopping processor")
+	p.quitOnce.Do(func() { close(p.quit) })
+
+	finished := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(finished)
+	}()
+	deadline := time.NewTimer(grace)
+	defer deadline.Stop()
+	select {
+	case <-finished:
+	case <-deadline.C:
+		p.logger.Warnw("Processor workers still busy, cancelling their handlers", "grace", grace)
+		p.cancel()
+		<-finished
+	}
 	p.cancel()
+
+	if p.deadLetter != nil {
+		if err := p.deadLetter.close(); err != nil {
+			p.logger.Errorw("Error closing dead-letter producer", "error", err)
+			return err
+		}
+	}
 	return nil
 }
 
-func (p *Processor) processLoop() {
-	defer func() {
-		if r := recover(); r != nil {
-			p.logger.Errorw("Processor panic recovered", "panic", r)
+// recoverWorker reports a panicking worker to the failure handler. It must be
+// deferred directly by the worker loop.
+func (p *Processor) recoverWorker(worker int) {
+	if r := recover(); r != nil {
+		p.logger.Errorw("Processor panic recovered", "panic", r, "worker", worker)
+		if p.onFailure != nil {
+			p.onFailure(fmt.Errorf("processor stopped after panic: %v", r))
 		}
-	}()
+	}
+}
+
+func (p *Processor) processLoop(worker int, input <-chan *models.ChannelMessage) {
+	defer p.workers.Done()
+	defer p.recoverWorker(worker)
 
 	for {
 		select {
-		case <-p.ctx.Done():
-			p.logger.Info("Processor loop stopped")
+		case <-p.quit:
+			p.logger.Infow("Processor loop stopped", "worker", worker)
 			return
-		case message := <-p.inputCh:
+		case message := <-input:
+			p.dequeued()
+			if message.IsDataMessage() && p.screenOut(message, messageLogger(p.logger, message)) {
+				continue
+			}
 			if err := p.processMessage(message); err != nil {
 				p.logger.Errorw("Error processing message", "error", err)
 			}
@@ -76,50 +258,213 @@ func (p *Processor) processLoop() {
 }
 
 func (p *Processor) processMessage(message *models.ChannelMessage) error {
-	p.logger.Debugw("Processing message", "type", message.Type, "size", len(message.Data))
+	logger := messageLogger(p.logger, message)
+	logger.Debugw("Processing message", "type", message.Type, "size", len(message.Data))
 
 	// For non-data messages (control messages), forward them as-is
 	if !message.IsDataMessage() {
-		outputMessage := &models.ChannelMessage{
-			Type:      message.Type,
-			Data:      message.Data,
-			Timestamp: message.Timestamp,
-		}
-
-		p.outputCh <- outputMessage
+		p.forwardControl(message)
 		return nil
 	}
 
 	// For data messages, apply processing
+	outputMessage, attempts, err := p.process(message, logger)
+	if err != nil {
+		p.errors.Add(1)
+		p.handleFailure(message, err, attempts)
+		return err
+	}
+	if outputMessage == nil {
+		logger.Debugw("Message dropped by processor", "key", message.Key)
+		p.offsets.release(message.Sources)
+		return nil
+	}
+
+	// Keep the key, headers and correlation ID so the output is published
+	// under the same key and can be traced, and the source topic in case it
+	// has to be dead-lettered
+	if outputMessage.CorrelationID == "" {
+		outputMessage.CorrelationID = message.CorrelationID
+	}
+	if outputMessage.Topic == "" {
+		outputMessage.Topic = message.Topic
+	}
+	if outputMessage.Key == "" {
+		outputMessage.Key = message.Key
+	}
+	if outputMessage.Headers == nil {
+		outputMessage.Headers = message.Headers
+	}
+	outputMessage.Sources = message.Sources
+
+	p.output.send(context.Background(), outputMessage)
+	p.processed.Add(1)
+	logger.Debug("Processed message sent to output channel")
+
+	return nil
+}
+
+// screenOut reports whether a data message was taken out of the pipeline
+// before processing: handed to the error policy for failing schema
+// validation, or dropped as a duplicate or by the filter
+func (p *Processor) screenOut(message *models.ChannelMessage, logger logging.Logger) bool {
+	if err := p.validator.check(message); err != nil {
+		logger.Warnw("Message failed schema validation", "key", message.Key, "topic", message.Topic, "error", err)
+		headers := make(map[string]string, len(message.Headers)+1)
+		for k, v := range message.Headers {
+			headers[k] = v
+		}
+		headers[HeaderValidationError] = err.Error()
+		message.Headers = headers
+		p.errors.Add(1)
+		p.handleFailure(message, err, 1)
+		return true
+	}
+	if p.dedup.duplicate(message) {
+		logger.Debugw("Duplicate message dropped", "key", message.Key)
+		p.offsets.release(message.Sources)
+		return true
+	}
+	return p.filterOut(message, logger)
+}
+
+// filterOut runs a data message through the filter and reports whether it
+// was taken out of the pipeline: dropped by the rules, or handed to the error
+// policy because its payload could not be evaluated
+func (p *Processor) filterOut(message *models.ChannelMessage, logger logging.Logger) bool {
+	keep, err := p.filter.keep(message)
+	if err != nil {
+		logger.Warnw("Message could not be filtered", "key", message.Key, "error", err)
+		p.errors.Add(1)
+		p.handleFailure(message, err, 1)
+		return true
+	}
+	if !keep {
+		logger.Debugw("Message dropped by filter", "key", message.Key)
+		p.offsets.release(message.Sources)
+		return true
+	}
+	return false
+}
+
+// forwardControl passes a control message through unchanged
+func (p *Processor) forwardControl(message *models.ChannelMessage) {
+	p.output.send(context.Background(), &models.ChannelMessage{
+		Type:      message.Type,
+		Data:      message.Data,
+		Timestamp: message.Timestamp,
+	})
+}
+
+// process runs message through the handler, retrying failures when the
+// retry policy is configured. The handler context carries the correlation ID
+// of message. The handler may settle the message with Ack or Nack, which
+// overrides the error it returns. It also returns the number of attempts
+// made.
+func (p *Processor) process(message *models.ChannelMessage, logger logging.Logger) (*models.ChannelMessage, int, error) {
+	var outputMessage *models.ChannelMessage
+	acks := &settlement{}
+	message.SetAcknowledger(acks)
+	attempts, err := p.retry(WithCorrelationID(p.ctx, message.CorrelationID), logger, func(ctx context.Context) error {
+		acks.begin()
+		output, err := p.handler.Process(ctx, message)
+		outputMessage = output
+		err = acks.end(err)
+		if requeued(err) {
+			p.requeued.Add(1)
+		}
+		return err
+	})
+	return outputMessage, attempts, err
+}
+
+// retry calls fn, calling it again after a failure up to maxRetries times
+// when the retry policy is configured, and returns the number of calls made.
+// A message nacked with requeue is retried up to maxRetries times under any
+// policy, and one nacked without requeue is not retried. Failed attempts are
+// logged to logger. Waiting for a retry stops early when ctx is done.
+func (p *Processor) retry(ctx context.Context, logger logging.Logger, fn func(ctx context.Context) error) (int, error) {
+	settings := p.currentConfig()
+	attempts := 1
+	if settings.retryEnabled() {
+		attempts += settings.MaxRetries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			p.retries.Add(1)
+			select {
+			case <-time.After(time.Duration(attempt) * retryBackoff):
+			case <-ctx.Done():
+				return attempt, err
+			}
+		}
+
+		if err = fn(ctx); err == nil {
+			return attempt + 1, nil
+		}
+		logger.Debugw("Processing attempt failed", "attempt", attempt+1, "error", err)
+		if rejected(err) {
+			return attempt + 1, err
+		}
+		if requeued(err) {
+			attempts = 1 + settings.MaxRetries
+		}
+	}
+	return attempts, err
+}
+
+// handleFailure applies the error policy to a message that failed processing
+// after the given number of attempts. A message nacked without requeue is
+// dead-lettered under any policy, when a dead-letter topic is configured. A
+// message the policy drops is done with; one whose dead-letter publish failed
+// is lost.
+func (p *Processor) handleFailure(message *models.ChannelMessage, cause error, attempts int) {
+	p.topicStats.failed(message.Topic)
+	deadLetter := p.currentConfig().deadLetterEnabled()
+	if rejected(cause) {
+		p.rejected.Add(1)
+		deadLetter = p.deadLetter.enabled()
+	}
+	if !deadLetter {
+		p.deadLetter.drop(message, cause)
+		p.offsets.release(message.Sources)
+		return
+	}
+	if p.deadLetter.handle(message, cause, attempts) {
+		p.offsets.release(message.Sources)
+	} else {
+		p.offsets.fail(message.Sources)
+	}
+}
+
+// processRecord is the default MessageProcessor: it decodes a
+// ProcessingRecord, applies the built-in transformation and encodes the result
+func (p *Processor) processRecord(ctx context.Context, message *models.ChannelMessage) (*models.ChannelMessage, error) {
 	var record ProcessingRecord
 	if err := json.Unmarshal(message.Data, &record); err != nil {
-		return fmt.Errorf("failed to unmarshal input record: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal input record: %w", err)
 	}
 
 	processedRecord, err := p.applyProcessing(record)
 	if err != nil {
-		return fmt.Errorf("failed to apply processing: %w", err)
+		return nil, fmt.Errorf("failed to apply processing: %w", err)
 	}
 
 	processedData, err := json.Marshal(processedRecord)
 	if err != nil {
-		return fmt.Errorf("failed to marshal processed record: %w", err)
+		return nil, fmt.Errorf("failed to marshal processed record: %w", err)
 	}
 
-	// Create a new message with processed data
-	outputMessage := models.NewDataMessage(processedData, "processor")
-
-	p.outputCh <- outputMessage
-	p.logger.Debug("Processed message sent to output channel")
-
-	return nil
+	return models.NewDataMessage(processedData, "processor"), nil
 }
 
 func (p *Processor) applyProcessing(input ProcessingRecord) (ProcessingRecord, error) {
 	p.logger.Debugw("Applying processing transformations", "record_id", input.ID)
 
-	if p.config.ProcessingDelay > 0 {
-		time.Sleep(p.config.ProcessingDelay)
+	if delay := p.currentConfig().ProcessingDelay; delay > 0 {
+		time.Sleep(delay)
 	}
 
 	processed := ProcessingRecord{
@@ -148,16 +493,52 @@ func (p *Processor) applyProcessing(input ProcessingRecord) (ProcessingRecord, e
 	}
 	processed.Data["processing_stats"] = map[string]interface{}{
 		"processed_fields":    len(input.Data),
-		"processing_delay_ms": p.config.ProcessingDelay.Milliseconds(),
+		"processing_delay_ms": p.currentConfig().ProcessingDelay.Milliseconds(),
 	}
 
 	return processed, nil
 }
 
 func (p *Processor) GetStats() map[string]interface{} {
+	config := p.currentConfig()
 	return map[string]interface{}{
-		"status":           "running",
-		"batch_size":       p.config.BatchSize,
-		"processing_delay": p.config.ProcessingDelay.String(),
+		"status":               "running",
+		"batch_size":           config.BatchSize,
+		"concurrency":          config.workerCount(),
+		"ordered_by_key":       config.OrderedByKey,
+		"worker_queue_depths":  p.queueDepths(),
+		"processing_delay":     config.ProcessingDelay.String(),
+		"error_policy":         config.ErrorPolicy,
+		"messages_processed":   p.processed.Load(),
+		"processing_errors":    p.errors.Load(),
+		"retries":              p.retries.Load(),
+		"dropped":              p.deadLetter.dropped.Load(),
+		"dead_lettered":        p.deadLetter.published.Load(),
+		"dead_letter_failures": p.deadLetter.failures.Load(),
+		"requeued":             p.requeued.Load(),
+		"rejected":             p.rejected.Load(),
+		"backpressure_drops":   p.output.dropped.Load(),
+		"dedup_window":         config.DedupWindow.String(),
+		"duplicates_dropped":   p.dedup.dropped(),
+		"dedup_entries":        p.dedup.size(),
+		"batch_mode":           config.BatchMode,
+		"batches_processed":    p.batches.Load(),
+		"average_batch_size":   p.averageBatchSize(),
+		"average_batch_fill":   p.averageBatchFill(config.BatchSize),
 	}
 }
+
+// UpdateConfig replaces the processor settings; it takes effect from the
+// next message processed
+func (p *Processor) UpdateConfig(config ProcessorConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.config = config
+}
+
+// currentConfig returns a snapshot of the processor settings
+func (p *Processor) currentConfig() ProcessorConfig {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.config
+}
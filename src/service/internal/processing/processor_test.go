@@ -2,48 +2,19 @@ package processing
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"servicegomodule/internal/config"
 	"servicegomodule/internal/models"
 	"sharedgomodule/logging"
 )
 
-// mockLoggerForProcessor implements the logging.Logger interface for testing
-type mockLoggerForProcessor struct{}
-
-func (m *mockLoggerForProcessor) SetLevel(level logging.Level)                                 {}
-func (m *mockLoggerForProcessor) GetLevel() logging.Level                                      { return logging.InfoLevel }
-func (m *mockLoggerForProcessor) IsLevelEnabled(level logging.Level) bool                      { return true }
-func (m *mockLoggerForProcessor) Debug(msg string)                                             {}
-func (m *mockLoggerForProcessor) Info(msg string)                                              {}
-func (m *mockLoggerForProcessor) Warn(msg string)                                              {}
-func (m *mockLoggerForProcessor) Error(msg string)                                             {}
-func (m *mockLoggerForProcessor) Fatal(msg string)                                             {}
-func (m *mockLoggerForProcessor) Panic(msg string)                                             {}
-func (m *mockLoggerForProcessor) Debugf(format string, args ...interface{})                    {}
-func (m *mockLoggerForProcessor) Infof(format string, args ...interface{})                     {}
-func (m *mockLoggerForProcessor) Warnf(format string, args ...interface{})                     {}
-func (m *mockLoggerForProcessor) Errorf(format string, args ...interface{})                    {}
-func (m *mockLoggerForProcessor) Fatalf(format string, args ...interface{})                    {}
-func (m *mockLoggerForProcessor) Panicf(format string, args ...interface{})                    {}
-func (m *mockLoggerForProcessor) Debugw(msg string, keysAndValues ...interface{})              {}
-func (m *mockLoggerForProcessor) Infow(msg string, keysAndValues ...interface{})               {}
-func (m *mockLoggerForProcessor) Warnw(msg string, keysAndValues ...interface{})               {}
-func (m *mockLoggerForProcessor) Errorw(msg string, keysAndValues ...interface{})              {}
-func (m *mockLoggerForProcessor) Fatalw(msg string, keysAndValues ...interface{})              {}
-func (m *mockLoggerForProcessor) Panicw(msg string, keysAndValues ...interface{})              {}
-func (m *mockLoggerForProcessor) WithFields(fields logging.Fields) logging.Logger              { return m }
-func (m *mockLoggerForProcessor) WithField(key string, value interface{}) logging.Logger       { return m }
-func (m *mockLoggerForProcessor) WithError(err error) logging.Logger                           { return m }
-func (m *mockLoggerForProcessor) WithContext(ctx context.Context) logging.Logger               { return m }
-func (m *mockLoggerForProcessor) Log(level logging.Level, msg string)                          {}
-func (m *mockLoggerForProcessor) Logf(level logging.Level, format string, args ...interface{}) {}
-func (m *mockLoggerForProcessor) Logw(level logging.Level, msg string, keysAndValues ...interface{}) {
-}
-func (m *mockLoggerForProcessor) Clone() logging.Logger { return m }
-func (m *mockLoggerForProcessor) Close() error          { return nil }
-
 func TestProcessorConfig(t *testing.T) {
 	config := ProcessorConfig{
 		ProcessingDelay: 10 * time.Millisecond,
@@ -64,11 +35,11 @@ func TestProcessorCreation(t *testing.T) {
 		ProcessingDelay: 5 * time.Millisecond,
 		BatchSize:       50,
 	}
-	logger := &mockLoggerForProcessor{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	if processor == nil {
 		t.Fatal("Expected processor to be created, got nil")
@@ -88,11 +59,11 @@ func TestProcessorStatsRetrieval(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       10,
 	}
-	logger := &mockLoggerForProcessor{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	stats := processor.GetStats()
 	if stats == nil {
@@ -114,11 +85,11 @@ func TestProcessorMessageFlow(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       5,
 	}
-	logger := &mockLoggerForProcessor{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 	err := processor.Start()
 	if err != nil {
 		t.Fatalf("Failed to start processor: %v", err)
@@ -153,11 +124,11 @@ func TestProcessorNonDataMessageForwarding(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       5,
 	}
-	logger := &mockLoggerForProcessor{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 	err := processor.Start()
 	if err != nil {
 		t.Fatalf("Failed to start processor: %v", err)
@@ -191,11 +162,11 @@ func TestProcessorLifecycle(t *testing.T) {
 		ProcessingDelay: 1 * time.Millisecond,
 		BatchSize:       5,
 	}
-	logger := &mockLoggerForProcessor{}
+	logger := logging.NewNopLogger()
 	inputCh := make(chan *models.ChannelMessage, 10)
 	outputCh := make(chan *models.ChannelMessage, 10)
 
-	processor := NewProcessor(config, logger, inputCh, outputCh)
+	processor := NewProcessor(config, logger, inputCh, outputCh, nil)
 
 	// Test start
 	err := processor.Start()
@@ -209,3 +180,269 @@ func TestProcessorLifecycle(t *testing.T) {
 		t.Fatalf("Failed to stop processor: %v", err)
 	}
 }
+
+// runProcessor feeds msg through a started processor and returns the output
+// message, or nil if none arrived, together with the stopped processor's stats
+func runProcessor(t *testing.T, processor *Processor, inputCh chan<- *models.ChannelMessage, outputCh <-chan *models.ChannelMessage, msg *models.ChannelMessage) (*models.ChannelMessage, map[string]interface{}) {
+	t.Helper()
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	inputCh <- msg
+
+	var output *models.ChannelMessage
+	select {
+	case output = <-outputCh:
+	case <-time.After(200 * time.Millisecond):
+	}
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Failed to stop processor: %v", err)
+	}
+	return output, processor.GetStats()
+}
+
+func TestProcessorCustomMessageProcessor(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 1)
+	outputCh := make(chan *models.ChannelMessage, 1)
+	upper := MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return models.NewDataMessage([]byte(strings.ToUpper(string(msg.Data))), "test"), nil
+	})
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1}, logging.NewNopLogger(), inputCh, outputCh, upper)
+
+	input := models.NewDataMessage([]byte("hello"), "test")
+	input.Key = "k1"
+	output, stats := runProcessor(t, processor, inputCh, outputCh, input)
+
+	if output == nil {
+		t.Fatal("Expected a processed message")
+	}
+	if string(output.Data) != "HELLO" {
+		t.Errorf("Expected transformed payload %q, got %q", "HELLO", output.Data)
+	}
+	if output.Key != "k1" {
+		t.Errorf("Expected the input key to be carried over, got %q", output.Key)
+	}
+	if stats["messages_processed"] != int64(1) {
+		t.Errorf("Expected 1 processed message, got %v", stats["messages_processed"])
+	}
+}
+
+func TestProcessorErrorPolicies(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = 100 * time.Millisecond }()
+
+	// failing fails the first n calls and then echoes the message
+	failing := func(n int) MessageProcessor {
+		calls := 0
+		return MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+			calls++
+			if calls <= n {
+				return nil, errors.New("boom")
+			}
+			return msg, nil
+		})
+	}
+
+	tests := []struct {
+		name       string
+		config     ProcessorConfig
+		failures   int
+		wantOutput bool
+		wantStats  map[string]int64
+	}{
+		{"drop", ProcessorConfig{ErrorPolicy: config.ErrorPolicyDrop}, 1, false,
+			map[string]int64{"processing_errors": 1, "dropped": 1, "retries": 0}},
+		{"retry succeeds", ProcessorConfig{ErrorPolicy: config.ErrorPolicyRetry, MaxRetries: 3}, 2, true,
+			map[string]int64{"processing_errors": 0, "retries": 2, "messages_processed": 1}},
+		{"retry exhausted", ProcessorConfig{ErrorPolicy: config.ErrorPolicyRetry, MaxRetries: 2}, 5, false,
+			map[string]int64{"processing_errors": 1, "retries": 2, "dropped": 1}},
+		{"dead letter", ProcessorConfig{ErrorPolicy: config.ErrorPolicyDeadLetter, DeadLetterTopic: "dlq"}, 1, false,
+			map[string]int64{"processing_errors": 1, "dead_lettered": 1, "dropped": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputCh := make(chan *models.ChannelMessage, 1)
+			outputCh := make(chan *models.ChannelMessage, 1)
+			processor := NewProcessor(tt.config, logging.NewNopLogger(), inputCh, outputCh, failing(tt.failures))
+			deadLetter := &mockProducerForOutput{}
+			processor.deadLetter.producer = deadLetter
+			processor.deadLetter.topic = tt.config.DeadLetterTopic
+
+			input := models.NewDataMessage([]byte("payload"), "test")
+			input.Key = "k1"
+			input.Topic = "orders"
+			output, stats := runProcessor(t, processor, inputCh, outputCh, input)
+
+			if (output != nil) != tt.wantOutput {
+				t.Errorf("Expected output %v, got %+v", tt.wantOutput, output)
+			}
+			for name, want := range tt.wantStats {
+				if stats[name] != want {
+					t.Errorf("Expected %s = %d, got %v", name, want, stats[name])
+				}
+			}
+
+			if tt.config.ErrorPolicy == config.ErrorPolicyDeadLetter {
+				if len(deadLetter.messages) != 1 {
+					t.Fatalf("Expected 1 dead-lettered message, got %d", len(deadLetter.messages))
+				}
+				dl := deadLetter.messages[0]
+				if dl.Topic != "dlq" || dl.Key != "k1" || string(dl.Value) != "payload" || dl.Headers["error"] != "boom" || dl.Headers["source_topic"] != "orders" || dl.Headers["attempts"] != "1" {
+					t.Errorf("Unexpected dead-letter message %+v", dl)
+				}
+			}
+			if !deadLetter.closed {
+				t.Error("Expected the dead-letter producer to be closed on Stop")
+			}
+		})
+	}
+}
+
+func TestProcessorWorkerPool(t *testing.T) {
+	const workers = 4
+	inputCh := make(chan *models.ChannelMessage, workers)
+	outputCh := make(chan *models.ChannelMessage, workers)
+
+	// Every call blocks until all workers are inside Process at once, which
+	// only happens if the messages are handled concurrently
+	var inFlight atomic.Int32
+	release := make(chan struct{})
+	barrier := MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		if inFlight.Add(1) == workers {
+			close(release)
+		}
+		select {
+		case <-release:
+			return msg, nil
+		case <-time.After(time.Second):
+			return nil, errors.New("workers did not run concurrently")
+		}
+	})
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1, Concurrency: workers}, logging.NewNopLogger(), inputCh, outputCh, barrier)
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+
+	for i := 0; i < workers; i++ {
+		inputCh <- models.NewDataMessage([]byte{byte(i)}, "test")
+	}
+	for i := 0; i < workers; i++ {
+		select {
+		case <-outputCh:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Received %d of %d messages", i, workers)
+		}
+	}
+
+	if err := processor.Stop(); err != nil {
+		t.Fatalf("Failed to stop processor: %v", err)
+	}
+	stats := processor.GetStats()
+	if stats["messages_processed"] != int64(workers) || stats["processing_errors"] != int64(0) {
+		t.Errorf("Expected %d processed messages and no errors, got %v and %v", workers, stats["messages_processed"], stats["processing_errors"])
+	}
+	if stats["concurrency"] != workers {
+		t.Errorf("Expected concurrency %d in stats, got %v", workers, stats["concurrency"])
+	}
+}
+
+func TestProcessorOrderedByKey(t *testing.T) {
+	const workers, perKey = 4, 50
+	inputCh := make(chan *models.ChannelMessage, 2*perKey)
+	outputCh := make(chan *models.ChannelMessage, 2*perKey)
+
+	// Uneven processing times reorder messages unless each key stays on
+	// one worker
+	var calls atomic.Int32
+	uneven := MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		time.Sleep(time.Duration(calls.Add(1)%3) * time.Millisecond)
+		return msg, nil
+	})
+	settings := ProcessorConfig{BatchSize: 1, Concurrency: workers, OrderedByKey: true}
+	processor := NewProcessor(settings, logging.NewNopLogger(), inputCh, outputCh, uneven)
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+	defer processor.Stop()
+
+	for i := 0; i < perKey; i++ {
+		for _, key := range []string{"a", "b"} {
+			message := models.NewDataMessage([]byte(fmt.Sprintf("%s-%d", key, i)), "test")
+			message.Key = key
+			inputCh <- message
+		}
+	}
+
+	next := map[string]int{}
+	for i := 0; i < 2*perKey; i++ {
+		select {
+		case message := <-outputCh:
+			if want := fmt.Sprintf("%s-%d", message.Key, next[message.Key]); string(message.Data) != want {
+				t.Fatalf("Expected %s next for key %s, got %s", want, message.Key, message.Data)
+			}
+			next[message.Key]++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Received %d of %d messages", i, 2*perKey)
+		}
+	}
+
+	stats := processor.GetStats()
+	if depths, ok := stats["worker_queue_depths"].([]int); !ok || len(depths) != workers {
+		t.Errorf("Expected %d worker queue depths in stats, got %v", workers, stats["worker_queue_depths"])
+	}
+}
+
+func TestProcessorRoute(t *testing.T) {
+	processor := &Processor{queues: make([]chan *models.ChannelMessage, 3)}
+	keyed := &models.ChannelMessage{Key: "entity-42"}
+	first := processor.route(keyed)
+	for i := 0; i < 10; i++ {
+		if queue := processor.route(keyed); queue != first {
+			t.Fatalf("Expected key to stay on queue %d, got %d", first, queue)
+		}
+	}
+
+	// Messages without a key go round-robin
+	unkeyed := &models.ChannelMessage{}
+	for i := 0; i < 6; i++ {
+		if queue := processor.route(unkeyed); queue != i%3 {
+			t.Errorf("Expected unkeyed message %d on queue %d, got %d", i, i%3, queue)
+		}
+	}
+}
+
+// BenchmarkProcessorWorkers compares throughput of one and eight workers with
+// a CPU-bound processor
+func BenchmarkProcessorWorkers(b *testing.B) {
+	cpuBound := MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		sum := sha256.Sum256(msg.Data)
+		for i := 0; i < 200; i++ {
+			sum = sha256.Sum256(sum[:])
+		}
+		return models.NewDataMessage(sum[:], "bench"), nil
+	})
+
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			inputCh := make(chan *models.ChannelMessage, 1000)
+			outputCh := make(chan *models.ChannelMessage, 1000)
+			processor := NewProcessor(ProcessorConfig{BatchSize: 1, Concurrency: workers}, logging.NewNopLogger(), inputCh, outputCh, cpuBound)
+			if err := processor.Start(); err != nil {
+				b.Fatalf("Failed to start processor: %v", err)
+			}
+			defer processor.Stop()
+
+			payload := []byte("benchmark payload")
+			b.ResetTimer()
+			go func() {
+				for i := 0; i < b.N; i++ {
+					inputCh <- models.NewDataMessage(payload, "bench")
+				}
+			}()
+			for i := 0; i < b.N; i++ {
+				<-outputCh
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package processing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"sharedgomodule/messagebus"
+)
+
+// Poll recovery defaults, used when the InputConfig fields are zero
+const (
+	defaultPollBackoff    = 100 * time.Millisecond
+	defaultPollMaxBackoff = 30 * time.Second
+	defaultReconnectAfter = 5
+	defaultMaxReconnects  = 3
+)
+
+// pollRecovery tracks the consecutive poll failures of the consume loop.
+// Only the consume loop touches it.
+type pollRecovery struct {
+	failures       int  // Failed polls since the last successful one
+	sinceReconnect int  // Failed polls since the consumer was last reconnected
+	reconnects     int  // Reconnects since the last successful poll
+	degraded       bool // onDegraded has been called for this run of failures
+}
+
+// pollBackoff returns the wait after the nth consecutive failed poll: the
+// configured backoff doubled for every failure before it, capped at the
+// configured maximum
+func (c InputConfig) pollBackoff(failures int) time.Duration {
+	backoff, limit := c.PollBackoff, c.PollMaxBackoff
+	if backoff <= 0 {
+		backoff = defaultPollBackoff
+	}
+	if limit <= 0 {
+		limit = defaultPollMaxBackoff
+	}
+	for n := 1; n < failures && backoff < limit; n++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+func (c InputConfig) reconnectAfter() int {
+	if c.ReconnectAfter <= 0 {
+		return defaultReconnectAfter
+	}
+	return c.ReconnectAfter
+}
+
+func (c InputConfig) maxReconnects() int {
+	if c.MaxReconnects <= 0 {
+		return defaultMaxReconnects
+	}
+	return c.MaxReconnects
+}
+
+// newBusConsumer creates a message bus consumer with the settings of
+// NewInputHandler, turning the panic of a bad configuration into an error
+func newBusConsumer() (consumer messagebus.Consumer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to create consumer: %v", r)
+		}
+	}()
+	return messagebus.NewConsumer(consumerConfigFile, consumerGroup), nil
+}
+
+// recoverPoll handles a failed poll on the consume loop. Polling backs off
+// exponentially while the failures last. A fatal error, or reconnectAfter
+// failures in a row, reconnects the consumer; once maxReconnects reconnects
+// have not brought polling back, the handler reports itself degraded and
+// keeps trying. It returns false if the handler was stopped while waiting.
+func (i *InputHandler) recoverPoll(err error) bool {
+	r := &i.recovery
+	r.failures++
+	r.sinceReconnect++
+	i.pollErrors.Add(1)
+	i.lastPollError.Store(err.Error())
+
+	fatal := errors.Is(err, messagebus.ErrFatal)
+	backoff := i.config.pollBackoff(r.failures)
+	i.logger.Warnw("Error polling for messages", "error", err, "attempt", r.failures, "fatal", fatal, "backoff", backoff)
+
+	if fatal || r.sinceReconnect >= i.config.reconnectAfter() {
+		if r.reconnects >= i.config.maxReconnects() && !r.degraded {
+			r.degraded = true
+			i.degraded.Store(true)
+			i.logger.Errorw("Input handler degraded, reconnecting has not restored polling", "error", err, "attempt", r.failures, "reconnects", r.reconnects)
+			if i.onDegraded != nil {
+				i.onDegraded(err)
+			}
+		}
+		r.reconnects++
+		r.sinceReconnect = 0
+		i.logger.Warnw("Reconnecting consumer", "error", err, "attempt", r.reconnects)
+		if reconnectErr := i.reconnect(); reconnectErr != nil {
+			i.reconnectFailures.Add(1)
+			i.logger.Errorw("Failed to reconnect consumer", "error", reconnectErr, "attempt", r.reconnects)
+		} else {
+			i.reconnects.Add(1)
+			i.logger.Infow("Consumer reconnected", "topics", i.Topics(), "attempt", r.reconnects)
+		}
+	}
+
+	return i.waitBackoff(backoff)
+}
+
+// pollSucceeded resets the recovery after a successful poll
+func (i *InputHandler) pollSucceeded() {
+	r := &i.recovery
+	if r.failures == 0 {
+		return
+	}
+	i.logger.Infow("Polling recovered", "attempt", r.failures, "reconnects", r.reconnects)
+	if r.degraded {
+		i.degraded.Store(false)
+		if i.onRecovered != nil {
+			i.onRecovered()
+		}
+	}
+	i.recovery = pollRecovery{}
+}
+
+// reconnect replaces the consumer with a new one subscribed to the current
+// topics, or resubscribes the existing one when the handler has no way to
+// create consumers. Messages still in flight may be consumed again.
+func (i *InputHandler) reconnect() error {
+	if i.newConsumer == nil {
+		return i.consumer.Subscribe(i.Topics())
+	}
+
+	consumer, err := i.newConsumer()
+	if err != nil {
+		return err
+	}
+	if err := consumer.Subscribe(i.Topics()); err != nil {
+		consumer.Close()
+		return fmt.Errorf("failed to subscribe to topics: %w", err)
+	}
+
+	previous := i.consumer
+	i.consumer = consumer
+	i.offsets.setConsumer(consumer)
+	if err := previous.Close(); err != nil {
+		i.logger.Warnw("Error closing replaced consumer", "error", err)
+	}
+	return nil
+}
+
+// waitBackoff waits out a poll backoff, still applying topic updates. It
+// returns false if the handler is stopped first.
+func (i *InputHandler) waitBackoff(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-i.ctx.Done():
+			return false
+		case update := <-i.updates:
+			update.result <- i.resubscribe(update.topics)
+		case <-timer.C:
+			return true
+		}
+	}
+}
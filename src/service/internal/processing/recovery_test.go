@@ -0,0 +1,244 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// scriptedConsumer fails its first failures polls with err, then serves its
+// queued messages, waiting out the poll timeout when there are none. A
+// negative failures fails every poll.
+type scriptedConsumer struct {
+	mutex      sync.Mutex
+	failures   int
+	err        error
+	polls      int
+	subscribed int
+	closed     bool
+	messages   []*messagebus.Message
+}
+
+func (s *scriptedConsumer) Subscribe(topics []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscribed++
+	return nil
+}
+
+func (s *scriptedConsumer) Poll(timeout time.Duration) (*messagebus.Message, error) {
+	s.mutex.Lock()
+	s.polls++
+	if s.failures < 0 || s.polls <= s.failures {
+		s.mutex.Unlock()
+		return nil, s.err
+	}
+	if len(s.messages) > 0 {
+		message := s.messages[0]
+		s.messages = s.messages[1:]
+		s.mutex.Unlock()
+		return message, nil
+	}
+	s.mutex.Unlock()
+	time.Sleep(timeout)
+	return nil, nil
+}
+
+func (s *scriptedConsumer) Commit(ctx context.Context, message *messagebus.Message) error {
+	return nil
+}
+
+func (s *scriptedConsumer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *scriptedConsumer) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+func (s *scriptedConsumer) setFailures(failures int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures = failures
+}
+
+func recoveryConfig() InputConfig {
+	return InputConfig{
+		Topics:            []string{"orders"},
+		PollTimeout:       5 * time.Millisecond,
+		ChannelBufferSize: 1,
+		PollBackoff:       time.Millisecond,
+		PollMaxBackoff:    4 * time.Millisecond,
+		ReconnectAfter:    3,
+		MaxReconnects:     2,
+	}
+}
+
+func receiveMessage(t *testing.T, handler *InputHandler) {
+	t.Helper()
+	select {
+	case <-handler.GetInputChannel():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a message on the input channel")
+	}
+}
+
+func TestInputConfigPollBackoff(t *testing.T) {
+	settings := InputConfig{PollBackoff: 10 * time.Millisecond, PollMaxBackoff: 50 * time.Millisecond}
+	for failures, want := range map[int]time.Duration{
+		1:  10 * time.Millisecond,
+		2:  20 * time.Millisecond,
+		3:  40 * time.Millisecond,
+		4:  50 * time.Millisecond,
+		60: 50 * time.Millisecond,
+	} {
+		if got := settings.pollBackoff(failures); got != want {
+			t.Errorf("pollBackoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+
+	var defaults InputConfig
+	if defaults.pollBackoff(1) != defaultPollBackoff || defaults.pollBackoff(100) != defaultPollMaxBackoff {
+		t.Errorf("Expected the default backoff to run from %v to %v", defaultPollBackoff, defaultPollMaxBackoff)
+	}
+	if defaults.reconnectAfter() != defaultReconnectAfter || defaults.maxReconnects() != defaultMaxReconnects {
+		t.Errorf("Expected default reconnect limits, got %d and %d", defaults.reconnectAfter(), defaults.maxReconnects())
+	}
+}
+
+func TestInputHandlerRecoversFromTransientPollErrors(t *testing.T) {
+	consumer := &scriptedConsumer{
+		failures: 2,
+		err:      errors.New("broker transport failure"),
+		messages: []*messagebus.Message{{Topic: "orders", Value: []byte("{}")}},
+	}
+	handler := NewInputHandlerWithConsumer(recoveryConfig(), consumer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	defer handler.Stop()
+
+	receiveMessage(t, handler)
+	stats := handler.GetStats()
+	if stats["poll_errors"] != int64(2) || stats["reconnects"] != int64(0) {
+		t.Errorf("Expected 2 poll errors and no reconnect, got %v and %v", stats["poll_errors"], stats["reconnects"])
+	}
+	if stats["last_poll_error"] != "broker transport failure" || stats["status"] != "running" {
+		t.Errorf("Expected the last error on a running handler, got %v (%v)", stats["last_poll_error"], stats["status"])
+	}
+}
+
+func TestInputHandlerReconnectsAfterConsecutiveFailures(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		failures int
+	}{
+		"transient errors": {errors.New("broker transport failure"), 3},
+		"fatal error":      {fmt.Errorf("%w: authentication failed", messagebus.ErrFatal), 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			original := &scriptedConsumer{failures: -1, err: tc.err}
+			replacement := &scriptedConsumer{messages: []*messagebus.Message{{Topic: "orders", Value: []byte("{}")}}}
+			handler := NewInputHandlerWithConsumer(recoveryConfig(), original, logging.NewNopLogger())
+			created := make(chan struct{}, 1)
+			handler.newConsumer = func() (messagebus.Consumer, error) {
+				created <- struct{}{}
+				return replacement, nil
+			}
+			if err := handler.Start(); err != nil {
+				t.Fatalf("Start() returned error: %v", err)
+			}
+			defer handler.Stop()
+
+			receiveMessage(t, handler)
+			if !original.isClosed() {
+				t.Error("Expected the failing consumer to be closed")
+			}
+			if replacement.subscribed != 1 {
+				t.Errorf("Expected the new consumer to be subscribed once, got %d", replacement.subscribed)
+			}
+			stats := handler.GetStats()
+			if stats["poll_errors"] != int64(tc.failures) || stats["reconnects"] != int64(1) {
+				t.Errorf("Expected %d poll errors and 1 reconnect, got %v and %v", tc.failures, stats["poll_errors"], stats["reconnects"])
+			}
+		})
+	}
+}
+
+func TestInputHandlerDegradesWhenReconnectingFails(t *testing.T) {
+	consumer := &scriptedConsumer{failures: -1, err: errors.New("broker transport failure")}
+	handler := NewInputHandlerWithConsumer(recoveryConfig(), consumer, logging.NewNopLogger())
+	degraded := make(chan error, 1)
+	recovered := make(chan struct{}, 1)
+	handler.onDegraded = func(err error) { degraded <- err }
+	handler.onRecovered = func() { recovered <- struct{}{} }
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	defer handler.Stop()
+
+	select {
+	case err := <-degraded:
+		if err.Error() != "broker transport failure" {
+			t.Errorf("Expected the last poll error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the handler to degrade")
+	}
+	if status := handler.GetStats()["status"]; status != "degraded" {
+		t.Errorf("Expected status degraded, got %v", status)
+	}
+
+	// Without a consumer factory, reconnecting resubscribes the consumer
+	consumer.mutex.Lock()
+	subscribed := consumer.subscribed
+	consumer.mutex.Unlock()
+	if subscribed < 3 {
+		t.Errorf("Expected Start and two reconnects to subscribe, got %d subscriptions", subscribed)
+	}
+
+	consumer.setFailures(0)
+	select {
+	case <-recovered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the handler to recover")
+	}
+	if status := handler.GetStats()["status"]; status != "running" {
+		t.Errorf("Expected status running after recovering, got %v", status)
+	}
+}
+
+func TestPipelineInputDegraded(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+
+	pipeline.inputDegraded(errors.New("broker down"))
+	if state := pipeline.Metrics().State; state != PipelineStopped {
+		t.Errorf("Expected a stopped pipeline to stay stopped, got %s", state)
+	}
+
+	pipeline.state.Store(PipelineRunning)
+	pipeline.inputHandler.lastPollError.Store("broker down")
+	pipeline.inputHandler.onDegraded(errors.New("broker down"))
+	if state := pipeline.Metrics().State; state != PipelineDegraded {
+		t.Errorf("Expected state degraded, got %s", state)
+	}
+	if err := pipeline.CheckHealth()[HealthComponentPipeline]; err == nil || err.Error() != "pipeline is degraded: broker down" {
+		t.Errorf("Expected the degraded pipeline to report the poll error, got %v", err)
+	}
+
+	pipeline.inputHandler.onRecovered()
+	if state := pipeline.Metrics().State; state != PipelineRunning {
+		t.Errorf("Expected state running after recovering, got %s", state)
+	}
+}
@@ -0,0 +1,116 @@
+package processing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"servicegomodule/internal/models"
+)
+
+// ErrInvalidSchema is returned when a schema file cannot be read or parsed
+var ErrInvalidSchema = errors.New("invalid payload schema")
+
+// HeaderValidationError is set on a message that failed schema validation
+// before it is handed to the error policy
+const HeaderValidationError = "validation_error"
+
+// ValidationConfig holds the payload schema validation settings
+type ValidationConfig struct {
+	Schemas map[string]string // JSON Schema file for each input topic; topics without one are not validated
+}
+
+// schemaValidator checks the JSON payload of data messages against the
+// schema for their topic
+type schemaValidator struct {
+	mutex   sync.RWMutex
+	schemas map[string]*jsonSchema
+	files   map[string]string
+
+	valid   atomic.Int64
+	invalid atomic.Int64
+}
+
+// load replaces the schemas with those named by settings. On error the
+// previous schemas stay in effect. It returns the number of schemas loaded.
+func (v *schemaValidator) load(settings ValidationConfig) (int, error) {
+	schemas := make(map[string]*jsonSchema, len(settings.Schemas))
+	files := make(map[string]string, len(settings.Schemas))
+	for topic, path := range settings.Schemas {
+		schema, err := loadSchema(path)
+		if err != nil {
+			return 0, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		schemas[topic] = schema
+		files[topic] = path
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.schemas = schemas
+	v.files = files
+	return len(schemas), nil
+}
+
+// loadSchema reads and compiles a JSON Schema file
+func loadSchema(path string) (*jsonSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	schema, err := compileSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSchema, path, err)
+	}
+	return schema, nil
+}
+
+// check validates the payload of message against the schema for its topic.
+// Messages on topics without a schema always pass. The methods of a nil
+// validator do nothing.
+func (v *schemaValidator) check(message *models.ChannelMessage) error {
+	if v == nil {
+		return nil
+	}
+	v.mutex.RLock()
+	schema := v.schemas[message.Topic]
+	v.mutex.RUnlock()
+	if schema == nil {
+		return nil
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(message.Data, &payload); err != nil {
+		v.invalid.Add(1)
+		return fmt.Errorf("schema validation: payload is not JSON: %v", err)
+	}
+	if err := schema.validate(payload); err != nil {
+		v.invalid.Add(1)
+		return fmt.Errorf("schema validation: %w", err)
+	}
+	v.valid.Add(1)
+	return nil
+}
+
+// stats returns the schema files in use and the counters
+func (v *schemaValidator) stats() map[string]interface{} {
+	if v == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	v.mutex.RLock()
+	files := make(map[string]string, len(v.files))
+	for topic, path := range v.files {
+		files[topic] = path
+	}
+	v.mutex.RUnlock()
+
+	return map[string]interface{}{
+		"enabled": len(files) > 0,
+		"schemas": files,
+		"valid":   v.valid.Load(),
+		"invalid": v.invalid.Load(),
+	}
+}
@@ -0,0 +1,198 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// orderSchema requires an order id and a positive integer quantity
+const orderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "order",
+	"type": "object",
+	"required": ["id", "quantity"],
+	"properties": {
+		"id": {"type": "string", "minLength": 1, "pattern": "^o-"},
+		"quantity": {"type": "integer", "minimum": 1},
+		"status": {"enum": ["new", "paid"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+	},
+	"additionalProperties": false
+}`
+
+// writeSchemaFile writes schema to a file in a temporary directory
+func writeSchemaFile(t *testing.T, schema string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(schema), 0o644); err != nil {
+		t.Fatalf("Failed to write schema file: %v", err)
+	}
+	return path
+}
+
+func topicMessage(topic, payload string) *models.ChannelMessage {
+	message := models.NewDataMessage([]byte(payload), "test")
+	message.Topic = topic
+	return message
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := compileSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatalf("compileSchema() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload interface{}
+		wantErr string
+	}{
+		{"valid", map[string]interface{}{"id": "o-1", "quantity": 2.0, "status": "paid", "tags": []interface{}{"a"}}, ""},
+		{"not an object", []interface{}{}, "$: expected object, got array"},
+		{"missing required", map[string]interface{}{"id": "o-1"}, `$: missing required property "quantity"`},
+		{"wrong type", map[string]interface{}{"id": "o-1", "quantity": "2"}, "$.quantity: expected integer, got string"},
+		{"fraction for integer", map[string]interface{}{"id": "o-1", "quantity": 1.5}, "$.quantity: expected integer, got number"},
+		{"below minimum", map[string]interface{}{"id": "o-1", "quantity": 0.0}, "$.quantity: 0 is less than the minimum 1"},
+		{"pattern", map[string]interface{}{"id": "x-1", "quantity": 1.0}, `$.id: "x-1" does not match pattern ^o-`},
+		{"enum", map[string]interface{}{"id": "o-1", "quantity": 1.0, "status": "lost"}, "$.status: lost is not one of the allowed values"},
+		{"array item", map[string]interface{}{"id": "o-1", "quantity": 1.0, "tags": []interface{}{"a", 1.0}}, "$.tags[1]: expected string, got integer"},
+		{"too many items", map[string]interface{}{"id": "o-1", "quantity": 1.0, "tags": []interface{}{"a", "b", "c"}}, "$.tags: expected at most 2 items, got 3"},
+		{"additional property", map[string]interface{}{"id": "o-1", "quantity": 1.0, "extra": true}, `$: unexpected property "extra"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.validate(tt.payload)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		`[]`,
+		`{"type":"decimal"}`,
+		`{"oneOf":[{"type":"string"}]}`,
+		`{"properties":{"id":{"pattern":"("}}}`,
+		`{"minLength":-1}`,
+	} {
+		if _, err := compileSchema([]byte(schema)); err == nil {
+			t.Errorf("compileSchema(%s) returned no error", schema)
+		}
+	}
+}
+
+func TestSchemaValidatorCheck(t *testing.T) {
+	validator := &schemaValidator{}
+	if _, err := validator.load(ValidationConfig{Schemas: map[string]string{"orders": writeSchemaFile(t, orderSchema)}}); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+
+	if err := validator.check(topicMessage("orders", `{"id":"o-1","quantity":3}`)); err != nil {
+		t.Errorf("Expected a valid payload to pass, got %v", err)
+	}
+	if err := validator.check(topicMessage("orders", `{"id":"o-1","quantity":-3}`)); err == nil || !strings.Contains(err.Error(), "minimum") {
+		t.Errorf("Expected an invalid payload to fail, got %v", err)
+	}
+	if err := validator.check(topicMessage("orders", "\x00\x01 not json")); err == nil || !strings.Contains(err.Error(), "not JSON") {
+		t.Errorf("Expected non-JSON bytes to fail, got %v", err)
+	}
+	if err := validator.check(topicMessage("payments", "not json")); err != nil {
+		t.Errorf("Expected a topic without a schema to pass, got %v", err)
+	}
+
+	stats := validator.stats()
+	if stats["enabled"] != true || stats["valid"] != int64(1) || stats["invalid"] != int64(2) {
+		t.Errorf("Expected 1 valid and 2 invalid, got %v", stats)
+	}
+
+	var disabled *schemaValidator
+	if disabled.check(topicMessage("orders", "not json")) != nil || disabled.stats()["enabled"] != false {
+		t.Error("Expected a nil validator to pass everything")
+	}
+}
+
+func TestSchemaValidatorLoadKeepsSchemasOnError(t *testing.T) {
+	validator := &schemaValidator{}
+	good := ValidationConfig{Schemas: map[string]string{"orders": writeSchemaFile(t, orderSchema)}}
+	if _, err := validator.load(good); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+
+	for _, path := range []string{writeSchemaFile(t, `{"type":`), filepath.Join(t.TempDir(), "missing.json")} {
+		_, err := validator.load(ValidationConfig{Schemas: map[string]string{"orders": path}})
+		if !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("load(%s) error = %v, want ErrInvalidSchema", path, err)
+		}
+	}
+	if err := validator.check(topicMessage("orders", `{"id":"o-1"}`)); err == nil {
+		t.Error("Expected the previous schema to stay in effect")
+	}
+}
+
+func TestProcessorValidationStage(t *testing.T) {
+	inputCh := make(chan *models.ChannelMessage, 3)
+	outputCh := make(chan *models.ChannelMessage, 3)
+	processor := NewProcessor(ProcessorConfig{BatchSize: 1, ErrorPolicy: config.ErrorPolicyDeadLetter}, logging.NewNopLogger(), inputCh, outputCh, MessageProcessorFunc(func(ctx context.Context, msg *models.ChannelMessage) (*models.ChannelMessage, error) {
+		return msg, nil
+	}))
+	producer := &mockProducerForOutput{}
+	processor.deadLetter = &deadLetterQueue{producer: producer, topic: "dlq", dropLevel: logging.ErrorLevel, logger: logging.NewNopLogger()}
+	processor.validator = &schemaValidator{}
+	if _, err := processor.validator.load(ValidationConfig{Schemas: map[string]string{"orders": writeSchemaFile(t, orderSchema)}}); err != nil {
+		t.Fatalf("load() returned error: %v", err)
+	}
+	if err := processor.Start(); err != nil {
+		t.Fatalf("Failed to start processor: %v", err)
+	}
+
+	valid := `{"id":"o-1","quantity":1}`
+	inputCh <- topicMessage("orders", `{"id":"o-2"}`)
+	inputCh <- topicMessage("orders", valid)
+	select {
+	case message := <-outputCh:
+		if string(message.Data) != valid || message.Headers[HeaderValidationError] != "" {
+			t.Errorf("Expected the valid message through untouched, got %s with headers %v", message.Data, message.Headers)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the valid message on the output channel")
+	}
+	processor.Stop()
+
+	if len(producer.messages) != 1 {
+		t.Fatalf("Expected the invalid message dead-lettered, got %d", len(producer.messages))
+	}
+	want := `schema validation: $: missing required property "quantity"`
+	if got := producer.messages[0].Headers[HeaderValidationError]; got != want {
+		t.Errorf("Expected header %s = %q, got %q", HeaderValidationError, want, got)
+	}
+	if processor.errors.Load() != 1 {
+		t.Errorf("Expected 1 processing error, got %d", processor.errors.Load())
+	}
+}
+
+func TestPipelineStartFailsOnInvalidSchema(t *testing.T) {
+	settings := DefaultConfig(nil)
+	settings.Validation.Schemas = map[string]string{"orders": writeSchemaFile(t, `{"type":"decimal"}`)}
+	pipeline := NewPipeline(settings, logging.NewNopLogger())
+
+	if err := pipeline.Start(); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("Start() error = %v, want ErrInvalidSchema", err)
+	}
+}
@@ -0,0 +1,97 @@
+package processing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/utils"
+)
+
+// HeaderContentType is the message header carrying the content type of a
+// published payload
+const HeaderContentType = "content_type"
+
+// Content types of the built-in serializers
+const (
+	ContentTypeRaw          = "application/octet-stream"
+	ContentTypeJSONEnvelope = "application/vnd.envelope+json"
+)
+
+// Serializer encodes the messages the output handler publishes. Marshal
+// returns the payload to publish and its content type, which is stamped into
+// the content_type header. Pipeline.SetSerializer installs a custom one, such
+// as a protobuf encoding.
+type Serializer interface {
+	Marshal(message *models.ChannelMessage) ([]byte, string, error)
+}
+
+// NewSerializer returns the built-in serializer for an output format:
+// config.OutputFormatRaw, or an empty format, or OutputFormatJSONEnvelope
+func NewSerializer(format string) (Serializer, error) {
+	switch format {
+	case "", config.OutputFormatRaw:
+		return RawSerializer{}, nil
+	case config.OutputFormatJSONEnvelope:
+		return JSONEnvelopeSerializer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// RawSerializer publishes message payloads unchanged
+type RawSerializer struct{}
+
+// Marshal returns the payload of message as is
+func (RawSerializer) Marshal(message *models.ChannelMessage) ([]byte, string, error) {
+	return message.Data, ContentTypeRaw, nil
+}
+
+// Envelope is the JSON document JSONEnvelopeSerializer publishes
+type Envelope struct {
+	ID            string    `json:"id"`                       // Unique to this published message
+	CorrelationID string    `json:"correlation_id,omitempty"` // Shared with the consumed message and its log lines
+	SourceTopic   string    `json:"source_topic,omitempty"`   // Topic the message was consumed from
+	Key           string    `json:"key,omitempty"`
+	ProcessedAt   time.Time `json:"processed_at"` // When the processor produced the message
+	// Payload holds a JSON payload as is; any other payload is base64
+	// encoded into a string and PayloadEncoding is set to "base64"
+	Payload         json.RawMessage `json:"payload"`
+	PayloadEncoding string          `json:"payload_encoding,omitempty"`
+}
+
+// JSONEnvelopeSerializer wraps each payload in an Envelope
+type JSONEnvelopeSerializer struct{}
+
+// Marshal returns message wrapped in an Envelope
+func (JSONEnvelopeSerializer) Marshal(message *models.ChannelMessage) ([]byte, string, error) {
+	id, err := utils.NewUUID()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+	envelope := Envelope{
+		ID:            id,
+		CorrelationID: message.CorrelationID,
+		SourceTopic:   message.Topic,
+		Key:           message.Key,
+		ProcessedAt:   message.Timestamp.UTC(),
+		Payload:       message.Data,
+	}
+	if envelope.ProcessedAt.IsZero() {
+		envelope.ProcessedAt = time.Now().UTC()
+	}
+	if !json.Valid(message.Data) {
+		encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(message.Data))
+		envelope.Payload = encoded
+		envelope.PayloadEncoding = "base64"
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return data, ContentTypeJSONEnvelope, nil
+}
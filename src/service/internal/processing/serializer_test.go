@@ -0,0 +1,132 @@
+package processing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/config"
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// upperSerializer stands in for a custom serializer such as protobuf
+type upperSerializer struct{}
+
+func (upperSerializer) Marshal(message *models.ChannelMessage) ([]byte, string, error) {
+	return []byte("custom:" + string(message.Data)), "application/x-custom", nil
+}
+
+func envelopeMessage(payload string) *models.ChannelMessage {
+	message := models.NewDataMessage([]byte(payload), "test")
+	message.Topic = "orders"
+	message.Key = "k1"
+	message.CorrelationID = "corr-1"
+	message.Timestamp = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return message
+}
+
+func TestJSONEnvelopeSerializer(t *testing.T) {
+	data, contentType, err := JSONEnvelopeSerializer{}.Marshal(envelopeMessage(`{"id":"o-1"}`))
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+	if contentType != ContentTypeJSONEnvelope {
+		t.Errorf("Expected content type %q, got %q", ContentTypeJSONEnvelope, contentType)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("Failed to decode envelope %s: %v", data, err)
+	}
+	if envelope.ID == "" || envelope.ID == "corr-1" {
+		t.Errorf("Expected a fresh message id, got %q", envelope.ID)
+	}
+	if envelope.CorrelationID != "corr-1" || envelope.SourceTopic != "orders" || envelope.Key != "k1" {
+		t.Errorf("Unexpected envelope metadata %+v", envelope)
+	}
+	if !envelope.ProcessedAt.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the message timestamp as processed_at, got %v", envelope.ProcessedAt)
+	}
+	if string(envelope.Payload) != `{"id":"o-1"}` || envelope.PayloadEncoding != "" {
+		t.Errorf("Expected the JSON payload embedded as is, got %s (%q)", envelope.Payload, envelope.PayloadEncoding)
+	}
+
+	other, _, _ := JSONEnvelopeSerializer{}.Marshal(envelopeMessage(`{"id":"o-1"}`))
+	var second Envelope
+	if err := json.Unmarshal(other, &second); err != nil || second.ID == envelope.ID {
+		t.Errorf("Expected every envelope to get its own id, got %q twice", envelope.ID)
+	}
+}
+
+func TestJSONEnvelopeSerializerBinaryPayload(t *testing.T) {
+	data, _, err := JSONEnvelopeSerializer{}.Marshal(envelopeMessage("\x00\x01binary"))
+	if err != nil {
+		t.Fatalf("Marshal() returned error: %v", err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("Failed to decode envelope %s: %v", data, err)
+	}
+	var encoded string
+	if err := json.Unmarshal(envelope.Payload, &encoded); err != nil || envelope.PayloadEncoding != "base64" {
+		t.Fatalf("Expected a base64 string payload, got %s (%q)", envelope.Payload, envelope.PayloadEncoding)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(encoded); err != nil || string(decoded) != "\x00\x01binary" {
+		t.Errorf("Expected the payload to round-trip, got %q, %v", decoded, err)
+	}
+}
+
+func TestNewSerializer(t *testing.T) {
+	for format, want := range map[string]Serializer{
+		"":                              RawSerializer{},
+		config.OutputFormatRaw:          RawSerializer{},
+		config.OutputFormatJSONEnvelope: JSONEnvelopeSerializer{},
+	} {
+		if got, err := NewSerializer(format); err != nil || got != want {
+			t.Errorf("NewSerializer(%q) = %T, %v; want %T", format, got, err, want)
+		}
+	}
+	if _, err := NewSerializer("avro"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestOutputHandlerStampsContentType(t *testing.T) {
+	producer := &mockProducerForOutput{}
+	handler := NewOutputHandlerWithProducer(OutputConfig{OutputTopic: "out", BatchSize: 1, Format: config.OutputFormatJSONEnvelope}, producer, logging.NewNopLogger())
+	message := envelopeMessage(`{"id":"o-1"}`)
+	message.Headers = map[string]string{"trace": "t1"}
+	if _, err := handler.sendWithRetry(message); err != nil {
+		t.Fatalf("sendWithRetry() returned error: %v", err)
+	}
+
+	published := producer.messages[0]
+	if published.Headers[HeaderContentType] != ContentTypeJSONEnvelope || published.Headers["trace"] != "t1" {
+		t.Errorf("Expected the content type added to the headers, got %v", published.Headers)
+	}
+	if _, ok := message.Headers[HeaderContentType]; ok {
+		t.Error("Expected the message headers not to be modified")
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(published.Value, &envelope); err != nil || envelope.SourceTopic != "orders" {
+		t.Errorf("Expected an envelope on the output topic, got %s", published.Value)
+	}
+}
+
+func TestPipelineSetSerializer(t *testing.T) {
+	pipeline := NewPipeline(DefaultConfig(nil), logging.NewNopLogger())
+	pipeline.SetSerializer(upperSerializer{})
+	rebuilt := pipeline.Rebuild(DefaultConfig(nil))
+
+	producer := &mockProducerForOutput{}
+	rebuilt.outputHandler.producer = producer
+	if _, err := rebuilt.outputHandler.sendWithRetry(models.NewDataMessage([]byte("x"), "test")); err != nil {
+		t.Fatalf("sendWithRetry() returned error: %v", err)
+	}
+	published := producer.messages[0]
+	if string(published.Value) != "custom:x" || published.Headers[HeaderContentType] != "application/x-custom" {
+		t.Errorf("Expected the custom serializer to carry over, got %s with %v", published.Value, published.Headers)
+	}
+}
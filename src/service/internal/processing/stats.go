@@ -0,0 +1,57 @@
+package processing
+
+import (
+	"math"
+	"time"
+)
+
+// startStatsReporter starts logging the pipeline counters every
+// StatsInterval until Stop. It does nothing when the interval is zero.
+func (p *Pipeline) startStatsReporter() {
+	if p.config.StatsInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(p.config.StatsInterval)
+	p.statsDone = make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		p.statsLoop(ticker.C)
+	}()
+}
+
+// statsLoop logs the pipeline stats on every tick until Stop
+func (p *Pipeline) statsLoop(ticks <-chan time.Time) {
+	defer close(p.statsDone)
+	for {
+		select {
+		case <-p.meterStop:
+			return
+		case <-ticks:
+			p.reportStats()
+		}
+	}
+}
+
+// reportStats logs the pipeline counters, rates and channel fill levels as a
+// single line on the pipeline logger
+func (p *Pipeline) reportStats() {
+	metrics := p.Metrics()
+	p.plogger.Infow("Pipeline stats",
+		"state", metrics.State,
+		"consumed", metrics.Consumed,
+		"processed", metrics.Processed,
+		"published", metrics.Published,
+		"failed", metrics.Failed,
+		"dropped", metrics.Dropped,
+		"consume_rate", roundTo2(metrics.ConsumeRate),
+		"process_rate", roundTo2(metrics.ProcessRate),
+		"publish_rate", roundTo2(metrics.PublishRate),
+		"input_channel_fill_pct", roundTo2(metrics.InputChannel.Fill*100),
+		"output_channel_fill_pct", roundTo2(metrics.OutputChannel.Fill*100),
+	)
+}
+
+// roundTo2 rounds value to two decimal places for logging
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
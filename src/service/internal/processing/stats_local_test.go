@@ -0,0 +1,62 @@
+//go:build local
+
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+)
+
+// statsPipeline returns a pipeline over the local message bus that reports
+// its stats every interval to recorder
+func statsPipeline(interval time.Duration, recorder *logging.TestLogger) *Pipeline {
+	settings := localConfig("stats-test")
+	settings.StatsInterval = interval
+	return newPipeline(settings, logging.NewNopLogger(), recorder)
+}
+
+func TestPipelineReportsStatsUntilStop(t *testing.T) {
+	recorder := logging.NewTestLogger()
+	pipeline := statsPipeline(20*time.Millisecond, recorder)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(statsLines(recorder)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	reported := len(statsLines(recorder))
+	if reported < 2 {
+		t.Fatalf("Expected periodic stats lines, got %d", reported)
+	}
+	if state := statsLines(recorder)[0].Fields["state"]; state != PipelineRunning {
+		t.Errorf("Expected the running state in the stats, got %v", state)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if len(statsLines(recorder)) != reported {
+		t.Error("Expected no stats lines after Stop")
+	}
+}
+
+func TestPipelineStatsDisabled(t *testing.T) {
+	recorder := logging.NewTestLogger()
+	pipeline := statsPipeline(0, recorder)
+	if err := pipeline.Start(); err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+
+	if pipeline.statsDone != nil || len(statsLines(recorder)) != 0 {
+		t.Error("Expected no stats reporter with a zero interval")
+	}
+}
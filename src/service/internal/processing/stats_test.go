@@ -0,0 +1,84 @@
+package processing
+
+import (
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+)
+
+// statsLines returns the stats lines recorded by logger
+func statsLines(logger *logging.TestLogger) []logging.Entry {
+	var lines []logging.Entry
+	for _, entry := range logger.Entries() {
+		if entry.Message == "Pipeline stats" {
+			lines = append(lines, entry)
+		}
+	}
+	return lines
+}
+
+// countMessages returns how many times msg was logged
+func countMessages(logger *logging.TestLogger, msg string) int {
+	count := 0
+	for _, entry := range logger.Entries() {
+		if entry.Message == msg {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPipelineStatsLoop(t *testing.T) {
+	recorder := logging.NewTestLogger()
+	pipeline := newPipeline(DefaultConfig(nil), logging.NewNopLogger(), recorder)
+	pipeline.inputHandler.consumed.Add(10)
+	pipeline.processor.processed.Add(8)
+	pipeline.processor.errors.Add(1)
+	pipeline.outputHandler.messagesSent.Add(7)
+	pipeline.processRate.update(8, 4*time.Second)
+	for i := 0; i < 250; i++ {
+		pipeline.outputHandler.outputCh <- models.NewDataMessage(nil, "test")
+	}
+
+	// Drive the reporter with a fake clock
+	ticks := make(chan time.Time)
+	pipeline.meterStop = make(chan struct{})
+	pipeline.statsDone = make(chan struct{})
+	go pipeline.statsLoop(ticks)
+	ticks <- time.Now()
+	ticks <- time.Now()
+	close(pipeline.meterStop)
+	select {
+	case <-pipeline.statsDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the stats reporter to stop")
+	}
+
+	lines := statsLines(recorder)
+	if len(lines) != 2 {
+		t.Fatalf("Expected a stats line per tick, got %d", len(lines))
+	}
+	want := map[string]interface{}{
+		"state":                   PipelineStopped,
+		"consumed":                int64(10),
+		"processed":               int64(8),
+		"published":               int64(7),
+		"failed":                  int64(1),
+		"dropped":                 int64(0),
+		"consume_rate":            0.0,
+		"process_rate":            2.0,
+		"publish_rate":            0.0,
+		"input_channel_fill_pct":  0.0,
+		"output_channel_fill_pct": 25.0,
+	}
+	for key, value := range want {
+		if got := lines[0].Fields[key]; got != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, got)
+		}
+	}
+	if len(lines[0].Fields) != len(want) {
+		t.Errorf("Unexpected stats fields %v", lines[0].Fields)
+	}
+}
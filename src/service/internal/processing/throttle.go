@@ -0,0 +1,81 @@
+package processing
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// throttle is a token bucket limiting how many messages per second the output
+// handler publishes. The bucket holds at most one token, so messages go out
+// evenly spaced rather than in bursts. A nil throttle never waits.
+type throttle struct {
+	interval time.Duration // Time to earn one token
+	tokens   float64       // May go negative while a caller waits for its token
+	last     time.Time     // When tokens was last topped up
+
+	throttled atomic.Int64 // Messages that had to wait
+	waited    atomic.Int64 // Cumulative wait in nanoseconds
+
+	now func() time.Time // Replaced in tests
+}
+
+// newThrottle returns a throttle allowing perSecond messages a second, or nil
+// when perSecond is not positive
+func newThrottle(perSecond float64) *throttle {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &throttle{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		tokens:   1,
+		now:      time.Now,
+	}
+}
+
+// wait takes a token, waiting until one is earned. It returns early once ctx
+// is done, so stopping the output handler is never held up by the limit. It
+// must not be called concurrently.
+func (t *throttle) wait(ctx context.Context) {
+	if t == nil || ctx.Err() != nil {
+		return
+	}
+	now := t.now()
+	if !t.last.IsZero() {
+		t.tokens += float64(now.Sub(t.last)) / float64(t.interval)
+		if t.tokens > 1 {
+			t.tokens = 1
+		}
+	}
+	t.last = now
+	t.tokens--
+	if t.tokens >= 0 {
+		return
+	}
+
+	delay := time.Duration(-t.tokens * float64(t.interval))
+	t.throttled.Add(1)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	t.waited.Add(int64(t.now().Sub(now)))
+}
+
+// waitTime returns the total time spent waiting for tokens
+func (t *throttle) waitTime() time.Duration {
+	if t == nil {
+		return 0
+	}
+	return time.Duration(t.waited.Load())
+}
+
+// throttledCount returns the number of messages that had to wait
+func (t *throttle) throttledCount() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.throttled.Load()
+}
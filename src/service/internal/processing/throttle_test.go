@@ -0,0 +1,151 @@
+package processing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"servicegomodule/internal/models"
+	"sharedgomodule/logging"
+	"sharedgomodule/messagebus"
+)
+
+// timedProducer records when each message was sent
+type timedProducer struct {
+	mockProducerForOutput
+	mutex sync.Mutex
+	sent  []time.Time
+}
+
+func (p *timedProducer) Send(ctx context.Context, message *messagebus.Message) (int32, int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.sent = append(p.sent, time.Now())
+	return p.mockProducerForOutput.Send(ctx, message)
+}
+
+func (p *timedProducer) sendTimes() []time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]time.Time(nil), p.sent...)
+}
+
+func newThrottledOutputHandler(perSecond float64, producer messagebus.Producer) *OutputHandler {
+	return NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:          "throttle-topic",
+		BatchSize:            5,
+		FlushTimeout:         10 * time.Millisecond,
+		ChannelBufferSize:    100,
+		MaxMessagesPerSecond: perSecond,
+	}, producer, logging.NewNopLogger())
+}
+
+func TestOutputHandlerThrottlesRate(t *testing.T) {
+	const perSecond, messages = 50, 26
+	producer := &timedProducer{}
+	handler := newThrottledOutputHandler(perSecond, producer)
+	for i := 0; i < messages; i++ {
+		handler.outputCh <- models.NewDataMessage([]byte("payload"), "test")
+	}
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+	defer handler.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(producer.sendTimes()) < messages && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	sent := producer.sendTimes()
+	if len(sent) != messages {
+		t.Fatalf("Expected %d messages sent, got %d", messages, len(sent))
+	}
+
+	rate := float64(messages-1) / sent[messages-1].Sub(sent[0]).Seconds()
+	if rate > perSecond*1.1 || rate < perSecond*0.7 {
+		t.Errorf("Expected an output rate near %d/s, measured %.1f/s", perSecond, rate)
+	}
+	stats := handler.GetStats()
+	if stats["throttled_messages"].(int64) < messages-2 || stats["throttle_wait_ms"].(int64) < 300 {
+		t.Errorf("Expected the throttled time in the stats, got %v messages and %vms", stats["throttled_messages"], stats["throttle_wait_ms"])
+	}
+}
+
+func TestOutputHandlerStopNotBlockedByThrottle(t *testing.T) {
+	producer := &timedProducer{}
+	handler := newThrottledOutputHandler(1, producer)
+	for i := 0; i < 5; i++ {
+		handler.outputCh <- models.NewDataMessage([]byte("payload"), "test")
+	}
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	began := time.Now()
+	if err := handler.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if elapsed := time.Since(began); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected Stop not to wait for tokens, took %v", elapsed)
+	}
+	if sent := len(producer.sendTimes()); sent != 5 {
+		t.Errorf("Expected the final flush to send all 5 messages, got %d", sent)
+	}
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	if newThrottle(0) != nil {
+		t.Error("Expected no throttle for a zero rate")
+	}
+	var unlimited *throttle
+	unlimited.wait(context.Background())
+	if unlimited.waitTime() != 0 || unlimited.throttledCount() != 0 {
+		t.Error("Expected a nil throttle to do nothing")
+	}
+}
+
+func TestOutputHandlerUpdateConfig(t *testing.T) {
+	producer := &timedProducer{}
+	handler := NewOutputHandlerWithProducer(OutputConfig{
+		OutputTopic:       "tuning-topic",
+		BatchSize:         100,
+		FlushTimeout:      time.Hour,
+		ChannelBufferSize: 10,
+	}, producer, logging.NewNopLogger())
+	if err := handler.Start(); err != nil {
+		t.Fatalf("Failed to start handler: %v", err)
+	}
+	defer handler.Stop()
+
+	awaitSent := func(count int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for len(producer.sendTimes()) < count && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if sent := len(producer.sendTimes()); sent != count {
+			t.Fatalf("Expected %d messages sent, got %d", count, sent)
+		}
+	}
+
+	// Shrinking the batch size flushes the pending batch
+	handler.outputCh <- models.NewDataMessage([]byte("first"), "test")
+	time.Sleep(50 * time.Millisecond)
+	if len(producer.sendTimes()) != 0 {
+		t.Fatal("Expected the message to wait for a full batch")
+	}
+	handler.UpdateConfig(OutputConfig{BatchSize: 1, FlushTimeout: time.Hour})
+	awaitSent(1)
+
+	// A shorter flush timeout takes effect without waiting out the old one
+	handler.UpdateConfig(OutputConfig{BatchSize: 100, FlushTimeout: 20 * time.Millisecond, MaxMessagesPerSecond: 50})
+	handler.outputCh <- models.NewDataMessage([]byte("second"), "test")
+	awaitSent(2)
+
+	stats := handler.GetStats()
+	if stats["batch_size"] != 100 || stats["flush_timeout"] != "20ms" || stats["max_messages_per_second"] != 50.0 {
+		t.Errorf("Expected the stats to show the new settings, got %v", stats)
+	}
+}
@@ -0,0 +1,124 @@
+package processing
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// topicCounters are the statistics of one input topic
+type topicCounters struct {
+	consumed    atomic.Int64
+	errors      atomic.Int64
+	lastMessage atomic.Int64 // Unix nanoseconds of the last consumed message; 0 before the first
+}
+
+// topicStats breaks the input counters down by topic. Counting takes a read
+// lock once a topic has been seen, so the consume loop and the workers do not
+// contend. It is safe for concurrent use; a nil topicStats records nothing.
+type topicStats struct {
+	mutex  sync.RWMutex
+	topics map[string]*topicCounters
+	now    func() time.Time // Replaced in tests
+}
+
+func newTopicStats() *topicStats {
+	return &topicStats{topics: make(map[string]*topicCounters), now: time.Now}
+}
+
+// counters returns the counters of topic, creating them on first use
+func (s *topicStats) counters(topic string) *topicCounters {
+	s.mutex.RLock()
+	counters, ok := s.topics[topic]
+	s.mutex.RUnlock()
+	if ok {
+		return counters
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if counters, ok = s.topics[topic]; !ok {
+		counters = &topicCounters{}
+		s.topics[topic] = counters
+	}
+	return counters
+}
+
+// consumed counts a message consumed from topic
+func (s *topicStats) consumed(topic string) {
+	if s == nil {
+		return
+	}
+	counters := s.counters(topic)
+	counters.consumed.Add(1)
+	counters.lastMessage.Store(s.now().UnixNano())
+}
+
+// failed counts a message from topic that failed processing or publishing.
+// Messages without a source topic are not counted.
+func (s *topicStats) failed(topic string) {
+	if s == nil || topic == "" {
+		return
+	}
+	s.counters(topic).errors.Add(1)
+}
+
+// TopicMetrics is a snapshot of the counters of one input topic
+type TopicMetrics struct {
+	Subscribed  bool
+	Consumed    int64     // Messages read from the topic
+	Errors      int64     // Messages from the topic that failed processing or publishing
+	LastMessage time.Time // When the last message was read; zero before the first
+}
+
+// metrics returns the counters of every subscribed topic and of every topic
+// a message was counted for, keyed by topic. Subscribed topics that have
+// seen no messages are included, so silent topics show up.
+func (s *topicStats) metrics(subscribed []string) map[string]TopicMetrics {
+	result := make(map[string]TopicMetrics, len(subscribed))
+	if s == nil {
+		return result
+	}
+	for _, topic := range subscribed {
+		s.counters(topic)
+	}
+	live := make(map[string]bool, len(subscribed))
+	for _, topic := range subscribed {
+		live[topic] = true
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for topic, counters := range s.topics {
+		metrics := TopicMetrics{
+			Subscribed: live[topic],
+			Consumed:   counters.consumed.Load(),
+			Errors:     counters.errors.Load(),
+		}
+		if nanos := counters.lastMessage.Load(); nanos != 0 {
+			metrics.LastMessage = time.Unix(0, nanos).UTC()
+		}
+		result[topic] = metrics
+	}
+	return result
+}
+
+// snapshot returns the metrics of each topic as reported under topics in the
+// input statistics
+func (s *topicStats) snapshot(subscribed []string) map[string]interface{} {
+	topics := s.metrics(subscribed)
+	result := make(map[string]interface{}, len(topics))
+	for topic, metrics := range topics {
+		var lastMessage interface{}
+		if !metrics.LastMessage.IsZero() {
+			lastMessage = metrics.LastMessage.Format(time.RFC3339Nano)
+		}
+		result[topic] = map[string]interface{}{
+			"subscribed":        metrics.Subscribed,
+			"messages_consumed": metrics.Consumed,
+			"errors":            metrics.Errors,
+			"last_message_at":   lastMessage,
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package processing
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"sharedgomodule/logging"
+)
+
+func TestTopicStats(t *testing.T) {
+	stats := newTopicStats()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stats.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				stats.consumed("orders")
+			}
+			stats.failed("orders")
+		}()
+	}
+	wg.Wait()
+	stats.consumed("retired")
+	stats.failed("")
+
+	snapshot := stats.snapshot([]string{"orders", "payments"})
+	want := map[string]map[string]interface{}{
+		"orders":   {"subscribed": true, "messages_consumed": int64(400), "errors": int64(4), "last_message_at": "2024-05-01T12:00:00Z"},
+		"payments": {"subscribed": true, "messages_consumed": int64(0), "errors": int64(0), "last_message_at": nil},
+		"retired":  {"subscribed": false, "messages_consumed": int64(1), "errors": int64(0), "last_message_at": "2024-05-01T12:00:00Z"},
+	}
+	if len(snapshot) != len(want) {
+		t.Fatalf("Expected stats for %d topics, got %v", len(want), snapshot)
+	}
+	for topic, fields := range want {
+		got := snapshot[topic].(map[string]interface{})
+		for key, value := range fields {
+			if got[key] != value {
+				t.Errorf("Expected %s %s = %v, got %v", topic, key, value, got[key])
+			}
+		}
+	}
+
+	var disabled *topicStats
+	disabled.consumed("orders")
+	disabled.failed("orders")
+	if len(disabled.snapshot([]string{"orders"})) != 0 {
+		t.Error("Expected a nil topicStats to report nothing")
+	}
+}
+
+func BenchmarkTopicStatsConsumed(b *testing.B) {
+	stats := newTopicStats()
+	topics := []string{"orders", "payments", "refunds"}
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			stats.consumed(topics[i%len(topics)])
+			i++
+		}
+	})
+}
+
+func TestPipelineTopicMetrics(t *testing.T) {
+	settings := DefaultConfig(nil)
+	settings.Input.Topics = []string{"orders"}
+	pipeline := NewPipeline(settings, logging.NewNopLogger())
+	pipeline.inputHandler.topicStats.consumed("orders")
+	pipeline.inputHandler.topicStats.consumed("retired")
+	pipeline.inputHandler.topicStats.failed("orders")
+
+	metrics := pipeline.TopicMetrics()
+	if got := metrics["orders"]; !got.Subscribed || got.Consumed != 1 || got.Errors != 1 || got.LastMessage.IsZero() {
+		t.Errorf("Unexpected metrics for a subscribed topic: %+v", got)
+	}
+	if got := metrics["retired"]; got.Subscribed || got.Consumed != 1 {
+		t.Errorf("Expected an unsubscribed topic to keep its counters, got %+v", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Package buildinfo exposes version metadata stamped into binaries at build
+// time, for example:
+//
+//	go build -ldflags "-X sharedgomodule/buildinfo.Version=1.2.3 \
+//	    -X sharedgomodule/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//	    -X sharedgomodule/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata set via -ldflags; empty values fall back to the module and
+// VCS information recorded by the Go toolchain
+var (
+	Version   = ""
+	GitCommit = ""
+	BuildTime = ""
+)
+
+// defaultVersion is reported when no version information is available
+const defaultVersion = "dev"
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version" xml:"version"`
+	GitCommit string `json:"git_commit,omitempty" xml:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty" xml:"build_time,omitempty"`
+	GoVersion string `json:"go_version" xml:"go_version"`
+}
+
+// readBuildInfo is swapped in tests
+var readBuildInfo = debug.ReadBuildInfo
+
+// Get returns the build metadata, preferring values set via -ldflags
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := readBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = defaultVersion
+	}
+	return info
+}
+
+// VersionString returns the version reported by Get
+func VersionString() string {
+	return Get().Version
+}
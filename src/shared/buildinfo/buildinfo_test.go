@@ -0,0 +1,70 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+// setBuildVars sets the -ldflags variables for the duration of a test
+func setBuildVars(t *testing.T, version, commit, buildTime string) {
+	t.Helper()
+	oldVersion, oldCommit, oldTime := Version, GitCommit, BuildTime
+	Version, GitCommit, BuildTime = version, commit, buildTime
+	t.Cleanup(func() { Version, GitCommit, BuildTime = oldVersion, oldCommit, oldTime })
+}
+
+// setReadBuildInfo replaces the toolchain build info for the duration of a test
+func setReadBuildInfo(t *testing.T, fn func() (*debug.BuildInfo, bool)) {
+	t.Helper()
+	old := readBuildInfo
+	readBuildInfo = fn
+	t.Cleanup(func() { readBuildInfo = old })
+}
+
+func TestGetPrefersLinkerVariables(t *testing.T) {
+	setBuildVars(t, "1.2.3", "abc123", "2024-01-01T00:00:00Z")
+	setReadBuildInfo(t, func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main:     debug.Module{Version: "v9.9.9"},
+			Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "ignored"}},
+		}, true
+	})
+
+	info := Get()
+	if info.Version != "1.2.3" || info.GitCommit != "abc123" || info.BuildTime != "2024-01-01T00:00:00Z" {
+		t.Errorf("Get() = %+v, want linker variables", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
+
+func TestGetFallsBackToBuildInfo(t *testing.T) {
+	setBuildVars(t, "", "", "")
+	setReadBuildInfo(t, func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Version: "v0.4.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "deadbeef"},
+				{Key: "vcs.time", Value: "2024-02-02T10:00:00Z"},
+			},
+		}, true
+	})
+
+	info := Get()
+	if info.Version != "v0.4.0" || info.GitCommit != "deadbeef" || info.BuildTime != "2024-02-02T10:00:00Z" {
+		t.Errorf("Get() = %+v, want build info fallback", info)
+	}
+}
+
+func TestGetDefaultsWithoutInformation(t *testing.T) {
+	setBuildVars(t, "", "", "")
+	setReadBuildInfo(t, func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, true
+	})
+
+	if got := VersionString(); got != defaultVersion {
+		t.Errorf("VersionString() = %q, want %q", got, defaultVersion)
+	}
+}
@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Async buffer defaults used when LoggerConfig leaves them unset
+const (
+	defaultBufferSize   = 1024 // Lines
+	defaultCloseTimeout = 5 * time.Second
+)
+
+// asyncWriter hands log lines to a background goroutine that writes them to
+// out, so logging calls do not wait on the file. When the buffer is full a
+// write blocks until there is room, or is dropped and counted when drop is
+// set. Fatal and panic lines bypass the buffer: everything queued before them
+// is written first, then the line itself, before the caller exits.
+type asyncWriter struct {
+	out     io.Writer
+	writeMu sync.Mutex // Serializes writes to out between the goroutine and fatal/panic lines
+	drop    bool
+
+	lines   chan []byte
+	flushes chan chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	dropped atomic.Uint64
+}
+
+// newAsyncWriter starts the goroutine writing to out
+func newAsyncWriter(out io.Writer, config *LoggerConfig) *asyncWriter {
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	w := &asyncWriter{
+		out:     out,
+		drop:    config.Overflow == OverflowDrop,
+		lines:   make(chan []byte, size),
+		flushes: make(chan chan struct{}),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// run writes queued lines until the writer is closed, then writes whatever
+// is still queued
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case line := <-w.lines:
+			w.write(line)
+		case flushed := <-w.flushes:
+			w.drain()
+			close(flushed)
+		case <-w.quit:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain writes the lines queued so far
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case line := <-w.lines:
+			w.write(line)
+		default:
+			return
+		}
+	}
+}
+
+func (w *asyncWriter) write(line []byte) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if _, err := w.out.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write log line: %v\n", err)
+	}
+}
+
+// Write queues a copy of p, since zerolog reuses its buffer
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.quit:
+		return 0, os.ErrClosed
+	default:
+	}
+	line := append([]byte(nil), p...)
+	if w.drop {
+		select {
+		case w.lines <- line:
+		case <-w.quit:
+			return 0, os.ErrClosed
+		default:
+			w.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+	select {
+	case w.lines <- line:
+		return len(p), nil
+	case <-w.quit:
+		return 0, os.ErrClosed
+	}
+}
+
+// WriteLevel queues p, except for fatal and panic lines which are written
+// synchronously after the queued ones
+func (w *asyncWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.FatalLevel || level == zerolog.NoLevel || level == zerolog.Disabled {
+		return w.Write(p)
+	}
+	w.Flush()
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return w.out.Write(p)
+}
+
+// Flush waits until the lines queued before the call are written. It
+// returns at once when the writer is closed.
+func (w *asyncWriter) Flush() {
+	flushed := make(chan struct{})
+	select {
+	case w.flushes <- flushed:
+		<-flushed
+	case <-w.done:
+	}
+}
+
+// Close writes the queued lines and stops the goroutine, waiting at most
+// timeout for it. Closing again does nothing.
+func (w *asyncWriter) Close(timeout time.Duration) error {
+	w.once.Do(func() { close(w.quit) })
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-w.done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s writing %d buffered log lines", timeout, len(w.lines))
+	}
+}
+
+// Dropped returns the number of lines dropped because the buffer was full
+func (w *asyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
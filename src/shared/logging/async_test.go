@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newAsyncLogger(t *testing.T, config LoggerConfig) (*ZerologLogger, string) {
+	t.Helper()
+	config.Level = InfoLevel
+	config.FilePath = filepath.Join(t.TempDir(), "app.log")
+	config.LoggerName = testLoggerName
+	config.ServiceName = testServiceName
+	config.Async = true
+
+	logger, err := NewLoggerWithConfig(&config)
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	return logger, config.FilePath
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+// gatedWriter blocks every write until release is closed, signalling on
+// started when the first write begins
+type gatedWriter struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	return len(p), nil
+}
+
+func TestAsyncLoggerCloseLosesNothing(t *testing.T) {
+	logger, path := newAsyncLogger(t, LoggerConfig{BufferSize: 16})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(clone Logger) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				clone.Infow("Queued", "i", i)
+			}
+		}(logger.WithField("goroutine", g))
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 2000 {
+		t.Fatalf("Expected all 2000 lines after Close(), got %d", len(lines))
+	}
+	for _, line := range lines {
+		decodeLogLine(t, line)
+	}
+	if logger.Dropped() != 0 {
+		t.Errorf("Expected no dropped lines when blocking, got %d", logger.Dropped())
+	}
+}
+
+func TestAsyncLoggerFlush(t *testing.T) {
+	logger, path := newAsyncLogger(t, LoggerConfig{})
+	defer logger.Close()
+
+	for i := 0; i < 100; i++ {
+		logger.Infow("Queued", "i", i)
+	}
+	logger.Flush()
+
+	lines := readLines(t, path)
+	if len(lines) != 100 {
+		t.Fatalf("Expected 100 lines after Flush(), got %d", len(lines))
+	}
+	if last := decodeLogLine(t, lines[99]); last["i"] != float64(99) {
+		t.Errorf("Expected lines in order, got %v last", last)
+	}
+}
+
+func TestAsyncWriterDropsWhenFull(t *testing.T) {
+	out := newGatedWriter()
+	w := newAsyncWriter(out, &LoggerConfig{BufferSize: 2, Overflow: OverflowDrop})
+
+	w.Write([]byte("first\n"))
+	<-out.started // The goroutine holds the first line, leaving the buffer empty
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte("more\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if w.Dropped() != 3 {
+		t.Errorf("Expected 3 lines dropped past a buffer of 2, got %d", w.Dropped())
+	}
+
+	close(out.release)
+	if err := w.Close(time.Second); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestAsyncWriterCloseTimeout(t *testing.T) {
+	out := newGatedWriter()
+	defer close(out.release)
+	w := newAsyncWriter(out, &LoggerConfig{})
+
+	w.Write([]byte("stuck\n"))
+	<-out.started
+	w.Write([]byte("queued\n"))
+	if err := w.Close(10 * time.Millisecond); err == nil {
+		t.Error("Expected Close() to time out while the output is blocked")
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil {
+		t.Error("Expected writes after Close() to fail")
+	}
+}
+
+func TestAsyncLoggerPanicWritesSynchronously(t *testing.T) {
+	logger, path := newAsyncLogger(t, LoggerConfig{})
+	defer logger.Close()
+
+	for i := 0; i < 10; i++ {
+		logger.Infow("Queued", "i", i)
+	}
+	func() {
+		defer func() { recover() }()
+		logger.Panic("Giving up")
+	}()
+
+	// No Flush: the panic line waits for the queued lines and is written
+	// before the panic unwinds
+	lines := readLines(t, path)
+	if len(lines) != 11 {
+		t.Fatalf("Expected the queued lines and the panic line, got %d lines", len(lines))
+	}
+	if last := decodeLogLine(t, lines[10]); last["level"] != "panic" {
+		t.Errorf("Expected the panic line last, got %v", last)
+	}
+}
+
+func BenchmarkLoggerFile(b *testing.B) {
+	for _, async := range []bool{false, true} {
+		name := "sync"
+		if async {
+			name = "async"
+		}
+		b.Run(name, func(b *testing.B) {
+			config := LoggerConfig{
+				Level:       InfoLevel,
+				FilePath:    filepath.Join(b.TempDir(), "app.log"),
+				LoggerName:  testLoggerName,
+				ServiceName: testServiceName,
+				Async:       async,
+			}
+			logger, err := NewLoggerWithConfig(&config)
+			if err != nil {
+				b.Fatalf(newLoggerErrorFmt, err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Infow("Benchmark", "key", "value")
+				}
+			})
+			b.StopTimer()
+			logger.Close()
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package logging
+
+import "context"
+
+// Fields WithContext attaches from the typed context helpers below
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+)
+
+// ContextKey is the type of context keys WithContext looks up for the names
+// in LoggerConfig.ContextKeys. Storing values under a ContextKey rather than
+// a plain string keeps them from colliding with other packages' keys.
+type ContextKey string
+
+// ContextExtractor returns the value of a log field carried by ctx, and
+// whether ctx carries it
+type ContextExtractor func(ctx context.Context) (interface{}, bool)
+
+type requestIDContextKey struct{}
+
+type traceIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty
+// string when it carries none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// ContextWithTraceID returns a copy of ctx carrying the trace ID
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, id)
+}
+
+// TraceIDFromContext returns the trace ID carried by ctx, or an empty string
+// when it carries none
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(traceIDContextKey{}).(string)
+	return id
+}
+
+// DefaultContextKeys are the fields DefaultConfig has WithContext attach
+func DefaultContextKeys() []string {
+	return []string{FieldRequestID, FieldTraceID}
+}
+
+// keyExtractor returns the extractor for a name in LoggerConfig.ContextKeys:
+// the typed helper for request_id and trace_id, or a lookup of
+// ContextKey(name)
+func keyExtractor(name string) ContextExtractor {
+	switch name {
+	case FieldRequestID:
+		return stringExtractor(RequestIDFromContext)
+	case FieldTraceID:
+		return stringExtractor(TraceIDFromContext)
+	}
+	return func(ctx context.Context) (interface{}, bool) {
+		value := ctx.Value(ContextKey(name))
+		return value, value != nil
+	}
+}
+
+// stringExtractor adapts a typed helper to a ContextExtractor, treating an
+// empty string as absent
+func stringExtractor(get func(context.Context) string) ContextExtractor {
+	return func(ctx context.Context) (interface{}, bool) {
+		value := get(ctx)
+		return value, value != ""
+	}
+}
+
+// contextFields returns the fields config declares that ctx carries
+func (c *LoggerConfig) contextFields(ctx context.Context) Fields {
+	fields := make(Fields)
+	if c == nil || ctx == nil {
+		return fields
+	}
+	for _, name := range c.ContextKeys {
+		if _, custom := c.ContextExtractors[name]; custom {
+			continue
+		}
+		if value, ok := keyExtractor(name)(ctx); ok {
+			fields[name] = value
+		}
+	}
+	for name, extract := range c.ContextExtractors {
+		if value, ok := extract(ctx); ok {
+			fields[name] = value
+		}
+	}
+	return fields
+}
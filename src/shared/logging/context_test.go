@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newContextLogger(t *testing.T, config LoggerConfig) *ZerologLogger {
+	t.Helper()
+	config.Level = InfoLevel
+	config.Output = OutputStdout
+	config.LoggerName = testLoggerName
+	config.ServiceName = testServiceName
+
+	logger, err := NewLoggerWithConfig(&config)
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	return logger
+}
+
+func TestContextHelpers(t *testing.T) {
+	ctx := ContextWithTraceID(ContextWithRequestID(context.Background(), "req-1"), "trace-1")
+	if got := RequestIDFromContext(ctx); got != "req-1" {
+		t.Errorf("RequestIDFromContext() = %q, want req-1", got)
+	}
+	if got := TraceIDFromContext(ctx); got != "trace-1" {
+		t.Errorf("TraceIDFromContext() = %q, want trace-1", got)
+	}
+	// A plain string key with the same name does not collide
+	plain := context.WithValue(context.Background(), "request_id", "other")
+	if got := RequestIDFromContext(plain); got != "" {
+		t.Errorf("Expected a plain string key to be ignored, got %q", got)
+	}
+	if RequestIDFromContext(nil) != "" || TraceIDFromContext(nil) != "" {
+		t.Error("Expected no IDs from a nil context")
+	}
+}
+
+func TestWithContextAttachesDeclaredKeys(t *testing.T) {
+	captured := captureStdout(t)
+	logger := newContextLogger(t, LoggerConfig{
+		ContextKeys: []string{FieldRequestID, FieldTraceID, "scenario"},
+		ContextExtractors: map[string]ContextExtractor{
+			"tenant": func(ctx context.Context) (interface{}, bool) {
+				tenant, ok := ctx.Value(ContextKey("tenant")).(string)
+				return strings.ToUpper(tenant), ok
+			},
+		},
+	})
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	ctx = ContextWithTraceID(ctx, "trace-1")
+	ctx = context.WithValue(ctx, ContextKey("scenario"), "checkout")
+	ctx = context.WithValue(ctx, ContextKey("tenant"), "acme")
+	ctx = context.WithValue(ctx, ContextKey("undeclared"), "ignored")
+	logger.WithContext(ctx).Info("With context")
+	logger.Info("Without context")
+
+	lines := strings.Split(strings.TrimSpace(captured()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", lines)
+	}
+	entry := decodeLogLine(t, lines[0])
+	want := map[string]string{"request_id": "req-1", "trace_id": "trace-1", "scenario": "checkout", "tenant": "ACME"}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, entry[key])
+		}
+	}
+	if _, ok := entry["undeclared"]; ok {
+		t.Error("Expected undeclared context keys to be left out")
+	}
+	if _, ok := decodeLogLine(t, lines[1])["request_id"]; ok {
+		t.Error("Expected the context fields to stay on the derived logger")
+	}
+}
+
+func TestWithContextBackground(t *testing.T) {
+	captured := captureStdout(t)
+	logger := newContextLogger(t, LoggerConfig{ContextKeys: DefaultContextKeys()})
+
+	logger.WithContext(context.Background()).Info("Background")
+
+	entry := decodeLogLine(t, strings.TrimSpace(captured()))
+	if entry["message"] != "Background" {
+		t.Errorf("Expected the line to be logged, got %v", entry)
+	}
+	for _, key := range DefaultContextKeys() {
+		if _, ok := entry[key]; ok {
+			t.Errorf("Expected no %s field without one in the context", key)
+		}
+	}
+}
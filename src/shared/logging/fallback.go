@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultFallbackAfter = 3
+	defaultFallbackRetry = 30 * time.Second
+)
+
+// OutputStats reports how writes to a logger's log file have fared
+type OutputStats struct {
+	FailedWrites uint64 // Writes the log file rejected
+	Fallbacks    uint64 // Times the output switched to the fallback
+	OnFallback   bool   // Lines currently go to the fallback instead of the log file
+}
+
+// OutputReporter is implemented by loggers that report OutputStats
+type OutputReporter interface {
+	OutputStats() OutputStats
+}
+
+// fallbackWriter writes to the log file, and to a fallback, stderr or
+// config.FallbackPath, when the file rejects a write, so no line is lost.
+// After config.FallbackAfter consecutive failures it stops trying the file
+// and writes to the fallback alone, retrying the file every
+// config.FallbackRetry until it accepts a line again. Both switches are
+// announced with a line on the fallback.
+type fallbackWriter struct {
+	mu      sync.Mutex
+	primary io.WriteCloser
+	path    string
+	after   int
+	retry   time.Duration
+	now     func() time.Time
+
+	// Announcements of the switches, in the logger's line format
+	service   string
+	timestamp timestampHook
+
+	fallback       io.Writer // Opened on first use
+	fallbackCloser io.Closer // Nil for stderr
+
+	consecutive int
+	onFallback  bool
+	nextRetry   time.Time
+	stats       OutputStats
+}
+
+func newFallbackWriter(primary io.WriteCloser, config *LoggerConfig, location *time.Location) *fallbackWriter {
+	after := config.FallbackAfter
+	if after <= 0 {
+		after = defaultFallbackAfter
+	}
+	retry := config.FallbackRetry
+	if retry <= 0 {
+		retry = defaultFallbackRetry
+	}
+	return &fallbackWriter{
+		primary:   primary,
+		path:      config.FallbackPath,
+		after:     after,
+		retry:     retry,
+		now:       time.Now,
+		service:   config.ServiceName,
+		timestamp: timestampHook{layout: config.timestampLayout(), location: location},
+	}
+}
+
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.onFallback {
+		if w.now().Before(w.nextRetry) {
+			return w.fallbackOutput().Write(p)
+		}
+		if _, err := w.primary.Write(p); err != nil {
+			w.stats.FailedWrites++
+			w.nextRetry = w.now().Add(w.retry)
+			return w.fallbackOutput().Write(p)
+		}
+		w.onFallback = false
+		w.stats.OnFallback = false
+		w.consecutive = 0
+		w.announce(nil, "Log file writable again, switching back to it")
+		return len(p), nil
+	}
+
+	n, err := w.primary.Write(p)
+	if err == nil {
+		w.consecutive = 0
+		return n, nil
+	}
+	w.stats.FailedWrites++
+	w.consecutive++
+	if w.consecutive >= w.after {
+		w.onFallback = true
+		w.stats.OnFallback = true
+		w.stats.Fallbacks++
+		w.nextRetry = w.now().Add(w.retry)
+		w.announce(err, "Log file unwritable, switching to the fallback output")
+	}
+	return w.fallbackOutput().Write(p)
+}
+
+// announce writes a line explaining a switch to the fallback
+func (w *fallbackWriter) announce(err error, msg string) {
+	logger := zerolog.New(w.fallbackOutput()).With().Str("service", w.service).Logger().Hook(w.timestamp)
+	event := logger.Warn()
+	if err != nil {
+		event = logger.Error().Err(err)
+	}
+	event.Uint64("failed_writes", w.stats.FailedWrites).Msg(msg)
+}
+
+// fallbackOutput returns the fallback, opening config.FallbackPath on first
+// use. It falls back to stderr when the path cannot be opened.
+func (w *fallbackWriter) fallbackOutput() io.Writer {
+	if w.fallback != nil {
+		return w.fallback
+	}
+	w.fallback = os.Stderr
+	if w.path != "" {
+		if file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			w.fallback = file
+			w.fallbackCloser = file
+		}
+	}
+	return w.fallback
+}
+
+// Stats returns the counters of failed writes and switches
+func (w *fallbackWriter) Stats() OutputStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Close closes the log file and an opened fallback file
+func (w *fallbackWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.primary.Close()
+	if w.fallbackCloser != nil {
+		if closeErr := w.fallbackCloser.Close(); err == nil {
+			err = closeErr
+		}
+		w.fallbackCloser = nil
+	}
+	return err
+}
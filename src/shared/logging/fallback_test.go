@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingWriter records the lines written to it, or rejects them while fail
+// is set
+type failingWriter struct {
+	fail     bool
+	attempts int
+	lines    []string
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.attempts++
+	if f.fail {
+		return 0, errors.New("no space left on device")
+	}
+	f.lines = append(f.lines, string(p))
+	return len(p), nil
+}
+
+func (f *failingWriter) Close() error {
+	return nil
+}
+
+func TestFallbackWriterSwitchesAndRecovers(t *testing.T) {
+	fallbackPath := filepath.Join(t.TempDir(), "fallback.log")
+	primary := &failingWriter{fail: true}
+	writer := newFallbackWriter(primary, &LoggerConfig{
+		ServiceName:   testServiceName,
+		FallbackPath:  fallbackPath,
+		FallbackAfter: 2,
+		FallbackRetry: time.Minute,
+	}, time.UTC)
+	now := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	writer.now = func() time.Time { return now }
+	write := func(line string) {
+		t.Helper()
+		if _, err := writer.Write([]byte(line + "\n")); err != nil {
+			t.Fatalf("Write(%q) error = %v", line, err)
+		}
+	}
+
+	write("first")
+	if stats := writer.Stats(); stats.OnFallback || stats.FailedWrites != 1 {
+		t.Errorf("Expected one failure without switching, got %+v", stats)
+	}
+	write("second")
+	if stats := writer.Stats(); stats != (OutputStats{FailedWrites: 2, Fallbacks: 1, OnFallback: true}) {
+		t.Errorf("Expected a switch after 2 failures, got %+v", stats)
+	}
+	// The log file is skipped until the retry interval has passed
+	primary.fail = false
+	write("third")
+	if primary.attempts != 2 {
+		t.Errorf("Expected the log file skipped, got %d attempts", primary.attempts)
+	}
+	now = now.Add(time.Minute)
+	write("fourth")
+	if stats := writer.Stats(); stats.OnFallback || len(primary.lines) != 1 || primary.lines[0] != "fourth\n" {
+		t.Errorf("Expected the log file back, got %+v and %q", stats, primary.lines)
+	}
+	writer.Close()
+
+	lines := readLines(t, fallbackPath)
+	// The notice precedes the line that made the output switch
+	if len(lines) != 5 || lines[0] != "first" || lines[2] != "second" || lines[3] != "third" {
+		t.Fatalf("Unexpected fallback lines %q", lines)
+	}
+	switched := decodeLogLine(t, lines[1])
+	if switched["level"] != "error" || switched["error"] != "no space left on device" || switched["failed_writes"] != float64(2) {
+		t.Errorf("Unexpected switch notice %v", switched)
+	}
+	if recovered := decodeLogLine(t, lines[4]); !strings.Contains(recovered["message"].(string), "switching back") {
+		t.Errorf("Unexpected recovery notice %v", recovered)
+	}
+}
+
+func TestLoggerOutputStats(t *testing.T) {
+	fallbackPath := filepath.Join(t.TempDir(), "fallback.log")
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:        InfoLevel,
+		FilePath:     filepath.Join(t.TempDir(), "app.log"),
+		LoggerName:   testLoggerName,
+		ServiceName:  testServiceName,
+		FallbackPath: fallbackPath,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	logger.output.primary = &failingWriter{fail: true}
+
+	clone := logger.WithField("component", "input").(*ZerologLogger)
+	for i := 0; i < 3; i++ {
+		clone.Infow("Kept on the fallback", "i", i)
+	}
+	if stats := logger.OutputStats(); stats != (OutputStats{FailedWrites: 3, Fallbacks: 1, OnFallback: true}) {
+		t.Errorf("OutputStats() = %+v", stats)
+	}
+	logger.Close()
+	if lines := readLines(t, fallbackPath); len(lines) != 4 {
+		t.Errorf("Expected the 3 lines and the switch notice on the fallback, got %q", lines)
+	}
+
+	stdout, err := NewLoggerWithConfig(&LoggerConfig{Output: OutputStdout, LoggerName: testLoggerName, ServiceName: testServiceName})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	if stats := stdout.OutputStats(); stats != (OutputStats{}) {
+		t.Errorf("Expected no stats without a log file, got %+v", stats)
+	}
+}
@@ -0,0 +1,33 @@
+package logging
+
+import "os"
+
+// eventLevel returns the level an event logged at level is written at:
+// config.FatalAsError turns fatal and panic events into error events
+func (c *LoggerConfig) eventLevel(level Level) Level {
+	if c.FatalAsError && level > ErrorLevel {
+		return ErrorLevel
+	}
+	return level
+}
+
+// terminate ends the program, or the goroutine, after a fatal or panic event
+// is written, through config.ExitFunc or config.PanicFunc when they are set.
+// Fatal and panic lines bypass the async buffer, so nothing queued before
+// them is lost.
+func (z *ZerologLogger) terminate(level Level, msg string) {
+	switch level {
+	case FatalLevel:
+		if z.config.ExitFunc != nil {
+			z.config.ExitFunc(1)
+			return
+		}
+		os.Exit(1)
+	case PanicLevel:
+		if z.config.PanicFunc != nil {
+			z.config.PanicFunc(msg)
+			return
+		}
+		panic(msg)
+	}
+}
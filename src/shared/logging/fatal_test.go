@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFatalCallsExitFunc(t *testing.T) {
+	var codes []int
+	var panics []string
+	lines := stackLines(t, func(c *LoggerConfig) {
+		c.ExitFunc = func(code int) { codes = append(codes, code) }
+		c.PanicFunc = func(msg string) { panics = append(panics, msg) }
+	}, func(logger Logger) {
+		logger.Fatalf("Failed to start: %v", "port in use")
+		logger.WithField("component", "input").Panicw("Corrupt state", "offset", 42)
+	})
+
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("Expected ExitFunc called once with 1, got %v", codes)
+	}
+	if len(panics) != 1 || panics[0] != "Corrupt state" {
+		t.Errorf("Expected PanicFunc called with the message, got %q", panics)
+	}
+	if len(lines) != 2 || lines[0]["level"] != "fatal" || lines[0]["message"] != "Failed to start: port in use" {
+		t.Fatalf("Expected the fatal line written before exiting, got %v", lines)
+	}
+	if lines[1]["level"] != "panic" || lines[1]["offset"] != float64(42) {
+		t.Errorf("Unexpected panic line %v", lines[1])
+	}
+}
+
+func TestPanicWithoutPanicFunc(t *testing.T) {
+	logger, err := NewLogger(&LoggerConfig{
+		Level:       InfoLevel,
+		FilePath:    filepath.Join(t.TempDir(), "app.log"),
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	defer logger.Close()
+
+	defer func() {
+		if recovered := recover(); recovered != "Corrupt state" {
+			t.Errorf("recover() = %v, want the message", recovered)
+		}
+	}()
+	logger.Panic("Corrupt state")
+	t.Error("Panic returned without a PanicFunc")
+}
+
+func TestFatalAsError(t *testing.T) {
+	exited := false
+	hook := &recordingHook{}
+	lines := stackLines(t, func(c *LoggerConfig) {
+		c.FatalAsError = true
+		c.ExitFunc = func(int) { exited = true }
+		c.Hooks = []Hook{hook}
+	}, func(logger Logger) {
+		logger.Fatal("Failed to start")
+		logger.Panic("Corrupt state")
+	})
+
+	if exited {
+		t.Error("Expected no exit with FatalAsError")
+	}
+	if len(lines) != 2 || lines[0]["level"] != "error" || lines[1]["level"] != "error" {
+		t.Errorf("Expected both lines at error level, got %v", lines)
+	}
+	if len(hook.events) != 2 || hook.events[0].level != ErrorLevel || hook.events[1].level != ErrorLevel {
+		t.Errorf("Expected hooks to see error level, got %v", hook.events)
+	}
+}
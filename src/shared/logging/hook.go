@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is called for every event a logger emits, before the event is
+// written. Fire runs synchronously on the logging goroutine, so it should be
+// quick; a hook that panics is reported and skipped.
+type Hook interface {
+	Fire(level Level, msg string, fields Fields)
+}
+
+// LevelHook is a Hook that only fires for events at or above MinLevel.
+// Hooks that do not implement it fire for every emitted event.
+type LevelHook interface {
+	Hook
+	MinLevel() Level
+}
+
+// hookSet holds the hooks shared by a logger and every logger derived from
+// it
+type hookSet struct {
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+func newHookSet(hooks []Hook) *hookSet {
+	return &hookSet{hooks: append([]Hook(nil), hooks...)}
+}
+
+func (s *hookSet) add(hook Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook)
+}
+
+// empty reports whether there is no hook, so callers can skip copying fields
+func (s *hookSet) empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.hooks) == 0
+}
+
+// fire calls the hooks whose minimum level admits level
+func (s *hookSet) fire(level Level, msg string, fields Fields) {
+	s.mu.RLock()
+	hooks := s.hooks
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if leveled, ok := hook.(LevelHook); ok && level < leveled.MinLevel() {
+			continue
+		}
+		fireHook(hook, level, msg, fields)
+	}
+}
+
+func fireHook(hook Hook, level Level, msg string, fields Fields) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "log hook %T panicked: %v\n", hook, r)
+		}
+	}()
+	hook.Fire(level, msg, fields)
+}
+
+// CountingHook counts emitted events by level, e.g. to export error rates as
+// metrics
+type CountingHook struct {
+	minLevel Level
+	counts   [PanicLevel + 1]atomic.Uint64
+}
+
+// NewCountingHook creates a hook counting the events at or above minLevel
+func NewCountingHook(minLevel Level) *CountingHook {
+	return &CountingHook{minLevel: minLevel}
+}
+
+// MinLevel returns the lowest level counted
+func (h *CountingHook) MinLevel() Level {
+	return h.minLevel
+}
+
+// Fire counts the event
+func (h *CountingHook) Fire(level Level, msg string, fields Fields) {
+	if level >= DebugLevel && level <= PanicLevel {
+		h.counts[level].Add(1)
+	}
+}
+
+// Count returns the number of events counted at level
+func (h *CountingHook) Count(level Level) uint64 {
+	if level < DebugLevel || level > PanicLevel {
+		return 0
+	}
+	return h.counts[level].Load()
+}
@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingHook records the events it is fired for
+type recordingHook struct {
+	mu       sync.Mutex
+	minLevel Level
+	events   []recordedEvent
+}
+
+type recordedEvent struct {
+	level  Level
+	msg    string
+	fields Fields
+}
+
+func (h *recordingHook) MinLevel() Level { return h.minLevel }
+
+func (h *recordingHook) Fire(level Level, msg string, fields Fields) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, recordedEvent{level: level, msg: msg, fields: fields})
+}
+
+type panickingHook struct{}
+
+func (panickingHook) Fire(Level, string, Fields) { panic("hook failed") }
+
+func newHookLogger(t *testing.T, hooks ...Hook) *ZerologLogger {
+	t.Helper()
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		Output:      OutputStdout,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+		Hooks:       hooks,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	return logger
+}
+
+func TestHookSeesMergedFields(t *testing.T) {
+	captureStdout(t)
+	hook := &recordingHook{}
+	logger := newHookLogger(t, hook)
+
+	logger.WithFields(Fields{"module": "orders"}).Errorw("Failed to publish", "topic", "out")
+	logger.Clone().Infof("Published %d", 3)
+
+	if len(hook.events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(hook.events))
+	}
+	first := hook.events[0]
+	if first.level != ErrorLevel || first.msg != "Failed to publish" {
+		t.Errorf("Expected the error event, got %+v", first)
+	}
+	if first.fields["module"] != "orders" || first.fields["topic"] != "out" {
+		t.Errorf("Expected the derived and call fields merged, got %v", first.fields)
+	}
+	if second := hook.events[1]; second.msg != "Published 3" || len(second.fields) != 0 {
+		t.Errorf("Expected the formatted message without fields, got %+v", second)
+	}
+}
+
+func TestHookLevelFiltering(t *testing.T) {
+	captureStdout(t)
+	counts := NewCountingHook(WarnLevel)
+	logger := newHookLogger(t, counts)
+
+	logger.Debug("Below the logger level")
+	logger.Info("Below the hook level")
+	logger.Warn("Counted")
+	logger.WithField("attempt", 2).Error("Counted")
+	logger.Log(ErrorLevel, "Counted")
+
+	if counts.Count(DebugLevel) != 0 || counts.Count(InfoLevel) != 0 {
+		t.Errorf("Expected nothing counted below warn, got debug=%d info=%d", counts.Count(DebugLevel), counts.Count(InfoLevel))
+	}
+	if counts.Count(WarnLevel) != 1 || counts.Count(ErrorLevel) != 2 {
+		t.Errorf("Expected 1 warn and 2 errors, got %d and %d", counts.Count(WarnLevel), counts.Count(ErrorLevel))
+	}
+
+	// Events the logger's level suppresses never reach a hook
+	all := &recordingHook{minLevel: DebugLevel}
+	logger.AddHook(all)
+	logger.Debug("Suppressed")
+	if len(all.events) != 0 {
+		t.Errorf("Expected suppressed events not to fire hooks, got %+v", all.events)
+	}
+}
+
+func TestAddHookReachesDerivedLoggers(t *testing.T) {
+	captureStdout(t)
+	logger := newHookLogger(t)
+	derived := logger.WithField("module", "processing")
+
+	hook := &recordingHook{}
+	derived.AddHook(hook)
+	logger.Error("From the root")
+	derived.Error("From the derived logger")
+
+	if len(hook.events) != 2 {
+		t.Errorf("Expected hooks added on a derived logger to fire for the root too, got %d events", len(hook.events))
+	}
+}
+
+func TestPanickingHookIsSkipped(t *testing.T) {
+	captured := captureStdout(t)
+	hook := &recordingHook{}
+	logger := newHookLogger(t, panickingHook{}, hook)
+
+	logger.Error("Still logged")
+
+	if len(hook.events) != 1 {
+		t.Errorf("Expected the hooks after a panicking one to fire, got %d events", len(hook.events))
+	}
+	if output := captured(); !strings.Contains(output, "Still logged") {
+		t.Errorf("Expected the event to be written, got %q", output)
+	}
+}
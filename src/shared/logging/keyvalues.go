@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// FieldLoggingError names the field describing malformed key/value pairs
+// passed to the w-suffixed methods, e.g. a key without a value
+const FieldLoggingError = "logging_error"
+
+// KeyValueChecker is implemented by loggers that can refuse malformed
+// key/value pairs instead of logging them
+type KeyValueChecker interface {
+	// TryLogw logs msg at level like Logw, unless keysAndValues are
+	// malformed, in which case it logs nothing and returns why
+	TryLogw(level Level, msg string, keysAndValues ...interface{}) error
+}
+
+// parseKeysAndValues turns alternating keys and values into Fields, with
+// non-string keys converted with fmt.Sprint and a trailing key without a
+// value dropped, and describes each of those problems
+func parseKeysAndValues(keysAndValues []interface{}) (Fields, []string) {
+	fields := make(Fields, len(keysAndValues)/2+1)
+	var problems []string
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+			problems = append(problems, fmt.Sprintf("non-string key %s of type %T", key, keysAndValues[i]))
+		}
+		if i+1 == len(keysAndValues) {
+			problems = append(problems, fmt.Sprintf("odd number of arguments, key %q without a value dropped", key))
+			break
+		}
+		fields[key] = keysAndValues[i+1]
+	}
+	return fields, problems
+}
+
+// keyValueError returns the error describing problems, nil without any
+func keyValueError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("malformed key/value pairs: " + strings.Join(problems, "; "))
+}
+
+// withKeysAndValues returns a logger adding the fields of keysAndValues to
+// its events. Malformed pairs are noted in a logging_error field or, with
+// config.StrictKeyValues set, reported with an error-level diagnostic
+// before the event is logged without the note.
+func (z *ZerologLogger) withKeysAndValues(msg string, keysAndValues []interface{}) Logger {
+	fields, problems := parseKeysAndValues(keysAndValues)
+	if len(problems) > 0 {
+		note := strings.Join(problems, "; ")
+		if !z.config.StrictKeyValues {
+			fields[FieldLoggingError] = note
+		} else if z.IsLevelEnabled(ErrorLevel) {
+			z.WithFields(Fields{FieldLoggingError: note, "logged_message": msg}).(*ZerologLogger).emit(ErrorLevel, "Malformed key/value pairs")
+		}
+	}
+	return z.WithFields(fields)
+}
+
+// logw logs msg at level with the fields of keysAndValues. A disabled level
+// returns before the pairs are looked at, unless one names a component or
+// logger whose override may enable it, and well-formed pairs are appended to
+// the event as they are instead of through a derived logger.
+func (z *ZerologLogger) logw(level Level, msg string, keysAndValues []interface{}) {
+	if !z.IsLevelEnabled(level) && !namesComponent(keysAndValues) {
+		return
+	}
+	if z.directPairs(keysAndValues) {
+		z.emit(level, msg, keysAndValues...)
+		return
+	}
+	z.withKeysAndValues(msg, keysAndValues).Log(level, msg)
+}
+
+// namesComponent reports whether keysAndValues has a component or
+// logger_name key, which may select a level override
+func namesComponent(keysAndValues []interface{}) bool {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok && (key == FieldComponent || key == FieldLoggerName) {
+			return true
+		}
+	}
+	return false
+}
+
+// directPairs reports whether keysAndValues can be appended to the event as
+// they are: string keys each with a value, none naming a component or logger
+// and none repeating another key or one of the logger's fields, which only a
+// derived logger handles
+func (z *ZerologLogger) directPairs(keysAndValues []interface{}) bool {
+	if len(keysAndValues)%2 != 0 {
+		return false
+	}
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok || key == FieldComponent || key == FieldLoggerName {
+			return false
+		}
+		if _, ok := z.fields[key]; ok {
+			return false
+		}
+		for j := 0; j < i; j += 2 {
+			if keysAndValues[j] == key {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// redacted returns value with the sensitive parts replaced, as WithFields
+// would store it
+func (z *ZerologLogger) redacted(key string, value interface{}) interface{} {
+	if z.redactor == nil {
+		return value
+	}
+	return z.redactor.field(key, value)
+}
+
+// appendField adds key and value to event through its typed methods, which
+// unlike Interface encode common values without reflection or allocations
+func appendField(event *zerolog.Event, key string, value interface{}) *zerolog.Event {
+	switch v := value.(type) {
+	case string:
+		return event.Str(key, v)
+	case bool:
+		return event.Bool(key, v)
+	case int:
+		return event.Int(key, v)
+	case int32:
+		return event.Int32(key, v)
+	case int64:
+		return event.Int64(key, v)
+	case uint:
+		return event.Uint(key, v)
+	case uint32:
+		return event.Uint32(key, v)
+	case uint64:
+		return event.Uint64(key, v)
+	case float32:
+		return event.Float32(key, v)
+	case float64:
+		return event.Float64(key, v)
+	}
+	return event.Interface(key, value)
+}
+
+// TryLogw logs msg at level like Logw, unless keysAndValues are malformed,
+// in which case it logs nothing and returns why
+func (z *ZerologLogger) TryLogw(level Level, msg string, keysAndValues ...interface{}) error {
+	fields, problems := parseKeysAndValues(keysAndValues)
+	if err := keyValueError(problems); err != nil {
+		return err
+	}
+	z.WithFields(fields).Log(level, msg)
+	return nil
+}
+
+// TryLogw records msg at level like Logw, unless keysAndValues are
+// malformed, in which case it records nothing and returns why
+func (t *TestLogger) TryLogw(level Level, msg string, keysAndValues ...interface{}) error {
+	fields, problems := parseKeysAndValues(keysAndValues)
+	if err := keyValueError(problems); err != nil {
+		return err
+	}
+	t.record(level, msg, fields)
+	return nil
+}
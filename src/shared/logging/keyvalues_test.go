@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLogwNotesMalformedPairs(t *testing.T) {
+	lines := stackLines(t, func(c *LoggerConfig) {}, func(logger Logger) {
+		logger.Infow("Odd", "topic", "orders", "partition")
+		logger.Warnw("Non-string key", 42, "answer")
+		logger.Errorw("Nil value", "error", nil)
+	})
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %v", lines)
+	}
+
+	if lines[0]["topic"] != "orders" || lines[0][FieldLoggingError] != `odd number of arguments, key "partition" without a value dropped` {
+		t.Errorf("Unexpected line for an odd count %v", lines[0])
+	}
+	if lines[1]["42"] != "answer" || lines[1][FieldLoggingError] != "non-string key 42 of type int" {
+		t.Errorf("Unexpected line for a non-string key %v", lines[1])
+	}
+	if value, ok := lines[2]["error"]; !ok || value != nil {
+		t.Errorf("Expected a null error field, got %v", lines[2])
+	}
+	if _, ok := lines[2][FieldLoggingError]; ok {
+		t.Errorf("Expected no logging_error for a nil value, got %v", lines[2])
+	}
+}
+
+func TestLogwStrictKeyValues(t *testing.T) {
+	lines := stackLines(t, func(c *LoggerConfig) { c.StrictKeyValues = true }, func(logger Logger) {
+		logger.Debugw("Odd", "topic", "orders", "partition")
+		logger.Infow("Well formed", "topic", "orders")
+	})
+	if len(lines) != 3 {
+		t.Fatalf("Expected the diagnostic, the event and the well-formed event, got %v", lines)
+	}
+
+	diagnostic := lines[0]
+	if diagnostic["level"] != "error" || diagnostic["logged_message"] != "Odd" || !strings.Contains(diagnostic[FieldLoggingError].(string), `key "partition"`) {
+		t.Errorf("Unexpected diagnostic %v", diagnostic)
+	}
+	if _, ok := lines[1][FieldLoggingError]; ok || lines[1]["message"] != "Odd" || lines[1]["topic"] != "orders" {
+		t.Errorf("Expected the event without the note, got %v", lines[1])
+	}
+}
+
+func TestTryLogw(t *testing.T) {
+	var err error
+	lines := stackLines(t, func(c *LoggerConfig) {}, func(logger Logger) {
+		checker := logger.(KeyValueChecker)
+		if err := checker.TryLogw(InfoLevel, "Well formed", "topic", "orders", "offset", nil); err != nil {
+			t.Errorf("TryLogw() error = %v for well-formed pairs", err)
+		}
+		err = checker.TryLogw(InfoLevel, "Odd", 7, "orders", "partition")
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "non-string key 7") || !strings.Contains(err.Error(), `key "partition" without a value`) {
+		t.Errorf("TryLogw() error = %v, want both problems", err)
+	}
+	if len(lines) != 1 || lines[0]["message"] != "Well formed" {
+		t.Errorf("Expected only the well-formed event logged, got %v", lines)
+	}
+
+	recorder := NewTestLogger()
+	if err := recorder.TryLogw(WarnLevel, "Odd", "partition"); err == nil || len(recorder.Entries()) != 0 {
+		t.Errorf("Expected TestLogger.TryLogw to refuse and record nothing, got %v and %v", err, recorder.Entries())
+	}
+}
+
+func TestInfowDirectPairs(t *testing.T) {
+	hook := &recordingHook{}
+	lines := stackLines(t, func(c *LoggerConfig) {
+		c.Level = InfoLevel
+		c.Redact = []string{"password"}
+		c.Hooks = []Hook{hook}
+	}, func(logger Logger) {
+		logger.Debugw("Filtered", "topic", "orders")
+		scoped := logger.WithField("topic", "orders")
+		scoped.Infow("Direct", "partition", 3, "ratio", 0.5, "ok", true, "password", "hunter2", "meta", map[string]string{"user": "bob"})
+		// Repeated keys go through a derived logger, the last value winning
+		scoped.Infow("Repeated", "topic", "payments", "partition", 1, "partition", 2)
+	})
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %v", lines)
+	}
+
+	direct := lines[0]
+	if direct["topic"] != "orders" || direct["partition"] != float64(3) || direct["ratio"] != 0.5 || direct["ok"] != true {
+		t.Errorf("Unexpected direct line %v", direct)
+	}
+	if direct["password"] != RedactedValue {
+		t.Errorf("Expected the password redacted, got %v", direct["password"])
+	}
+	if meta, _ := direct["meta"].(map[string]interface{}); meta["user"] != "bob" {
+		t.Errorf("Expected the map logged as an object, got %v", direct["meta"])
+	}
+	if fields := hook.events[0].fields; fields["password"] != RedactedValue || fields["partition"] != 3 || fields["topic"] != "orders" {
+		t.Errorf("Expected hooks to see the pairs redacted, got %v", fields)
+	}
+
+	if repeated := lines[1]; repeated["topic"] != "payments" || repeated["partition"] != float64(2) {
+		t.Errorf("Unexpected line for repeated keys %v", repeated)
+	}
+}
+
+func TestInfowComponentOverrideOnDisabledLevel(t *testing.T) {
+	lines := stackLines(t, func(c *LoggerConfig) {
+		c.Level = WarnLevel
+		c.LevelOverrides = map[string]Level{"input": DebugLevel}
+	}, func(logger Logger) {
+		logger.Debugw("Filtered", "topic", "orders")
+		logger.Debugw("Enabled by the override", FieldComponent, "input")
+	})
+	if len(lines) != 1 || lines[0]["message"] != "Enabled by the override" {
+		t.Errorf("Expected only the line for the overridden component, got %v", lines)
+	}
+}
+
+func TestInfowDisabledDoesNotAllocate(t *testing.T) {
+	logger, err := NewLoggerWithConfig(&LoggerConfig{Level: WarnLevel, Writer: io.Discard, LoggerName: testLoggerName, ServiceName: testServiceName})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Infow("Record processed", "topic", "orders", "partition", 3)
+	})
+	if allocs != 0 {
+		t.Errorf("Infow at a disabled level allocated %v times", allocs)
+	}
+}
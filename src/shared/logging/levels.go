@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// FieldComponent is the field naming the component a logger logs for
+const FieldComponent = "component"
+
+// componentFields are the fields whose values LoggerConfig.LevelOverrides
+// is keyed on, outermost first
+var componentFields = []string{FieldComponent, FieldLoggerName}
+
+// ComponentLeveler is implemented by loggers whose level can be overridden
+// per component or logger name while they run
+type ComponentLeveler interface {
+	// SetComponentLevel sets the level of the loggers for component, and of
+	// the loggers derived from them, leaving the others at theirs
+	SetComponentLevel(component string, level Level)
+	// ResetComponentLevel makes the loggers for component follow the global
+	// level again
+	ResetComponentLevel(component string)
+	// ComponentLevels returns the overridden levels by component
+	ComponentLevels() map[string]Level
+}
+
+// levelOverride is the level of a component or logger name. Until it is
+// set the loggers for the name follow the global level.
+type levelOverride struct {
+	set   atomic.Bool
+	level atomic.Int32
+}
+
+func (o *levelOverride) store(level Level) {
+	o.level.Store(int32(level))
+	o.set.Store(true)
+}
+
+func (o *levelOverride) clear() {
+	o.set.Store(false)
+}
+
+// levelOverrides holds the overrides of a root logger by component or
+// logger name, shared with every logger derived from it. An unset entry is
+// created for every name a logger is derived with, so an override set later
+// reaches the loggers that already exist.
+type levelOverrides struct {
+	mu     sync.Mutex
+	byName map[string]*levelOverride
+}
+
+func newLevelOverrides(levels map[string]Level) *levelOverrides {
+	o := &levelOverrides{byName: make(map[string]*levelOverride)}
+	for name, level := range levels {
+		o.get(name).store(level)
+	}
+	return o
+}
+
+// get returns the override for name, creating it unset on first use
+func (o *levelOverrides) get(name string) *levelOverride {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	override, ok := o.byName[name]
+	if !ok {
+		override = &levelOverride{}
+		o.byName[name] = override
+	}
+	return override
+}
+
+// levels returns the overrides that are set
+func (o *levelOverrides) levels() map[string]Level {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	levels := make(map[string]Level)
+	for name, override := range o.byName {
+		if override.set.Load() {
+			levels[name] = Level(override.level.Load())
+		}
+	}
+	return levels
+}
+
+// chain returns c extended with the overrides for the component and logger
+// names in fields
+func (o *levelOverrides) chain(c levelChain, fields Fields) levelChain {
+	for _, key := range componentFields {
+		if name, ok := fields[key].(string); ok && name != "" {
+			c = c.with(o.get(name))
+		}
+	}
+	return c
+}
+
+// levelChain holds the overrides of the component and logger names a logger
+// was created or derived with, outermost first. The innermost override that
+// is set decides the logger's level, so an override for a component also
+// covers the loggers derived from it for other components without their own.
+type levelChain []*levelOverride
+
+// effective returns the innermost override that is set, and root when none
+// is
+func (c levelChain) effective(root *atomic.Int32) Level {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].set.Load() {
+			return Level(c[i].level.Load())
+		}
+	}
+	return Level(root.Load())
+}
+
+// with returns c extended with override, leaving c unchanged since loggers
+// derived from the same one share it
+func (c levelChain) with(override *levelOverride) levelChain {
+	if len(c) > 0 && c[len(c)-1] == override {
+		return c
+	}
+	extended := make(levelChain, len(c), len(c)+1)
+	copy(extended, c)
+	return append(extended, override)
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLevelOverridesByComponent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLogger(&LoggerConfig{
+		Level:          InfoLevel,
+		FilePath:       path,
+		LoggerName:     testLoggerName,
+		ServiceName:    testServiceName,
+		LevelOverrides: map[string]Level{"processing": DebugLevel},
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+
+	processing := logger.WithField(FieldComponent, "processing")
+	processing.Debug("Processing detail")
+	// Derived components inherit the override
+	processing.WithField(FieldComponent, "input").Debug("Input detail")
+	logger.WithField(FieldComponent, "api").Debug("API detail")
+	logger.Debug("Root detail")
+	logger.Close()
+
+	var messages []interface{}
+	for _, line := range readLines(t, path) {
+		messages = append(messages, decodeLogLine(t, line)["message"])
+	}
+	if want := []interface{}{"Processing detail", "Input detail"}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %v, want %v", messages, want)
+	}
+}
+
+func TestLevelOverridesByConfigName(t *testing.T) {
+	logger, err := NewLogger(&LoggerConfig{
+		Level:          ErrorLevel,
+		FilePath:       filepath.Join(t.TempDir(), "pipeline.log"),
+		LoggerName:     "pipeline",
+		ComponentName:  "processing",
+		ServiceName:    testServiceName,
+		LevelOverrides: map[string]Level{"processing": DebugLevel},
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	defer logger.Close()
+
+	if logger.GetLevel() != DebugLevel {
+		t.Errorf("Expected the override for the configured component, got %v", logger.GetLevel())
+	}
+	// A logger name override is more specific than the component's
+	logger.(ComponentLeveler).SetComponentLevel("pipeline", WarnLevel)
+	if logger.GetLevel() != WarnLevel {
+		t.Errorf("Expected the logger name override to win, got %v", logger.GetLevel())
+	}
+}
+
+func TestSetComponentLevel(t *testing.T) {
+	logger := NewTestLogger()
+	logger.SetLevel(InfoLevel)
+	api := logger.WithField(FieldComponent, "api")
+
+	// Reaches loggers derived before the override was set
+	logger.SetComponentLevel("api", ErrorLevel)
+	api.Warn("API warning")
+	logger.Warn("Root warning")
+	if logger.HasEntry(WarnLevel, "API warning") || !logger.HasEntry(WarnLevel, "Root warning") {
+		t.Errorf("Expected only the root warning, got %+v", logger.Entries())
+	}
+	if got := logger.ComponentLevels(); !reflect.DeepEqual(got, map[string]Level{"api": ErrorLevel}) {
+		t.Errorf("ComponentLevels() = %v", got)
+	}
+
+	logger.ResetComponentLevel("api")
+	api.Warn("API warning")
+	if !logger.HasEntry(WarnLevel, "API warning") || len(logger.ComponentLevels()) != 0 {
+		t.Errorf("Expected the API warning after ResetComponentLevel(), got %+v", logger.Entries())
+	}
+}
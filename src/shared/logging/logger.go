@@ -3,6 +3,9 @@ package logging
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 )
 
 // Level represents the logging level
@@ -17,9 +20,9 @@ const (
 	WarnLevel
 	// ErrorLevel logs are high-priority. If an application is running smoothly, it shouldn't generate any error-level logs
 	ErrorLevel
-	// FatalLevel logs a message, then calls os.Exit(1)
+	// FatalLevel logs a message, then calls os.Exit(1) or LoggerConfig.ExitFunc
 	FatalLevel
-	// PanicLevel logs a message, then panics
+	// PanicLevel logs a message, then panics or calls LoggerConfig.PanicFunc
 	PanicLevel
 )
 
@@ -43,6 +46,47 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel returns the level named s, ignoring case and surrounding
+// space. Besides the names String returns it accepts the aliases "warning"
+// and "err".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error", "err":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown log level %q, valid levels are debug, info, warn, error, fatal, panic", s)
+	}
+}
+
+// MarshalText encodes the level as its lowercase name, so levels read and
+// write as e.g. level: debug in YAML and JSON
+func (l Level) MarshalText() ([]byte, error) {
+	if l < DebugLevel || l > PanicLevel {
+		return nil, fmt.Errorf("unknown log level %d", int(l))
+	}
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+// UnmarshalText decodes a level name accepted by ParseLevel
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
 // Fields represents structured logging fields
 type Fields map[string]interface{}
 
@@ -90,6 +134,9 @@ type Logger interface {
 	Logf(level Level, format string, args ...interface{})
 	Logw(level Level, msg string, keysAndValues ...interface{})
 
+	// Hooks called for every emitted event, shared with derived loggers
+	AddHook(hook Hook)
+
 	// Clone creates a copy of the logger
 	Clone() Logger
 
@@ -97,18 +144,36 @@ type Logger interface {
 	Close() error
 }
 
-// Helper function to convert key-value pairs to Fields
+// keysAndValuesToFields converts alternating keys and values to Fields,
+// noting malformed pairs in a logging_error field
 func keysAndValuesToFields(keysAndValues ...interface{}) Fields {
-	fields := make(Fields)
-	for i := 0; i < len(keysAndValues); i += 2 {
-		if i+1 < len(keysAndValues) {
-			key := fmt.Sprintf("%v", keysAndValues[i])
-			fields[key] = keysAndValues[i+1]
-		}
+	fields, problems := parseKeysAndValues(keysAndValues)
+	if len(problems) > 0 {
+		fields[FieldLoggingError] = strings.Join(problems, "; ")
 	}
 	return fields
 }
 
+// Log outputs selectable with LoggerConfig.Output and LoggerConfig.Outputs
+const (
+	OutputFile   = "file"   // Append to FilePath, the default
+	OutputStdout = "stdout" // Write to standard output, e.g. in containers
+	OutputStderr = "stderr" // Write to standard error
+)
+
+// Log formats selectable with LoggerConfig.Format
+const (
+	FormatJSON    = "json"    // JSON lines, the default
+	FormatText    = "text"    // Human-readable lines from zerolog's console writer
+	FormatConsole = "console" // Alias of FormatText
+)
+
+// Policies for a full async buffer, selectable with LoggerConfig.Overflow
+const (
+	OverflowBlock = "block" // Wait for room in the buffer, the default
+	OverflowDrop  = "drop"  // Drop the line and count it in Dropped
+)
+
 // LoggerConfig holds comprehensive configuration for creating loggers
 type LoggerConfig struct {
 	// Basic configuration
@@ -117,6 +182,91 @@ type LoggerConfig struct {
 	LoggerName    string // Name identifier for the logger instance
 	ComponentName string // Component/module name for structured logging
 	ServiceName   string // Service name for structured logging
+
+	Output string // OutputFile, OutputStdout or OutputStderr; empty means OutputFile
+	Format string // FormatJSON, FormatText or FormatConsole for every output; empty means FormatJSON
+	Color  bool   // Colorize human-readable output; leave unset for files
+
+	// Writer takes the place of the log file, e.g. a bytes.Buffer in tests,
+	// making FilePath optional. Close closes it when it is an io.Closer other
+	// than os.Stdout or os.Stderr.
+	Writer io.Writer
+
+	// Multiple outputs
+	Outputs []string // Outputs written at once, e.g. file and stdout; overrides Output when set
+	Console bool     // Write the stdout and stderr copies in zerolog's human-readable console format; files stay JSON
+	Strict  bool     // Fail when any output cannot be opened, instead of reporting it and writing to the rest
+
+	// File rotation
+	IsLogRotatable bool // Rotate the log file under the limits below; the file grows forever otherwise
+	MaxSize        int  // Megabytes the log file may reach before it is rotated. 0 means 100
+	MaxAge         int  // Days rotated backups are kept. 0 keeps them regardless of age
+	MaxBackups     int  // Rotated backups kept. 0 keeps them all
+	Compress       bool // Gzip rotated backups
+
+	// Asynchronous writes
+	Async        bool          // Write lines from a background goroutine instead of the logging call
+	BufferSize   int           // Lines queued in async mode. 0 means 1024
+	Overflow     string        // OverflowBlock or OverflowDrop when the buffer is full; empty means OverflowBlock
+	CloseTimeout time.Duration // How long Close waits for queued lines. 0 means 5s
+
+	// Context fields
+	ContextKeys       []string                    // Fields WithContext attaches when the context carries them, e.g. request_id, trace_id
+	ContextExtractors map[string]ContextExtractor // Fields WithContext attaches by calling the extractor; overrides ContextKeys of the same name
+
+	// Hooks called for every emitted event, before it is written
+	Hooks []Hook
+
+	// Redaction
+	Redact []string // Field name globs, e.g. password or *_secret, whose values are logged as "***" at any depth; case-insensitive
+
+	// Levels by component or logger name, e.g. {"processing": DebugLevel}, for
+	// loggers created with a matching ComponentName or LoggerName or derived
+	// with a matching component or logger_name field. Loggers derived from them
+	// inherit the override unless they match one of their own; the others use
+	// Level.
+	LevelOverrides map[string]Level
+
+	// Error details
+	IncludeStack bool   // Add a stack trace, and the chain of wrapped errors, to error-level events of loggers from WithError
+	StackCapture string // StackCaptureLog or StackCaptureWithError; empty means StackCaptureLog
+
+	// Timestamps
+	TimestampFormat string // Go time layout, or TimestampRFC3339 or TimestampRFC3339Nano by name; empty means RFC3339
+	TimeZone        string // UTC, Local or an IANA zone name such as Europe/Paris; empty means UTC
+
+	// Fallback when the log file rejects writes, e.g. on a full disk
+	FallbackPath  string        // File written instead of the log file while it keeps failing; empty means stderr
+	FallbackAfter int           // Consecutive failed writes before the log file is skipped. 0 means 3
+	FallbackRetry time.Duration // How often a skipped log file is tried again. 0 means 30s
+
+	// Key/value pairs of the w-suffixed methods
+	StrictKeyValues bool // Report malformed pairs with an error-level diagnostic instead of a logging_error field on the event
+
+	// Fatal and panic events, interceptable so tests can assert them
+	ExitFunc     func(code int)   // Called with 1 after a fatal event is written; nil means os.Exit
+	PanicFunc    func(msg string) // Called with the message after a panic event is written; nil means panic(msg)
+	FatalAsError bool             // Log fatal and panic events at error level and carry on, for tests
+}
+
+// humanReadable reports whether output is written in the console format
+// rather than as JSON lines
+func (c *LoggerConfig) humanReadable(output string) bool {
+	if c.Format == FormatText || c.Format == FormatConsole {
+		return true
+	}
+	return c.Console && (output == OutputStdout || output == OutputStderr)
+}
+
+// outputs returns the outputs the logger writes to
+func (c *LoggerConfig) outputs() []string {
+	if len(c.Outputs) > 0 {
+		return c.Outputs
+	}
+	if c.Output == "" {
+		return []string{OutputFile}
+	}
+	return []string{c.Output}
 }
 
 // DefaultConfig returns the default logger configuration
@@ -127,13 +277,61 @@ func DefaultConfig() *LoggerConfig {
 		LoggerName:    "default",
 		ComponentName: "application",
 		ServiceName:   "service",
+		ContextKeys:   DefaultContextKeys(),
+		Redact:        DefaultRedactPatterns(),
 	}
 }
 
 // Validate validates the logger configuration
 func (c *LoggerConfig) Validate() error {
-	if c.FilePath == "" {
-		return fmt.Errorf("filename is required - stdout logging is not allowed")
+	for _, output := range c.outputs() {
+		switch output {
+		case OutputFile:
+			if c.FilePath == "" && c.Writer == nil {
+				return fmt.Errorf("filename is required unless a writer is set or output is %s or %s", OutputStdout, OutputStderr)
+			}
+		case OutputStdout, OutputStderr:
+		default:
+			return fmt.Errorf("output %q must be %s, %s or %s", output, OutputFile, OutputStdout, OutputStderr)
+		}
+	}
+	switch c.Format {
+	case "", FormatJSON, FormatText, FormatConsole:
+	default:
+		return fmt.Errorf("format %q must be %s, %s or %s", c.Format, FormatJSON, FormatText, FormatConsole)
+	}
+	if err := ValidateTimestamp(c.TimestampFormat, c.TimeZone); err != nil {
+		return err
+	}
+	switch c.StackCapture {
+	case "", StackCaptureLog, StackCaptureWithError:
+	default:
+		return fmt.Errorf("stack capture %q must be %s or %s", c.StackCapture, StackCaptureLog, StackCaptureWithError)
+	}
+	switch c.Overflow {
+	case "", OverflowBlock, OverflowDrop:
+	default:
+		return fmt.Errorf("overflow %q must be %s or %s", c.Overflow, OverflowBlock, OverflowDrop)
+	}
+	if c.FallbackAfter < 0 || c.FallbackRetry < 0 {
+		return fmt.Errorf("fallback threshold and retry interval must not be negative")
+	}
+	if c.BufferSize < 0 || c.CloseTimeout < 0 {
+		return fmt.Errorf("async buffer size and close timeout must not be negative")
+	}
+	if err := validateRedactPatterns(c.Redact); err != nil {
+		return err
+	}
+	for name, level := range c.LevelOverrides {
+		if name == "" {
+			return fmt.Errorf("level overrides need a component or logger name")
+		}
+		if level < DebugLevel || level > PanicLevel {
+			return fmt.Errorf("level override for %q has unknown level %d", name, int(level))
+		}
+	}
+	if c.MaxSize < 0 || c.MaxAge < 0 || c.MaxBackups < 0 {
+		return fmt.Errorf("rotation limits must not be negative")
 	}
 	if c.LoggerName == "" {
 		return fmt.Errorf("logger name is required")
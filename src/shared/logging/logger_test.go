@@ -1,9 +1,13 @@
 package logging
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -50,6 +54,67 @@ func TestLevelString(t *testing.T) {
 	}
 }
 
+func TestParseLevel(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  Level
+	}{
+		{"debug", DebugLevel},
+		{"INFO", InfoLevel},
+		{"Warn", WarnLevel},
+		{"warning", WarnLevel},
+		{"error", ErrorLevel},
+		{"err", ErrorLevel},
+		{" fatal ", FatalLevel},
+		{"panic", PanicLevel},
+	} {
+		if got, err := ParseLevel(tt.input); err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "verbose", "!!"} {
+		if _, err := ParseLevel(input); err == nil || !strings.Contains(err.Error(), "valid levels are debug, info, warn, error, fatal, panic") {
+			t.Errorf("ParseLevel(%q) error = %v, want an error listing the valid levels", input, err)
+		}
+	}
+}
+
+func TestLevelTextRoundTrip(t *testing.T) {
+	type document struct {
+		Level Level `json:"level" yaml:"level"`
+	}
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel} {
+		name := strings.ToLower(level.String())
+
+		encoded, err := yaml.Marshal(document{Level: level})
+		if err != nil || string(encoded) != "level: "+name+"\n" {
+			t.Errorf("yaml.Marshal(%v) = %q, %v", level, encoded, err)
+		}
+		var fromYAML document
+		if err := yaml.Unmarshal(encoded, &fromYAML); err != nil || fromYAML.Level != level {
+			t.Errorf("yaml.Unmarshal(%q) = %v, %v, want %v", encoded, fromYAML.Level, err, level)
+		}
+
+		encoded, err = json.Marshal(document{Level: level})
+		if err != nil || string(encoded) != `{"level":"`+name+`"}` {
+			t.Errorf("json.Marshal(%v) = %s, %v", level, encoded, err)
+		}
+		var fromJSON document
+		if err := json.Unmarshal(encoded, &fromJSON); err != nil || fromJSON.Level != level {
+			t.Errorf("json.Unmarshal(%s) = %v, %v, want %v", encoded, fromJSON.Level, err, level)
+		}
+	}
+
+	var junk document
+	if err := yaml.Unmarshal([]byte("level: loud\n"), &junk); err == nil {
+		t.Error("Expected yaml.Unmarshal to reject an unknown level")
+	}
+	if _, err := Level(42).MarshalText(); err == nil {
+		t.Error("Expected MarshalText to reject an unknown level")
+	}
+}
+
 func TestLoggerConfigValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -79,6 +144,16 @@ func TestLoggerConfigValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  errFilenameRequired,
 		},
+		{
+			name: "writer without filename",
+			config: LoggerConfig{
+				Level:       InfoLevel,
+				Writer:      io.Discard,
+				LoggerName:  testLoggerName,
+				ServiceName: testServiceName,
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty filename",
 			config: LoggerConfig{
@@ -91,6 +166,68 @@ func TestLoggerConfigValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  errFilenameRequired,
 		},
+		{
+			name: "stdout without filename",
+			config: LoggerConfig{
+				Level:         InfoLevel,
+				LoggerName:    testLoggerName,
+				ComponentName: testComponentName,
+				ServiceName:   testServiceName,
+				Output:        OutputStdout,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown output",
+			config: LoggerConfig{
+				Level:         InfoLevel,
+				FilePath:      testLogFile,
+				LoggerName:    testLoggerName,
+				ComponentName: testComponentName,
+				ServiceName:   testServiceName,
+				Output:        "syslog",
+			},
+			wantErr: true,
+			errMsg:  `output "syslog" must be file, stdout or stderr`,
+		},
+		{
+			name: "outputs including file without filename",
+			config: LoggerConfig{
+				Level:         InfoLevel,
+				LoggerName:    testLoggerName,
+				ComponentName: testComponentName,
+				ServiceName:   testServiceName,
+				Outputs:       []string{OutputStdout, OutputFile},
+			},
+			wantErr: true,
+			errMsg:  errFilenameRequired,
+		},
+		{
+			name: "unknown format",
+			config: LoggerConfig{
+				Level:         InfoLevel,
+				FilePath:      testLogFile,
+				LoggerName:    testLoggerName,
+				ComponentName: testComponentName,
+				ServiceName:   testServiceName,
+				Format:        "xml",
+			},
+			wantErr: true,
+			errMsg:  `format "xml" must be json, text or console`,
+		},
+		{
+			name: "unknown overflow policy",
+			config: LoggerConfig{
+				Level:       InfoLevel,
+				FilePath:    testLogFile,
+				LoggerName:  testLoggerName,
+				ServiceName: testServiceName,
+				Async:       true,
+				Overflow:    "spill",
+			},
+			wantErr: true,
+			errMsg:  `overflow "spill" must be block or drop`,
+		},
 		{
 			name: "missing logger name",
 			config: LoggerConfig{
@@ -137,6 +274,54 @@ func TestLoggerConfigValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  errServiceRequired,
 		},
+		{
+			name: "unknown override level",
+			config: LoggerConfig{
+				Level:          InfoLevel,
+				FilePath:       testLogFile,
+				LoggerName:     testLoggerName,
+				ServiceName:    testServiceName,
+				LevelOverrides: map[string]Level{"processing": Level(42)},
+			},
+			wantErr: true,
+			errMsg:  `level override for "processing" has unknown level 42`,
+		},
+		{
+			name: "unknown stack capture",
+			config: LoggerConfig{
+				Level:        InfoLevel,
+				FilePath:     testLogFile,
+				LoggerName:   testLoggerName,
+				ServiceName:  testServiceName,
+				StackCapture: "panic",
+			},
+			wantErr: true,
+			errMsg:  `stack capture "panic" must be log or with_error`,
+		},
+		{
+			name: "timestamp format without layout elements",
+			config: LoggerConfig{
+				Level:           InfoLevel,
+				FilePath:        testLogFile,
+				LoggerName:      testLoggerName,
+				ServiceName:     testServiceName,
+				TimestampFormat: "%Y-%m-%d",
+			},
+			wantErr: true,
+			errMsg:  `timestamp format "%Y-%m-%d" is not a Go time layout`,
+		},
+		{
+			name: "unknown time zone",
+			config: LoggerConfig{
+				Level:       InfoLevel,
+				FilePath:    testLogFile,
+				LoggerName:  testLoggerName,
+				ServiceName: testServiceName,
+				TimeZone:    "Mars/Olympus",
+			},
+			wantErr: true,
+			errMsg:  `time zone "Mars/Olympus" must be UTC, Local or an IANA zone name`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,17 +396,17 @@ func TestKeysAndValuesToFields(t *testing.T) {
 		{
 			name:           "odd number of arguments",
 			keysAndValues:  []interface{}{"key1", "value1", "key2"},
-			expectedFields: Fields{"key1": "value1"},
+			expectedFields: Fields{"key1": "value1", FieldLoggingError: `odd number of arguments, key "key2" without a value dropped`},
 		},
 		{
 			name:           "single key no value",
 			keysAndValues:  []interface{}{"lonely_key"},
-			expectedFields: Fields{},
+			expectedFields: Fields{FieldLoggingError: `odd number of arguments, key "lonely_key" without a value dropped`},
 		},
 		{
 			name:           "mixed types as keys",
 			keysAndValues:  []interface{}{123, "number_key", true, "bool_key", 3.14, "float_key"},
-			expectedFields: Fields{"123": "number_key", "true": "bool_key", "3.14": "float_key"},
+			expectedFields: Fields{"123": "number_key", "true": "bool_key", "3.14": "float_key", FieldLoggingError: "non-string key 123 of type int; non-string key true of type bool; non-string key 3.14 of type float64"},
 		},
 		{
 			name:           "nil values",
@@ -510,8 +695,8 @@ func TestKeysAndValuesToFieldsEdgeCases(t *testing.T) {
 
 	// Test with nil arguments
 	result = keysAndValuesToFields(nil, nil)
-	if len(result) != 1 || result["<nil>"] != nil {
-		t.Errorf("Expected one field with nil key and value")
+	if v, ok := result["<nil>"]; len(result) != 2 || !ok || v != nil || result[FieldLoggingError] == nil {
+		t.Errorf("Expected a field with nil key and value, and the non-string key noted, got %v", result)
 	}
 
 	// Test with large number of arguments
@@ -0,0 +1,45 @@
+package logging
+
+import "context"
+
+// NopLogger is a Logger that discards everything, for tests and callers that
+// need a logger but no output. Its zero value is ready to use, and it can be
+// embedded to implement Logger while overriding only some methods.
+type NopLogger struct{}
+
+// NewNopLogger returns a logger that discards everything
+func NewNopLogger() Logger {
+	return &NopLogger{}
+}
+
+func (n *NopLogger) SetLevel(level Level)                                       {}
+func (n *NopLogger) GetLevel() Level                                            { return InfoLevel }
+func (n *NopLogger) IsLevelEnabled(level Level) bool                            { return false }
+func (n *NopLogger) Debug(msg string)                                           {}
+func (n *NopLogger) Info(msg string)                                            {}
+func (n *NopLogger) Warn(msg string)                                            {}
+func (n *NopLogger) Error(msg string)                                           {}
+func (n *NopLogger) Fatal(msg string)                                           {}
+func (n *NopLogger) Panic(msg string)                                           {}
+func (n *NopLogger) Debugf(format string, args ...interface{})                  {}
+func (n *NopLogger) Infof(format string, args ...interface{})                   {}
+func (n *NopLogger) Warnf(format string, args ...interface{})                   {}
+func (n *NopLogger) Errorf(format string, args ...interface{})                  {}
+func (n *NopLogger) Fatalf(format string, args ...interface{})                  {}
+func (n *NopLogger) Panicf(format string, args ...interface{})                  {}
+func (n *NopLogger) Debugw(msg string, keysAndValues ...interface{})            {}
+func (n *NopLogger) Infow(msg string, keysAndValues ...interface{})             {}
+func (n *NopLogger) Warnw(msg string, keysAndValues ...interface{})             {}
+func (n *NopLogger) Errorw(msg string, keysAndValues ...interface{})            {}
+func (n *NopLogger) Fatalw(msg string, keysAndValues ...interface{})            {}
+func (n *NopLogger) Panicw(msg string, keysAndValues ...interface{})            {}
+func (n *NopLogger) WithFields(fields Fields) Logger                            { return n }
+func (n *NopLogger) WithField(key string, value interface{}) Logger             { return n }
+func (n *NopLogger) WithError(err error) Logger                                 { return n }
+func (n *NopLogger) WithContext(ctx context.Context) Logger                     { return n }
+func (n *NopLogger) Log(level Level, msg string)                                {}
+func (n *NopLogger) Logf(level Level, format string, args ...interface{})       {}
+func (n *NopLogger) Logw(level Level, msg string, keysAndValues ...interface{}) {}
+func (n *NopLogger) AddHook(hook Hook)                                          {}
+func (n *NopLogger) Clone() Logger                                              { return n }
+func (n *NopLogger) Close() error                                               { return nil }
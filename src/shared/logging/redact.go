@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the values of fields matching LoggerConfig.Redact
+const RedactedValue = "***"
+
+// DefaultRedactPatterns are the field name patterns DefaultConfig redacts
+func DefaultRedactPatterns() []string {
+	return []string{"password", "*_password", "secret", "*_secret", "authorization", "*_token", "api_key", "apikey"}
+}
+
+// redactor replaces the values of fields whose names match one of its
+// patterns. Patterns are path.Match globs, matched case-insensitively.
+type redactor struct {
+	patterns []string // Lowercase
+}
+
+// newRedactor returns a redactor for patterns, or nil when there are none
+func newRedactor(patterns []string) *redactor {
+	if len(patterns) == 0 {
+		return nil
+	}
+	r := &redactor{patterns: make([]string, 0, len(patterns))}
+	for _, pattern := range patterns {
+		r.patterns = append(r.patterns, strings.ToLower(pattern))
+	}
+	return r
+}
+
+// validateRedactPatterns reports the first malformed pattern
+func validateRedactPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("redact pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// sensitive reports whether key matches one of the patterns
+func (r *redactor) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns a copy of fields with sensitive values replaced, at any
+// depth. A nil redactor returns fields unchanged.
+func (r *redactor) fields(fields Fields) Fields {
+	if r == nil {
+		return fields
+	}
+	redacted := make(Fields, len(fields))
+	for key, value := range fields {
+		redacted[key] = r.field(key, value)
+	}
+	return redacted
+}
+
+func (r *redactor) field(key string, value interface{}) interface{} {
+	if r.sensitive(key) {
+		return RedactedValue
+	}
+	return r.value(value)
+}
+
+// value redacts the sensitive fields nested in value. Maps and slices are
+// copied; structs are converted to their JSON form first, which is how they
+// would be logged anyway, so their json tags name the fields.
+func (r *redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int, int64, float64, error:
+		return value
+	case Fields:
+		return r.fields(v)
+	case map[string]interface{}:
+		return map[string]interface{}(r.fields(v))
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for key, item := range v {
+			if r.sensitive(key) {
+				item = RedactedValue
+			}
+			redacted[key] = item
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.value(item)
+		}
+		return redacted
+	}
+
+	if !nested(reflect.TypeOf(value)) {
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return r.value(decoded)
+}
+
+// nested reports whether values of typ can hold named fields
+func nested(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return typ.Key().Kind() == reflect.String
+	case reflect.Slice, reflect.Array:
+		return nested(typ.Elem())
+	case reflect.Interface:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactorSensitive(t *testing.T) {
+	r := newRedactor([]string{"password", "*_secret", "Authorization"})
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"PASSWORD", true},
+		{"client_secret", true},
+		{"Client_Secret", true},
+		{"authorization", true},
+		{"secret", false},
+		{"password_hint", false},
+		{"user", false},
+	}
+	for _, tt := range tests {
+		if got := r.sensitive(tt.key); got != tt.want {
+			t.Errorf("sensitive(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRedactPatterns(t *testing.T) {
+	config := DefaultConfig()
+	config.Redact = []string{"password", "[unclosed"}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), `"[unclosed"`) {
+		t.Errorf("Expected the malformed pattern to be reported, got %v", err)
+	}
+}
+
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+type createUserRequest struct {
+	Name        string            `json:"name"`
+	Credentials credentials       `json:"credentials"`
+	Headers     map[string]string `json:"headers"`
+	Backups     []*credentials    `json:"backups"`
+}
+
+func TestRedactSensitiveFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		FilePath:    path,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+		Redact:      []string{"password", "*_secret", "authorization"},
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+
+	request := &createUserRequest{
+		Name:        "alice",
+		Credentials: credentials{User: "alice", Password: "leak-1"},
+		Headers:     map[string]string{"Authorization": "Bearer leak-2", "Accept": "application/json"},
+		Backups:     []*credentials{{User: "bob", Password: "leak-3"}},
+	}
+	logger.WithField("password", "leak-4").Info("Top level")
+	logger.WithFields(Fields{"db": map[string]interface{}{"host": "db1", "options": Fields{"client_secret": "leak-5"}}}).Info("Nested maps")
+	logger.Infow("Creating user", "request", request, "Client_Secret", "leak-6")
+	logger.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	output := string(content)
+	if strings.Contains(output, "leak-") {
+		t.Errorf("Expected every sensitive value redacted, got:\n%s", output)
+	}
+	for _, kept := range []string{`"host":"db1"`, `"user":"bob"`, `"Accept":"application/json"`, `"name":"alice"`} {
+		if !strings.Contains(output, kept) {
+			t.Errorf("Expected %s to be logged, got:\n%s", kept, output)
+		}
+	}
+	if got := strings.Count(output, `"`+RedactedValue+`"`); got != 6 {
+		t.Errorf("Expected 6 redacted values, got %d:\n%s", got, output)
+	}
+	if request.Credentials.Password != "leak-1" || request.Headers["Authorization"] != "Bearer leak-2" {
+		t.Error("Expected the logged values themselves to be left untouched")
+	}
+}
+
+func TestRedactDisabled(t *testing.T) {
+	fields := Fields{"password": "visible"}
+	if got := newRedactor(nil).fields(fields); got["password"] != "visible" {
+		t.Errorf("Expected no redaction without patterns, got %v", got)
+	}
+}
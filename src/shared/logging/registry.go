@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"sort"
+	"sync"
+)
+
+// FieldLoggerName is the field naming the loggers handed out by a Registry
+const FieldLoggerName = "logger_name"
+
+// namer is implemented by loggers that can derive a named logger with a
+// level of its own that does not close the shared outputs
+type namer interface {
+	named(name string) Logger
+	resetLevel()
+}
+
+// Registry hands out named loggers derived from one root logger, so every
+// module writes through the same outputs, file rotation and hooks, told apart
+// by the logger_name field. A named logger follows the root level until its
+// own is set with SetLevel. Close the registry, not the named loggers: it
+// closes the root, and with it the shared outputs, once.
+type Registry struct {
+	root Logger
+
+	mu      sync.Mutex
+	loggers map[string]Logger
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRegistry creates the root logger from config and a registry around it
+func NewRegistry(config *LoggerConfig) (*Registry, error) {
+	root, err := NewLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistryWithRoot(root), nil
+}
+
+// NewRegistryWithRoot creates a registry handing out loggers derived from
+// root, which it takes ownership of
+func NewRegistryWithRoot(root Logger) *Registry {
+	return &Registry{root: root, loggers: make(map[string]Logger)}
+}
+
+// Root returns the root logger. Setting its level changes every named logger
+// that has no level of its own.
+func (r *Registry) Root() Logger {
+	return r.root
+}
+
+// Get returns the logger named name, creating it on first use
+func (r *Registry) Get(name string) Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if logger, ok := r.loggers[name]; ok {
+		return logger
+	}
+	var logger Logger
+	if n, ok := r.root.(namer); ok {
+		logger = n.named(name)
+	} else {
+		logger = r.root.WithField(FieldLoggerName, name)
+	}
+	r.loggers[name] = logger
+	return logger
+}
+
+// Names returns the names of the loggers handed out so far, sorted
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.loggers))
+	for name := range r.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLevel sets the level of the logger named name, and of the loggers
+// derived from it, leaving the others alone. For a root logger that cannot
+// name loggers it sets the shared level instead.
+func (r *Registry) SetLevel(name string, level Level) {
+	r.Get(name).SetLevel(level)
+}
+
+// ResetLevel makes the logger named name follow the root level again
+func (r *Registry) ResetLevel(name string) {
+	if n, ok := r.Get(name).(namer); ok {
+		n.resetLevel()
+	}
+}
+
+// Close closes the root logger and the outputs shared with the named
+// loggers. Closing again returns the first result.
+func (r *Registry) Close() error {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.root.Close()
+	})
+	return r.closeErr
+}
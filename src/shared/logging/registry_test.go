@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T) (*Registry, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	registry, err := NewRegistry(&LoggerConfig{
+		Level:       InfoLevel,
+		FilePath:    path,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	return registry, path
+}
+
+func TestRegistrySharesOneFile(t *testing.T) {
+	registry, path := newTestRegistry(t)
+
+	registry.Get("main").Info("From main")
+	registry.Get("pipeline").WithField("component", "input").Info("From pipeline")
+	// Closing a named logger leaves the shared file open
+	if err := registry.Get("main").Close(); err != nil {
+		t.Errorf("Close() on a named logger error = %v", err)
+	}
+	registry.Get("main").Info("After closing a named logger")
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines in the shared file, got %q", lines)
+	}
+	names := []interface{}{}
+	for _, line := range lines {
+		names = append(names, decodeLogLine(t, line)[FieldLoggerName])
+	}
+	if want := []interface{}{"main", "pipeline", "main"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("logger names = %v, want %v", names, want)
+	}
+	if got := registry.Names(); !reflect.DeepEqual(got, []string{"main", "pipeline"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestRegistryLevelOverrides(t *testing.T) {
+	root := NewTestLogger()
+	root.SetLevel(InfoLevel)
+	registry := NewRegistryWithRoot(root)
+	processing, api := registry.Get("processing"), registry.Get("api")
+
+	registry.SetLevel("processing", DebugLevel)
+	processing.WithField("component", "input").Debug("Processing detail")
+	api.Debug("API detail")
+	if !root.HasEntry(DebugLevel, "Processing detail") || root.HasEntry(DebugLevel, "API detail") {
+		t.Errorf("Expected only the overridden logger's debug line, got %+v", root.Entries())
+	}
+
+	// The root level reaches the loggers without a level of their own
+	root.SetLevel(WarnLevel)
+	if api.GetLevel() != WarnLevel || processing.GetLevel() != DebugLevel {
+		t.Errorf("levels = api %v, processing %v", api.GetLevel(), processing.GetLevel())
+	}
+	registry.ResetLevel("processing")
+	if processing.GetLevel() != WarnLevel {
+		t.Errorf("Expected processing to follow the root after ResetLevel(), got %v", processing.GetLevel())
+	}
+}
+
+// closeCounter counts the Close calls reaching the root logger
+type closeCounter struct {
+	*TestLogger
+	closes int
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestRegistryClosesRootOnce(t *testing.T) {
+	root := &closeCounter{TestLogger: NewTestLogger()}
+	registry := NewRegistryWithRoot(root)
+	registry.Get("main").Close()
+
+	registry.Close()
+	registry.Close()
+	if root.closes != 1 {
+		t.Errorf("Expected the root closed exactly once, got %d", root.closes)
+	}
+	if registry.Get("main") != registry.Get("main") {
+		t.Error("Expected Get() to return the same logger for a name")
+	}
+}
@@ -0,0 +1,256 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// megabyte is the unit of LoggerConfig.MaxSize; tests shrink it
+var megabyte int64 = 1024 * 1024
+
+// Rotation defaults used when the limits are not set
+const (
+	defaultMaxSize = 100 // Megabytes
+
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressedSuffix = ".gz"
+)
+
+// rotatingFile is a log file that is renamed to a timestamped backup once it
+// would grow past maxSize, after which a fresh file is opened at the same
+// path. Backups beyond maxBackups or older than maxAge are removed, and the
+// rest gzipped when compress is set. It is safe for concurrent writes from
+// loggers sharing it.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64         // Bytes
+	maxAge     time.Duration // 0 keeps backups regardless of age
+	maxBackups int           // 0 keeps every backup
+	compress   bool
+	file       *os.File // Nil once closed
+	size       int64
+}
+
+// openRotatingFile opens the log file at config.FilePath for appending,
+// rotating it under config's limits
+func openRotatingFile(config *LoggerConfig) (*rotatingFile, error) {
+	maxSize := int64(config.MaxSize)
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	r := &rotatingFile{
+		path:       config.FilePath,
+		maxSize:    maxSize * megabyte,
+		maxAge:     time.Duration(config.MaxAge) * 24 * time.Hour,
+		maxBackups: config.MaxBackups,
+		compress:   config.Compress,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the file at r.path for appending and records its size
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", r.path, err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write writes p to the log file, rotating first when p would take the file
+// past its size limit. A single write larger than the limit goes to a fresh
+// file on its own.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close flushes and closes the active log file. Closing again does nothing.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	syncErr := r.file.Sync()
+	closeErr := r.file.Close()
+	r.file = nil
+	if closeErr != nil {
+		return closeErr
+	}
+	return syncErr
+}
+
+// rotate renames the active file to a backup, opens a fresh one and prunes
+// the backups. Called with r.mu held.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %s for rotation: %w", r.path, err)
+	}
+	r.file = nil
+
+	backup := r.backupName(time.Now())
+	if err := os.Rename(r.path, backup); err != nil {
+		// Keep writing to the same file rather than losing log lines
+		if openErr := r.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("failed to rotate log file %s: %w", r.path, err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if r.compress {
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress log backup %s: %v\n", backup, err)
+		}
+	}
+	if err := r.prune(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to prune log backups of %s: %v\n", r.path, err)
+	}
+	return nil
+}
+
+// backupName returns the path of a backup rotated at t, e.g.
+// /var/log/main-2026-10-17T10-45-07.000.log for /var/log/main.log. A name
+// taken by an earlier rotation in the same millisecond moves on to the next.
+func (r *rotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+compressedSuffix) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// nameParts splits r.path into its directory, the backup name prefix and
+// the extension
+func (r *rotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(r.path)
+	base := filepath.Base(r.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// logBackup is a rotated log file found next to the active one
+type logBackup struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backups returns the backups of the log file, newest first
+func (r *rotatingFile) backups() ([]logBackup, error) {
+	dir, prefix, ext := r.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []logBackup
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), compressedSuffix)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		rotatedAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, logBackup{path: filepath.Join(dir, entry.Name()), rotatedAt: rotatedAt})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+	return backups, nil
+}
+
+// prune removes the backups beyond maxBackups and those older than maxAge
+func (r *rotatingFile) prune(now time.Time) error {
+	if r.maxBackups <= 0 && r.maxAge <= 0 {
+		return nil
+	}
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, backup := range backups {
+		tooMany := r.maxBackups > 0 && i >= r.maxBackups
+		tooOld := r.maxAge > 0 && now.Sub(backup.rotatedAt) > r.maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+compressedSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		target.Close()
+		os.Remove(path + compressedSuffix)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		target.Close()
+		os.Remove(path + compressedSuffix)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
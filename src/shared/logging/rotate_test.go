@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shrinkMegabyte makes LoggerConfig.MaxSize count bytes for the duration of
+// the test
+func shrinkMegabyte(t *testing.T) {
+	t.Helper()
+	original := megabyte
+	megabyte = 1
+	t.Cleanup(func() { megabyte = original })
+}
+
+func newRotatingLogger(t *testing.T, config LoggerConfig) (*ZerologLogger, string) {
+	t.Helper()
+	config.Level = InfoLevel
+	config.FilePath = filepath.Join(t.TempDir(), "app.log")
+	config.LoggerName = testLoggerName
+	config.ServiceName = testServiceName
+	config.IsLogRotatable = true
+
+	logger, err := NewLoggerWithConfig(&config)
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	return logger, config.FilePath
+}
+
+// readLogLines returns the lines of every log file in dir, gunzipping
+// compressed backups
+func readLogLines(t *testing.T, dir string) (lines []string, files []string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read log directory: %v", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		var reader io.Reader = file
+		if strings.HasSuffix(path, compressedSuffix) {
+			if reader, err = gzip.NewReader(file); err != nil {
+				t.Fatalf("Failed to gunzip %s: %v", path, err)
+			}
+		}
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		file.Close()
+		files = append(files, entry.Name())
+	}
+	return lines, files
+}
+
+func TestRotatingFileRotatesPastMaxSize(t *testing.T) {
+	shrinkMegabyte(t)
+	logger, path := newRotatingLogger(t, LoggerConfig{MaxSize: 512})
+
+	// Cloned loggers share the rotating file and write concurrently
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(clone Logger) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				clone.Infow("Rotating", "i", i)
+			}
+		}(logger.WithField("goroutine", g))
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines, files := readLogLines(t, filepath.Dir(path))
+	if len(files) < 2 {
+		t.Fatalf("Expected backups next to the log file, got %v", files)
+	}
+	if len(lines) != 200 {
+		t.Errorf("Expected all 200 lines across the log files, got %d", len(lines))
+	}
+	for _, line := range lines {
+		decodeLogLine(t, line)
+	}
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		if err != nil || info.Size() > 512 {
+			t.Errorf("Expected %s to stay within 512 bytes, got %v (%v)", name, info.Size(), err)
+		}
+	}
+}
+
+func TestRotatingFileCompressesAndPrunesBackups(t *testing.T) {
+	shrinkMegabyte(t)
+	logger, path := newRotatingLogger(t, LoggerConfig{MaxSize: 256, MaxBackups: 2, Compress: true})
+	for i := 0; i < 50; i++ {
+		logger.Infow("Rotating", "i", i)
+	}
+	logger.Close()
+
+	lines, files := readLogLines(t, filepath.Dir(path))
+	if len(files) != 3 {
+		t.Fatalf("Expected the log file and 2 backups, got %v", files)
+	}
+	for _, name := range files {
+		if name != "app.log" && !strings.HasSuffix(name, ".log"+compressedSuffix) {
+			t.Errorf("Expected backup %s to be compressed", name)
+		}
+	}
+	// The newest lines survive pruning; the active file sorts last
+	if last := decodeLogLine(t, lines[len(lines)-1]); last["i"] != float64(49) {
+		t.Errorf("Expected the last line to be kept, got %v", last)
+	}
+}
+
+func TestRotatingFilePrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	r := &rotatingFile{path: filepath.Join(dir, "app.log"), maxAge: 24 * time.Hour}
+	now := time.Now()
+	old := r.backupName(now.Add(-48 * time.Hour))
+	recent := r.backupName(now.Add(-time.Hour))
+	unrelated := filepath.Join(dir, "app-notes.log")
+	for _, name := range []string{old, recent, unrelated} {
+		if err := os.WriteFile(name, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.prune(now); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	for name, kept := range map[string]bool{old: false, recent: true, unrelated: true} {
+		if fileExists(name) != kept {
+			t.Errorf("%s: kept = %v, want %v", filepath.Base(name), !kept, kept)
+		}
+	}
+}
+
+func TestRotatingFileClose(t *testing.T) {
+	logger, _ := newRotatingLogger(t, LoggerConfig{})
+	clone := logger.Clone().(*ZerologLogger)
+	file := logger.output.primary.(*rotatingFile)
+	logger.Info("Before close")
+
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	// The clone shares the closed file, so closing it again does nothing
+	if err := clone.Close(); err != nil {
+		t.Errorf("Close() on a clone error = %v", err)
+	}
+	if _, err := file.Write([]byte("after close\n")); err == nil {
+		t.Error("Expected writes after Close() to fail")
+	}
+}
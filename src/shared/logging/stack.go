@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Fields added to error-level events of a logger with an attached error
+// when LoggerConfig.IncludeStack is set
+const (
+	FieldStack      = "stack"
+	FieldErrorChain = "error_chain"
+)
+
+// Where the stack of an attached error is captured, selectable with
+// LoggerConfig.StackCapture
+const (
+	StackCaptureLog       = "log"        // At the logging call, the default
+	StackCaptureWithError = "with_error" // At the WithError call, even if the event is never logged
+)
+
+// maxStackFrames bounds the frames kept in a captured stack
+const maxStackFrames = 32
+
+// packageDir is the directory of this package, whose frames are trimmed from
+// captured stacks
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// captureStack returns the stack of its caller as "function (file:line)"
+// entries, innermost first, without the frames of this package or the
+// runtime
+func captureStack() []string {
+	pcs := make([]uintptr, maxStackFrames+16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []string
+	for len(stack) < maxStackFrames {
+		frame, more := frames.Next()
+		internal := filepath.Dir(frame.File) == packageDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// errorChain returns the messages of err and of the errors it wraps, depth
+// first, following both fmt.Errorf's %w and errors.Join
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		chain = append(chain, err.Error())
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range wrapped.Unwrap() {
+				walk(inner)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return chain
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stackLines logs through a logger configured by configure and returns the
+// decoded lines
+func stackLines(t *testing.T, configure func(*LoggerConfig), log func(Logger)) []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	config := &LoggerConfig{Level: DebugLevel, Writer: &buf, LoggerName: testLoggerName, ServiceName: testServiceName}
+	configure(config)
+	logger, err := NewLogger(config)
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	log(logger)
+	logger.Close()
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line != "" {
+			lines = append(lines, decodeLogLine(t, line))
+		}
+	}
+	return lines
+}
+
+func TestWithErrorStackAtErrorLevel(t *testing.T) {
+	cause := errors.Join(errors.New("broker down"), errors.New("retries exhausted"))
+	err := fmt.Errorf("publish: %w", cause)
+	lines := stackLines(t, func(c *LoggerConfig) { c.IncludeStack = true }, func(logger Logger) {
+		failed := logger.WithError(err)
+		failed.Info("Retrying")
+		failed.Error("Publish failed")
+	})
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %v", lines)
+	}
+
+	if _, ok := lines[0][FieldStack]; ok {
+		t.Errorf("Expected no stack at info level, got %v", lines[0])
+	}
+	stack, ok := lines[1][FieldStack].([]interface{})
+	if !ok || len(stack) == 0 {
+		t.Fatalf("Expected a stack at error level, got %v", lines[1])
+	}
+	// Frames inside the logging package are trimmed
+	if frame := stack[0].(string); !strings.Contains(frame, "TestWithErrorStackAtErrorLevel") {
+		t.Errorf("Expected the stack to start at the logging call, got %q", frame)
+	}
+	want := []interface{}{err.Error(), cause.Error(), "broker down", "retries exhausted"}
+	if chain := lines[1][FieldErrorChain]; !reflect.DeepEqual(chain, want) {
+		t.Errorf("error_chain = %v, want %v", chain, want)
+	}
+}
+
+func attachError(logger Logger) Logger {
+	return logger.WithError(errors.New("invalid payload"))
+}
+
+func TestStackCaptureWithError(t *testing.T) {
+	lines := stackLines(t, func(c *LoggerConfig) {
+		c.IncludeStack = true
+		c.StackCapture = StackCaptureWithError
+	}, func(logger Logger) {
+		attachError(logger).Errorw("Rejected", "topic", "orders")
+	})
+
+	stack, _ := lines[0][FieldStack].([]interface{})
+	if len(stack) == 0 || !strings.Contains(stack[0].(string), "attachError") {
+		t.Errorf("Expected the stack to start at WithError, got %v", stack)
+	}
+	if _, ok := lines[0][FieldErrorChain]; ok {
+		t.Errorf("Expected no error_chain for an error wrapping nothing, got %v", lines[0])
+	}
+}
+
+func TestWithErrorWithoutStack(t *testing.T) {
+	lines := stackLines(t, func(c *LoggerConfig) {}, func(logger Logger) {
+		logger.WithError(fmt.Errorf("publish: %w", errors.New("broker down"))).Error("Publish failed")
+	})
+	if _, ok := lines[0][FieldStack]; ok {
+		t.Errorf("Expected no stack unless IncludeStack is set, got %v", lines[0])
+	}
+	if _, ok := lines[0][FieldErrorChain]; ok {
+		t.Errorf("Expected no error_chain unless IncludeStack is set, got %v", lines[0])
+	}
+}
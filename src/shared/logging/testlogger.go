@@ -0,0 +1,246 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Entry is one event recorded by a TestLogger
+type Entry struct {
+	Level   Level
+	Message string
+	Fields  Fields // The logger's fields merged with the call's
+}
+
+// testRecorder holds the entries of a TestLogger and every logger derived
+// from it
+type testRecorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// TestLogger is a Logger that records the events it emits in memory so tests
+// can assert on them. Loggers derived from it with WithFields, WithField,
+// WithError, WithContext or Clone record into the same entries with their
+// fields attached. Fatal and Panic record their entry without exiting or
+// panicking. It is safe for concurrent use.
+type TestLogger struct {
+	recorder *testRecorder
+	level    *atomic.Int32
+	hooks    *hookSet
+	levels   *levelOverrides
+	chain    levelChain
+	override *levelOverride // Set on loggers named by a Registry
+	fields   Fields
+}
+
+// NewTestLogger returns a logger recording events at every level
+func NewTestLogger() *TestLogger {
+	level := &atomic.Int32{}
+	level.Store(int32(DebugLevel))
+	return &TestLogger{
+		recorder: &testRecorder{},
+		level:    level,
+		hooks:    newHookSet(nil),
+		levels:   newLevelOverrides(nil),
+		fields:   make(Fields),
+	}
+}
+
+// Entries returns a copy of the recorded entries, oldest first
+func (t *TestLogger) Entries() []Entry {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	return append([]Entry(nil), t.recorder.entries...)
+}
+
+// Reset discards the recorded entries
+func (t *TestLogger) Reset() {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.entries = nil
+}
+
+// HasEntry reports whether an entry at level with a message containing
+// substring was recorded
+func (t *TestLogger) HasEntry(level Level, substring string) bool {
+	_, ok := t.FindEntry(level, substring)
+	return ok
+}
+
+// FindEntry returns the first entry at level with a message containing
+// substring
+func (t *TestLogger) FindEntry(level Level, substring string) (Entry, bool) {
+	for _, entry := range t.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, substring) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// record stores an entry for msg with the logger's fields and extra
+func (t *TestLogger) record(level Level, msg string, extra Fields) {
+	if !t.IsLevelEnabled(level) {
+		return
+	}
+	fields := make(Fields, len(t.fields)+len(extra))
+	for key, value := range t.fields {
+		fields[key] = value
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	t.hooks.fire(level, msg, fields)
+
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.recorder.entries = append(t.recorder.entries, Entry{Level: level, Message: msg, Fields: fields})
+}
+
+// SetLevel sets the level of this logger and every logger derived from the
+// same root, or of the name only on a logger named by a Registry
+func (t *TestLogger) SetLevel(level Level) {
+	if t.override != nil {
+		t.override.store(level)
+		return
+	}
+	t.level.Store(int32(level))
+}
+
+func (t *TestLogger) GetLevel() Level {
+	return t.chain.effective(t.level)
+}
+
+// SetComponentLevel overrides the level of the loggers derived with a
+// component or logger_name field of component
+func (t *TestLogger) SetComponentLevel(component string, level Level) {
+	t.levels.get(component).store(level)
+}
+
+func (t *TestLogger) ResetComponentLevel(component string) {
+	t.levels.get(component).clear()
+}
+
+func (t *TestLogger) ComponentLevels() map[string]Level {
+	return t.levels.levels()
+}
+
+func (t *TestLogger) named(name string) Logger {
+	named := t.WithField(FieldLoggerName, name).(*TestLogger)
+	named.override = t.levels.get(name)
+	return named
+}
+
+func (t *TestLogger) resetLevel() {
+	if t.override != nil {
+		t.override.clear()
+	}
+}
+
+func (t *TestLogger) IsLevelEnabled(level Level) bool {
+	return level >= t.GetLevel()
+}
+
+func (t *TestLogger) Debug(msg string) { t.record(DebugLevel, msg, nil) }
+func (t *TestLogger) Info(msg string)  { t.record(InfoLevel, msg, nil) }
+func (t *TestLogger) Warn(msg string)  { t.record(WarnLevel, msg, nil) }
+func (t *TestLogger) Error(msg string) { t.record(ErrorLevel, msg, nil) }
+func (t *TestLogger) Fatal(msg string) { t.record(FatalLevel, msg, nil) }
+func (t *TestLogger) Panic(msg string) { t.record(PanicLevel, msg, nil) }
+
+func (t *TestLogger) Debugf(format string, args ...interface{}) {
+	t.record(DebugLevel, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Infof(format string, args ...interface{}) {
+	t.record(InfoLevel, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Warnf(format string, args ...interface{}) {
+	t.record(WarnLevel, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Errorf(format string, args ...interface{}) {
+	t.record(ErrorLevel, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Fatalf(format string, args ...interface{}) {
+	t.record(FatalLevel, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Panicf(format string, args ...interface{}) {
+	t.record(PanicLevel, fmt.Sprintf(format, args...), nil)
+}
+
+func (t *TestLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	t.record(DebugLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+func (t *TestLogger) Infow(msg string, keysAndValues ...interface{}) {
+	t.record(InfoLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+func (t *TestLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	t.record(WarnLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+func (t *TestLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	t.record(ErrorLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+func (t *TestLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	t.record(FatalLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+func (t *TestLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	t.record(PanicLevel, msg, keysAndValuesToFields(keysAndValues...))
+}
+
+func (t *TestLogger) Log(level Level, msg string) { t.record(level, msg, nil) }
+func (t *TestLogger) Logf(level Level, format string, args ...interface{}) {
+	t.record(level, fmt.Sprintf(format, args...), nil)
+}
+func (t *TestLogger) Logw(level Level, msg string, keysAndValues ...interface{}) {
+	t.record(level, msg, keysAndValuesToFields(keysAndValues...))
+}
+
+// WithFields returns a logger recording into the same entries with fields
+// attached
+func (t *TestLogger) WithFields(fields Fields) Logger {
+	derived := t.clone()
+	for key, value := range fields {
+		derived.fields[key] = value
+	}
+	derived.chain = t.levels.chain(derived.chain, fields)
+	return derived
+}
+
+func (t *TestLogger) WithField(key string, value interface{}) Logger {
+	return t.WithFields(Fields{key: value})
+}
+
+func (t *TestLogger) WithError(err error) Logger {
+	if err == nil {
+		return t
+	}
+	return t.WithField("error", err.Error())
+}
+
+func (t *TestLogger) WithContext(ctx context.Context) Logger {
+	return t.clone()
+}
+
+func (t *TestLogger) AddHook(hook Hook) {
+	t.hooks.add(hook)
+}
+
+func (t *TestLogger) Clone() Logger {
+	return t.clone()
+}
+
+func (t *TestLogger) clone() *TestLogger {
+	fields := make(Fields, len(t.fields))
+	for key, value := range t.fields {
+		fields[key] = value
+	}
+	return &TestLogger{recorder: t.recorder, level: t.level, hooks: t.hooks, levels: t.levels, chain: t.chain, override: t.override, fields: fields}
+}
+
+// Close does nothing; the entries stay available
+func (t *TestLogger) Close() error {
+	return nil
+}
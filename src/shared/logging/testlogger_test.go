@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestNopLogger(t *testing.T) {
+	var logger Logger = NewNopLogger()
+	logger.WithField("key", "value").WithError(errors.New("ignored")).Errorw("Discarded", "a", 1)
+	logger.Panic("Does not panic")
+	if logger.IsLevelEnabled(PanicLevel) {
+		t.Error("Expected no level to be enabled")
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestTestLoggerRecordsEntries(t *testing.T) {
+	logger := NewTestLogger()
+	derived := logger.WithFields(Fields{"module": "orders"}).WithError(errors.New("broker down"))
+
+	derived.Errorw("Publish failed", "topic", "out")
+	logger.Infof("Published %d", 3)
+	logger.Fatal("Recorded without exiting")
+
+	entries := logger.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+	first := entries[0]
+	if first.Level != ErrorLevel || first.Message != "Publish failed" {
+		t.Errorf("Unexpected first entry %+v", first)
+	}
+	if first.Fields["module"] != "orders" || first.Fields["error"] != "broker down" || first.Fields["topic"] != "out" {
+		t.Errorf("Expected merged fields, got %v", first.Fields)
+	}
+	if len(entries[1].Fields) != 0 {
+		t.Errorf("Expected the root logger's entry without fields, got %v", entries[1].Fields)
+	}
+	if !logger.HasEntry(InfoLevel, "Published 3") || logger.HasEntry(WarnLevel, "Published") {
+		t.Error("HasEntry() does not match on level and message")
+	}
+	if entry, ok := logger.FindEntry(FatalLevel, "exiting"); !ok || entry.Message != "Recorded without exiting" {
+		t.Errorf("FindEntry() = %+v, %v", entry, ok)
+	}
+
+	logger.Reset()
+	if len(derived.(*TestLogger).Entries()) != 0 {
+		t.Error("Expected Reset() to clear the entries shared with derived loggers")
+	}
+}
+
+func TestTestLoggerLevelAndHooks(t *testing.T) {
+	logger := NewTestLogger()
+	counts := NewCountingHook(DebugLevel)
+	logger.AddHook(counts)
+	logger.SetLevel(WarnLevel)
+
+	logger.Clone().Info("Filtered")
+	logger.WithField("k", "v").Warn("Kept")
+
+	if len(logger.Entries()) != 1 || counts.Count(WarnLevel) != 1 || counts.Count(InfoLevel) != 0 {
+		t.Errorf("Expected only the warning recorded and counted, got %+v", logger.Entries())
+	}
+}
+
+func TestTestLoggerConcurrent(t *testing.T) {
+	logger := NewTestLogger()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.WithField("goroutine", g).Infow("Concurrent", "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if got := len(logger.Entries()); got != 800 {
+		t.Errorf("Expected 800 entries, got %d", got)
+	}
+}
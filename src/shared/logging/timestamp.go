@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Timestamp layouts selectable by name in LoggerConfig.TimestampFormat
+const (
+	TimestampRFC3339     = "RFC3339"     // 2006-01-02T15:04:05Z07:00, the default
+	TimestampRFC3339Nano = "RFC3339Nano" // 2006-01-02T15:04:05.999999999Z07:00
+)
+
+var timestampLayouts = map[string]string{
+	TimestampRFC3339:     time.RFC3339,
+	TimestampRFC3339Nano: time.RFC3339Nano,
+}
+
+// timestampLayout returns the time layout of config's timestamps
+func (c *LoggerConfig) timestampLayout() string {
+	if c.TimestampFormat == "" {
+		return time.RFC3339
+	}
+	if layout, ok := timestampLayouts[c.TimestampFormat]; ok {
+		return layout
+	}
+	return c.TimestampFormat
+}
+
+// timeLocation returns the zone of config's timestamps
+func (c *LoggerConfig) timeLocation() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %q must be UTC, Local or an IANA zone name: %w", c.TimeZone, err)
+	}
+	return location, nil
+}
+
+// ValidateTimestamp checks a LoggerConfig.TimestampFormat and TimeZone: the
+// layout must format a time that parses back, and the zone must exist
+func ValidateTimestamp(format, zone string) error {
+	config := &LoggerConfig{TimestampFormat: format, TimeZone: zone}
+	layout := config.timestampLayout()
+	reference := time.Date(2009, time.November, 10, 23, 4, 5, 0, time.UTC)
+	formatted := reference.Format(layout)
+	if _, err := time.Parse(layout, formatted); err != nil || formatted == layout {
+		return fmt.Errorf("timestamp format %q is not a Go time layout such as %s", format, time.RFC3339)
+	}
+	_, err := config.timeLocation()
+	return err
+}
+
+// timestampHook adds the time field to every event, in the configured
+// layout and zone instead of zerolog's process-wide ones
+type timestampHook struct {
+	layout   string
+	location *time.Location
+}
+
+func (h timestampHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	e.Str(zerolog.TimestampFieldName, time.Now().In(h.location).Format(h.layout))
+}
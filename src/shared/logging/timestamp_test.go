@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loggedTime logs one line through a logger with the given timestamp
+// settings and returns its time field
+func loggedTime(t *testing.T, format, zone string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := NewLogger(&LoggerConfig{
+		Level:           InfoLevel,
+		FilePath:        path,
+		LoggerName:      testLoggerName,
+		ServiceName:     testServiceName,
+		TimestampFormat: format,
+		TimeZone:        zone,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	logger.Info("Stamped")
+	logger.Close()
+
+	lines := readLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %q", lines)
+	}
+	stamp, ok := decodeLogLine(t, lines[0])["time"].(string)
+	if !ok {
+		t.Fatalf("Expected a time field, got %s", lines[0])
+	}
+	return stamp
+}
+
+func TestTimestampDefaultsToRFC3339UTC(t *testing.T) {
+	stamp := loggedTime(t, "", "")
+	parsed, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		t.Fatalf("time %q is not RFC3339: %v", stamp, err)
+	}
+	if _, offset := parsed.Zone(); offset != 0 || stamp[len(stamp)-1] != 'Z' {
+		t.Errorf("Expected a UTC timestamp, got %q", stamp)
+	}
+}
+
+func TestTimestampFormatAndZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	for _, tt := range []struct {
+		format, layout string
+	}{
+		{TimestampRFC3339Nano, time.RFC3339Nano},
+		{"2006-01-02 15:04:05.000 -0700", "2006-01-02 15:04:05.000 -0700"},
+	} {
+		stamp := loggedTime(t, tt.format, "Asia/Tokyo")
+		parsed, err := time.Parse(tt.layout, stamp)
+		if err != nil {
+			t.Errorf("%s: time %q does not match the layout: %v", tt.format, stamp, err)
+			continue
+		}
+		if _, offset := parsed.Zone(); offset != 9*60*60 {
+			t.Errorf("%s: time %q is not in Asia/Tokyo", tt.format, stamp)
+		}
+		if since := time.Since(parsed.In(tokyo)); since < 0 || since > time.Minute {
+			t.Errorf("%s: time %q is not the logging time", tt.format, stamp)
+		}
+	}
+}
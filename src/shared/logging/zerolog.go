@@ -2,9 +2,13 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -13,75 +17,260 @@ import (
 type ZerologLogger struct {
 	mu       sync.RWMutex
 	logger   zerolog.Logger
-	level    Level
+	level    *atomic.Int32 // Shared with every logger derived from this one, so SetLevel reaches them all
 	fields   Fields
 	context  context.Context
 	errorKey string
 	config   *LoggerConfig
-	file     *os.File
+	file     io.Closer       // Nil when writing to a standard stream, which Close leaves open
+	async    *asyncWriter    // Nil unless config.Async is set
+	output   *fallbackWriter // The log file, nil when writing to standard streams only
+	hooks    *hookSet        // Shared with every logger derived from this one, like level
+	redactor *redactor       // Nil unless config.Redact lists patterns
+	levels   *levelOverrides // Level overrides by component or logger name, shared like level
+	chain    levelChain      // Overrides of the names this logger was created or derived with
+	override *levelOverride  // Set on loggers named by a Registry, which SetLevel changes instead of level
+	borrowed bool            // Named by a Registry, which closes the outputs instead
+	err      error           // Attached with WithError, for the stack and error chain
+	errStack []string        // Captured at WithError under StackCaptureWithError
 }
 
 // NewLoggerWithConfig creates a new ZerologLogger with comprehensive configuration
 func NewLoggerWithConfig(config *LoggerConfig) (*ZerologLogger, error) {
-	// Open the log file for writing (create if not exists, append if exists)
-	file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	location, err := config.timeLocation()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file %s: %w", config.FilePath, err)
+		return nil, err
+	}
+	writers, file, failures, err := openOutputs(config, location)
+	if err != nil {
+		return nil, err
+	}
+	writer := writers[0]
+	if len(writers) > 1 {
+		writer = zerolog.MultiLevelWriter(writers...)
+	}
+	var async *asyncWriter
+	if config.Async {
+		async = newAsyncWriter(writer, config)
+		writer = async
 	}
 
 	//set global logger to lowest level so that
 	// explicit logger instance level can always take effect
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	// Configure zerolog to write to the file with JSON format
-	logger := zerolog.New(file).With().
-		Timestamp().
+	// Configure zerolog to write JSON lines to the file or stream, stamped
+	// in the configured layout and zone
+	logger := zerolog.New(writer).With().
 		Str("service", config.ServiceName).
 		Logger().
-		Level(levelToZerolog(config.Level))
-
-	return &ZerologLogger{
+		Hook(timestampHook{layout: config.timestampLayout(), location: location}).
+		Level(zerolog.DebugLevel) // Filtered by IsLevelEnabled against the shared level
+
+	level := &atomic.Int32{}
+	level.Store(int32(config.Level))
+	levels := newLevelOverrides(config.LevelOverrides)
+	output, _ := file.(*fallbackWriter)
+	z := &ZerologLogger{
 		logger:   logger,
-		level:    config.Level,
+		level:    level,
 		fields:   make(Fields),
 		errorKey: "error",
 		config:   config,
 		file:     file,
-	}, nil
+		output:   output,
+		async:    async,
+		hooks:    newHookSet(config.Hooks),
+		redactor: newRedactor(config.Redact),
+		levels:   levels,
+		chain:    levels.chain(nil, Fields{FieldComponent: config.ComponentName, FieldLoggerName: config.LoggerName}),
+	}
+	for _, failure := range failures {
+		z.Warnw("Log output unavailable, writing to the remaining outputs", "error", failure.Error())
+	}
+	return z, nil
 }
 
-// Close closes the log file
+// openLogFile opens the log file for appending, creating it if needed, and
+// wraps it in a rotating writer when config.IsLogRotatable is set
+func openLogFile(config *LoggerConfig) (io.WriteCloser, error) {
+	if config.IsLogRotatable {
+		return openRotatingFile(config)
+	}
+	file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", config.FilePath, err)
+	}
+	return file, nil
+}
+
+// isStandardStream reports whether w is os.Stdout or os.Stderr, which Close
+// leaves open
+func isStandardStream(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
+
+// openOutputs opens a writer for each of config's outputs. An output that
+// cannot be opened is returned in failures, or fails the whole call when
+// config.Strict is set or no output could be opened. file is the opened log
+// file, or config.Writer when it is to be closed, for Close. Human-readable
+// outputs show times in location.
+func openOutputs(config *LoggerConfig, location *time.Location) (writers []io.Writer, file io.WriteCloser, failures []error, err error) {
+	seen := make(map[string]bool)
+	for _, output := range config.outputs() {
+		if seen[output] {
+			continue
+		}
+		seen[output] = true
+
+		var writer io.Writer
+		switch output {
+		case OutputStdout:
+			writer = os.Stdout
+		case OutputStderr:
+			writer = os.Stderr
+		case OutputFile, "":
+			if config.Writer != nil {
+				writer = config.Writer
+				if closer, ok := config.Writer.(io.WriteCloser); ok && !isStandardStream(config.Writer) {
+					file = closer
+				}
+				break
+			}
+			opened, openErr := openLogFile(config)
+			if openErr != nil {
+				failures = append(failures, openErr)
+				continue
+			}
+			file = newFallbackWriter(opened, config, location)
+			writer = file
+		default:
+			failures = append(failures, fmt.Errorf("unknown log output %q", output))
+			continue
+		}
+		if config.humanReadable(output) {
+			writer = zerolog.ConsoleWriter{Out: writer, NoColor: !config.Color, TimeFormat: time.RFC3339, TimeLocation: location}
+		}
+		writers = append(writers, writer)
+	}
+
+	if len(failures) > 0 && (config.Strict || len(writers) == 0) {
+		if file != nil {
+			file.Close()
+		}
+		return nil, nil, nil, errors.Join(failures...)
+	}
+	return writers, file, failures, nil
+}
+
+// Close closes the log file, or config.Writer when it is an io.Closer. A
+// logger writing to stdout or stderr leaves the stream open, and a logger
+// named by a Registry leaves closing to the registry. In async mode the
+// queued lines are written first, for at most config.CloseTimeout.
 func (z *ZerologLogger) Close() error {
+	if z.borrowed {
+		return nil
+	}
 	z.mu.Lock()
 	defer z.mu.Unlock()
 
+	var asyncErr error
+	if z.async != nil {
+		timeout := z.config.CloseTimeout
+		if timeout <= 0 {
+			timeout = defaultCloseTimeout
+		}
+		asyncErr = z.async.Close(timeout)
+	}
 	if z.file != nil {
 		err := z.file.Close()
 		z.file = nil
-		return err
+		return errors.Join(asyncErr, err)
+	}
+	return asyncErr
+}
+
+// Flush waits until the lines logged so far are written. It does nothing
+// unless config.Async is set.
+func (z *ZerologLogger) Flush() {
+	if z.async != nil {
+		z.async.Flush()
 	}
-	return nil
 }
 
-// SetLevel sets the logging level
+// Dropped returns the number of lines dropped because the async buffer was
+// full, shared by every logger derived from the same root
+func (z *ZerologLogger) Dropped() uint64 {
+	if z.async == nil {
+		return 0
+	}
+	return z.async.Dropped()
+}
+
+// OutputStats returns the counters of failed writes to the log file and of
+// switches to the fallback output, shared by every logger derived from the
+// same root. They stay zero for a logger without a log file.
+func (z *ZerologLogger) OutputStats() OutputStats {
+	if z.output == nil {
+		return OutputStats{}
+	}
+	return z.output.Stats()
+}
+
+// SetLevel sets the logging level of this logger, the logger it was
+// derived from and every other logger derived from the same root through
+// Clone, WithFields, WithField, WithError or WithContext. On a logger named
+// by a Registry it sets the level of that name only.
 func (z *ZerologLogger) SetLevel(level Level) {
-	z.mu.Lock()
-	defer z.mu.Unlock()
-	z.level = level
-	z.logger = z.logger.Level(levelToZerolog(level))
+	if z.override != nil {
+		z.override.store(level)
+		return
+	}
+	z.level.Store(int32(level))
 }
 
-// GetLevel returns the current logging level
+// GetLevel returns the current logging level: the override for the
+// innermost component or logger name the logger was derived with that has
+// one, and the level set with SetLevel otherwise
 func (z *ZerologLogger) GetLevel() Level {
-	z.mu.RLock()
-	defer z.mu.RUnlock()
-	return z.level
+	return z.chain.effective(z.level)
+}
+
+// SetComponentLevel overrides the level of the loggers for component, as
+// named by their component or logger_name field, including those already
+// derived
+func (z *ZerologLogger) SetComponentLevel(component string, level Level) {
+	z.levels.get(component).store(level)
+}
+
+// ResetComponentLevel removes the override for component
+func (z *ZerologLogger) ResetComponentLevel(component string) {
+	z.levels.get(component).clear()
+}
+
+// ComponentLevels returns the overridden levels by component
+func (z *ZerologLogger) ComponentLevels() map[string]Level {
+	return z.levels.levels()
+}
+
+// named returns a logger tagged with name whose level follows this one's
+// until it is set, and whose Close leaves the outputs open
+func (z *ZerologLogger) named(name string) Logger {
+	named := z.WithField(FieldLoggerName, name).(*ZerologLogger)
+	named.override = z.levels.get(name)
+	named.borrowed = true
+	return named
+}
+
+// resetLevel makes a named logger follow the level of its root again
+func (z *ZerologLogger) resetLevel() {
+	if z.override != nil {
+		z.override.clear()
+	}
 }
 
 // IsLevelEnabled checks if the given level is enabled
 func (z *ZerologLogger) IsLevelEnabled(level Level) bool {
-	z.mu.RLock()
-	defer z.mu.RUnlock()
-	return level >= z.level
+	return level >= z.GetLevel()
 }
 
 // levelToZerolog converts our Level to zerolog.Level
@@ -118,9 +307,10 @@ func (z *ZerologLogger) getEvent(level Level) *zerolog.Event {
 	case ErrorLevel:
 		event = z.logger.Error()
 	case FatalLevel:
-		event = z.logger.Fatal()
+		// WithLevel leaves exiting and panicking to terminate
+		event = z.logger.WithLevel(zerolog.FatalLevel)
 	case PanicLevel:
-		event = z.logger.Panic()
+		event = z.logger.WithLevel(zerolog.PanicLevel)
 	default:
 		event = z.logger.Info()
 	}
@@ -128,48 +318,106 @@ func (z *ZerologLogger) getEvent(level Level) *zerolog.Event {
 	// Add fields
 	z.mu.RLock()
 	for key, value := range z.fields {
-		event = event.Interface(key, value)
+		event = appendField(event, key, value)
 	}
 	z.mu.RUnlock()
 
 	return event
 }
 
+// AddHook adds a hook to this logger, the logger it was derived from and
+// every other logger derived from the same root
+func (z *ZerologLogger) AddHook(hook Hook) {
+	z.hooks.add(hook)
+}
+
+// emit fires the hooks for the event, then writes it, and exits or panics
+// after a fatal or panic event. pairs are key/value pairs added to the
+// logger's fields, well-formed as checked by directPairs.
+func (z *ZerologLogger) emit(level Level, msg string, pairs ...interface{}) {
+	level = z.config.eventLevel(level)
+	details := z.errorDetails(level)
+	if !z.hooks.empty() {
+		z.mu.RLock()
+		fields := make(Fields, len(z.fields)+len(pairs)/2+len(details))
+		for key, value := range z.fields {
+			fields[key] = value
+		}
+		z.mu.RUnlock()
+		for i := 0; i < len(pairs); i += 2 {
+			key := pairs[i].(string)
+			fields[key] = z.redacted(key, pairs[i+1])
+		}
+		for key, value := range details {
+			fields[key] = value
+		}
+		z.hooks.fire(level, msg, fields)
+	}
+	event := z.getEvent(level)
+	for i := 0; i < len(pairs); i += 2 {
+		key := pairs[i].(string)
+		event = appendField(event, key, z.redacted(key, pairs[i+1]))
+	}
+	for key, value := range details {
+		event = event.Interface(key, value)
+	}
+	event.Msg(msg)
+	z.terminate(level, msg)
+}
+
+// errorDetails returns the stack and error chain of an event at level. Only
+// error-level events of a logger with an attached error carry them, and only
+// with config.IncludeStack set, as capturing the stack is costly.
+func (z *ZerologLogger) errorDetails(level Level) Fields {
+	if z.err == nil || level < ErrorLevel || !z.config.IncludeStack {
+		return nil
+	}
+	stack := z.errStack
+	if stack == nil {
+		stack = captureStack()
+	}
+	details := Fields{FieldStack: stack}
+	if chain := errorChain(z.err); len(chain) > 1 {
+		details[FieldErrorChain] = chain
+	}
+	return details
+}
+
 // Basic logging methods
 func (z *ZerologLogger) Debug(msg string) {
 	if !z.IsLevelEnabled(DebugLevel) {
 		return
 	}
-	z.getEvent(DebugLevel).Msg(msg)
+	z.emit(DebugLevel, msg)
 }
 
 func (z *ZerologLogger) Info(msg string) {
 	if !z.IsLevelEnabled(InfoLevel) {
 		return
 	}
-	z.getEvent(InfoLevel).Msg(msg)
+	z.emit(InfoLevel, msg)
 }
 
 func (z *ZerologLogger) Warn(msg string) {
 	if !z.IsLevelEnabled(WarnLevel) {
 		return
 	}
-	z.getEvent(WarnLevel).Msg(msg)
+	z.emit(WarnLevel, msg)
 }
 
 func (z *ZerologLogger) Error(msg string) {
 	if !z.IsLevelEnabled(ErrorLevel) {
 		return
 	}
-	z.getEvent(ErrorLevel).Msg(msg)
+	z.emit(ErrorLevel, msg)
 }
 
 func (z *ZerologLogger) Fatal(msg string) {
-	z.getEvent(FatalLevel).Msg(msg)
+	z.emit(FatalLevel, msg)
 }
 
 func (z *ZerologLogger) Panic(msg string) {
-	z.getEvent(PanicLevel).Msg(msg)
+	z.emit(PanicLevel, msg)
 }
 
 // Formatted logging methods
@@ -177,71 +425,71 @@ func (z *ZerologLogger) Debugf(format string, args ...interface{}) {
 	if !z.IsLevelEnabled(DebugLevel) {
 		return
 	}
-	z.getEvent(DebugLevel).Msgf(format, args...)
+	z.emit(DebugLevel, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Infof(format string, args ...interface{}) {
 	if !z.IsLevelEnabled(InfoLevel) {
 		return
 	}
-	z.getEvent(InfoLevel).Msgf(format, args...)
+	z.emit(InfoLevel, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Warnf(format string, args ...interface{}) {
 	if !z.IsLevelEnabled(WarnLevel) {
 		return
 	}
-	z.getEvent(WarnLevel).Msgf(format, args...)
+	z.emit(WarnLevel, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Errorf(format string, args ...interface{}) {
 	if !z.IsLevelEnabled(ErrorLevel) {
 		return
 	}
-	z.getEvent(ErrorLevel).Msgf(format, args...)
+	z.emit(ErrorLevel, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Fatalf(format string, args ...interface{}) {
-	z.getEvent(FatalLevel).Msgf(format, args...)
+	z.emit(FatalLevel, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Panicf(format string, args ...interface{}) {
-	z.getEvent(PanicLevel).Msgf(format, args...)
+	z.emit(PanicLevel, fmt.Sprintf(format, args...))
 }
 
 // Variadic logging methods
 func (z *ZerologLogger) Debugw(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Debug(msg)
+	z.logw(DebugLevel, msg, keysAndValues)
 }
 
 func (z *ZerologLogger) Infow(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Info(msg)
+	z.logw(InfoLevel, msg, keysAndValues)
 }
 
 func (z *ZerologLogger) Warnw(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Warn(msg)
+	z.logw(WarnLevel, msg, keysAndValues)
 }
 
 func (z *ZerologLogger) Errorw(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Error(msg)
+	z.logw(ErrorLevel, msg, keysAndValues)
 }
 
 func (z *ZerologLogger) Fatalw(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Fatal(msg)
+	z.withKeysAndValues(msg, keysAndValues).Fatal(msg)
 }
 
 func (z *ZerologLogger) Panicw(msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Panic(msg)
+	z.withKeysAndValues(msg, keysAndValues).Panic(msg)
 }
 
-// Structured logging with fields
+// WithFields returns a logger adding fields to its events, with the values
+// of sensitive fields redacted
 func (z *ZerologLogger) WithFields(fields Fields) Logger {
-	newLogger := z.Clone().(*ZerologLogger)
-	newLogger.mu.Lock()
+	newLogger := z.clone(len(fields))
 	for k, v := range fields {
-		newLogger.fields[k] = v
+		newLogger.fields[k] = z.redacted(k, v)
 	}
-	newLogger.mu.Unlock()
+	newLogger.chain = z.levels.chain(newLogger.chain, fields)
 	return newLogger
 }
 
@@ -249,16 +497,26 @@ func (z *ZerologLogger) WithField(key string, value interface{}) Logger {
 	return z.WithFields(Fields{key: value})
 }
 
+// WithError returns a logger adding err's message to its events. With
+// config.IncludeStack set, its error-level events also carry a stack trace
+// and the chain of errors err wraps.
 func (z *ZerologLogger) WithError(err error) Logger {
 	if err == nil {
 		return z
 	}
-	return z.WithField(z.errorKey, err.Error())
+	newLogger := z.WithField(z.errorKey, err.Error()).(*ZerologLogger)
+	newLogger.err = err
+	newLogger.errStack = nil
+	if z.config.IncludeStack && z.config.StackCapture == StackCaptureWithError {
+		newLogger.errStack = captureStack()
+	}
+	return newLogger
 }
 
-// Context-aware logging
+// WithContext returns a logger bound to ctx, with the fields config declares
+// in ContextKeys and ContextExtractors that ctx carries attached
 func (z *ZerologLogger) WithContext(ctx context.Context) Logger {
-	newLogger := z.Clone().(*ZerologLogger)
+	newLogger := z.WithFields(z.config.contextFields(ctx)).(*ZerologLogger)
 	newLogger.context = ctx
 	// Update the underlying zerolog logger with context
 	newLogger.logger = newLogger.logger.With().Ctx(ctx).Logger()
@@ -270,37 +528,52 @@ func (z *ZerologLogger) Log(level Level, msg string) {
 	if !z.IsLevelEnabled(level) {
 		return
 	}
-	z.getEvent(level).Msg(msg)
+	z.emit(level, msg)
 }
 
 func (z *ZerologLogger) Logf(level Level, format string, args ...interface{}) {
 	if !z.IsLevelEnabled(level) {
 		return
 	}
-	z.getEvent(level).Msgf(format, args...)
+	z.emit(level, fmt.Sprintf(format, args...))
 }
 
 func (z *ZerologLogger) Logw(level Level, msg string, keysAndValues ...interface{}) {
-	z.WithFields(keysAndValuesToFields(keysAndValues...)).Log(level, msg)
+	z.logw(level, msg, keysAndValues)
 }
 
 // Clone creates a copy of the logger (shares the same file and config)
 func (z *ZerologLogger) Clone() Logger {
+	return z.clone(0)
+}
+
+// clone copies the logger with room for extra more fields
+func (z *ZerologLogger) clone(extra int) *ZerologLogger {
 	z.mu.RLock()
 	defer z.mu.RUnlock()
 
-	newFields := make(Fields)
+	newFields := make(Fields, len(z.fields)+extra)
 	for k, v := range z.fields {
 		newFields[k] = v
 	}
 
 	return &ZerologLogger{
 		logger:   z.logger,
-		level:    z.level,
+		level:    z.level, // Share the same level
 		fields:   newFields,
 		context:  z.context,
 		errorKey: z.errorKey,
 		config:   z.config,
 		file:     z.file, // Share the same file
+		async:    z.async,
+		output:   z.output,
+		hooks:    z.hooks,
+		redactor: z.redactor,
+		levels:   z.levels,
+		chain:    z.chain,
+		override: z.override,
+		borrowed: z.borrowed,
+		err:      z.err,
+		errStack: z.errStack,
 	}
 }
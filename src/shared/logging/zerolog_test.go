@@ -1,9 +1,14 @@
 package logging
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -72,6 +77,196 @@ func TestNewLoggerWithConfigFileError(t *testing.T) {
 	}
 }
 
+// captureStdout redirects os.Stdout to a pipe until the returned function
+// is called, which restores it and returns what was written
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	return func() string {
+		os.Stdout = stdout
+		writer.Close()
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Failed to read captured stdout: %v", err)
+		}
+		return string(output)
+	}
+}
+
+// decodeLogLine decodes a JSON log line
+func decodeLogLine(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", line, err)
+	}
+	return entry
+}
+
+func TestNewLoggerWithConfigStdout(t *testing.T) {
+	captured := captureStdout(t)
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:         InfoLevel,
+		LoggerName:    testLoggerName,
+		ComponentName: testComponentName,
+		ServiceName:   testServiceName,
+		Output:        OutputStdout,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	logger.Infow("To stdout", "key", "value")
+	logger.Debug(debugMessage)
+
+	// Close must leave the stream open
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := os.Stdout.Write([]byte("still open\n")); err != nil {
+		t.Errorf("Expected stdout to stay open after Close(), got %v", err)
+	}
+
+	output := captured()
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || lines[1] != "still open" {
+		t.Fatalf("Expected one log line on stdout, got %q", output)
+	}
+	entry := decodeLogLine(t, lines[0])
+	if entry["message"] != "To stdout" || entry["key"] != "value" || entry["service"] != testServiceName || entry["level"] != "info" {
+		t.Errorf("Unexpected log line %v", entry)
+	}
+}
+
+func TestNewLoggerWithConfigMultipleOutputs(t *testing.T) {
+	for _, console := range []bool{false, true} {
+		t.Run(fmt.Sprintf("console=%v", console), func(t *testing.T) {
+			logFile := filepath.Join(t.TempDir(), "multi.log")
+			captured := captureStdout(t)
+			logger, err := NewLoggerWithConfig(&LoggerConfig{
+				Level:         InfoLevel,
+				FilePath:      logFile,
+				LoggerName:    testLoggerName,
+				ComponentName: testComponentName,
+				ServiceName:   testServiceName,
+				Outputs:       []string{OutputFile, OutputStdout},
+				Console:       console,
+			})
+			if err != nil {
+				t.Fatalf(newLoggerErrorFmt, err)
+			}
+			logger.Infow("To both", "key", "value")
+			logger.Close()
+
+			// The file always gets JSON
+			content, err := os.ReadFile(logFile)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			if entry := decodeLogLine(t, strings.TrimSpace(string(content))); entry["message"] != "To both" || entry["key"] != "value" {
+				t.Errorf("Unexpected log file line %v", entry)
+			}
+
+			output := strings.TrimSpace(captured())
+			if console {
+				if strings.HasPrefix(output, "{") || !strings.Contains(output, "INF") || !strings.Contains(output, "To both") {
+					t.Errorf("Expected a console formatted line on stdout, got %q", output)
+				}
+			} else if entry := decodeLogLine(t, output); entry["message"] != "To both" {
+				t.Errorf("Unexpected stdout line %v", entry)
+			}
+		})
+	}
+}
+
+func TestNewLoggerWithConfigFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		color  bool
+	}{
+		{"", false},
+		{FormatJSON, false},
+		{FormatText, false},
+		{FormatConsole, true},
+	} {
+		t.Run(fmt.Sprintf("%s color=%v", tt.format, tt.color), func(t *testing.T) {
+			logFile := filepath.Join(t.TempDir(), "format.log")
+			logger, err := NewLoggerWithConfig(&LoggerConfig{
+				Level:       InfoLevel,
+				FilePath:    logFile,
+				LoggerName:  testLoggerName,
+				ServiceName: testServiceName,
+				Format:      tt.format,
+				Color:       tt.color,
+			})
+			if err != nil {
+				t.Fatalf(newLoggerErrorFmt, err)
+			}
+			logger.Warnw("Format check", "key", "value")
+			logger.Close()
+
+			content, err := os.ReadFile(logFile)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			line := strings.TrimSpace(string(content))
+			if tt.format == "" || tt.format == FormatJSON {
+				if entry := decodeLogLine(t, line); entry["message"] != "Format check" || entry["level"] != "warn" {
+					t.Errorf("Unexpected JSON line %v", entry)
+				}
+				return
+			}
+			if json.Valid([]byte(line)) || !strings.Contains(line, "WRN") || !strings.Contains(line, "Format check") {
+				t.Errorf("Expected a human-readable line with the level and message, got %q", line)
+			}
+			if colored := strings.Contains(line, "\x1b["); colored != tt.color {
+				t.Errorf("Expected colors %v, got %q", tt.color, line)
+			}
+		})
+	}
+}
+
+func TestNewLoggerWithConfigOutputFailure(t *testing.T) {
+	config := &LoggerConfig{
+		Level:         InfoLevel,
+		FilePath:      "/invalid/path/test.log",
+		LoggerName:    testLoggerName,
+		ComponentName: testComponentName,
+		ServiceName:   testServiceName,
+		Outputs:       []string{OutputFile, OutputStdout},
+	}
+
+	// Without Strict the failure is reported on the outputs that work
+	captured := captureStdout(t)
+	logger, err := NewLoggerWithConfig(config)
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	logger.Info("Still logging")
+	lines := strings.Split(strings.TrimSpace(captured()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a warning and a log line on stdout, got %q", lines)
+	}
+	if warning := decodeLogLine(t, lines[0]); warning["level"] != "warn" || !strings.Contains(fmt.Sprint(warning["error"]), "/invalid/path/test.log") {
+		t.Errorf("Expected a warning naming the log file, got %v", warning)
+	}
+	if entry := decodeLogLine(t, lines[1]); entry["message"] != "Still logging" {
+		t.Errorf("Unexpected log line %v", entry)
+	}
+
+	config.Strict = true
+	if logger, err := NewLoggerWithConfig(config); err == nil {
+		logger.Close()
+		t.Error("NewLoggerWithConfig() expected an error with Strict set but got none")
+	}
+}
+
 func TestZerologLoggerSetLevel(t *testing.T) {
 	logFile := "/tmp/test_set_level.log"
 	os.Remove(logFile)
@@ -113,13 +308,40 @@ func TestZerologLoggerSetLevel(t *testing.T) {
 	os.Remove(logFile)
 }
 
-func TestZerologLoggerFormattedLogging(t *testing.T) {
-	logFile := "/tmp/test_formatted_logging.log"
-	os.Remove(logFile)
+func TestZerologLoggerSetLevelPropagates(t *testing.T) {
+	captured := captureStdout(t)
+	root, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+		Output:      OutputStdout,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	derived := root.WithField("module", "orders").WithContext(context.Background())
+	clone := derived.Clone()
+
+	// A level set on any logger reaches the others, whenever they were derived
+	root.SetLevel(DebugLevel)
+	derived.Debug("debug from derived")
+	clone.SetLevel(WarnLevel)
+	if root.GetLevel() != WarnLevel || derived.GetLevel() != WarnLevel {
+		t.Errorf("Expected the level set on a clone to reach the root, got %v and %v", root.GetLevel(), derived.GetLevel())
+	}
+	root.Info("info at warn")
 
+	output := captured()
+	if !strings.Contains(output, "debug from derived") || strings.Contains(output, "info at warn") {
+		t.Errorf("Expected only the debug line, got %q", output)
+	}
+}
+
+func TestZerologLoggerFormattedLogging(t *testing.T) {
+	var buf bytes.Buffer
 	config := &LoggerConfig{
 		Level:         DebugLevel,
-		FilePath:      logFile,
+		Writer:        &buf,
 		LoggerName:    testLoggerName,
 		ComponentName: testComponentName,
 		ServiceName:   testServiceName,
@@ -137,14 +359,16 @@ func TestZerologLoggerFormattedLogging(t *testing.T) {
 	logger.Warnf("Warning message with %d warnings", 3)
 	logger.Errorf("Error message with error code %d", 500)
 
-	// Check if log file was created and has content
-	if stat, err := os.Stat(logFile); err != nil {
-		t.Errorf(logFileNotCreatedFmt, err)
-	} else if stat.Size() == 0 {
-		t.Error(logFileEmptyMsg)
+	want := []string{"Debug message with string and 42", "Info message with parameter", "Warning message with 3 warnings", "Error message with error code 500"}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), lines)
+	}
+	for i, line := range lines {
+		if message := decodeLogLine(t, line)["message"]; message != want[i] {
+			t.Errorf("Line %d message = %v, want %q", i, message, want[i])
+		}
 	}
-
-	os.Remove(logFile)
 }
 
 func TestZerologLoggerVariadicLogging(t *testing.T) {
@@ -182,12 +406,10 @@ func TestZerologLoggerVariadicLogging(t *testing.T) {
 }
 
 func TestZerologLoggerWithFields(t *testing.T) {
-	logFile := "/tmp/test_with_fields.log"
-	os.Remove(logFile)
-
+	var buf bytes.Buffer
 	config := &LoggerConfig{
 		Level:         InfoLevel,
-		FilePath:      logFile,
+		Writer:        &buf,
 		LoggerName:    testLoggerName,
 		ComponentName: testComponentName,
 		ServiceName:   testServiceName,
@@ -213,14 +435,18 @@ func TestZerologLoggerWithFields(t *testing.T) {
 	singleFieldLogger := logger.WithField("request_id", "req-12345")
 	singleFieldLogger.Info("Processing request")
 
-	// Check if log file was created and has content
-	if stat, err := os.Stat(logFile); err != nil {
-		t.Errorf(logFileNotCreatedFmt, err)
-	} else if stat.Size() == 0 {
-		t.Error(logFileEmptyMsg)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", lines)
+	}
+	for _, line := range lines[:2] {
+		if entry := decodeLogLine(t, line); entry["user_id"] != float64(123) || entry["session_id"] != "abc-def-ghi" || entry["module"] != "auth" {
+			t.Errorf("Expected the WithFields fields, got %v", entry)
+		}
+	}
+	if entry := decodeLogLine(t, lines[2]); entry["request_id"] != "req-12345" || entry["user_id"] != nil {
+		t.Errorf("Expected only the WithField field, got %v", entry)
 	}
-
-	os.Remove(logFile)
 }
 
 func TestZerologLoggerWithError(t *testing.T) {
@@ -768,3 +994,91 @@ func TestZerologLoggerLevelBoundaryTesting(t *testing.T) {
 
 	os.Remove(logFile)
 }
+
+// closeRecorder is a bytes.Buffer that records being closed
+type closeRecorder struct {
+	bytes.Buffer
+	closed int
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestZerologLoggerCloseWriter(t *testing.T) {
+	writer := &closeRecorder{}
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		Writer:      writer,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	logger.Info("Test message before close")
+	logger.Close()
+	logger.Close()
+	if writer.closed != 1 || !strings.Contains(writer.String(), "Test message before close") {
+		t.Errorf("Expected the writer written and closed once, got %d closes and %q", writer.closed, writer.String())
+	}
+
+	stdout, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		Writer:      os.Stdout,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+	})
+	if err != nil {
+		t.Fatalf(newLoggerErrorFmt, err)
+	}
+	stdout.Close()
+	if _, err := os.Stdout.Stat(); err != nil {
+		t.Errorf("Expected stdout left open, got %v", err)
+	}
+}
+
+// newBenchmarkLogger returns a logger at InfoLevel discarding its lines
+func newBenchmarkLogger(b *testing.B) *ZerologLogger {
+	b.Helper()
+	logger, err := NewLoggerWithConfig(&LoggerConfig{
+		Level:       InfoLevel,
+		Writer:      io.Discard,
+		LoggerName:  testLoggerName,
+		ServiceName: testServiceName,
+	})
+	if err != nil {
+		b.Fatalf(newLoggerErrorFmt, err)
+	}
+	return logger
+}
+
+func BenchmarkInfowDisabled(b *testing.B) {
+	logger := newBenchmarkLogger(b)
+	logger.SetLevel(WarnLevel)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Infow("Record processed", "topic", "orders", "partition", 3, "offset", int64(42))
+	}
+}
+
+func BenchmarkInfowEnabled(b *testing.B) {
+	logger := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Infow("Record processed", "topic", "orders", "partition", 3, "offset", int64(42))
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	logger := newBenchmarkLogger(b).WithField(FieldComponent, "processing")
+	fields := Fields{"topic": "orders", "partition": 3}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(fields).Info("Record processed")
+	}
+}
@@ -232,8 +232,13 @@ func (c *KafkaConsumer) Subscribe(topics []string) error {
 func (c *KafkaConsumer) Poll(timeout time.Duration) (*Message, error) {
 	kafkaMessage, err := c.consumer.ReadMessage(timeout)
 	if err != nil {
-		if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
-			return nil, nil // Timeout is not an error
+		if kafkaErr, ok := err.(kafka.Error); ok {
+			if kafkaErr.Code() == kafka.ErrTimedOut {
+				return nil, nil // Timeout is not an error
+			}
+			if isFatalConsumerError(kafkaErr) {
+				return nil, fmt.Errorf("%w: %w", ErrFatal, err)
+			}
 		}
 		return nil, err
 	}
@@ -256,6 +261,18 @@ func (c *KafkaConsumer) Poll(timeout time.Duration) (*Message, error) {
 	return message, nil
 }
 
+// isFatalConsumerError reports whether err leaves the consumer unusable:
+// librdkafka fatal errors and authentication or authorization failures,
+// which persist until the client is recreated with fresh credentials
+func isFatalConsumerError(err kafka.Error) bool {
+	switch err.Code() {
+	case kafka.ErrAuthentication, kafka.ErrSaslAuthenticationFailed,
+		kafka.ErrTopicAuthorizationFailed, kafka.ErrGroupAuthorizationFailed:
+		return true
+	}
+	return err.IsFatal()
+}
+
 // Commit manually commits the offset
 func (c *KafkaConsumer) Commit(ctx context.Context, message *Message) error {
 	topicPartition := kafka.TopicPartition{
@@ -18,8 +18,24 @@ import (
 var (
 	messageBusDir = "/tmp/cratos-messagebus"
 	globalMutex   = sync.RWMutex{}
+	dirMutex      sync.RWMutex // guards messageBusDir, which new producers and consumers may change
 )
 
+// busDir returns the directory holding the message files
+func busDir() string {
+	dirMutex.RLock()
+	defer dirMutex.RUnlock()
+	return messageBusDir
+}
+
+// setBusDir points the message bus at dir, creating it if needed
+func setBusDir(dir string) {
+	dirMutex.Lock()
+	defer dirMutex.Unlock()
+	messageBusDir = dir
+	os.MkdirAll(dir, 0755)
+}
+
 // init ensures the message bus directory exists
 func init() {
 	os.MkdirAll(messageBusDir, 0755)
@@ -40,9 +56,7 @@ func NewProducer(configPath string) Producer {
 
 	// Update messageBusDir if specified in config
 	if baseDir := GetStringValue(configMap, "local.base.dir", ""); baseDir != "" {
-		messageBusDir = baseDir
-		// Ensure the directory exists
-		os.MkdirAll(messageBusDir, 0755)
+		setBusDir(baseDir)
 	}
 
 	return &LocalProducer{}
@@ -57,7 +71,7 @@ func (p *LocalProducer) Send(ctx context.Context, message *Message) (int32, int6
 	message.Partition = 0 // Single partition for local
 
 	// Create topic directory if it doesn't exist
-	topicDir := filepath.Join(messageBusDir, message.Topic)
+	topicDir := filepath.Join(busDir(), message.Topic)
 	if err := os.MkdirAll(topicDir, 0755); err != nil {
 		return 0, 0, fmt.Errorf("failed to create topic directory: %w", err)
 	}
@@ -123,9 +137,10 @@ func (p *LocalProducer) Close() error {
 
 // LocalConsumer file-based implementation for development
 type LocalConsumer struct {
-	topics   []string
-	lastRead map[string]int64
-	mutex    sync.RWMutex
+	topics    []string
+	lastRead  map[string]int64
+	committed map[string]int64 // Offset of the last message committed per topic
+	mutex     sync.RWMutex
 }
 
 // NewConsumer creates a new local consumer with configuration from YAML file
@@ -139,14 +154,13 @@ func NewConsumer(configPath string, cgroup string) Consumer {
 
 	// Create local consumer with configuration
 	consumer := &LocalConsumer{
-		lastRead: make(map[string]int64),
+		lastRead:  make(map[string]int64),
+		committed: make(map[string]int64),
 	}
 
 	// Update messageBusDir if specified in config
 	if baseDir := GetStringValue(configMap, "local.base.dir", ""); baseDir != "" {
-		messageBusDir = baseDir
-		// Ensure the directory exists
-		os.MkdirAll(messageBusDir, 0755)
+		setBusDir(baseDir)
 	}
 
 	return consumer
@@ -175,7 +189,7 @@ func (c *LocalConsumer) Poll(timeout time.Duration) (*Message, error) {
 		c.mutex.Lock() // Use Lock instead of RLock since we might modify lastRead
 
 		for _, topic := range c.topics {
-			topicDir := filepath.Join(messageBusDir, topic)
+			topicDir := filepath.Join(busDir(), topic)
 
 			// Check if topic directory exists
 			if _, err := os.Stat(topicDir); os.IsNotExist(err) {
@@ -237,12 +251,24 @@ func (c *LocalConsumer) Poll(timeout time.Duration) (*Message, error) {
 	}
 }
 
-// Commit commits the offset (no-op for local)
+// Commit records the offset of message as committed. Local consumers always
+// start from the beginning of a topic, so it only serves Committed.
 func (c *LocalConsumer) Commit(ctx context.Context, message *Message) error {
-	// Local implementation doesn't need actual commit
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.committed[message.Topic] = message.Offset
 	return nil
 }
 
+// Committed returns the offset of the last message committed on topic, and
+// false if none has been
+func (c *LocalConsumer) Committed(topic string) (int64, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	offset, ok := c.committed[topic]
+	return offset, ok
+}
+
 // Close closes the local consumer
 func (c *LocalConsumer) Close() error {
 	return nil
@@ -250,19 +276,20 @@ func (c *LocalConsumer) Close() error {
 
 // CleanupMessageBus removes all message files (useful for development)
 func CleanupMessageBus() error {
-	return os.RemoveAll(messageBusDir)
+	return os.RemoveAll(busDir())
 }
 
 // GetMessageBusStats returns statistics about the message bus
 func GetMessageBusStats() map[string]interface{} {
 	stats := make(map[string]interface{})
+	dir := busDir()
 
-	if _, err := os.Stat(messageBusDir); os.IsNotExist(err) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		stats["status"] = "no_messages"
 		return stats
 	}
 
-	topics, err := os.ReadDir(messageBusDir)
+	topics, err := os.ReadDir(dir)
 	if err != nil {
 		stats["error"] = err.Error()
 		return stats
@@ -271,7 +298,7 @@ func GetMessageBusStats() map[string]interface{} {
 	topicStats := make(map[string]int)
 	for _, topic := range topics {
 		if topic.IsDir() {
-			topicDir := filepath.Join(messageBusDir, topic.Name())
+			topicDir := filepath.Join(dir, topic.Name())
 			files, err := os.ReadDir(topicDir)
 			if err == nil {
 				topicStats[topic.Name()] = len(files)
@@ -280,6 +307,6 @@ func GetMessageBusStats() map[string]interface{} {
 	}
 
 	stats["topics"] = topicStats
-	stats["message_bus_dir"] = messageBusDir
+	stats["message_bus_dir"] = dir
 	return stats
 }
@@ -131,8 +131,16 @@ func TestLocalConsumer_Commit(t *testing.T) {
 		Partition: 0,
 	}
 
+	local := consumer.(*LocalConsumer)
+	_, ok := local.Committed("test-topic")
+	assert.False(t, ok)
+
 	err := consumer.Commit(ctx, message)
-	assert.NoError(t, err) // Should be no-op for local implementation
+	assert.NoError(t, err)
+
+	offset, ok := local.Committed("test-topic")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), offset)
 }
 
 // MockProducer for testing edge cases
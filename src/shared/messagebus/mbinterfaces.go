@@ -2,9 +2,15 @@ package messagebus
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrFatal is wrapped by consumer errors that retrying on the same client
+// cannot fix, such as failed authentication; the client has to be recreated.
+// Check for it with errors.Is.
+var ErrFatal = errors.New("fatal message bus error")
+
 // Message represents a message in the message bus
 type Message struct {
 	Topic     string            `json:"topic"`
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"crypto/rand"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // GetEnv gets an environment variable with a default value
@@ -22,3 +25,55 @@ func GetEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// GetEnvFloat gets a floating point environment variable with a default value
+func GetEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// GetEnvBool gets a boolean environment variable with a default value.
+// Accepts the values understood by strconv.ParseBool (1, t, true, 0, f, false, ...)
+func GetEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// GetEnvOrFile gets a secret from the file named by the key+"_FILE"
+// environment variable, e.g. SERVER_API_KEYS_FILE, as used for Docker and
+// Kubernetes mounted secrets. The file contents are trimmed of surrounding
+// whitespace and take precedence over the plain key. When the file cannot be
+// read or is empty a warning is written to stderr and the lookup falls back
+// to GetEnv(key, defaultValue).
+func GetEnvOrFile(key, defaultValue string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: cannot read %s_FILE: %v\n", key, err)
+		} else if value := strings.TrimSpace(string(data)); value != "" {
+			return value
+		} else {
+			fmt.Fprintf(os.Stderr, "WARNING: %s_FILE %s is empty\n", key, path)
+		}
+	}
+	return GetEnv(key, defaultValue)
+}
+
+// NewUUID generates a random (version 4) UUID string
+func NewUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -1 +1,100 @@
 package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+var uuidV4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewUUID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := NewUUID()
+		if err != nil {
+			t.Fatalf("NewUUID() error = %v", err)
+		}
+		if !uuidV4Pattern.MatchString(id) {
+			t.Errorf("NewUUID() = %q, not a valid v4 UUID", id)
+		}
+		if seen[id] {
+			t.Errorf("NewUUID() returned duplicate %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	testCases := []struct {
+		name         string
+		value        string
+		defaultValue bool
+		expected     bool
+	}{
+		{"unset uses default", "", true, true},
+		{"true value", "true", false, true},
+		{"numeric false", "0", true, false},
+		{"invalid uses default", "maybe", true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("UTILS_TEST_BOOL", tc.value)
+			if got := GetEnvBool("UTILS_TEST_BOOL", tc.defaultValue); got != tc.expected {
+				t.Errorf("GetEnvBool() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	t.Setenv("UTILS_TEST_FLOAT", "2.5")
+	if got := GetEnvFloat("UTILS_TEST_FLOAT", 1); got != 2.5 {
+		t.Errorf("GetEnvFloat() = %v, want 2.5", got)
+	}
+
+	t.Setenv("UTILS_TEST_FLOAT", "not-a-number")
+	if got := GetEnvFloat("UTILS_TEST_FLOAT", 1); got != 1 {
+		t.Errorf("GetEnvFloat() with invalid value = %v, want default 1", got)
+	}
+}
+
+func TestGetEnvOrFile(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretFile, []byte("  from-file\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatalf("Failed to write empty file: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		value    string
+		file     string
+		expected string
+	}{
+		{name: "default when nothing set", expected: "default"},
+		{name: "plain env var", value: "from-env", expected: "from-env"},
+		{name: "file takes precedence over env var", value: "from-env", file: secretFile, expected: "from-file"},
+		{name: "file without env var", file: secretFile, expected: "from-file"},
+		{name: "missing file falls back to env var", value: "from-env", file: filepath.Join(dir, "missing"), expected: "from-env"},
+		{name: "missing file falls back to default", file: filepath.Join(dir, "missing"), expected: "default"},
+		{name: "empty file falls back to env var", value: "from-env", file: emptyFile, expected: "from-env"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TEST_SECRET", tc.value)
+			t.Setenv("TEST_SECRET_FILE", tc.file)
+
+			if got := GetEnvOrFile("TEST_SECRET", "default"); got != tc.expected {
+				t.Errorf("GetEnvOrFile() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
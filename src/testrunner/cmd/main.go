@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"sharedgomodule/buildinfo"
 	"sharedgomodule/messagebus"
 	"sharedgomodule/utils"
 	"testgomodule/internal/config"
@@ -46,7 +47,7 @@ func main() {
 		return
 	}
 
-	log.Printf("Starting Cratos Test Runner...")
+	log.Printf("Starting Cratos Test Runner %s...", buildinfo.VersionString())
 
 	// Load configuration from centralized location using SERVICE_HOME
 	homeDir := os.Getenv("SERVICE_HOME")
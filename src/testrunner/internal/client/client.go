@@ -2,9 +2,11 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 )
@@ -33,20 +35,75 @@ const (
 	defaultTimeout  = 30 * time.Second
 )
 
+// unixSocketBaseURL is the base URL used for requests sent over a Unix
+// socket; the host is ignored by the socket transport
+const unixSocketBaseURL = "http://unix"
+
 // Client represents a client for the service API
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	transport  http.RoundTripper // Transport wrapped by SetAPIKey; nil means http.DefaultTransport
+}
+
+// apiKeyTransport adds the API key to every outgoing request
+type apiKeyTransport struct {
+	apiKey string
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", "Bearer "+t.apiKey)
+	return t.base.RoundTrip(authReq)
 }
 
 // NewClient creates a new API client
 func NewClient(baseURL string) *Client {
+	return NewClientWithHTTPClient(baseURL, &http.Client{
+		Timeout: defaultTimeout,
+	})
+}
+
+// NewClientWithHTTPClient creates an API client that sends requests through
+// the given http.Client, e.g. one with a custom transport
+func NewClientWithHTTPClient(baseURL string, httpClient *http.Client) *Client {
 	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		transport:  httpClient.Transport,
+	}
+}
+
+// NewUnixSocketClient creates an API client that talks to a service
+// listening on the Unix domain socket at socketPath
+func NewUnixSocketClient(socketPath string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
 		},
 	}
+	return NewClientWithHTTPClient(unixSocketBaseURL, &http.Client{
+		Timeout:   defaultTimeout,
+		Transport: transport,
+	})
+}
+
+// SetAPIKey sets the API key sent as a bearer token on every request.
+// An empty key removes authentication from subsequent requests.
+func (c *Client) SetAPIKey(apiKey string) {
+	if apiKey == "" {
+		c.httpClient.Transport = c.transport
+		return
+	}
+	base := c.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.httpClient.Transport = &apiKeyTransport{apiKey: apiKey, base: base}
 }
 
 // User represents a user response from the API
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"sharedgomodule/utils"
 	"testgomodule/internal/client"
 )
 
@@ -24,10 +25,19 @@ type TestSuite struct {
 	results []TestResult
 }
 
-// NewTestSuite creates a new test suite
+// NewTestSuite creates a new test suite. When TEST_API_KEY is set, the
+// client authenticates with it against the service's /api/ routes. When
+// TEST_SERVICE_SOCKET is set, requests go over that Unix socket instead of
+// to serviceURL.
 func NewTestSuite(serviceURL string) *TestSuite {
+	apiClient := client.NewClient(serviceURL)
+	if socketPath := utils.GetEnv("TEST_SERVICE_SOCKET", ""); socketPath != "" {
+		apiClient = client.NewUnixSocketClient(socketPath)
+	}
+	apiClient.SetAPIKey(utils.GetEnvOrFile("TEST_API_KEY", ""))
+
 	return &TestSuite{
-		client:  client.NewClient(serviceURL),
+		client:  apiClient,
 		results: make([]TestResult, 0),
 	}
 }